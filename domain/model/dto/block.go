@@ -1,4 +1,4 @@
-﻿// MIT License
+// MIT License
 
 // Copyright (c) 2024 ISSuh
 
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/internal/encryption"
 )
 
 type BlockHeaders []BlockHeader
@@ -34,6 +35,15 @@ func (h BlockHeaders) Empty() bool {
 	return len(h) == 0
 }
 
+// Size is the size of the content in the blocks.
+func (h BlockHeaders) Size() int {
+	size := 0
+	for _, header := range h {
+		size += header.ContentSize()
+	}
+	return size
+}
+
 func (h BlockHeaders) ToEntity() entity.BlockHeaders {
 	headers := make(entity.BlockHeaders, 0, len(h))
 	for _, header := range h {
@@ -49,6 +59,18 @@ type BlockHeader struct {
 	Size      int             `json:"size"`
 	Timestamp time.Time       `json:"timestamp"`
 	Checksum  uint32          `json:"-"`
+	Replicas  entity.Nodes    `json:"-"`
+
+	ErasureCoding entity.ErasureCoding `json:"-"`
+	Shards        entity.Shards        `json:"-"`
+	Hash          string               `json:"-"`
+	// CustomerKeyed blocks are sealed with the key of the client by the explorer, and DataKeySealed ones
+	// with the data key of the version by the node storing them. The size and checksum are of the sealed data.
+	CustomerKeyed bool `json:"-"`
+	DataKeySealed bool `json:"-"`
+	// Compression is the codec the block is stored with, UncompressedSize the size of its content
+	Compression      string `json:"-"`
+	UncompressedSize int    `json:"-"`
 }
 
 func NewBlockHeaderFromModel(h entity.BlockHeader) BlockHeader {
@@ -59,15 +81,34 @@ func NewBlockHeaderFromModel(h entity.BlockHeader) BlockHeader {
 		Size:      h.Size(),
 		Checksum:  h.Checksum(),
 		Timestamp: h.Timestamp(),
+		Replicas:  h.Replicas(),
+
+		ErasureCoding: h.ErasureCoding(),
+		Shards:        h.Shards(),
+		Hash:          h.Hash(),
+		CustomerKeyed: h.CustomerKeyed(),
+		DataKeySealed: h.DataKeySealed(),
+
+		Compression:      h.Compression(),
+		UncompressedSize: h.UncompressedSize(),
 	}
 }
 
+func NewBlockHeadersFromModel(headers entity.BlockHeaders) BlockHeaders {
+	blockHeaders := make(BlockHeaders, 0, len(headers))
+	for _, header := range headers {
+		blockHeaders = append(blockHeaders, NewBlockHeaderFromModel(header))
+	}
+	return blockHeaders
+}
+
 func NewEmptyBlockHeader() BlockHeader {
 	return BlockHeader{}
 }
 
 func (d BlockHeader) Empty() bool {
-	return d == BlockHeader{}
+	return !d.BlockID.IsValid() && !d.ObjectID.IsValid() && d.Index == 0 && d.Size == 0 &&
+		d.Timestamp.IsZero() && d.Checksum == 0 && d.Replicas.Empty() && d.Shards.Empty()
 }
 
 func (d BlockHeader) ToEntity() entity.BlockHeader {
@@ -78,9 +119,33 @@ func (d BlockHeader) ToEntity() entity.BlockHeader {
 		Size(d.Size).
 		Timestamp(d.Timestamp).
 		Checksum(d.Checksum).
+		Replicas(d.Replicas).
+		ErasureCoding(d.ErasureCoding).
+		Shards(d.Shards).
+		Hash(d.Hash).
+		CustomerKeyed(d.CustomerKeyed).
+		DataKeySealed(d.DataKeySealed).
+		Compression(d.Compression).
+		UncompressedSize(d.UncompressedSize).
 		Build()
 }
 
+// ContentSize is the size of the content in the block, as it was before it was compressed or sealed.
+func (d BlockHeader) ContentSize() int {
+	if d.Compression != "" {
+		return d.UncompressedSize
+	}
+
+	size := d.Size
+	if d.CustomerKeyed {
+		size -= encryption.Overhead
+	}
+	if d.DataKeySealed {
+		size -= encryption.Overhead
+	}
+	return size
+}
+
 type Block struct {
 	Header BlockHeader
 	Data   []byte
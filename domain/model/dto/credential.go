@@ -0,0 +1,57 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dto
+
+import (
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+)
+
+type Credential struct {
+	AccessKey  string    `json:"access_key"`
+	SecretKey  string    `json:"-"`
+	Principal  string    `json:"principal"`
+	CreatedAt  time.Time `json:"created_at"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+func NewCredentialFromModel(c *entity.Credential) *Credential {
+	return &Credential{
+		AccessKey:  c.AccessKey(),
+		SecretKey:  c.SecretKey(),
+		Principal:  c.Principal(),
+		CreatedAt:  c.CreatedAt,
+		ModifiedAt: c.ModifiedAt,
+	}
+}
+
+func (d *Credential) ToEntity() entity.Credential {
+	return *entity.NewCredentialBuilder().
+		AccessKey(d.AccessKey).
+		SecretKey(d.SecretKey).
+		Principal(d.Principal).
+		CreatedAt(d.CreatedAt).
+		ModifiedAt(d.ModifiedAt).
+		Build()
+}
@@ -0,0 +1,63 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dto
+
+// DedupStats counts the deduplicated blocks. LogicalBytes is what the references would take
+// without deduplication and Ratio is LogicalBytes over PhysicalBytes.
+type DedupStats struct {
+	Blocks        int     `json:"blocks"`
+	References    int64   `json:"references"`
+	PhysicalBytes int64   `json:"physical_bytes"`
+	LogicalBytes  int64   `json:"logical_bytes"`
+	Ratio         float64 `json:"ratio"`
+}
+
+func (s *DedupStats) Add(stats DedupStats) {
+	s.Blocks += stats.Blocks
+	s.References += stats.References
+	s.PhysicalBytes += stats.PhysicalBytes
+	s.LogicalBytes += stats.LogicalBytes
+	s.Ratio = s.ratio()
+}
+
+func (s DedupStats) ratio() float64 {
+	if s.PhysicalBytes == 0 {
+		return 0
+	}
+	return float64(s.LogicalBytes) / float64(s.PhysicalBytes)
+}
+
+// DedupReport is the deduplication of the whole cluster and of every block storage node. Excluded
+// lists the objects that are stored without deduplication even though it is enabled.
+type DedupReport struct {
+	Enabled  bool            `json:"enabled"`
+	Excluded []string        `json:"excluded"`
+	Total    DedupStats      `json:"total"`
+	Nodes    []DedupNodeStat `json:"nodes"`
+	Errors   []string        `json:"errors"`
+}
+
+type DedupNodeStat struct {
+	Node  string     `json:"node"`
+	Stats DedupStats `json:"stats"`
+}
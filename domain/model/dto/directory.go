@@ -0,0 +1,51 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dto
+
+import (
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+)
+
+type Directory struct {
+	Group          string            `json:"group"`
+	Partition      string            `json:"partition"`
+	Path           string            `json:"path"`
+	Objects        []entity.ObjectID `json:"objects"`
+	SubDirectories []string          `json:"sub_directories"`
+	CreatedAt      time.Time         `json:"created_at"`
+	ModifiedAt     time.Time         `json:"modified_at"`
+}
+
+func NewDirectoryFromModel(d *entity.Directory) *Directory {
+	return &Directory{
+		Group:          d.Group(),
+		Partition:      d.Partition(),
+		Path:           d.Path(),
+		Objects:        d.Objects(),
+		SubDirectories: d.SubDirectory(),
+		CreatedAt:      d.CreatedAt,
+		ModifiedAt:     d.ModifiedAt,
+	}
+}
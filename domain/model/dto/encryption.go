@@ -0,0 +1,58 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dto
+
+import "github.com/ISSuh/sos/internal/encryption"
+
+// RotationReport is the outcome of wrapping the data keys again with the primary master key.
+type RotationReport struct {
+	KeyID             string   `json:"key_id"`
+	ScannedObjects    int      `json:"scanned_objects"`
+	RewrappedVersions int      `json:"rewrapped_versions"`
+	Errors            []string `json:"errors"`
+}
+
+// CustomerKey is the key a client encrypts its object with, as it is sent in the request headers.
+// The key is never stored, so it comes with every request reading or writing the object.
+type CustomerKey struct {
+	Algorithm string
+	Key       string
+	KeyMD5    string
+}
+
+func (k CustomerKey) Empty() bool {
+	return k.Algorithm == "" && k.Key == "" && k.KeyMD5 == ""
+}
+
+// Parse decodes the key and returns it along with the MD5 digest identifying it.
+func (k CustomerKey) Parse() ([]byte, string, error) {
+	return encryption.ParseCustomerKey(k.Algorithm, k.Key, k.KeyMD5)
+}
+
+// String keeps the key out of the logs.
+func (k CustomerKey) String() string {
+	if k.Empty() {
+		return "{}"
+	}
+	return "{Algorithm:" + k.Algorithm + " KeyMD5:" + k.KeyMD5 + "}"
+}
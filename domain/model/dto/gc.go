@@ -0,0 +1,61 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dto
+
+import (
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+)
+
+// GarbageReport is the result of a garbage collection. Orphans lists the first unreferenced
+// blocks found while the counters cover all of them. MiscountedContents is the referenced
+// deduplicated content whose reference count differs from the references in metadata.
+type GarbageReport struct {
+	DryRun             bool         `json:"dry_run"`
+	GracePeriod        string       `json:"grace_period"`
+	StartedAt          time.Time    `json:"started_at"`
+	FinishedAt         time.Time    `json:"finished_at"`
+	ScannedBlocks      int          `json:"scanned_blocks"`
+	OrphanBlocks       int          `json:"orphan_blocks"`
+	OrphanBytes        int64        `json:"orphan_bytes"`
+	DeletedBlocks      int          `json:"deleted_blocks"`
+	MiscountedContents int          `json:"miscounted_contents"`
+	ReconciledContents int          `json:"reconciled_contents"`
+	Orphans            OrphanBlocks `json:"orphans"`
+	Errors             []string     `json:"errors"`
+}
+
+type OrphanBlocks []OrphanBlock
+
+type OrphanBlock struct {
+	Node      string          `json:"node"`
+	ObjectID  entity.ObjectID `json:"object_id"`
+	BlockID   entity.BlockID  `json:"block_id"`
+	Index     int             `json:"index"`
+	Hash      string          `json:"hash,omitempty"`
+	Size      int             `json:"size"`
+	Timestamp time.Time       `json:"timestamp"`
+	Deleted   bool            `json:"deleted"`
+	Error     string          `json:"error,omitempty"`
+}
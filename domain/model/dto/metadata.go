@@ -37,6 +37,7 @@ type Metadata struct {
 	Name       string          `json:"name"`
 	Path       string          `json:"path"`
 	Versions   Versions        `json:"versions"`
+	Tags       entity.Tags     `json:"tags,omitempty"`
 	CreatedAt  time.Time       `json:"created_at"`
 	ModifiedAt time.Time       `json:"modified_at"`
 }
@@ -49,6 +50,7 @@ func NewMetadataFromModel(m *entity.ObjectMetadata) *Metadata {
 		Name:       m.Name(),
 		Path:       m.Path(),
 		Versions:   NewVersionsFromModel(m.Versions()),
+		Tags:       m.Tags(),
 		CreatedAt:  m.CreatedAt,
 		ModifiedAt: m.ModifiedAt,
 	}
@@ -66,6 +68,7 @@ func (d *Metadata) ToEntity() entity.ObjectMetadata {
 		Name(d.Name).
 		Path(d.Path).
 		Versions(d.Versions.ToEntity()).
+		Tags(d.Tags).
 		CreatedAt(d.CreatedAt).
 		ModifiedAt(d.ModifiedAt).
 		Build()
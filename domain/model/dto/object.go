@@ -23,6 +23,8 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/ISSuh/sos/domain/model/entity"
 )
 
@@ -35,6 +37,16 @@ type Object struct {
 	Size         int             `json:"size"`
 	VersionNum   int             `json:"version"`
 	BlockHeaders BlockHeaders    `json:"block_headers"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+	ETag         string          `json:"etag"`
+
+	// IfMatch and IfNoneMatch are the preconditions the current version must meet to commit.
+	IfMatch     string `json:"-"`
+	IfNoneMatch string `json:"-"`
+
+	entity.ContentMetadata
+
+	Encryption entity.Encryption `json:"-"`
 }
 
 func (o *Object) ToEntity() entity.ObjectMetadata {
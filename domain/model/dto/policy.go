@@ -0,0 +1,158 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/internal/validation"
+)
+
+type Policies []Policy
+
+type Policy struct {
+	Group      string           `json:"group"`
+	Partition  string           `json:"partition,omitempty"`
+	Statements PolicyStatements `json:"statements"`
+	CreatedAt  time.Time        `json:"created_at"`
+	ModifiedAt time.Time        `json:"modified_at"`
+}
+
+func NewPolicyFromModel(p *entity.Policy) *Policy {
+	statements := make(PolicyStatements, 0, len(p.Statements()))
+	for _, statement := range p.Statements() {
+		statements = append(statements, NewPolicyStatementFromModel(&statement))
+	}
+
+	return &Policy{
+		Group:      p.Group(),
+		Partition:  p.Partition(),
+		Statements: statements,
+		CreatedAt:  p.CreatedAt,
+		ModifiedAt: p.ModifiedAt,
+	}
+}
+
+func (d *Policy) Validate() error {
+	switch {
+	case validation.IsEmpty(d.Group):
+		return errors.New("group is empty")
+	case len(d.Statements) == 0:
+		return errors.New("policy has no statements")
+	}
+
+	for i, statement := range d.Statements {
+		if err := statement.Validate(); err != nil {
+			return fmt.Errorf("statement %d is invalid. %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (d *Policy) ToEntity() entity.Policy {
+	return *entity.NewPolicyBuilder().
+		Group(d.Group).
+		Partition(d.Partition).
+		Statements(d.Statements.ToEntity()).
+		CreatedAt(d.CreatedAt).
+		ModifiedAt(d.ModifiedAt).
+		Build()
+}
+
+type PolicyStatements []PolicyStatement
+
+func (s PolicyStatements) ToEntity() entity.PolicyStatements {
+	statements := make(entity.PolicyStatements, 0, len(s))
+	for _, statement := range s {
+		statements = append(statements, statement.ToEntity())
+	}
+	return statements
+}
+
+// PolicyStatement applies its effect to the actions of its principals on the resources
+// under prefix. A prefix matches whole path segments, so a/b applies to a/b and a/b/c but not
+// to a/bc, while a/b/ applies only below a/b. An empty prefix applies to the whole group or partition.
+type PolicyStatement struct {
+	Effect     entity.PolicyEffect   `json:"effect"`
+	Principals []string              `json:"principals"`
+	Actions    []entity.PolicyAction `json:"actions"`
+	Prefix     string                `json:"prefix,omitempty"`
+}
+
+func NewPolicyStatementFromModel(s *entity.PolicyStatement) PolicyStatement {
+	return PolicyStatement{
+		Effect:     s.Effect(),
+		Principals: s.Principals(),
+		Actions:    s.Actions(),
+		Prefix:     s.Prefix(),
+	}
+}
+
+func (d PolicyStatement) Validate() error {
+	switch {
+	case !d.Effect.IsValid():
+		return fmt.Errorf("effect %s is invalid", d.Effect)
+	case len(d.Principals) == 0:
+		return errors.New("principals are empty")
+	case len(d.Actions) == 0:
+		return errors.New("actions are empty")
+	}
+
+	for _, action := range d.Actions {
+		if !action.IsValid() {
+			return fmt.Errorf("action %s is invalid", action)
+		}
+	}
+	return nil
+}
+
+func (d PolicyStatement) Matches(principal string, action entity.PolicyAction, resource string) bool {
+	switch {
+	case !slices.Contains(d.Principals, principal) && !slices.Contains(d.Principals, entity.PolicyPrincipalAll):
+		return false
+	case !slices.Contains(d.Actions, action) && !slices.Contains(d.Actions, entity.PolicyActionAll):
+		return false
+	}
+
+	prefix := entity.CleanPolicyPrefix(d.Prefix)
+	if !strings.HasPrefix(resource, prefix) {
+		return false
+	}
+
+	rest := resource[len(prefix):]
+	return prefix == "" || rest == "" || strings.HasSuffix(prefix, "/") || strings.HasPrefix(rest, "/")
+}
+
+func (d PolicyStatement) ToEntity() entity.PolicyStatement {
+	return *entity.NewPolicyStatementBuilder().
+		Effect(d.Effect).
+		Principals(d.Principals).
+		Actions(d.Actions).
+		Prefix(d.Prefix).
+		Build()
+}
@@ -0,0 +1,94 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package dto
+
+import (
+	"testing"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+)
+
+func TestPolicyStatementMatches(t *testing.T) {
+	alice := PolicyStatement{
+		Effect:     entity.PolicyEffectAllow,
+		Principals: []string{"alice"},
+		Actions:    []entity.PolicyAction{entity.PolicyActionGet, entity.PolicyActionList},
+		Prefix:     "a/b",
+	}
+	anyone := PolicyStatement{
+		Effect:     entity.PolicyEffectAllow,
+		Principals: []string{entity.PolicyPrincipalAll},
+		Actions:    []entity.PolicyAction{entity.PolicyActionAll},
+	}
+
+	tests := []struct {
+		name      string
+		statement PolicyStatement
+		principal string
+		action    entity.PolicyAction
+		resource  string
+		want      bool
+	}{
+		{"object named by the prefix", alice, "alice", entity.PolicyActionGet, "a/b", true},
+		{"object below the prefix", alice, "alice", entity.PolicyActionGet, "a/b/c.txt", true},
+		{"listed directory of the prefix", alice, "alice", entity.PolicyActionList, "a/b/", true},
+		{"sibling sharing the prefix", alice, "alice", entity.PolicyActionGet, "a/bc/d.txt", false},
+		{"object sharing the prefix", alice, "alice", entity.PolicyActionGet, "a/b.txt", false},
+		{"parent of the prefix", alice, "alice", entity.PolicyActionList, "a/", false},
+		{"other principal", alice, "bob", entity.PolicyActionGet, "a/b/c.txt", false},
+		{"other action", alice, "alice", entity.PolicyActionPut, "a/b/c.txt", false},
+		{"any principal and action", anyone, "bob", entity.PolicyActionDeleteVersion, "x/y", true},
+		{"empty prefix on the root", anyone, "bob", entity.PolicyActionList, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.statement.Matches(test.principal, test.action, test.resource); got != test.want {
+				t.Errorf("Matches(%s, %s, %q) = %v, want %v", test.principal, test.action, test.resource, got, test.want)
+			}
+		})
+	}
+}
+
+// A prefix ending with a slash covers what is below the directory, and a leading slash is ignored
+// as resources are relative to the partition root.
+func TestPolicyStatementMatchesDirectoryPrefix(t *testing.T) {
+	statement := PolicyStatement{
+		Effect:     entity.PolicyEffectDeny,
+		Principals: []string{entity.PolicyPrincipalAll},
+		Actions:    []entity.PolicyAction{entity.PolicyActionAll},
+		Prefix:     "/private/",
+	}
+
+	for resource, want := range map[string]bool{
+		"private/":         true,
+		"private/key.pem":  true,
+		"private/a/b":      true,
+		"private":          false,
+		"privateer/a.txt":  false,
+		"public/private/a": false,
+	} {
+		if got := statement.Matches("alice", entity.PolicyActionGet, resource); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", resource, got, want)
+		}
+	}
+}
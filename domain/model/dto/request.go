@@ -24,20 +24,38 @@ package dto
 
 import (
 	"context"
+	"time"
 
 	"github.com/ISSuh/sos/domain/model/entity"
 )
 
 type Request struct {
-	ObjectID     entity.ObjectID
-	Group        string
-	Partition    string
-	Path         string
-	Name         string
-	Size         int
-	Version      int
-	Limit        int
-	LastObjectID entity.ObjectID
+	ObjectID        entity.ObjectID
+	Group           string
+	Partition       string
+	Path            string
+	Name            string
+	Size            int
+	Version         int
+	Limit           int
+	LastObjectID    entity.ObjectID
+	Range           string
+	IfRange         string
+	IfMatch         string
+	IfNoneMatch     string
+	IfModifiedSince string
+	UploadID        entity.UploadID
+	PartNumber      int
+	Method          string
+	Expiry          time.Duration
+	DryRun          bool
+	Tags            entity.Tags
+	TagExpression   []string
+	Destination     Destination
+	AllVersions     bool
+	CustomerKey     CustomerKey
+
+	entity.ContentMetadata
 }
 
 func RequestFromContext(c context.Context, key any) Request {
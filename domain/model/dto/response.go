@@ -45,6 +45,7 @@ type Item struct {
 	Path       string          `json:"path"`
 	Name       string          `json:"name"`
 	Versions   Versions        `json:"versions"`
+	Tags       entity.Tags     `json:"tags,omitempty"`
 	CreatedAt  time.Time       `json:"created_at"`
 	ModifiedAt time.Time       `json:"modified_at"`
 }
@@ -57,6 +58,7 @@ func NewItemFromMetadata(m Metadata) Item {
 		Path:       m.Path,
 		Name:       m.Name,
 		Versions:   m.Versions,
+		Tags:       m.Tags,
 		CreatedAt:  m.CreatedAt,
 		ModifiedAt: m.ModifiedAt,
 	}
@@ -65,3 +67,26 @@ func NewItemFromMetadata(m Metadata) Item {
 func (i Item) Empty() bool {
 	return i.ID == 0
 }
+
+// ItemsPage is one page of a listing. NextCursor is empty on the last page.
+type ItemsPage struct {
+	Items      Items           `json:"items"`
+	NextCursor entity.ObjectID `json:"next_cursor,omitempty"`
+}
+
+// Children is the listing of a directory, its sub directories by name and the objects on it.
+type Children struct {
+	Group       string          `json:"group"`
+	Partition   string          `json:"partition"`
+	Path        string          `json:"path"`
+	Directories []string        `json:"directories"`
+	Items       Items           `json:"items"`
+	NextCursor  entity.ObjectID `json:"next_cursor,omitempty"`
+}
+
+// PresignedURL downloads or uploads an object with Method and no credential until ExpiresAt.
+type PresignedURL struct {
+	Method    string    `json:"method"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,65 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dto
+
+import (
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+)
+
+type ScrubStatus struct {
+	Running     bool             `json:"running"`
+	Passes      int              `json:"passes"`
+	Current     ScrubPass        `json:"current"`
+	Last        ScrubPass        `json:"last"`
+	Corruptions ScrubCorruptions `json:"corruptions"`
+}
+
+type ScrubPass struct {
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	ScannedBlocks   int       `json:"scanned_blocks"`
+	ScannedBytes    int64     `json:"scanned_bytes"`
+	CorruptedBlocks int       `json:"corrupted_blocks"`
+	RepairedBlocks  int       `json:"repaired_blocks"`
+	Error           string    `json:"error"`
+}
+
+type ScrubCorruptions []ScrubCorruption
+
+// ScrubCorruption is a block whose stored data did not match its checksum. Error is set
+// when the block could not be quarantined or repaired. Deduplicated content is identified
+// by its Hash alone.
+type ScrubCorruption struct {
+	ObjectID     entity.ObjectID `json:"object_id"`
+	BlockID      entity.BlockID  `json:"block_id"`
+	Index        int             `json:"index"`
+	Hash         string          `json:"hash,omitempty"`
+	Reason       string          `json:"reason"`
+	Quarantined  bool            `json:"quarantined"`
+	Repaired     bool            `json:"repaired"`
+	RepairedFrom string          `json:"repaired_from"`
+	Error        string          `json:"error"`
+	DetectedAt   time.Time       `json:"detected_at"`
+}
@@ -22,5 +22,168 @@
 
 package dto
 
-type Upload struct {
+import (
+	"fmt"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/internal/checksum"
+)
+
+type UploadSessions []UploadSession
+
+func NewUploadSessionsFromModel(s entity.UploadSessions) UploadSessions {
+	sessions := make(UploadSessions, 0, len(s))
+	for _, session := range s {
+		sessions = append(sessions, *NewUploadSessionFromModel(&session))
+	}
+	return sessions
+}
+
+type UploadSession struct {
+	ID         entity.UploadID `json:"upload_id"`
+	ObjectID   entity.ObjectID `json:"object_id"`
+	Group      string          `json:"group"`
+	Partition  string          `json:"partition"`
+	Path       string          `json:"path"`
+	Name       string          `json:"name"`
+	Version    int             `json:"version"`
+	Parts      UploadParts     `json:"parts"`
+	ExpiresAt  time.Time       `json:"expires_at"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ModifiedAt time.Time       `json:"modified_at"`
+
+	entity.ContentMetadata
+
+	Encryption entity.Encryption `json:"-"`
+}
+
+func NewUploadSessionFromModel(s *entity.UploadSession) *UploadSession {
+	parts := make(UploadParts, 0, len(s.Parts()))
+	for _, part := range s.Parts() {
+		parts = append(parts, NewUploadPartFromModel(part))
+	}
+
+	return &UploadSession{
+		ID:         s.ID(),
+		ObjectID:   s.ObjectID(),
+		Group:      s.Group(),
+		Partition:  s.Partition(),
+		Path:       s.Path(),
+		Name:       s.Name(),
+		Version:    s.Version(),
+		Parts:      parts,
+		ExpiresAt:  s.ExpiresAt(),
+		CreatedAt:  s.CreatedAt,
+		ModifiedAt: s.ModifiedAt,
+
+		ContentMetadata: s.Content(),
+		Encryption:      s.Encryption(),
+	}
+}
+
+func (d *UploadSession) IsExpired(now time.Time) bool {
+	return !now.Before(d.ExpiresAt)
+}
+
+func (d *UploadSession) ToEntity() entity.UploadSession {
+	return entity.NewUploadSessionBuilder().
+		ID(d.ID).
+		ObjectID(d.ObjectID).
+		Group(d.Group).
+		Partition(d.Partition).
+		Path(d.Path).
+		Name(d.Name).
+		Version(d.Version).
+		Content(d.ContentMetadata).
+		Encryption(d.Encryption).
+		Parts(d.Parts.ToEntity()).
+		ExpiresAt(d.ExpiresAt).
+		CreatedAt(d.CreatedAt).
+		ModifiedAt(d.ModifiedAt).
+		Build()
+}
+
+type UploadParts []UploadPart
+
+func (p UploadParts) Empty() bool {
+	return len(p) == 0
+}
+
+func (p UploadParts) Part(number int) (UploadPart, bool) {
+	for _, part := range p {
+		if part.Number == number {
+			return part, true
+		}
+	}
+	return UploadPart{}, false
+}
+
+func (p UploadParts) Size() int {
+	size := 0
+	for _, part := range p {
+		size += part.Size
+	}
+	return size
+}
+
+func (p UploadParts) BlockHeaders() BlockHeaders {
+	var headers BlockHeaders
+	for _, part := range p {
+		headers = append(headers, part.BlockHeaders...)
+	}
+	return headers
+}
+
+// ETag combines the checksums of the parts, so it is not the checksum of the whole content.
+func (p UploadParts) ETag() string {
+	checksums := make([]string, 0, len(p))
+	for _, part := range p {
+		checksums = append(checksums, part.Checksum)
+	}
+	return fmt.Sprintf("%s-%d", checksum.Combine(checksums), len(p))
+}
+
+func (p UploadParts) ToEntity() entity.UploadParts {
+	parts := make(entity.UploadParts, 0, len(p))
+	for _, part := range p {
+		parts = append(parts, part.ToEntity())
+	}
+	return parts
+}
+
+type UploadPart struct {
+	Number       int          `json:"part_number"`
+	Size         int          `json:"size"`
+	BlockHeaders BlockHeaders `json:"-"`
+	Checksum     string       `json:"checksum"`
+	CreatedAt    time.Time    `json:"created_at"`
+	ModifiedAt   time.Time    `json:"modified_at"`
+}
+
+func NewUploadPartFromModel(p entity.UploadPart) UploadPart {
+	headers := make(BlockHeaders, 0, len(p.BlockHeaders()))
+	for _, h := range p.BlockHeaders() {
+		headers = append(headers, NewBlockHeaderFromModel(h))
+	}
+
+	return UploadPart{
+		Number:       p.Number(),
+		Size:         p.Size(),
+		BlockHeaders: headers,
+		Checksum:     p.Checksum(),
+		CreatedAt:    p.CreatedAt,
+		ModifiedAt:   p.ModifiedAt,
+	}
+}
+
+func (d *UploadPart) ToEntity() entity.UploadPart {
+	return entity.NewUploadPartBuilder().
+		Number(d.Number).
+		Size(d.Size).
+		BlockHeaders(d.BlockHeaders.ToEntity()).
+		Checksum(d.Checksum).
+		CreatedAt(d.CreatedAt).
+		ModifiedAt(d.ModifiedAt).
+		Build()
 }
@@ -32,9 +32,13 @@ import (
 
 type Versions []Version
 
+// NewVersionsFromModel leaves out the pending versions, which are never visible.
 func NewVersionsFromModel(v entity.Versions) Versions {
 	versions := make(Versions, 0, len(v))
 	for _, version := range v {
+		if version.Pending() {
+			continue
+		}
 		versions = append(versions, NewVersionFromModel(version))
 	}
 	return versions
@@ -81,8 +85,13 @@ type Version struct {
 	Number       int          `json:"number"`
 	Size         int          `json:"size"`
 	BlockHeaders BlockHeaders `json:"-"`
+	ETag         string       `json:"etag"`
 	CreatedAt    time.Time    `json:"created_at"`
 	ModifiedAt   time.Time    `json:"modified_at"`
+
+	entity.ContentMetadata
+
+	Encryption entity.Encryption `json:"-"`
 }
 
 func NewVersionFromModel(v entity.Version) Version {
@@ -95,8 +104,12 @@ func NewVersionFromModel(v entity.Version) Version {
 		Number:       v.Number(),
 		Size:         v.Size(),
 		BlockHeaders: headers,
+		ETag:         v.ETag(),
 		CreatedAt:    v.CreatedAt,
 		ModifiedAt:   v.ModifiedAt,
+
+		ContentMetadata: v.Content(),
+		Encryption:      v.Encryption(),
 	}
 }
 
@@ -106,6 +119,9 @@ func (v *Version) ToEntity() entity.Version {
 		Number(v.Number).
 		Size(v.Size).
 		BlockHeaders(headers).
+		ETag(v.ETag).
+		Content(v.ContentMetadata).
+		Encryption(v.Encryption).
 		CreatedAt(v.CreatedAt).
 		ModifiedAt(v.ModifiedAt).
 		Build()
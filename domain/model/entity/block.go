@@ -28,10 +28,14 @@ import (
 	"errors"
 
 	"github.com/ISSuh/sos/internal/crc"
+	"github.com/ISSuh/sos/internal/encryption"
 )
 
 const (
-	BlockSize = 4 * 1024 * 1024
+	DefaultBlockSize = 4 * 1024 * 1024
+
+	// MaxBlockSize bounds the block size a deployment can configure
+	MaxBlockSize = 64 * 1024 * 1024
 )
 
 type Blocks []Block
@@ -48,7 +52,15 @@ func (b *Block) Validate() error {
 		return err
 	}
 
-	if len(b.buffer) > BlockSize {
+	limit := MaxBlockSize
+	if b.header.CustomerKeyed() {
+		limit += encryption.Overhead
+	}
+	if b.header.DataKeySealed() {
+		limit += encryption.Overhead
+	}
+
+	if len(b.buffer) > limit {
 		return errors.New("block size is too large")
 	}
 
@@ -26,6 +26,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"errors"
+	"io"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -39,8 +40,18 @@ type BlockHeader struct {
 	index     int       `bson:"index"`
 	size      int       `bson:"size"`
 	node      Node      `bson:"node"`
+	replicas  Nodes     `bson:"replicas"`
 	timestamp time.Time `bson:"timestamp"`
 	checksum  uint32    `bson:"checksum"`
+
+	erasureCoding ErasureCoding `bson:"erasure_coding"`
+	shards        Shards        `bson:"shards"`
+	hash          string        `bson:"hash"`
+	customerKeyed bool          `bson:"customer_keyed"`
+	dataKeySealed bool          `bson:"data_key_sealed"`
+
+	compression      string `bson:"compression"`
+	uncompressedSize int    `bson:"uncompressed_size"`
 }
 
 func (b *BlockHeader) BlockID() BlockID {
@@ -63,6 +74,48 @@ func (b *BlockHeader) Node() Node {
 	return b.node
 }
 
+// Replicas returns every node holding a copy of the block, the primary node first.
+func (b *BlockHeader) Replicas() Nodes {
+	return b.replicas
+}
+
+func (b *BlockHeader) ErasureCoding() ErasureCoding {
+	return b.erasureCoding
+}
+
+// Shards returns the erasure coded shards of the block, data shards first.
+func (b *BlockHeader) Shards() Shards {
+	return b.shards
+}
+
+// Hash returns the content hash of a deduplicated block, which is shared by every object holding the
+// same data. It is empty for a block owned by a single object.
+func (b *BlockHeader) Hash() string {
+	return b.hash
+}
+
+// CustomerKeyed reports whether the explorer sealed the data with the key of the client before sending
+// it. The size and the checksum of the block are the ones of the sealed data everywhere.
+func (b *BlockHeader) CustomerKeyed() bool {
+	return b.customerKeyed
+}
+
+// DataKeySealed reports whether the node sealed the stored data with the data key of its version. It
+// is only set on the headers a node stores, whose size and checksum are the ones of the sealed data.
+func (b *BlockHeader) DataKeySealed() bool {
+	return b.dataKeySealed
+}
+
+// Compression returns the codec the stored data is compressed with. It is empty for a block stored raw.
+func (b *BlockHeader) Compression() string {
+	return b.compression
+}
+
+// UncompressedSize returns the size of the data before it was compressed.
+func (b *BlockHeader) UncompressedSize() int {
+	return b.uncompressedSize
+}
+
 func (b *BlockHeader) Timestamp() time.Time {
 	return b.timestamp
 }
@@ -94,16 +147,36 @@ func (b *BlockHeader) MarshalBSON() ([]byte, error) {
 		Index     int       `bson:"index"`
 		Size      int       `bson:"size"`
 		Node      Node      `bson:"node"`
+		Replicas  Nodes     `bson:"replicas"`
 		Timestamp time.Time `bson:"timestamp"`
 		Checksum  uint32    `bson:"checksum"`
+
+		ErasureCoding ErasureCoding `bson:"erasure_coding"`
+		Shards        Shards        `bson:"shards"`
+		Hash          string        `bson:"hash,omitempty"`
+		CustomerKeyed bool          `bson:"customer_keyed,omitempty"`
+		DataKeySealed bool          `bson:"data_key_sealed,omitempty"`
+
+		Compression      string `bson:"compression,omitempty"`
+		UncompressedSize int    `bson:"uncompressed_size,omitempty"`
 	}{
 		BlockID:   b.blockID,
 		ObjectID:  b.objectID,
 		Index:     b.index,
 		Size:      b.size,
 		Node:      b.node,
+		Replicas:  b.replicas,
 		Timestamp: b.timestamp,
 		Checksum:  b.checksum,
+
+		ErasureCoding: b.erasureCoding,
+		Shards:        b.shards,
+		Hash:          b.hash,
+		CustomerKeyed: b.customerKeyed,
+		DataKeySealed: b.dataKeySealed,
+
+		Compression:      b.compression,
+		UncompressedSize: b.uncompressedSize,
 	}
 
 	return bson.Marshal(dto)
@@ -116,8 +189,18 @@ func (b *BlockHeader) UnmarshalBSON(data []byte) error {
 		Index     int       `bson:"index"`
 		Size      int       `bson:"size"`
 		Node      Node      `bson:"node"`
+		Replicas  Nodes     `bson:"replicas"`
 		Timestamp time.Time `bson:"timestamp"`
 		Checksum  uint32    `bson:"checksum"`
+
+		ErasureCoding ErasureCoding `bson:"erasure_coding"`
+		Shards        Shards        `bson:"shards"`
+		Hash          string        `bson:"hash,omitempty"`
+		CustomerKeyed bool          `bson:"customer_keyed,omitempty"`
+		DataKeySealed bool          `bson:"data_key_sealed,omitempty"`
+
+		Compression      string `bson:"compression,omitempty"`
+		UncompressedSize int    `bson:"uncompressed_size,omitempty"`
 	}{}
 
 	if err := bson.Unmarshal(data, &dto); err != nil {
@@ -129,8 +212,16 @@ func (b *BlockHeader) UnmarshalBSON(data []byte) error {
 	b.index = dto.Index
 	b.size = dto.Size
 	b.node = dto.Node
+	b.replicas = dto.Replicas
 	b.timestamp = dto.Timestamp
 	b.checksum = dto.Checksum
+	b.erasureCoding = dto.ErasureCoding
+	b.shards = dto.Shards
+	b.hash = dto.Hash
+	b.customerKeyed = dto.CustomerKeyed
+	b.dataKeySealed = dto.DataKeySealed
+	b.compression = dto.Compression
+	b.uncompressedSize = dto.UncompressedSize
 
 	return nil
 }
@@ -160,6 +251,30 @@ func (b *BlockHeader) GobEncode() ([]byte, error) {
 	if err := enc.Encode(b.checksum); err != nil {
 		return nil, err
 	}
+	if err := enc.Encode(b.replicas); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(b.erasureCoding); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(b.shards); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(b.hash); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(b.customerKeyed); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(b.dataKeySealed); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(b.compression); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(b.uncompressedSize); err != nil {
+		return nil, err
+	}
 	return buffer.Bytes(), nil
 }
 
@@ -188,18 +303,60 @@ func (b *BlockHeader) GobDecode(data []byte) error {
 	if err := dec.Decode(&b.checksum); err != nil {
 		return err
 	}
+	// blocks written by older versions end before the replica and shard layout
+	if err := dec.Decode(&b.replicas); err != nil {
+		return b.ignoreEOF(err)
+	}
+	if err := dec.Decode(&b.erasureCoding); err != nil {
+		return b.ignoreEOF(err)
+	}
+	if err := dec.Decode(&b.shards); err != nil {
+		return b.ignoreEOF(err)
+	}
+	if err := dec.Decode(&b.hash); err != nil {
+		return b.ignoreEOF(err)
+	}
+	if err := dec.Decode(&b.customerKeyed); err != nil {
+		return b.ignoreEOF(err)
+	}
+	if err := dec.Decode(&b.dataKeySealed); err != nil {
+		return b.ignoreEOF(err)
+	}
+	if err := dec.Decode(&b.compression); err != nil {
+		return b.ignoreEOF(err)
+	}
+	if err := dec.Decode(&b.uncompressedSize); err != nil {
+		return b.ignoreEOF(err)
+	}
 
 	return nil
 }
 
+func (b *BlockHeader) ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
 type BlockHeaderBuilder struct {
 	blockID   BlockID
 	objectID  ObjectID
 	index     int
 	size      int
 	node      Node
+	replicas  Nodes
 	timestamp time.Time
 	checksum  uint32
+
+	erasureCoding ErasureCoding
+	shards        Shards
+	hash          string
+	customerKeyed bool
+	dataKeySealed bool
+
+	compression      string
+	uncompressedSize int
 }
 
 func NewBlockHeaderBuilder() *BlockHeaderBuilder {
@@ -231,6 +388,11 @@ func (b *BlockHeaderBuilder) Node(node Node) *BlockHeaderBuilder {
 	return b
 }
 
+func (b *BlockHeaderBuilder) Replicas(replicas Nodes) *BlockHeaderBuilder {
+	b.replicas = replicas
+	return b
+}
+
 func (b *BlockHeaderBuilder) Timestamp(timestamp time.Time) *BlockHeaderBuilder {
 	b.timestamp = timestamp
 	return b
@@ -241,14 +403,64 @@ func (b *BlockHeaderBuilder) Checksum(checksum uint32) *BlockHeaderBuilder {
 	return b
 }
 
+func (b *BlockHeaderBuilder) ErasureCoding(erasureCoding ErasureCoding) *BlockHeaderBuilder {
+	b.erasureCoding = erasureCoding
+	return b
+}
+
+func (b *BlockHeaderBuilder) Shards(shards Shards) *BlockHeaderBuilder {
+	b.shards = shards
+	return b
+}
+
+func (b *BlockHeaderBuilder) Hash(hash string) *BlockHeaderBuilder {
+	b.hash = hash
+	return b
+}
+
+func (b *BlockHeaderBuilder) CustomerKeyed(customerKeyed bool) *BlockHeaderBuilder {
+	b.customerKeyed = customerKeyed
+	return b
+}
+
+func (b *BlockHeaderBuilder) DataKeySealed(dataKeySealed bool) *BlockHeaderBuilder {
+	b.dataKeySealed = dataKeySealed
+	return b
+}
+
+func (b *BlockHeaderBuilder) Compression(compression string) *BlockHeaderBuilder {
+	b.compression = compression
+	return b
+}
+
+func (b *BlockHeaderBuilder) UncompressedSize(uncompressedSize int) *BlockHeaderBuilder {
+	b.uncompressedSize = uncompressedSize
+	return b
+}
+
 func (b *BlockHeaderBuilder) Build() BlockHeader {
+	node := b.node
+	if node.Empty() {
+		node = b.replicas.Primary()
+	}
+
 	return BlockHeader{
 		blockID:   b.blockID,
 		objectID:  b.objectID,
 		index:     b.index,
 		size:      b.size,
-		node:      b.node,
+		node:      node,
+		replicas:  b.replicas,
 		timestamp: b.timestamp,
 		checksum:  b.checksum,
+
+		erasureCoding: b.erasureCoding,
+		shards:        b.shards,
+		hash:          b.hash,
+		customerKeyed: b.customerKeyed,
+		dataKeySealed: b.dataKeySealed,
+
+		compression:      b.compression,
+		uncompressedSize: b.uncompressedSize,
 	}
 }
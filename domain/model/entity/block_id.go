@@ -23,6 +23,7 @@
 package entity
 
 import (
+	"hash/fnv"
 	"strconv"
 
 	"github.com/ISSuh/sos/internal/generator"
@@ -34,6 +35,19 @@ func NewBlockID() BlockID {
 	return BlockID(generator.ID().Generate())
 }
 
+// NewBlockIDFromHash derives the block id from a content hash, so identical data of
+// deduplicated blocks is always placed on the same nodes.
+func NewBlockIDFromHash(hash string) BlockID {
+	h := fnv.New64a()
+	h.Write([]byte(hash))
+
+	id := int64(h.Sum64() &^ (1 << 63))
+	if id == 0 {
+		id = 1
+	}
+	return BlockID(id)
+}
+
 func NewBlockIDFrom(id int64) BlockID {
 	return BlockID(id)
 }
@@ -0,0 +1,62 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package entity
+
+import (
+	"errors"
+	"maps"
+)
+
+// MaxUserMetadataSize bounds the sum of the lengths of the user metadata keys and values.
+const MaxUserMetadataSize = 2 * 1024
+
+var ErrUserMetadataTooLarge = errors.New("user metadata is too large")
+
+// ContentMetadata is the system and user defined metadata a version is served with.
+type ContentMetadata struct {
+	ContentType     string            `bson:"content_type,omitempty" json:"content_type,omitempty"`
+	ContentEncoding string            `bson:"content_encoding,omitempty" json:"content_encoding,omitempty"`
+	CacheControl    string            `bson:"cache_control,omitempty" json:"cache_control,omitempty"`
+	UserMetadata    map[string]string `bson:"user_metadata,omitempty" json:"user_metadata,omitempty"`
+}
+
+func (e ContentMetadata) Validate() error {
+	size := 0
+	for key, value := range e.UserMetadata {
+		if key == "" {
+			return errors.New("user metadata key is empty")
+		}
+		size += len(key) + len(value)
+	}
+
+	if size > MaxUserMetadataSize {
+		return ErrUserMetadataTooLarge
+	}
+	return nil
+}
+
+func (e ContentMetadata) Clone() ContentMetadata {
+	clone := e
+	clone.UserMetadata = maps.Clone(e.UserMetadata)
+	return clone
+}
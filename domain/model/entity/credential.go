@@ -0,0 +1,140 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Credential is an access key and the secret that signs requests made with it.
+// The secret is kept as is, because a signature can only be verified with it.
+type Credential struct {
+	accessKey string
+	secretKey string
+	principal string
+
+	ModifiedTime
+}
+
+func (e *Credential) AccessKey() string {
+	return e.accessKey
+}
+
+func (e *Credential) SecretKey() string {
+	return e.secretKey
+}
+
+func (e *Credential) Principal() string {
+	return e.principal
+}
+
+func (e *Credential) MarshalBSON() ([]byte, error) {
+	dto := struct {
+		AccessKey  string    `bson:"access_key"`
+		SecretKey  string    `bson:"secret_key"`
+		Principal  string    `bson:"principal"`
+		CreatedAt  time.Time `bson:"created_at"`
+		ModifiedAt time.Time `bson:"modified_at"`
+	}{
+		AccessKey:  e.accessKey,
+		SecretKey:  e.secretKey,
+		Principal:  e.principal,
+		CreatedAt:  e.CreatedAt,
+		ModifiedAt: e.ModifiedAt,
+	}
+
+	return bson.Marshal(dto)
+}
+
+func (e *Credential) UnmarshalBSON(data []byte) error {
+	dto := struct {
+		AccessKey  string    `bson:"access_key"`
+		SecretKey  string    `bson:"secret_key"`
+		Principal  string    `bson:"principal"`
+		CreatedAt  time.Time `bson:"created_at"`
+		ModifiedAt time.Time `bson:"modified_at"`
+	}{}
+
+	if err := bson.Unmarshal(data, &dto); err != nil {
+		return err
+	}
+
+	e.accessKey = dto.AccessKey
+	e.secretKey = dto.SecretKey
+	e.principal = dto.Principal
+	e.CreatedAt = dto.CreatedAt
+	e.ModifiedAt = dto.ModifiedAt
+
+	return nil
+}
+
+type CredentialBuilder struct {
+	accessKey  string
+	secretKey  string
+	principal  string
+	createdAt  time.Time
+	modifiedAt time.Time
+}
+
+func NewCredentialBuilder() *CredentialBuilder {
+	return &CredentialBuilder{}
+}
+
+func (b *CredentialBuilder) AccessKey(accessKey string) *CredentialBuilder {
+	b.accessKey = accessKey
+	return b
+}
+
+func (b *CredentialBuilder) SecretKey(secretKey string) *CredentialBuilder {
+	b.secretKey = secretKey
+	return b
+}
+
+func (b *CredentialBuilder) Principal(principal string) *CredentialBuilder {
+	b.principal = principal
+	return b
+}
+
+func (b *CredentialBuilder) CreatedAt(createAt time.Time) *CredentialBuilder {
+	b.createdAt = createAt
+	return b
+}
+
+func (b *CredentialBuilder) ModifiedAt(modifiedAt time.Time) *CredentialBuilder {
+	b.modifiedAt = modifiedAt
+	return b
+}
+
+func (b *CredentialBuilder) Build() *Credential {
+	return &Credential{
+		accessKey: b.accessKey,
+		secretKey: b.secretKey,
+		principal: b.principal,
+		ModifiedTime: ModifiedTime{
+			CreatedAt:  b.createdAt,
+			ModifiedAt: b.modifiedAt,
+		},
+	}
+}
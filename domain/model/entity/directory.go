@@ -22,23 +22,52 @@
 
 package entity
 
-import "time"
+import (
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// RootDirectoryPath is the top of the directory tree of a partition. e.g. the path of an S3 key without a slash
+const RootDirectoryPath = "/"
 
 type Directories []Directory
 
 type Directory struct {
-	id           ObjectID
 	group        string
 	partition    string
-	name         string
+	path         string
 	objects      []ObjectID
-	subDirectory []ObjectID
+	subDirectory []string
+	revision     int64
 
 	ModifiedTime
 }
 
-func (e *Directory) ID() ObjectID {
-	return e.id
+// CleanDirectoryPath normalizes an object path to the form used as a directory key. e.g. "/a//b/" to "a/b"
+func CleanDirectoryPath(p string) string {
+	cleaned := strings.Trim(path.Clean("/"+p), "/")
+	if cleaned == "" {
+		return RootDirectoryPath
+	}
+	return cleaned
+}
+
+// ParentDirectoryPath splits a directory path into its parent path and its own name.
+func ParentDirectoryPath(p string) (string, string) {
+	p = CleanDirectoryPath(p)
+	if p == RootDirectoryPath {
+		return RootDirectoryPath, ""
+	}
+
+	parent := path.Dir(p)
+	if parent == "." {
+		parent = RootDirectoryPath
+	}
+	return parent, path.Base(p)
 }
 
 func (e *Directory) Group() string {
@@ -49,33 +78,120 @@ func (e *Directory) Partition() string {
 	return e.partition
 }
 
+func (e *Directory) Path() string {
+	return e.path
+}
+
 func (e *Directory) Name() string {
-	return e.name
+	_, name := ParentDirectoryPath(e.path)
+	return name
 }
 
 func (e *Directory) Objects() []ObjectID {
 	return e.objects
 }
 
-func (e *Directory) SubDirectory() []ObjectID {
+func (e *Directory) SubDirectory() []string {
 	return e.subDirectory
 }
 
+// Revision counts the writes of the directory. Removing a sub directory is only applied to the
+// revision it was read at, so a sub directory linked meanwhile is never dropped.
+func (e *Directory) Revision() int64 {
+	return e.revision
+}
+
+func (e *Directory) IncreaseRevision() {
+	e.revision++
+}
+
+func (e *Directory) Empty() bool {
+	return len(e.objects) == 0 && len(e.subDirectory) == 0
+}
+
 func (e *Directory) AddObjectID(id ObjectID) {
-	e.objects = append(e.objects, id)
+	if !slices.Contains(e.objects, id) {
+		e.objects = append(e.objects, id)
+	}
+}
+
+func (e *Directory) DeleteObjectID(id ObjectID) {
+	e.objects = slices.DeleteFunc(e.objects, func(objectID ObjectID) bool {
+		return objectID == id
+	})
 }
 
-func (e *Directory) AddChild(id ObjectID) {
-	e.subDirectory = append(e.subDirectory, id)
+func (e *Directory) AddChild(name string) {
+	if !slices.Contains(e.subDirectory, name) {
+		e.subDirectory = append(e.subDirectory, name)
+	}
+}
+
+func (e *Directory) DeleteChild(name string) {
+	e.subDirectory = slices.DeleteFunc(e.subDirectory, func(child string) bool {
+		return child == name
+	})
+}
+
+func (e *Directory) MarshalBSON() ([]byte, error) {
+	dto := struct {
+		Group        string     `bson:"group"`
+		Partition    string     `bson:"partition"`
+		Path         string     `bson:"path"`
+		Objects      []ObjectID `bson:"objects"`
+		SubDirectory []string   `bson:"sub_directory"`
+		Revision     int64      `bson:"revision"`
+		CreatedAt    time.Time  `bson:"created_at"`
+		ModifiedAt   time.Time  `bson:"modified_at"`
+	}{
+		Group:        e.group,
+		Partition:    e.partition,
+		Path:         e.path,
+		Objects:      e.objects,
+		SubDirectory: e.subDirectory,
+		Revision:     e.revision,
+		CreatedAt:    e.CreatedAt,
+		ModifiedAt:   e.ModifiedAt,
+	}
+
+	return bson.Marshal(dto)
+}
+
+func (e *Directory) UnmarshalBSON(data []byte) error {
+	dto := struct {
+		Group        string     `bson:"group"`
+		Partition    string     `bson:"partition"`
+		Path         string     `bson:"path"`
+		Objects      []ObjectID `bson:"objects"`
+		SubDirectory []string   `bson:"sub_directory"`
+		Revision     int64      `bson:"revision"`
+		CreatedAt    time.Time  `bson:"created_at"`
+		ModifiedAt   time.Time  `bson:"modified_at"`
+	}{}
+
+	if err := bson.Unmarshal(data, &dto); err != nil {
+		return err
+	}
+
+	e.group = dto.Group
+	e.partition = dto.Partition
+	e.path = dto.Path
+	e.objects = dto.Objects
+	e.subDirectory = dto.SubDirectory
+	e.revision = dto.Revision
+	e.CreatedAt = dto.CreatedAt
+	e.ModifiedAt = dto.ModifiedAt
+
+	return nil
 }
 
 type DirectoryBuilder struct {
-	id           ObjectID
 	group        string
 	partition    string
-	name         string
+	path         string
 	objects      []ObjectID
-	subDirectory []ObjectID
+	subDirectory []string
+	revision     int64
 	createdAt    time.Time
 	modifiedAt   time.Time
 }
@@ -84,11 +200,6 @@ func NewDirectoryBuilder() *DirectoryBuilder {
 	return &DirectoryBuilder{}
 }
 
-func (b *DirectoryBuilder) ID(id ObjectID) *DirectoryBuilder {
-	b.id = id
-	return b
-}
-
 func (b *DirectoryBuilder) Group(group string) *DirectoryBuilder {
 	b.group = group
 	return b
@@ -99,8 +210,8 @@ func (b *DirectoryBuilder) Partition(partition string) *DirectoryBuilder {
 	return b
 }
 
-func (b *DirectoryBuilder) Name(name string) *DirectoryBuilder {
-	b.name = name
+func (b *DirectoryBuilder) Path(path string) *DirectoryBuilder {
+	b.path = path
 	return b
 }
 
@@ -109,11 +220,16 @@ func (b *DirectoryBuilder) Objects(objects []ObjectID) *DirectoryBuilder {
 	return b
 }
 
-func (b *DirectoryBuilder) SubDirectory(subDirectory []ObjectID) *DirectoryBuilder {
+func (b *DirectoryBuilder) SubDirectory(subDirectory []string) *DirectoryBuilder {
 	b.subDirectory = subDirectory
 	return b
 }
 
+func (b *DirectoryBuilder) Revision(revision int64) *DirectoryBuilder {
+	b.revision = revision
+	return b
+}
+
 func (b *DirectoryBuilder) CreatedAt(createAt time.Time) *DirectoryBuilder {
 	b.createdAt = createAt
 	return b
@@ -126,12 +242,12 @@ func (b *DirectoryBuilder) ModifiedAt(modifiedAt time.Time) *DirectoryBuilder {
 
 func (b *DirectoryBuilder) Build() *Directory {
 	return &Directory{
-		id:           b.id,
 		group:        b.group,
 		partition:    b.partition,
-		name:         b.name,
+		path:         b.path,
 		objects:      b.objects,
 		subDirectory: b.subDirectory,
+		revision:     b.revision,
 		ModifiedTime: ModifiedTime{
 			CreatedAt:  b.createdAt,
 			ModifiedAt: b.modifiedAt,
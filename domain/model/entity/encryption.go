@@ -0,0 +1,42 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package entity
+
+// Encryption is the key the blocks of a version are encrypted with. A data key is only stored
+// wrapped by the master key KeyID of the key management service, and a key provided by the client
+// is not stored at all but identified by its MD5 digest.
+type Encryption struct {
+	Algorithm      string `bson:"algorithm,omitempty"`
+	KeyID          string `bson:"key_id,omitempty"`
+	DataKey        []byte `bson:"data_key,omitempty"`
+	CustomerKeyMD5 string `bson:"customer_key_md5,omitempty"`
+}
+
+func (e Encryption) Enabled() bool {
+	return e.Algorithm != ""
+}
+
+// CustomerProvided reports whether the client holds the key, which then has to come with every read.
+func (e Encryption) CustomerProvided() bool {
+	return e.CustomerKeyMD5 != ""
+}
@@ -0,0 +1,50 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package entity
+
+type ErasureCoding struct {
+	DataShards   int `bson:"data_shards"`
+	ParityShards int `bson:"parity_shards"`
+}
+
+func (e ErasureCoding) Enabled() bool {
+	return e.DataShards > 0
+}
+
+func (e ErasureCoding) TotalShards() int {
+	return e.DataShards + e.ParityShards
+}
+
+type Shards []Shard
+
+func (s Shards) Empty() bool {
+	return len(s) == 0
+}
+
+// Shard is one erasure coded piece of a block, stored on the block storage as a block of its own.
+type Shard struct {
+	BlockID  BlockID `bson:"block_id"`
+	Node     Node    `bson:"node"`
+	Size     int     `bson:"size"`
+	Checksum uint32  `bson:"checksum"`
+}
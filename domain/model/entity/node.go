@@ -22,6 +22,39 @@
 
 package entity
 
+type Nodes []Node
+
+func NewNodesFromHosts(hosts []string) Nodes {
+	nodes := make(Nodes, 0, len(hosts))
+	for _, host := range hosts {
+		nodes = append(nodes, Node{Host: host})
+	}
+	return nodes
+}
+
+func (n Nodes) Empty() bool {
+	return len(n) == 0
+}
+
+func (n Nodes) Primary() Node {
+	if n.Empty() {
+		return Node{}
+	}
+	return n[0]
+}
+
+func (n Nodes) Hosts() []string {
+	hosts := make([]string, 0, len(n))
+	for _, node := range n {
+		hosts = append(hosts, node.Host)
+	}
+	return hosts
+}
+
 type Node struct {
 	Host string `bson:"host"`
 }
+
+func (n Node) Empty() bool {
+	return n.Host == ""
+}
@@ -24,6 +24,7 @@ package entity
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -38,6 +39,8 @@ type ObjectMetadata struct {
 	name      string   `bson:"name"`
 	path      string   `bson:"path"`
 	versions  Versions `bson:"versions"`
+	tags      Tags     `bson:"tags"`
+	revision  int64    `bson:"revision"`
 
 	ModifiedTime
 }
@@ -66,17 +69,54 @@ func (e *ObjectMetadata) Versions() Versions {
 	return e.versions
 }
 
+func (e *ObjectMetadata) Tags() Tags {
+	return e.tags
+}
+
+// SetTags replaces the tags of the object. The versions are left as they are.
+func (e *ObjectMetadata) SetTags(tags Tags) {
+	e.tags = tags
+}
+
+// Move places the object under the path with the name. The versions and their blocks are left as they are.
+func (e *ObjectMetadata) Move(path, name string) {
+	e.path = path
+	e.name = name
+}
+
+// Revision counts the writes of the metadata. A write is only applied to the revision it was read at,
+// so that concurrent writers never overwrite each other.
+func (e *ObjectMetadata) Revision() int64 {
+	return e.revision
+}
+
+func (e *ObjectMetadata) IncreaseRevision() {
+	e.revision++
+}
+
 func (e *ObjectMetadata) IsValid() bool {
 	return e.id.IsValid()
 }
 
+// Clone returns a copy whose versions can be changed without changing the original.
+func (e *ObjectMetadata) Clone() ObjectMetadata {
+	clone := *e
+	clone.versions = make(Versions, 0, len(e.versions))
+	for _, version := range e.versions {
+		version.content = version.content.Clone()
+		clone.versions = append(clone.versions, version)
+	}
+	clone.tags = e.tags.Clone()
+	return clone
+}
+
 func (e *ObjectMetadata) AppendVersion(version Version) {
 	e.versions = append(e.versions, version)
 }
 
 func (e *ObjectMetadata) DeleteVersion(versionNum int) error {
 	for i, version := range e.versions {
-		if version.Number() == versionNum {
+		if version.Number() == versionNum && !version.Pending() {
 			e.versions = append(e.versions[:i], e.versions[i+1:]...)
 			return nil
 		}
@@ -84,11 +124,130 @@ func (e *ObjectMetadata) DeleteVersion(versionNum int) error {
 	return errors.New("version not exist")
 }
 
-func (e *ObjectMetadata) LastVersion() int {
-	if len(e.versions) == 0 {
-		return -1
+// DeleteVersions removes every version, the pending ones too, and returns the blocks recorded on
+// the pending versions, which are no longer deleted with them.
+func (e *ObjectMetadata) DeleteVersions() BlockHeaders {
+	var recorded BlockHeaders
+	for _, version := range e.versions {
+		if version.Pending() {
+			recorded = append(recorded, version.BlockHeaders()...)
+		}
+	}
+
+	e.versions = nil
+	return recorded
+}
+
+// NextVersion returns the number following every committed and pending version.
+func (e *ObjectMetadata) NextVersion() int {
+	next := 0
+	for _, version := range e.versions {
+		next = max(next, version.Number()+1)
+	}
+	return next
+}
+
+// LastCommittedVersion returns the version committed last, which is the current content of the object.
+func (e *ObjectMetadata) LastCommittedVersion() (Version, bool) {
+	for i := len(e.versions) - 1; i >= 0; i-- {
+		if !e.versions[i].Pending() {
+			return e.versions[i], true
+		}
 	}
-	return e.versions[len(e.versions)-1].Number()
+	return Version{}, false
+}
+
+func (e *ObjectMetadata) HasCommittedVersion() bool {
+	return slices.ContainsFunc(e.versions, func(version Version) bool {
+		return !version.Pending()
+	})
+}
+
+func (e *ObjectMetadata) HasPendingVersion() bool {
+	return slices.ContainsFunc(e.versions, func(version Version) bool {
+		return version.Pending()
+	})
+}
+
+// CommitVersion replaces the pending version of the same number and moves it last,
+// so the last version is always the one committed last.
+func (e *ObjectMetadata) CommitVersion(version Version) error {
+	for i, pending := range e.versions {
+		if pending.Number() == version.Number() && pending.Pending() {
+			e.versions = append(e.versions[:i], e.versions[i+1:]...)
+			e.versions = append(e.versions, version)
+			return nil
+		}
+	}
+	return errors.New("version is not reserved")
+}
+
+// HasStaleDataKey reports whether a committed version has its data key wrapped by another
+// master key than keyID.
+func (e *ObjectMetadata) HasStaleDataKey(keyID string) bool {
+	for _, version := range e.versions {
+		encryption := version.Encryption()
+		if !version.Pending() && encryption.Enabled() && !encryption.CustomerProvided() && encryption.KeyID != keyID {
+			return true
+		}
+	}
+	return false
+}
+
+// RewrapVersion replaces the wrapped data key of the committed version. The data key itself does
+// not change, so the blocks of the version stay readable.
+func (e *ObjectMetadata) RewrapVersion(versionNum int, encryption Encryption) bool {
+	for i := range e.versions {
+		version := &e.versions[i]
+		current := version.Encryption()
+		if version.Number() == versionNum && !version.Pending() && current.Enabled() && !current.CustomerProvided() {
+			version.encryption = encryption
+			return true
+		}
+	}
+	return false
+}
+
+// RecordBlocks appends the blocks written for the pending version, so they are known even when the
+// upload never commits.
+func (e *ObjectMetadata) RecordBlocks(versionNum int, blockHeaders BlockHeaders) error {
+	for i := range e.versions {
+		version := &e.versions[i]
+		if version.Number() == versionNum && version.Pending() {
+			version.blockHeaders = append(version.blockHeaders, blockHeaders...)
+			return nil
+		}
+	}
+	return errors.New("version is not reserved")
+}
+
+// AbortVersion removes the pending version and returns the blocks recorded on it.
+func (e *ObjectMetadata) AbortVersion(versionNum int) (BlockHeaders, error) {
+	for i, version := range e.versions {
+		if version.Number() == versionNum && version.Pending() {
+			e.versions = append(e.versions[:i], e.versions[i+1:]...)
+			return version.BlockHeaders(), nil
+		}
+	}
+	return nil, errors.New("version is not reserved")
+}
+
+// ReleaseExpiredVersions removes the pending versions expired at now, reports whether any was and
+// returns the blocks recorded on them.
+func (e *ObjectMetadata) ReleaseExpiredVersions(now time.Time) (BlockHeaders, bool) {
+	var recorded BlockHeaders
+	versions := make(Versions, 0, len(e.versions))
+	for _, version := range e.versions {
+		if version.IsExpired(now) {
+			recorded = append(recorded, version.BlockHeaders()...)
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	released := len(versions) != len(e.versions)
+	e.versions = versions
+	return recorded, released
 }
 
 func (e *ObjectMetadata) MarshalBSON() ([]byte, error) {
@@ -101,6 +260,8 @@ func (e *ObjectMetadata) MarshalBSON() ([]byte, error) {
 		Size       int       `bson:"size"`
 		Node       Node      `bson:"node"`
 		Versions   Versions  `bson:"versions"`
+		Tags       []Tag     `bson:"tags,omitempty"`
+		Revision   int64     `bson:"revision"`
 		CreatedAt  time.Time `bson:"created_at"`
 		ModifiedAt time.Time `bson:"modified_at"`
 	}{
@@ -110,6 +271,8 @@ func (e *ObjectMetadata) MarshalBSON() ([]byte, error) {
 		Name:       e.name,
 		Path:       e.path,
 		Versions:   e.versions,
+		Tags:       e.tags.List(),
+		Revision:   e.revision,
 		CreatedAt:  e.CreatedAt,
 		ModifiedAt: e.ModifiedAt,
 	}
@@ -125,6 +288,8 @@ func (e *ObjectMetadata) UnmarshalBSON(data []byte) error {
 		Name       string    `bson:"name"`
 		Path       string    `bson:"path"`
 		Versions   Versions  `bson:"versions"`
+		Tags       []Tag     `bson:"tags,omitempty"`
+		Revision   int64     `bson:"revision"`
 		CreatedAt  time.Time `bson:"created_at"`
 		ModifiedAt time.Time `bson:"modified_at"`
 	}{}
@@ -139,6 +304,8 @@ func (e *ObjectMetadata) UnmarshalBSON(data []byte) error {
 	e.name = dto.Name
 	e.path = dto.Path
 	e.versions = dto.Versions
+	e.tags = NewTagsFromList(dto.Tags)
+	e.revision = dto.Revision
 	e.CreatedAt = dto.CreatedAt
 	e.ModifiedAt = dto.ModifiedAt
 	return nil
@@ -151,6 +318,7 @@ type ObjectMetadataBuilder struct {
 	name       string
 	path       string
 	versions   Versions
+	tags       Tags
 	createdAt  time.Time
 	modifiedAt time.Time
 }
@@ -189,6 +357,11 @@ func (b *ObjectMetadataBuilder) Versions(versions Versions) *ObjectMetadataBuild
 	return b
 }
 
+func (b *ObjectMetadataBuilder) Tags(tags Tags) *ObjectMetadataBuilder {
+	b.tags = tags
+	return b
+}
+
 func (b *ObjectMetadataBuilder) CreatedAt(createAt time.Time) *ObjectMetadataBuilder {
 	b.createdAt = createAt
 	return b
@@ -207,6 +380,7 @@ func (b *ObjectMetadataBuilder) Build() ObjectMetadata {
 		name:      b.name,
 		path:      b.path,
 		versions:  b.versions,
+		tags:      b.tags,
 		ModifiedTime: ModifiedTime{
 			CreatedAt:  b.createdAt,
 			ModifiedAt: b.modifiedAt,
@@ -0,0 +1,293 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type PolicyEffect string
+
+const (
+	PolicyEffectAllow PolicyEffect = "allow"
+	PolicyEffectDeny  PolicyEffect = "deny"
+)
+
+type PolicyAction string
+
+const (
+	PolicyActionList          PolicyAction = "list"
+	PolicyActionGet           PolicyAction = "get"
+	PolicyActionPut           PolicyAction = "put"
+	PolicyActionDelete        PolicyAction = "delete"
+	PolicyActionDeleteVersion PolicyAction = "delete-version"
+	PolicyActionAll           PolicyAction = "*"
+)
+
+const (
+	PolicyPrincipalAll = "*"
+)
+
+func (a PolicyAction) IsValid() bool {
+	switch a {
+	case PolicyActionList, PolicyActionGet, PolicyActionPut, PolicyActionDelete,
+		PolicyActionDeleteVersion, PolicyActionAll:
+		return true
+	}
+	return false
+}
+
+func (e PolicyEffect) IsValid() bool {
+	return e == PolicyEffectAllow || e == PolicyEffectDeny
+}
+
+// PolicyResource is the path an action is checked against by the prefix of a statement.
+// An object is its directory path and name, e.g. a/b/c.txt, and a listed directory ends
+// with a slash, e.g. a/b/. Both are relative to the root of the partition.
+func PolicyResource(directoryPath, name string) string {
+	directoryPath = CleanDirectoryPath(directoryPath)
+	if directoryPath == RootDirectoryPath {
+		return name
+	}
+	return directoryPath + "/" + name
+}
+
+// CleanPolicyPrefix makes a prefix relative to the root of the partition, like a PolicyResource.
+func CleanPolicyPrefix(prefix string) string {
+	return strings.TrimLeft(prefix, "/")
+}
+
+type PolicyStatements []PolicyStatement
+
+type PolicyStatement struct {
+	effect     PolicyEffect
+	principals []string
+	actions    []PolicyAction
+	prefix     string
+}
+
+func (e *PolicyStatement) Effect() PolicyEffect {
+	return e.effect
+}
+
+func (e *PolicyStatement) Principals() []string {
+	return e.principals
+}
+
+func (e *PolicyStatement) Actions() []PolicyAction {
+	return e.actions
+}
+
+func (e *PolicyStatement) Prefix() string {
+	return e.prefix
+}
+
+func (e *PolicyStatement) MarshalBSON() ([]byte, error) {
+	dto := struct {
+		Effect     PolicyEffect   `bson:"effect"`
+		Principals []string       `bson:"principals"`
+		Actions    []PolicyAction `bson:"actions"`
+		Prefix     string         `bson:"prefix"`
+	}{
+		Effect:     e.effect,
+		Principals: e.principals,
+		Actions:    e.actions,
+		Prefix:     e.prefix,
+	}
+
+	return bson.Marshal(dto)
+}
+
+func (e *PolicyStatement) UnmarshalBSON(data []byte) error {
+	dto := struct {
+		Effect     PolicyEffect   `bson:"effect"`
+		Principals []string       `bson:"principals"`
+		Actions    []PolicyAction `bson:"actions"`
+		Prefix     string         `bson:"prefix"`
+	}{}
+
+	if err := bson.Unmarshal(data, &dto); err != nil {
+		return err
+	}
+
+	e.effect = dto.Effect
+	e.principals = dto.Principals
+	e.actions = dto.Actions
+	e.prefix = dto.Prefix
+
+	return nil
+}
+
+type PolicyStatementBuilder struct {
+	effect     PolicyEffect
+	principals []string
+	actions    []PolicyAction
+	prefix     string
+}
+
+func NewPolicyStatementBuilder() *PolicyStatementBuilder {
+	return &PolicyStatementBuilder{}
+}
+
+func (b *PolicyStatementBuilder) Effect(effect PolicyEffect) *PolicyStatementBuilder {
+	b.effect = effect
+	return b
+}
+
+func (b *PolicyStatementBuilder) Principals(principals []string) *PolicyStatementBuilder {
+	b.principals = principals
+	return b
+}
+
+func (b *PolicyStatementBuilder) Actions(actions []PolicyAction) *PolicyStatementBuilder {
+	b.actions = actions
+	return b
+}
+
+func (b *PolicyStatementBuilder) Prefix(prefix string) *PolicyStatementBuilder {
+	b.prefix = prefix
+	return b
+}
+
+func (b *PolicyStatementBuilder) Build() *PolicyStatement {
+	return &PolicyStatement{
+		effect:     b.effect,
+		principals: b.principals,
+		actions:    b.actions,
+		prefix:     b.prefix,
+	}
+}
+
+// Policy allows or denies actions of principals on a group, when partition is empty,
+// or on a partition of the group.
+type Policy struct {
+	group      string
+	partition  string
+	statements PolicyStatements
+
+	ModifiedTime
+}
+
+func (e *Policy) Group() string {
+	return e.group
+}
+
+func (e *Policy) Partition() string {
+	return e.partition
+}
+
+func (e *Policy) Statements() PolicyStatements {
+	return e.statements
+}
+
+func (e *Policy) MarshalBSON() ([]byte, error) {
+	dto := struct {
+		Group      string           `bson:"group"`
+		Partition  string           `bson:"partition"`
+		Statements PolicyStatements `bson:"statements"`
+		CreatedAt  time.Time        `bson:"created_at"`
+		ModifiedAt time.Time        `bson:"modified_at"`
+	}{
+		Group:      e.group,
+		Partition:  e.partition,
+		Statements: e.statements,
+		CreatedAt:  e.CreatedAt,
+		ModifiedAt: e.ModifiedAt,
+	}
+
+	return bson.Marshal(dto)
+}
+
+func (e *Policy) UnmarshalBSON(data []byte) error {
+	dto := struct {
+		Group      string           `bson:"group"`
+		Partition  string           `bson:"partition"`
+		Statements PolicyStatements `bson:"statements"`
+		CreatedAt  time.Time        `bson:"created_at"`
+		ModifiedAt time.Time        `bson:"modified_at"`
+	}{}
+
+	if err := bson.Unmarshal(data, &dto); err != nil {
+		return err
+	}
+
+	e.group = dto.Group
+	e.partition = dto.Partition
+	e.statements = dto.Statements
+	e.CreatedAt = dto.CreatedAt
+	e.ModifiedAt = dto.ModifiedAt
+
+	return nil
+}
+
+type PolicyBuilder struct {
+	group      string
+	partition  string
+	statements PolicyStatements
+	createdAt  time.Time
+	modifiedAt time.Time
+}
+
+func NewPolicyBuilder() *PolicyBuilder {
+	return &PolicyBuilder{}
+}
+
+func (b *PolicyBuilder) Group(group string) *PolicyBuilder {
+	b.group = group
+	return b
+}
+
+func (b *PolicyBuilder) Partition(partition string) *PolicyBuilder {
+	b.partition = partition
+	return b
+}
+
+func (b *PolicyBuilder) Statements(statements PolicyStatements) *PolicyBuilder {
+	b.statements = statements
+	return b
+}
+
+func (b *PolicyBuilder) CreatedAt(createdAt time.Time) *PolicyBuilder {
+	b.createdAt = createdAt
+	return b
+}
+
+func (b *PolicyBuilder) ModifiedAt(modifiedAt time.Time) *PolicyBuilder {
+	b.modifiedAt = modifiedAt
+	return b
+}
+
+func (b *PolicyBuilder) Build() *Policy {
+	return &Policy{
+		group:      b.group,
+		partition:  b.partition,
+		statements: b.statements,
+		ModifiedTime: ModifiedTime{
+			CreatedAt:  b.createdAt,
+			ModifiedAt: b.modifiedAt,
+		},
+	}
+}
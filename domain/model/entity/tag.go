@@ -0,0 +1,204 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const (
+	MaxTagsPerObject  = 50
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+)
+
+var (
+	ErrInvalidTags          = errors.New("tags are invalid")
+	ErrInvalidTagExpression = errors.New("tag expression is invalid")
+)
+
+// Tags classify an object apart from its versions, so changing them creates no version.
+type Tags map[string]string
+
+func (t Tags) Validate() error {
+	if len(t) > MaxTagsPerObject {
+		return fmt.Errorf("%w. more than %d tags", ErrInvalidTags, MaxTagsPerObject)
+	}
+
+	for key, value := range t {
+		switch {
+		case key == "":
+			return fmt.Errorf("%w. key is empty", ErrInvalidTags)
+		case len(key) > MaxTagKeyLength:
+			return fmt.Errorf("%w. key %s is too long", ErrInvalidTags, key)
+		case len(value) > MaxTagValueLength:
+			return fmt.Errorf("%w. value of %s is too long", ErrInvalidTags, key)
+		case strings.ContainsAny(key, tagExpressionSymbols):
+			return fmt.Errorf("%w. key %s has one of %s", ErrInvalidTags, key, tagExpressionSymbols)
+		}
+	}
+	return nil
+}
+
+func (t Tags) Clone() Tags {
+	return maps.Clone(t)
+}
+
+// List returns the tags ordered by key, in the form they are stored and indexed in.
+func (t Tags) List() []Tag {
+	list := make([]Tag, 0, len(t))
+	for key, value := range t {
+		list = append(list, Tag{Key: key, Value: value})
+	}
+
+	slices.SortFunc(list, func(a, b Tag) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return list
+}
+
+func NewTagsFromList(list []Tag) Tags {
+	if len(list) == 0 {
+		return nil
+	}
+
+	tags := make(Tags, len(list))
+	for _, tag := range list {
+		tags[tag.Key] = tag.Value
+	}
+	return tags
+}
+
+type Tag struct {
+	Key   string `bson:"key"`
+	Value string `bson:"value"`
+}
+
+const tagExpressionSymbols = "!=|"
+
+type TagOperator string
+
+const (
+	TagOperatorEqual     TagOperator = "="
+	TagOperatorNotEqual  TagOperator = "!="
+	TagOperatorExists    TagOperator = "exists"
+	TagOperatorNotExists TagOperator = "!exists"
+)
+
+// TagCondition is a term of a tag expression. Equal matches a tag with one of the values, and
+// not equal matches an object without such a tag.
+type TagCondition struct {
+	Key      string
+	Operator TagOperator
+	Values   []string
+}
+
+func (e TagCondition) Match(tags Tags) bool {
+	value, exist := tags[e.Key]
+	switch e.Operator {
+	case TagOperatorEqual:
+		return exist && slices.Contains(e.Values, value)
+	case TagOperatorNotEqual:
+		return !exist || !slices.Contains(e.Values, value)
+	case TagOperatorExists:
+		return exist
+	case TagOperatorNotExists:
+		return !exist
+	}
+	return false
+}
+
+func (e TagCondition) String() string {
+	switch e.Operator {
+	case TagOperatorExists:
+		return e.Key
+	case TagOperatorNotExists:
+		return "!" + e.Key
+	}
+	return e.Key + string(e.Operator) + strings.Join(e.Values, "|")
+}
+
+// TagExpression matches the objects meeting all of its conditions.
+type TagExpression []TagCondition
+
+// ParseTagExpression parses the terms of an expression. A term is one of key=value, key!=value,
+// key to match an object with the tag and !key to match one without it. The value of key=a|b
+// is either a or b.
+func ParseTagExpression(terms []string) (TagExpression, error) {
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w. expression is empty", ErrInvalidTagExpression)
+	}
+
+	expression := make(TagExpression, 0, len(terms))
+	for _, term := range terms {
+		condition, err := parseTagCondition(term)
+		if err != nil {
+			return nil, err
+		}
+		expression = append(expression, condition)
+	}
+	return expression, nil
+}
+
+func parseTagCondition(term string) (TagCondition, error) {
+	var condition TagCondition
+	if key, values, found := strings.Cut(term, string(TagOperatorNotEqual)); found {
+		condition = TagCondition{Key: key, Operator: TagOperatorNotEqual, Values: strings.Split(values, "|")}
+	} else if key, values, found := strings.Cut(term, string(TagOperatorEqual)); found {
+		condition = TagCondition{Key: key, Operator: TagOperatorEqual, Values: strings.Split(values, "|")}
+	} else if key, found := strings.CutPrefix(term, "!"); found {
+		condition = TagCondition{Key: key, Operator: TagOperatorNotExists}
+	} else {
+		condition = TagCondition{Key: term, Operator: TagOperatorExists}
+	}
+
+	switch {
+	case condition.Key == "":
+		return condition, fmt.Errorf("%w. key of %s is empty", ErrInvalidTagExpression, term)
+	case strings.ContainsAny(condition.Key, tagExpressionSymbols):
+		return condition, fmt.Errorf("%w. key of %s is malformed", ErrInvalidTagExpression, term)
+	}
+	return condition, nil
+}
+
+func (e TagExpression) Match(tags Tags) bool {
+	for _, condition := range e {
+		if !condition.Match(tags) {
+			return false
+		}
+	}
+	return true
+}
+
+// Terms returns the expression in the form ParseTagExpression reads.
+func (e TagExpression) Terms() []string {
+	terms := make([]string, 0, len(e))
+	for _, condition := range e {
+		terms = append(terms, condition.String())
+	}
+	return terms
+}
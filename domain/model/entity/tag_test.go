@@ -0,0 +1,124 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package entity
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseTagExpression(t *testing.T) {
+	tests := []struct {
+		terms []string
+		want  TagExpression
+	}{
+		{
+			terms: []string{"env=prod"},
+			want:  TagExpression{{Key: "env", Operator: TagOperatorEqual, Values: []string{"prod"}}},
+		},
+		{
+			terms: []string{"env=prod|stage", "team"},
+			want: TagExpression{
+				{Key: "env", Operator: TagOperatorEqual, Values: []string{"prod", "stage"}},
+				{Key: "team", Operator: TagOperatorExists},
+			},
+		},
+		// != is read before =, and the leading ! of a term without a value before both
+		{
+			terms: []string{"env!=prod|dev", "!archived"},
+			want: TagExpression{
+				{Key: "env", Operator: TagOperatorNotEqual, Values: []string{"prod", "dev"}},
+				{Key: "archived", Operator: TagOperatorNotExists},
+			},
+		},
+		// only the first operator splits the term, the rest belongs to the value
+		{
+			terms: []string{"query=a=b", "note="},
+			want: TagExpression{
+				{Key: "query", Operator: TagOperatorEqual, Values: []string{"a=b"}},
+				{Key: "note", Operator: TagOperatorEqual, Values: []string{""}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := ParseTagExpression(test.terms)
+		if err != nil {
+			t.Errorf("ParseTagExpression(%q): %v", test.terms, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseTagExpression(%q) = %+v, want %+v", test.terms, got, test.want)
+		}
+
+		if terms := got.Terms(); !reflect.DeepEqual(terms, test.terms) {
+			t.Errorf("Terms() = %q, want %q", terms, test.terms)
+		}
+	}
+}
+
+func TestParseTagExpressionMalformed(t *testing.T) {
+	for _, terms := range [][]string{
+		nil,
+		{""},
+		{"=prod"},
+		{"!=prod"},
+		{"!"},
+		{"!env=prod"},
+		{"env|team"},
+		{"env=prod", "a|b=c"},
+	} {
+		if _, err := ParseTagExpression(terms); !errors.Is(err, ErrInvalidTagExpression) {
+			t.Errorf("ParseTagExpression(%q): %v, want %v", terms, err, ErrInvalidTagExpression)
+		}
+	}
+}
+
+// The terms are all met, while the values of a term are alternatives. A not equal term matches an
+// object without the tag as well.
+func TestTagExpressionMatch(t *testing.T) {
+	expression, err := ParseTagExpression([]string{"env=prod|stage", "team!=ops", "!archived"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tags Tags
+		want bool
+	}{
+		{Tags{"env": "prod"}, true},
+		{Tags{"env": "stage", "team": "web"}, true},
+		{Tags{"env": "dev"}, false},
+		{Tags{"env": "prod", "team": "ops"}, false},
+		{Tags{"env": "prod", "archived": ""}, false},
+		{Tags{"team": "web"}, false},
+		{nil, false},
+	}
+
+	for _, test := range tests {
+		if got := expression.Match(test.tags); got != test.want {
+			t.Errorf("Match(%v) = %v, want %v", test.tags, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package entity
+
+import (
+	"strconv"
+
+	"github.com/ISSuh/sos/internal/generator"
+)
+
+type UploadID int64
+
+func NewUploadID() UploadID {
+	return UploadID(generator.ID().Generate())
+}
+
+func NewUploadIDFrom(value int64) UploadID {
+	return UploadID(value)
+}
+
+func (i UploadID) IsValid() bool {
+	return i.ToInt64() > 0
+}
+
+func (i UploadID) ToInt64() int64 {
+	return int64(i)
+}
+
+func (i UploadID) String() string {
+	return strconv.FormatInt(i.ToInt64(), 10)
+}
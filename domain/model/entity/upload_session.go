@@ -0,0 +1,439 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package entity
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type UploadSessions []UploadSession
+
+type UploadSession struct {
+	id         UploadID
+	objectID   ObjectID
+	group      string
+	partition  string
+	path       string
+	name       string
+	version    int
+	content    ContentMetadata
+	encryption Encryption
+	parts      UploadParts
+	expiresAt  time.Time
+
+	ModifiedTime
+}
+
+func (e *UploadSession) ID() UploadID {
+	return e.id
+}
+
+func (e *UploadSession) ObjectID() ObjectID {
+	return e.objectID
+}
+
+func (e *UploadSession) Group() string {
+	return e.group
+}
+
+func (e *UploadSession) Partition() string {
+	return e.partition
+}
+
+func (e *UploadSession) Path() string {
+	return e.path
+}
+
+func (e *UploadSession) Name() string {
+	return e.name
+}
+
+// Version is the version number reserved for the object when the session was initiated.
+func (e *UploadSession) Version() int {
+	return e.version
+}
+
+// Content is the metadata the version is committed with.
+func (e *UploadSession) Content() ContentMetadata {
+	return e.content
+}
+
+// Encryption is the data key the parts are encrypted with, which the version is committed with.
+func (e *UploadSession) Encryption() Encryption {
+	return e.encryption
+}
+
+func (e *UploadSession) Parts() UploadParts {
+	return e.parts
+}
+
+func (e *UploadSession) ExpiresAt() time.Time {
+	return e.expiresAt
+}
+
+func (e *UploadSession) IsValid() bool {
+	return e.id.IsValid()
+}
+
+func (e *UploadSession) IsExpired(now time.Time) bool {
+	return !now.Before(e.expiresAt)
+}
+
+// PutPart records the part, replacing a part previously uploaded with the same number.
+func (e *UploadSession) PutPart(part UploadPart) {
+	parts := make(UploadParts, 0, len(e.parts)+1)
+	for _, p := range e.parts {
+		if p.Number() != part.Number() {
+			parts = append(parts, p)
+		}
+	}
+
+	parts = append(parts, part)
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].Number() < parts[j].Number()
+	})
+
+	e.parts = parts
+}
+
+func (e *UploadSession) MarshalBSON() ([]byte, error) {
+	// parts are keyed by part number so that a single part can be replaced atomically
+	parts := make(map[string]*UploadPart, len(e.parts))
+	for i := range e.parts {
+		parts[strconv.Itoa(e.parts[i].Number())] = &e.parts[i]
+	}
+
+	dto := struct {
+		ID         UploadID               `bson:"upload_id"`
+		ObjectID   ObjectID               `bson:"object_id"`
+		Group      string                 `bson:"group"`
+		Partition  string                 `bson:"partition"`
+		Path       string                 `bson:"path"`
+		Name       string                 `bson:"name"`
+		Version    int                    `bson:"version"`
+		Content    ContentMetadata        `bson:"content"`
+		Encryption Encryption             `bson:"encryption,omitempty"`
+		Parts      map[string]*UploadPart `bson:"parts"`
+		ExpiresAt  time.Time              `bson:"expires_at"`
+		CreatedAt  time.Time              `bson:"created_at"`
+		ModifiedAt time.Time              `bson:"modified_at"`
+	}{
+		ID:         e.id,
+		ObjectID:   e.objectID,
+		Group:      e.group,
+		Partition:  e.partition,
+		Path:       e.path,
+		Name:       e.name,
+		Version:    e.version,
+		Content:    e.content,
+		Encryption: e.encryption,
+		Parts:      parts,
+		ExpiresAt:  e.expiresAt,
+		CreatedAt:  e.CreatedAt,
+		ModifiedAt: e.ModifiedAt,
+	}
+
+	return bson.Marshal(dto)
+}
+
+func (e *UploadSession) UnmarshalBSON(data []byte) error {
+	dto := struct {
+		ID         UploadID               `bson:"upload_id"`
+		ObjectID   ObjectID               `bson:"object_id"`
+		Group      string                 `bson:"group"`
+		Partition  string                 `bson:"partition"`
+		Path       string                 `bson:"path"`
+		Name       string                 `bson:"name"`
+		Version    int                    `bson:"version"`
+		Content    ContentMetadata        `bson:"content"`
+		Encryption Encryption             `bson:"encryption,omitempty"`
+		Parts      map[string]*UploadPart `bson:"parts"`
+		ExpiresAt  time.Time              `bson:"expires_at"`
+		CreatedAt  time.Time              `bson:"created_at"`
+		ModifiedAt time.Time              `bson:"modified_at"`
+	}{}
+
+	if err := bson.Unmarshal(data, &dto); err != nil {
+		return err
+	}
+
+	e.id = dto.ID
+	e.objectID = dto.ObjectID
+	e.group = dto.Group
+	e.partition = dto.Partition
+	e.path = dto.Path
+	e.name = dto.Name
+	e.version = dto.Version
+	e.content = dto.Content
+	e.encryption = dto.Encryption
+	e.parts = nil
+	for _, part := range dto.Parts {
+		e.PutPart(*part)
+	}
+	e.expiresAt = dto.ExpiresAt
+	e.CreatedAt = dto.CreatedAt
+	e.ModifiedAt = dto.ModifiedAt
+
+	return nil
+}
+
+type UploadParts []UploadPart
+
+func (p UploadParts) Empty() bool {
+	return len(p) == 0
+}
+
+type UploadPart struct {
+	number       int
+	size         int
+	blockHeaders BlockHeaders
+	checksum     string
+
+	ModifiedTime
+}
+
+func (e *UploadPart) Number() int {
+	return e.number
+}
+
+func (e *UploadPart) Size() int {
+	return e.size
+}
+
+func (e *UploadPart) BlockHeaders() BlockHeaders {
+	return e.blockHeaders
+}
+
+// Checksum is the SHA-256 of the part content.
+func (e *UploadPart) Checksum() string {
+	return e.checksum
+}
+
+func (e *UploadPart) MarshalBSON() ([]byte, error) {
+	dto := struct {
+		Number       int          `bson:"number"`
+		Size         int          `bson:"size"`
+		BlockHeaders BlockHeaders `bson:"block_headers"`
+		Checksum     string       `bson:"checksum,omitempty"`
+		CreatedAt    time.Time    `bson:"created_at"`
+		ModifiedAt   time.Time    `bson:"modified_at"`
+	}{
+		Number:       e.number,
+		Size:         e.size,
+		BlockHeaders: e.blockHeaders,
+		Checksum:     e.checksum,
+		CreatedAt:    e.CreatedAt,
+		ModifiedAt:   e.ModifiedAt,
+	}
+
+	return bson.Marshal(dto)
+}
+
+func (e *UploadPart) UnmarshalBSON(data []byte) error {
+	dto := struct {
+		Number       int          `bson:"number"`
+		Size         int          `bson:"size"`
+		BlockHeaders BlockHeaders `bson:"block_headers"`
+		Checksum     string       `bson:"checksum,omitempty"`
+		CreatedAt    time.Time    `bson:"created_at"`
+		ModifiedAt   time.Time    `bson:"modified_at"`
+	}{}
+
+	if err := bson.Unmarshal(data, &dto); err != nil {
+		return err
+	}
+
+	e.number = dto.Number
+	e.size = dto.Size
+	e.blockHeaders = dto.BlockHeaders
+	e.checksum = dto.Checksum
+	e.CreatedAt = dto.CreatedAt
+	e.ModifiedAt = dto.ModifiedAt
+
+	return nil
+}
+
+type UploadSessionBuilder struct {
+	id         UploadID
+	objectID   ObjectID
+	group      string
+	partition  string
+	path       string
+	name       string
+	version    int
+	content    ContentMetadata
+	encryption Encryption
+	parts      UploadParts
+	expiresAt  time.Time
+	createdAt  time.Time
+	modifiedAt time.Time
+}
+
+func NewUploadSessionBuilder() *UploadSessionBuilder {
+	return &UploadSessionBuilder{}
+}
+
+func (b *UploadSessionBuilder) ID(id UploadID) *UploadSessionBuilder {
+	b.id = id
+	return b
+}
+
+func (b *UploadSessionBuilder) ObjectID(objectID ObjectID) *UploadSessionBuilder {
+	b.objectID = objectID
+	return b
+}
+
+func (b *UploadSessionBuilder) Group(group string) *UploadSessionBuilder {
+	b.group = group
+	return b
+}
+
+func (b *UploadSessionBuilder) Partition(partition string) *UploadSessionBuilder {
+	b.partition = partition
+	return b
+}
+
+func (b *UploadSessionBuilder) Path(path string) *UploadSessionBuilder {
+	b.path = path
+	return b
+}
+
+func (b *UploadSessionBuilder) Name(name string) *UploadSessionBuilder {
+	b.name = name
+	return b
+}
+
+func (b *UploadSessionBuilder) Version(version int) *UploadSessionBuilder {
+	b.version = version
+	return b
+}
+
+func (b *UploadSessionBuilder) Content(content ContentMetadata) *UploadSessionBuilder {
+	b.content = content
+	return b
+}
+
+func (b *UploadSessionBuilder) Encryption(encryption Encryption) *UploadSessionBuilder {
+	b.encryption = encryption
+	return b
+}
+
+func (b *UploadSessionBuilder) Parts(parts UploadParts) *UploadSessionBuilder {
+	b.parts = parts
+	return b
+}
+
+func (b *UploadSessionBuilder) ExpiresAt(expiresAt time.Time) *UploadSessionBuilder {
+	b.expiresAt = expiresAt
+	return b
+}
+
+func (b *UploadSessionBuilder) CreatedAt(createdAt time.Time) *UploadSessionBuilder {
+	b.createdAt = createdAt
+	return b
+}
+
+func (b *UploadSessionBuilder) ModifiedAt(modifiedAt time.Time) *UploadSessionBuilder {
+	b.modifiedAt = modifiedAt
+	return b
+}
+
+func (b *UploadSessionBuilder) Build() UploadSession {
+	return UploadSession{
+		id:         b.id,
+		objectID:   b.objectID,
+		group:      b.group,
+		partition:  b.partition,
+		path:       b.path,
+		name:       b.name,
+		version:    b.version,
+		content:    b.content,
+		encryption: b.encryption,
+		parts:      b.parts,
+		expiresAt:  b.expiresAt,
+		ModifiedTime: ModifiedTime{
+			CreatedAt:  b.createdAt,
+			ModifiedAt: b.modifiedAt,
+		},
+	}
+}
+
+type UploadPartBuilder struct {
+	number       int
+	size         int
+	blockHeaders BlockHeaders
+	checksum     string
+	createdAt    time.Time
+	modifiedAt   time.Time
+}
+
+func NewUploadPartBuilder() *UploadPartBuilder {
+	return &UploadPartBuilder{}
+}
+
+func (b *UploadPartBuilder) Number(number int) *UploadPartBuilder {
+	b.number = number
+	return b
+}
+
+func (b *UploadPartBuilder) Size(size int) *UploadPartBuilder {
+	b.size = size
+	return b
+}
+
+func (b *UploadPartBuilder) BlockHeaders(blockHeaders BlockHeaders) *UploadPartBuilder {
+	b.blockHeaders = blockHeaders
+	return b
+}
+
+func (b *UploadPartBuilder) Checksum(checksum string) *UploadPartBuilder {
+	b.checksum = checksum
+	return b
+}
+
+func (b *UploadPartBuilder) CreatedAt(createdAt time.Time) *UploadPartBuilder {
+	b.createdAt = createdAt
+	return b
+}
+
+func (b *UploadPartBuilder) ModifiedAt(modifiedAt time.Time) *UploadPartBuilder {
+	b.modifiedAt = modifiedAt
+	return b
+}
+
+func (b *UploadPartBuilder) Build() UploadPart {
+	return UploadPart{
+		number:       b.number,
+		size:         b.size,
+		blockHeaders: b.blockHeaders,
+		checksum:     b.checksum,
+		ModifiedTime: ModifiedTime{
+			CreatedAt:  b.createdAt,
+			ModifiedAt: b.modifiedAt,
+		},
+	}
+}
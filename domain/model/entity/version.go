@@ -23,8 +23,11 @@
 package entity
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ISSuh/sos/internal/checksum"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -34,11 +37,18 @@ func (e Versions) Empty() bool {
 	return len(e) == 0
 }
 
+// Version is pending from its reservation until its blocks are committed, and is never
+// visible while pending.
 type Version struct {
-	number       int          `bson:"number"`
-	size         int          `bson:"size"`
-	node         Node         `bson:"node"`
-	blockHeaders BlockHeaders `bson:"block_headers"`
+	number       int             `bson:"number"`
+	size         int             `bson:"size"`
+	node         Node            `bson:"node"`
+	blockHeaders BlockHeaders    `bson:"block_headers"`
+	etag         string          `bson:"etag"`
+	content      ContentMetadata `bson:"content"`
+	encryption   Encryption      `bson:"encryption"`
+	pending      bool            `bson:"pending"`
+	expiresAt    time.Time       `bson:"expires_at"`
 
 	ModifiedTime
 }
@@ -59,19 +69,63 @@ func (e *Version) BlockHeaders() BlockHeaders {
 	return e.blockHeaders
 }
 
+// ETag is the strong entity tag of the version content. Versions stored before the content
+// checksum was recorded get one derived from their block checksums.
+func (e *Version) ETag() string {
+	if e.etag != "" || e.pending {
+		return e.etag
+	}
+
+	var builder strings.Builder
+	for _, header := range e.blockHeaders {
+		fmt.Fprintf(&builder, "%d:%d:%d;", header.BlockID().ToInt64(), header.Size(), header.Checksum())
+	}
+	return checksum.Calculate([]byte(builder.String()))
+}
+
+func (e *Version) Content() ContentMetadata {
+	return e.content
+}
+
+func (e *Version) Encryption() Encryption {
+	return e.encryption
+}
+
+func (e *Version) Pending() bool {
+	return e.pending
+}
+
+func (e *Version) ExpiresAt() time.Time {
+	return e.expiresAt
+}
+
+func (e *Version) IsExpired(now time.Time) bool {
+	return e.pending && !now.Before(e.expiresAt)
+}
+
 func (e *Version) MarshalBSON() ([]byte, error) {
 	dto := struct {
-		Number       int          `bson:"number"`
-		Size         int          `bson:"size"`
-		Node         Node         `bson:"node"`
-		BlockHeaders BlockHeaders `bson:"block_headers"`
-		CreatedAt    time.Time    `bson:"created_at"`
-		ModifiedAt   time.Time    `bson:"modified_at"`
+		Number       int             `bson:"number"`
+		Size         int             `bson:"size"`
+		Node         Node            `bson:"node"`
+		BlockHeaders BlockHeaders    `bson:"block_headers"`
+		ETag         string          `bson:"etag,omitempty"`
+		Content      ContentMetadata `bson:"content"`
+		Encryption   Encryption      `bson:"encryption,omitempty"`
+		Pending      bool            `bson:"pending,omitempty"`
+		ExpiresAt    time.Time       `bson:"expires_at,omitempty"`
+		CreatedAt    time.Time       `bson:"created_at"`
+		ModifiedAt   time.Time       `bson:"modified_at"`
 	}{
 		Number:       e.number,
 		Size:         e.size,
 		Node:         e.node,
 		BlockHeaders: e.blockHeaders,
+		ETag:         e.etag,
+		Content:      e.content,
+		Encryption:   e.encryption,
+		Pending:      e.pending,
+		ExpiresAt:    e.expiresAt,
 		CreatedAt:    e.CreatedAt,
 		ModifiedAt:   e.ModifiedAt,
 	}
@@ -81,12 +135,17 @@ func (e *Version) MarshalBSON() ([]byte, error) {
 
 func (e *Version) UnmarshalBSON(data []byte) error {
 	dto := struct {
-		Number       int          `bson:"number"`
-		Size         int          `bson:"size"`
-		Node         Node         `bson:"node"`
-		BlockHeaders BlockHeaders `bson:"block_headers"`
-		CreatedAt    time.Time    `bson:"created_at"`
-		ModifiedAt   time.Time    `bson:"modified_at"`
+		Number       int             `bson:"number"`
+		Size         int             `bson:"size"`
+		Node         Node            `bson:"node"`
+		BlockHeaders BlockHeaders    `bson:"block_headers"`
+		ETag         string          `bson:"etag,omitempty"`
+		Content      ContentMetadata `bson:"content"`
+		Encryption   Encryption      `bson:"encryption,omitempty"`
+		Pending      bool            `bson:"pending,omitempty"`
+		ExpiresAt    time.Time       `bson:"expires_at,omitempty"`
+		CreatedAt    time.Time       `bson:"created_at"`
+		ModifiedAt   time.Time       `bson:"modified_at"`
 	}{}
 
 	if err := bson.Unmarshal(data, &dto); err != nil {
@@ -97,6 +156,11 @@ func (e *Version) UnmarshalBSON(data []byte) error {
 	e.size = dto.Size
 	e.node = dto.Node
 	e.blockHeaders = dto.BlockHeaders
+	e.etag = dto.ETag
+	e.content = dto.Content
+	e.encryption = dto.Encryption
+	e.pending = dto.Pending
+	e.expiresAt = dto.ExpiresAt
 	e.CreatedAt = dto.CreatedAt
 	e.ModifiedAt = dto.ModifiedAt
 
@@ -108,6 +172,11 @@ type VersionBuilder struct {
 	size         int
 	node         Node
 	blockHeaders BlockHeaders
+	etag         string
+	content      ContentMetadata
+	encryption   Encryption
+	pending      bool
+	expiresAt    time.Time
 	createdAt    time.Time
 	modifiedAt   time.Time
 }
@@ -136,6 +205,31 @@ func (b *VersionBuilder) BlockHeaders(blockHeaders BlockHeaders) *VersionBuilder
 	return b
 }
 
+func (b *VersionBuilder) ETag(etag string) *VersionBuilder {
+	b.etag = etag
+	return b
+}
+
+func (b *VersionBuilder) Content(content ContentMetadata) *VersionBuilder {
+	b.content = content
+	return b
+}
+
+func (b *VersionBuilder) Encryption(encryption Encryption) *VersionBuilder {
+	b.encryption = encryption
+	return b
+}
+
+func (b *VersionBuilder) Pending(pending bool) *VersionBuilder {
+	b.pending = pending
+	return b
+}
+
+func (b *VersionBuilder) ExpiresAt(expiresAt time.Time) *VersionBuilder {
+	b.expiresAt = expiresAt
+	return b
+}
+
 func (b *VersionBuilder) CreatedAt(createdAt time.Time) *VersionBuilder {
 	b.createdAt = createdAt
 	return b
@@ -152,6 +246,11 @@ func (b *VersionBuilder) Build() Version {
 		size:         b.size,
 		node:         b.node,
 		blockHeaders: b.blockHeaders,
+		etag:         b.etag,
+		content:      b.content,
+		encryption:   b.encryption,
+		pending:      b.pending,
+		expiresAt:    b.expiresAt,
 		ModifiedTime: ModifiedTime{
 			CreatedAt:  b.createdAt,
 			ModifiedAt: b.modifiedAt,
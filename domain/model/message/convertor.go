@@ -66,6 +66,9 @@ func FromVersionDTO(version *dto.Version) *Version {
 		Number:       int32(version.Number),
 		Size:         int32(version.Size),
 		BlockHeaders: blockHeaders,
+		Etag:         version.ETag,
+		Content:      FromContentMetadata(version.ContentMetadata),
+		Encryption:   FromEncryption(version.Encryption),
 		CreatedAt:    timestamppb.New(version.CreatedAt),
 		ModifiedAt:   timestamppb.New(version.ModifiedAt),
 	}
@@ -85,8 +88,63 @@ func ToVersionDTO(version *Version) *dto.Version {
 		Number:       int(version.Number),
 		Size:         int(version.Size),
 		BlockHeaders: blockHeaders,
+		ETag:         version.Etag,
 		CreatedAt:    version.CreatedAt.AsTime(),
 		ModifiedAt:   version.ModifiedAt.AsTime(),
+
+		ContentMetadata: ToContentMetadata(version.Content),
+		Encryption:      ToEncryption(version.Encryption),
+	}
+}
+
+func FromEncryption(encryption entity.Encryption) *Encryption {
+	if !encryption.Enabled() {
+		return nil
+	}
+
+	return &Encryption{
+		Algorithm: encryption.Algorithm,
+		KeyID:     encryption.KeyID,
+		DataKey:   encryption.DataKey,
+
+		CustomerKeyMD5: encryption.CustomerKeyMD5,
+	}
+}
+
+func ToEncryption(encryption *Encryption) entity.Encryption {
+	// an object stored as it is carries no encryption at all
+	if encryption == nil {
+		return empty.Struct[entity.Encryption]()
+	}
+
+	return entity.Encryption{
+		Algorithm: encryption.Algorithm,
+		KeyID:     encryption.KeyID,
+		DataKey:   encryption.DataKey,
+
+		CustomerKeyMD5: encryption.CustomerKeyMD5,
+	}
+}
+
+func FromContentMetadata(content entity.ContentMetadata) *ContentMetadata {
+	return &ContentMetadata{
+		ContentType:     content.ContentType,
+		ContentEncoding: content.ContentEncoding,
+		CacheControl:    content.CacheControl,
+		UserMetadata:    content.UserMetadata,
+	}
+}
+
+func ToContentMetadata(content *ContentMetadata) entity.ContentMetadata {
+	if validation.IsNil(content) {
+		return empty.Struct[entity.ContentMetadata]()
+	}
+
+	return entity.ContentMetadata{
+		ContentType:     content.ContentType,
+		ContentEncoding: content.ContentEncoding,
+		CacheControl:    content.CacheControl,
+		UserMetadata:    content.UserMetadata,
 	}
 }
 
@@ -115,6 +173,7 @@ func FromObjectMetadataDTO(objectMetadata *dto.Metadata) *ObjectMetadata {
 		Path:       objectMetadata.Path,
 		Name:       objectMetadata.Name,
 		Versions:   versions,
+		Tags:       objectMetadata.Tags,
 		CreatedAt:  timestamppb.New(objectMetadata.CreatedAt),
 		ModifiedAt: timestamppb.New(objectMetadata.ModifiedAt),
 	}
@@ -136,6 +195,7 @@ func ToObjectMetadataDTO(objectMetadata *ObjectMetadata) *dto.Metadata {
 		Partition:  objectMetadata.Partition,
 		Name:       objectMetadata.Name,
 		Versions:   versions,
+		Tags:       objectMetadata.Tags,
 		Path:       objectMetadata.Path,
 		CreatedAt:  objectMetadata.CreatedAt.AsTime(),
 		ModifiedAt: objectMetadata.ModifiedAt.AsTime(),
@@ -186,14 +246,33 @@ func ToItemsDTO(list *ObjectMetadataList) dto.Items {
 	return dto.NewItemsFromMetadataList(metadataList)
 }
 
+func ToItemsPageDTO(list *ObjectMetadataList) dto.ItemsPage {
+	return dto.ItemsPage{
+		Items:      ToItemsDTO(list),
+		NextCursor: entity.NewObjectIDFrom(list.NextCursor),
+	}
+}
+
 func FromBlockHeader(blockHeader *entity.BlockHeader) *BlockHeader {
 	return &BlockHeader{
 		ObjectID:  FromObjectID(blockHeader.ObjectID()),
 		BlockID:   FromBlockID(blockHeader.BlockID()),
 		Index:     int32(blockHeader.Index()),
 		Size:      int32(blockHeader.Size()),
+		Node:      blockHeader.Node().Host,
+		Replicas:  blockHeader.Replicas().Hosts(),
 		Checksum:  blockHeader.Checksum(),
 		Timestamp: timestamppb.New(blockHeader.Timestamp()),
+
+		DataShards:    int32(blockHeader.ErasureCoding().DataShards),
+		ParityShards:  int32(blockHeader.ErasureCoding().ParityShards),
+		Shards:        fromShards(blockHeader.Shards()),
+		Hash:          blockHeader.Hash(),
+		CustomerKeyed: blockHeader.CustomerKeyed(),
+		DataKeySealed: blockHeader.DataKeySealed(),
+
+		Compression:      blockHeader.Compression(),
+		UncompressedSize: int32(blockHeader.UncompressedSize()),
 	}
 }
 
@@ -203,8 +282,20 @@ func FromBlockHeaderDTO(blockHeader *dto.BlockHeader) *BlockHeader {
 		BlockID:   FromBlockID(blockHeader.BlockID),
 		Index:     int32(blockHeader.Index),
 		Size:      int32(blockHeader.Size),
+		Node:      blockHeader.Replicas.Primary().Host,
+		Replicas:  blockHeader.Replicas.Hosts(),
 		Checksum:  blockHeader.Checksum,
 		Timestamp: timestamppb.New(blockHeader.Timestamp),
+
+		DataShards:    int32(blockHeader.ErasureCoding.DataShards),
+		ParityShards:  int32(blockHeader.ErasureCoding.ParityShards),
+		Shards:        fromShards(blockHeader.Shards),
+		Hash:          blockHeader.Hash,
+		CustomerKeyed: blockHeader.CustomerKeyed,
+		DataKeySealed: blockHeader.DataKeySealed,
+
+		Compression:      blockHeader.Compression,
+		UncompressedSize: int32(blockHeader.UncompressedSize),
 	}
 }
 
@@ -219,7 +310,16 @@ func ToBlockHeader(blockHeader *BlockHeader) entity.BlockHeader {
 		BlockID(ToBlockID(blockHeader.BlockID)).
 		Index(int(blockHeader.Index)).
 		Size(int(blockHeader.Size)).
+		Node(entity.Node{Host: blockHeader.Node}).
+		Replicas(toReplicas(blockHeader)).
+		ErasureCoding(toErasureCoding(blockHeader)).
+		Shards(toShards(blockHeader.Shards)).
 		Checksum(blockHeader.Checksum).
+		Hash(blockHeader.Hash).
+		CustomerKeyed(blockHeader.CustomerKeyed).
+		DataKeySealed(blockHeader.DataKeySealed).
+		Compression(blockHeader.Compression).
+		UncompressedSize(int(blockHeader.UncompressedSize)).
 		Timestamp(blockHeader.Timestamp.AsTime())
 
 	return builder.Build()
@@ -236,7 +336,84 @@ func ToBlockHeaderDTO(blockHeader *BlockHeader) dto.BlockHeader {
 		Size:      int(blockHeader.Size),
 		Checksum:  blockHeader.Checksum,
 		Timestamp: blockHeader.Timestamp.AsTime(),
+		Replicas:  toReplicas(blockHeader),
+
+		ErasureCoding: toErasureCoding(blockHeader),
+		Shards:        toShards(blockHeader.Shards),
+		Hash:          blockHeader.Hash,
+		CustomerKeyed: blockHeader.CustomerKeyed,
+		DataKeySealed: blockHeader.DataKeySealed,
+
+		Compression:      blockHeader.Compression,
+		UncompressedSize: int(blockHeader.UncompressedSize),
+	}
+}
+
+func FromBlockHeaderListDTO(headers dto.BlockHeaders) *BlockHeaderList {
+	list := &BlockHeaderList{
+		Headers: make([]*BlockHeader, 0, len(headers)),
+	}
+
+	for _, header := range headers {
+		list.Headers = append(list.Headers, FromBlockHeaderDTO(&header))
+	}
+	return list
+}
+
+func ToBlockHeaderListDTO(list *BlockHeaderList) dto.BlockHeaders {
+	if validation.IsNil(list) {
+		return dto.BlockHeaders{}
+	}
+
+	headers := make(dto.BlockHeaders, 0, len(list.Headers))
+	for _, header := range list.Headers {
+		headers = append(headers, ToBlockHeaderDTO(header))
+	}
+	return headers
+}
+
+func fromShards(shards entity.Shards) []*BlockShard {
+	msgs := make([]*BlockShard, 0, len(shards))
+	for _, shard := range shards {
+		msgs = append(msgs, &BlockShard{
+			BlockID:  FromBlockID(shard.BlockID),
+			Node:     shard.Node.Host,
+			Size:     int32(shard.Size),
+			Checksum: shard.Checksum,
+		})
+	}
+	return msgs
+}
+
+func toShards(msgs []*BlockShard) entity.Shards {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	shards := make(entity.Shards, 0, len(msgs))
+	for _, msg := range msgs {
+		shards = append(shards, entity.Shard{
+			BlockID:  ToBlockID(msg.BlockID),
+			Node:     entity.Node{Host: msg.Node},
+			Size:     int(msg.Size),
+			Checksum: msg.Checksum,
+		})
+	}
+	return shards
+}
+
+func toErasureCoding(blockHeader *BlockHeader) entity.ErasureCoding {
+	return entity.ErasureCoding{
+		DataShards:   int(blockHeader.DataShards),
+		ParityShards: int(blockHeader.ParityShards),
+	}
+}
+
+func toReplicas(blockHeader *BlockHeader) entity.Nodes {
+	if len(blockHeader.Replicas) == 0 && !validation.IsEmpty(blockHeader.Node) {
+		return entity.Nodes{{Host: blockHeader.Node}}
 	}
+	return entity.NewNodesFromHosts(blockHeader.Replicas)
 }
 
 func FromBlock(block *entity.Block) *Block {
@@ -282,6 +459,12 @@ func FromObjectDTO(object *dto.Object) *Object {
 		Size:         int32(object.Size),
 		VersionNum:   int32(object.VersionNum),
 		BlockHeaders: blockHeaders,
+		ExpiresAt:    timestamppb.New(object.ExpiresAt),
+		Etag:         object.ETag,
+		IfMatch:      object.IfMatch,
+		IfNoneMatch:  object.IfNoneMatch,
+		Content:      FromContentMetadata(object.ContentMetadata),
+		Encryption:   FromEncryption(object.Encryption),
 	}
 }
 
@@ -304,5 +487,366 @@ func ToObjectDTO(object *Object) *dto.Object {
 		Size:         int(object.Size),
 		VersionNum:   int(object.VersionNum),
 		BlockHeaders: blockHeaders,
+		ExpiresAt:    object.ExpiresAt.AsTime(),
+		ETag:         object.Etag,
+		IfMatch:      object.IfMatch,
+		IfNoneMatch:  object.IfNoneMatch,
+
+		ContentMetadata: ToContentMetadata(object.Content),
+		Encryption:      ToEncryption(object.Encryption),
+	}
+}
+
+func FromUploadPartDTO(part *dto.UploadPart) *UploadPart {
+	blockHeaders := make([]*BlockHeader, 0, len(part.BlockHeaders))
+	for _, header := range part.BlockHeaders {
+		blockHeaders = append(blockHeaders, FromBlockHeaderDTO(&header))
+	}
+
+	return &UploadPart{
+		Number:       int32(part.Number),
+		Size:         int32(part.Size),
+		BlockHeaders: blockHeaders,
+		Checksum:     part.Checksum,
+		CreatedAt:    timestamppb.New(part.CreatedAt),
+		ModifiedAt:   timestamppb.New(part.ModifiedAt),
+	}
+}
+
+func ToUploadPartDTO(part *UploadPart) *dto.UploadPart {
+	if validation.IsNil(part) {
+		return nil
+	}
+
+	blockHeaders := make([]dto.BlockHeader, 0, len(part.BlockHeaders))
+	for _, header := range part.BlockHeaders {
+		blockHeaders = append(blockHeaders, ToBlockHeaderDTO(header))
+	}
+
+	return &dto.UploadPart{
+		Number:       int(part.Number),
+		Size:         int(part.Size),
+		BlockHeaders: blockHeaders,
+		Checksum:     part.Checksum,
+		CreatedAt:    part.CreatedAt.AsTime(),
+		ModifiedAt:   part.ModifiedAt.AsTime(),
+	}
+}
+
+func FromUploadSessionDTO(session *dto.UploadSession) *UploadSession {
+	parts := make([]*UploadPart, 0, len(session.Parts))
+	for _, part := range session.Parts {
+		parts = append(parts, FromUploadPartDTO(&part))
+	}
+
+	return &UploadSession{
+		Id:         session.ID.ToInt64(),
+		ObjectID:   FromObjectID(session.ObjectID),
+		Group:      session.Group,
+		Partition:  session.Partition,
+		Path:       session.Path,
+		Name:       session.Name,
+		Version:    int32(session.Version),
+		Content:    FromContentMetadata(session.ContentMetadata),
+		Encryption: FromEncryption(session.Encryption),
+		Parts:      parts,
+		ExpiresAt:  timestamppb.New(session.ExpiresAt),
+		CreatedAt:  timestamppb.New(session.CreatedAt),
+		ModifiedAt: timestamppb.New(session.ModifiedAt),
+	}
+}
+
+func ToUploadSessionDTO(session *UploadSession) *dto.UploadSession {
+	if validation.IsNil(session) {
+		return nil
 	}
+
+	parts := make(dto.UploadParts, 0, len(session.Parts))
+	for _, part := range session.Parts {
+		parts = append(parts, *ToUploadPartDTO(part))
+	}
+
+	return &dto.UploadSession{
+		ID:         entity.NewUploadIDFrom(session.Id),
+		ObjectID:   ToObjectID(session.ObjectID),
+		Group:      session.Group,
+		Partition:  session.Partition,
+		Path:       session.Path,
+		Name:       session.Name,
+		Version:    int(session.Version),
+		Parts:      parts,
+		ExpiresAt:  session.ExpiresAt.AsTime(),
+		CreatedAt:  session.CreatedAt.AsTime(),
+		ModifiedAt: session.ModifiedAt.AsTime(),
+
+		ContentMetadata: ToContentMetadata(session.Content),
+		Encryption:      ToEncryption(session.Encryption),
+	}
+}
+
+func FromUploadSessionListDTO(list dto.UploadSessions) *UploadSessionList {
+	sessions := make([]*UploadSession, 0, len(list))
+	for _, session := range list {
+		sessions = append(sessions, FromUploadSessionDTO(&session))
+	}
+
+	return &UploadSessionList{
+		Sessions: sessions,
+	}
+}
+
+func ToUploadSessionListDTO(list *UploadSessionList) dto.UploadSessions {
+	sessions := make(dto.UploadSessions, 0, len(list.Sessions))
+	for _, session := range list.Sessions {
+		sessions = append(sessions, *ToUploadSessionDTO(session))
+	}
+
+	return sessions
+}
+
+func FromDirectoryDTO(directory *dto.Directory) *Directory {
+	objects := make([]*ObjectID, 0, len(directory.Objects))
+	for _, objectID := range directory.Objects {
+		objects = append(objects, FromObjectID(objectID))
+	}
+
+	return &Directory{
+		Group:          directory.Group,
+		Partition:      directory.Partition,
+		Path:           directory.Path,
+		Objects:        objects,
+		SubDirectories: directory.SubDirectories,
+		CreatedAt:      timestamppb.New(directory.CreatedAt),
+		ModifiedAt:     timestamppb.New(directory.ModifiedAt),
+	}
+}
+
+func ToDirectoryDTO(directory *Directory) *dto.Directory {
+	if validation.IsNil(directory) {
+		return nil
+	}
+
+	objects := make([]entity.ObjectID, 0, len(directory.Objects))
+	for _, objectID := range directory.Objects {
+		objects = append(objects, ToObjectID(objectID))
+	}
+
+	return &dto.Directory{
+		Group:          directory.Group,
+		Partition:      directory.Partition,
+		Path:           directory.Path,
+		Objects:        objects,
+		SubDirectories: directory.SubDirectories,
+		CreatedAt:      directory.CreatedAt.AsTime(),
+		ModifiedAt:     directory.ModifiedAt.AsTime(),
+	}
+}
+
+func FromCredentialDTO(credential *dto.Credential) *Credential {
+	return &Credential{
+		AccessKey:  credential.AccessKey,
+		SecretKey:  credential.SecretKey,
+		Principal:  credential.Principal,
+		CreatedAt:  timestamppb.New(credential.CreatedAt),
+		ModifiedAt: timestamppb.New(credential.ModifiedAt),
+	}
+}
+
+func ToCredentialDTO(credential *Credential) *dto.Credential {
+	if validation.IsNil(credential) {
+		return nil
+	}
+
+	return &dto.Credential{
+		AccessKey:  credential.AccessKey,
+		SecretKey:  credential.SecretKey,
+		Principal:  credential.Principal,
+		CreatedAt:  credential.CreatedAt.AsTime(),
+		ModifiedAt: credential.ModifiedAt.AsTime(),
+	}
+}
+
+func FromPolicyDTO(policy *dto.Policy) *Policy {
+	statements := make([]*PolicyStatement, 0, len(policy.Statements))
+	for _, statement := range policy.Statements {
+		actions := make([]string, 0, len(statement.Actions))
+		for _, action := range statement.Actions {
+			actions = append(actions, string(action))
+		}
+
+		statements = append(statements, &PolicyStatement{
+			Effect:     string(statement.Effect),
+			Principals: statement.Principals,
+			Actions:    actions,
+			Prefix:     statement.Prefix,
+		})
+	}
+
+	return &Policy{
+		Group:      policy.Group,
+		Partition:  policy.Partition,
+		Statements: statements,
+		CreatedAt:  timestamppb.New(policy.CreatedAt),
+		ModifiedAt: timestamppb.New(policy.ModifiedAt),
+	}
+}
+
+func ToPolicyDTO(policy *Policy) *dto.Policy {
+	if validation.IsNil(policy) {
+		return nil
+	}
+
+	statements := make(dto.PolicyStatements, 0, len(policy.Statements))
+	for _, statement := range policy.Statements {
+		actions := make([]entity.PolicyAction, 0, len(statement.Actions))
+		for _, action := range statement.Actions {
+			actions = append(actions, entity.PolicyAction(action))
+		}
+
+		statements = append(statements, dto.PolicyStatement{
+			Effect:     entity.PolicyEffect(statement.Effect),
+			Principals: statement.Principals,
+			Actions:    actions,
+			Prefix:     statement.Prefix,
+		})
+	}
+
+	return &dto.Policy{
+		Group:      policy.Group,
+		Partition:  policy.Partition,
+		Statements: statements,
+		CreatedAt:  policy.CreatedAt.AsTime(),
+		ModifiedAt: policy.ModifiedAt.AsTime(),
+	}
+}
+
+func FromPoliciesDTO(policies dto.Policies) *Policies {
+	msg := &Policies{
+		Policies: make([]*Policy, 0, len(policies)),
+	}
+
+	for _, policy := range policies {
+		msg.Policies = append(msg.Policies, FromPolicyDTO(&policy))
+	}
+	return msg
+}
+
+func ToPoliciesDTO(policies *Policies) dto.Policies {
+	if validation.IsNil(policies) {
+		return dto.Policies{}
+	}
+
+	list := make(dto.Policies, 0, len(policies.Policies))
+	for _, policy := range policies.Policies {
+		list = append(list, *ToPolicyDTO(policy))
+	}
+	return list
+}
+
+func FromScrubPassDTO(pass *dto.ScrubPass) *ScrubPass {
+	return &ScrubPass{
+		StartedAt:       timestamppb.New(pass.StartedAt),
+		FinishedAt:      timestamppb.New(pass.FinishedAt),
+		ScannedBlocks:   int64(pass.ScannedBlocks),
+		ScannedBytes:    pass.ScannedBytes,
+		CorruptedBlocks: int64(pass.CorruptedBlocks),
+		RepairedBlocks:  int64(pass.RepairedBlocks),
+		Error:           pass.Error,
+	}
+}
+
+func ToScrubPassDTO(pass *ScrubPass) dto.ScrubPass {
+	if validation.IsNil(pass) {
+		return dto.ScrubPass{}
+	}
+
+	return dto.ScrubPass{
+		StartedAt:       pass.StartedAt.AsTime(),
+		FinishedAt:      pass.FinishedAt.AsTime(),
+		ScannedBlocks:   int(pass.ScannedBlocks),
+		ScannedBytes:    pass.ScannedBytes,
+		CorruptedBlocks: int(pass.CorruptedBlocks),
+		RepairedBlocks:  int(pass.RepairedBlocks),
+		Error:           pass.Error,
+	}
+}
+
+func FromScrubStatusDTO(status *dto.ScrubStatus) *ScrubStatus {
+	corruptions := make([]*ScrubCorruption, 0, len(status.Corruptions))
+	for _, corruption := range status.Corruptions {
+		corruptions = append(corruptions, &ScrubCorruption{
+			ObjectID:     FromObjectID(corruption.ObjectID),
+			BlockID:      FromBlockID(corruption.BlockID),
+			Index:        int32(corruption.Index),
+			Reason:       corruption.Reason,
+			Quarantined:  corruption.Quarantined,
+			Repaired:     corruption.Repaired,
+			RepairedFrom: corruption.RepairedFrom,
+			Error:        corruption.Error,
+			DetectedAt:   timestamppb.New(corruption.DetectedAt),
+			Hash:         corruption.Hash,
+		})
+	}
+
+	return &ScrubStatus{
+		Running:     status.Running,
+		Passes:      int64(status.Passes),
+		Current:     FromScrubPassDTO(&status.Current),
+		Last:        FromScrubPassDTO(&status.Last),
+		Corruptions: corruptions,
+	}
+}
+
+func ToScrubStatusDTO(status *ScrubStatus) dto.ScrubStatus {
+	if validation.IsNil(status) {
+		return dto.ScrubStatus{}
+	}
+
+	corruptions := make(dto.ScrubCorruptions, 0, len(status.Corruptions))
+	for _, corruption := range status.Corruptions {
+		corruptions = append(corruptions, dto.ScrubCorruption{
+			ObjectID:     ToObjectID(corruption.ObjectID),
+			BlockID:      ToBlockID(corruption.BlockID),
+			Index:        int(corruption.Index),
+			Reason:       corruption.Reason,
+			Quarantined:  corruption.Quarantined,
+			Repaired:     corruption.Repaired,
+			RepairedFrom: corruption.RepairedFrom,
+			Error:        corruption.Error,
+			DetectedAt:   corruption.DetectedAt.AsTime(),
+			Hash:         corruption.Hash,
+		})
+	}
+
+	return dto.ScrubStatus{
+		Running:     status.Running,
+		Passes:      int(status.Passes),
+		Current:     ToScrubPassDTO(status.Current),
+		Last:        ToScrubPassDTO(status.Last),
+		Corruptions: corruptions,
+	}
+}
+
+func FromDedupStatsDTO(stats *dto.DedupStats) *DedupStats {
+	return &DedupStats{
+		Blocks:        int64(stats.Blocks),
+		References:    stats.References,
+		PhysicalBytes: stats.PhysicalBytes,
+		LogicalBytes:  stats.LogicalBytes,
+	}
+}
+
+func ToDedupStatsDTO(stats *DedupStats) dto.DedupStats {
+	if validation.IsNil(stats) {
+		return dto.DedupStats{}
+	}
+
+	result := dto.DedupStats{}
+	result.Add(dto.DedupStats{
+		Blocks:        int(stats.Blocks),
+		References:    stats.References,
+		PhysicalBytes: stats.PhysicalBytes,
+		LogicalBytes:  stats.LogicalBytes,
+	})
+	return result
 }
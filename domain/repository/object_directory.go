@@ -24,16 +24,20 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/ISSuh/sos/domain/model/entity"
 )
 
+// ObjectDirectory keeps the directory tree of a partition.
+// Adding an entry creates the directory when it does not exist yet. Every write increases the revision
+// of the directory, and a sub directory is only removed at the revision it was read at. It fails with
+// conflict otherwise.
 type ObjectDirectory interface {
-	Create(c context.Context, metadata *entity.Directory) error
-	Delete(c context.Context, metadata *entity.Directory) error
-	AddObject(c context.Context, metadata *entity.Directory) error
-	DeleteObject(c context.Context, metadata *entity.Directory) error
-	AddSubDirectory(c context.Context, metadata *entity.Directory) error
-	DeleteSubDirectory(c context.Context, metadata *entity.Directory) error
+	AddObject(c context.Context, group, partition, path string, objectID entity.ObjectID, now time.Time) error
+	DeleteObject(c context.Context, group, partition, path string, objectID entity.ObjectID, now time.Time) error
+	AddSubDirectory(c context.Context, group, partition, path, name string, now time.Time) error
+	DeleteSubDirectory(c context.Context, group, partition, path, name string, revision int64, now time.Time) error
+	DeleteIfEmpty(c context.Context, group, partition, path string) (bool, error)
 	FindMetadata(c context.Context, group, partition, path string) (*entity.Directory, error)
 }
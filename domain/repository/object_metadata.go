@@ -24,15 +24,50 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/ISSuh/sos/domain/model/entity"
 )
 
+// ObjectMetadata fails a write with a conflict error when it would clobber another one.
+// Create conflicts with an object of the same name on the path, and Update and Delete
+// conflict when the metadata was written since it was read.
 type ObjectMetadata interface {
 	Create(c context.Context, metadata *entity.ObjectMetadata) error
 	Update(c context.Context, metadata *entity.ObjectMetadata) error
 	Delete(c context.Context, metadata *entity.ObjectMetadata) error
+
+	// Move stores the metadata under the path and name on the same partition. Like Update, it fails
+	// with a conflict when the metadata was written since it was read, or when the name is taken.
+	Move(c context.Context, metadata *entity.ObjectMetadata, path, name string) error
+
 	MetadataByObjectName(c context.Context, group, partition, path, name string) (*entity.ObjectMetadata, error)
 	MetadataByObjectID(c context.Context, group, partition, path string, objectID int64) (*entity.ObjectMetadata, error)
-	FindMetadata(c context.Context, group, partition, path string) (entity.ObjectMetadataList, error)
+	// FindMetadata returns the metadata on the path ordered by object id, starting after lastObjectID.
+	// A limit of 0 returns everything.
+	FindMetadata(
+		c context.Context, group, partition, path string, lastObjectID int64, limit int,
+	) (entity.ObjectMetadataList, error)
+
+	// FindMetadataByTags returns the metadata in the partition whose tags match the expression, ordered
+	// by object id and starting after lastObjectID. A limit of 0 returns everything.
+	FindMetadataByTags(
+		c context.Context, group, partition string, expression entity.TagExpression, lastObjectID int64, limit int,
+	) (entity.ObjectMetadataList, error)
+
+	// MetadataByObjectIDs returns the metadata of the objects wherever they are stored.
+	MetadataByObjectIDs(c context.Context, objectIDs []int64) (entity.ObjectMetadataList, error)
+
+	// MetadataByContentHashes returns the metadata holding a block of deduplicated content with one of the hashes.
+	MetadataByContentHashes(c context.Context, hashes []string) (entity.ObjectMetadataList, error)
+
+	// FindMetadataByStaleDataKey returns the metadata holding a committed version whose data key is
+	// wrapped by another master key than keyID, ordered by object id and starting after lastObjectID.
+	// A limit of 0 returns everything.
+	FindMetadataByStaleDataKey(
+		c context.Context, keyID string, lastObjectID int64, limit int,
+	) (entity.ObjectMetadataList, error)
+
+	// MetadataWithExpiredVersions returns the metadata holding a pending version expired at now.
+	MetadataWithExpiredVersions(c context.Context, now time.Time) (entity.ObjectMetadataList, error)
 }
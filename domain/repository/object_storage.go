@@ -24,6 +24,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/ISSuh/sos/domain/model/entity"
 )
@@ -40,4 +41,64 @@ type ObjectStorage interface {
 	) (*entity.BlockHeader, error)
 
 	Delete(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) error
+
+	// Scan returns up to limit stored blocks after the cursor in key order and the cursor of
+	// the next page, which is empty once every block was visited. The deduplicated content is
+	// visited after the blocks.
+	Scan(c context.Context, cursor string, limit int) ([]ScannedBlock, string, error)
+
+	// ScanQuarantined is Scan over the quarantined blocks.
+	ScanQuarantined(c context.Context, cursor string, limit int) ([]ScannedBlock, string, error)
+
+	// Quarantine moves a stored block aside so it is no longer served.
+	Quarantine(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) error
+
+	DeleteQuarantined(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) error
+
+	// PutContent stores a deduplicated block under its content hash, unless the content is
+	// already stored, and takes a reference on it.
+	PutContent(c context.Context, block *entity.Block) error
+
+	// ReferenceContent takes another reference on stored content. It reports false without a
+	// reference when the content is not stored.
+	ReferenceContent(c context.Context, hash string) (bool, error)
+
+	GetContent(c context.Context, hash string) (*entity.Block, error)
+
+	// ReleaseContent drops a reference on stored content and deletes it with the last one.
+	ReleaseContent(c context.Context, hash string) error
+
+	// ReplaceContent replaces the data of stored content, keeping its references.
+	ReplaceContent(c context.Context, block *entity.Block) error
+
+	// ReconcileContent sets the reference count of stored content, deleting the content at zero,
+	// unless the count changed since before. It returns the count found and whether it was set.
+	ReconcileContent(c context.Context, hash string, references int64, before time.Time) (int64, bool, error)
+
+	ContentStats(c context.Context) (ContentStats, error)
+}
+
+// ContentStats counts the deduplicated content. LogicalBytes is what the references would
+// take without deduplication, PhysicalBytes what is actually stored.
+type ContentStats struct {
+	Blocks        int
+	References    int64
+	PhysicalBytes int64
+	LogicalBytes  int64
+}
+
+// ScannedBlock is a stored block visited by Scan. Err is set instead of Block when the
+// stored value can not be decoded. Deduplicated content is only identified by its Hash, and
+// carries its reference count and the time the count last changed.
+type ScannedBlock struct {
+	ObjectID entity.ObjectID
+	BlockID  entity.BlockID
+	Index    int
+	Size     int
+	Block    *entity.Block
+	Err      error
+
+	Hash         string
+	References   int64
+	ReferencedAt time.Time
 }
@@ -0,0 +1,42 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+)
+
+type UploadSession interface {
+	Create(c context.Context, session *entity.UploadSession) error
+	Delete(c context.Context, uploadID int64) error
+	PutPart(c context.Context, uploadID int64, part *entity.UploadPart, now time.Time) error
+	SessionByID(c context.Context, uploadID int64) (*entity.UploadSession, error)
+	FindExpired(c context.Context, now time.Time) (entity.UploadSessions, error)
+	SessionsByObjectIDs(c context.Context, objectIDs []int64) (entity.UploadSessions, error)
+
+	// SessionsByContentHashes returns the sessions holding a part block of deduplicated content with one of the hashes.
+	SessionsByContentHashes(c context.Context, hashes []string) (entity.UploadSessions, error)
+}
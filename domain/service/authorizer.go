@@ -0,0 +1,125 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/model/message"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
+	"github.com/ISSuh/sos/internal/auth"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/validation"
+)
+
+// Authorizer checks the principal of a request against the policies of its group and partition.
+// A request without a principal is allowed, because authentication is disabled.
+type Authorizer interface {
+	Authorize(c context.Context, action entity.PolicyAction, group, partition, resource string) error
+	AuthorizeAdmin(c context.Context) error
+}
+
+type authorizer struct {
+	metadataRequestor rpc.MetadataRegistryRequestor
+	admins            map[string]bool
+}
+
+func NewAuthorizer(metadataRequestor rpc.MetadataRegistryRequestor, admins []string) (Authorizer, error) {
+	switch {
+	case validation.IsNil(metadataRequestor):
+		return nil, errors.New("MetadataRegistry requestor is nil")
+	}
+
+	a := &authorizer{
+		metadataRequestor: metadataRequestor,
+		admins:            map[string]bool{},
+	}
+	for _, admin := range admins {
+		a.admins[admin] = true
+	}
+	return a, nil
+}
+
+// Authorize allows an action when a statement allows it and no statement denies it.
+// Admins are allowed any action.
+func (a *authorizer) Authorize(
+	c context.Context, action entity.PolicyAction, group, partition, resource string,
+) error {
+	principal, ok := auth.PrincipalFromContext(c)
+	if !ok || a.admins[principal.Name] {
+		return nil
+	}
+
+	msg := rpcmessage.PolicyRequest{
+		Group:     group,
+		Partition: partition,
+	}
+
+	resp, err := a.metadataRequestor.FindPolicies(c, &msg)
+	if err != nil {
+		return err
+	}
+
+	allowed := false
+	for _, policy := range message.ToPoliciesDTO(resp) {
+		for _, statement := range policy.Statements {
+			if !statement.Matches(principal.Name, action, resource) {
+				continue
+			}
+
+			if statement.Effect == entity.PolicyEffectDeny {
+				return a.deny(c, principal, action, group, partition, resource)
+			}
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		return a.deny(c, principal, action, group, partition, resource)
+	}
+	return nil
+}
+
+// AuthorizeAdmin allows only admins, e.g. to manage policies.
+func (a *authorizer) AuthorizeAdmin(c context.Context) error {
+	principal, ok := auth.PrincipalFromContext(c)
+	if !ok || a.admins[principal.Name] {
+		return nil
+	}
+
+	log.FromContext(c).Infof("[authorizer.AuthorizeAdmin] principal %s is not an admin", principal.Name)
+	return soserror.NewForbiddenError(fmt.Errorf("principal %s is not an admin", principal.Name))
+}
+
+func (a *authorizer) deny(
+	c context.Context, principal auth.Principal, action entity.PolicyAction, group, partition, resource string,
+) error {
+	log.FromContext(c).Infof("[authorizer.Authorize] principal %s is denied %s on %s/%s/%s",
+		principal.Name, action, group, partition, resource)
+	return soserror.NewForbiddenError(fmt.Errorf("principal %s is denied %s on %s", principal.Name, action, resource))
+}
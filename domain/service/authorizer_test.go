@@ -0,0 +1,156 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/model/message"
+	"github.com/ISSuh/sos/domain/service"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
+	"github.com/ISSuh/sos/internal/auth"
+	soserror "github.com/ISSuh/sos/internal/error"
+)
+
+// policyRegistry serves fixed policies and fails on any other call of the registry.
+type policyRegistry struct {
+	rpc.MetadataRegistryRequestor
+	policies dto.Policies
+	lookups  int
+}
+
+func (r *policyRegistry) FindPolicies(c context.Context, req *rpcmessage.PolicyRequest) (*message.Policies, error) {
+	r.lookups++
+	return message.FromPoliciesDTO(r.policies), nil
+}
+
+func statement(effect entity.PolicyEffect, principal string, action entity.PolicyAction, prefix string) dto.PolicyStatement {
+	return dto.PolicyStatement{
+		Effect:     effect,
+		Principals: []string{principal},
+		Actions:    []entity.PolicyAction{action},
+		Prefix:     prefix,
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	registry := &policyRegistry{
+		policies: dto.Policies{
+			{
+				Group: "group",
+				Statements: dto.PolicyStatements{
+					statement(entity.PolicyEffectAllow, entity.PolicyPrincipalAll, entity.PolicyActionGet, "public/"),
+					statement(entity.PolicyEffectAllow, "alice", entity.PolicyActionAll, "home/alice"),
+				},
+			},
+			{
+				Group:     "group",
+				Partition: "partition",
+				Statements: dto.PolicyStatements{
+					statement(entity.PolicyEffectDeny, entity.PolicyPrincipalAll, entity.PolicyActionAll, "home/alice/secret/"),
+					statement(entity.PolicyEffectDeny, "bob", entity.PolicyActionGet, "public/drafts/"),
+				},
+			},
+		},
+	}
+
+	authorizer, err := service.NewAuthorizer(registry, []string{"root"})
+	must(t, err)
+
+	tests := []struct {
+		principal string
+		action    entity.PolicyAction
+		resource  string
+		allowed   bool
+	}{
+		// a wildcard principal allows anyone, a wildcard action allows anything
+		{"bob", entity.PolicyActionGet, "public/a.txt", true},
+		{"alice", entity.PolicyActionDeleteVersion, "home/alice/a.txt", true},
+		{"bob", entity.PolicyActionPut, "public/a.txt", false},
+
+		// a deny wins over an allow, in another policy as well
+		{"alice", entity.PolicyActionGet, "home/alice/secret/key.pem", false},
+		{"bob", entity.PolicyActionGet, "public/drafts/a.txt", false},
+		{"carol", entity.PolicyActionGet, "public/drafts/a.txt", true},
+
+		// a prefix covers whole path segments only
+		{"alice", entity.PolicyActionGet, "home/alice", true},
+		{"alice", entity.PolicyActionGet, "home/alice2/a.txt", false},
+
+		// nothing allows the rest
+		{"bob", entity.PolicyActionList, "", false},
+
+		// admins are allowed anything, a denied resource as well
+		{"root", entity.PolicyActionGet, "home/alice/secret/key.pem", true},
+	}
+
+	for _, test := range tests {
+		c := auth.WithPrincipal(context.Background(), auth.Principal{Name: test.principal})
+		err := authorizer.Authorize(c, test.action, "group", "partition", test.resource)
+
+		switch {
+		case test.allowed && err != nil:
+			t.Errorf("%s %s %q: %v, want allowed", test.principal, test.action, test.resource, err)
+		case !test.allowed && !errors.Is(err, soserror.Forbidden):
+			t.Errorf("%s %s %q: %v, want forbidden", test.principal, test.action, test.resource, err)
+		}
+	}
+}
+
+// Without authentication there is no principal, and the policies are not looked up at all.
+func TestAuthorizeWithoutPrincipal(t *testing.T) {
+	registry := &policyRegistry{
+		policies: dto.Policies{{
+			Group: "group",
+			Statements: dto.PolicyStatements{
+				statement(entity.PolicyEffectDeny, entity.PolicyPrincipalAll, entity.PolicyActionAll, ""),
+			},
+		}},
+	}
+
+	authorizer, err := service.NewAuthorizer(registry, nil)
+	must(t, err)
+
+	must(t, authorizer.Authorize(context.Background(), entity.PolicyActionPut, "group", "partition", "a.txt"))
+	must(t, authorizer.AuthorizeAdmin(context.Background()))
+	if registry.lookups != 0 {
+		t.Errorf("policies looked up %d times, want 0", registry.lookups)
+	}
+}
+
+func TestAuthorizeAdmin(t *testing.T) {
+	authorizer, err := service.NewAuthorizer(&policyRegistry{}, []string{"root"})
+	must(t, err)
+
+	root := auth.WithPrincipal(context.Background(), auth.Principal{Name: "root"})
+	must(t, authorizer.AuthorizeAdmin(root))
+
+	alice := auth.WithPrincipal(context.Background(), auth.Principal{Name: "alice"})
+	if err := authorizer.AuthorizeAdmin(alice); !errors.Is(err, soserror.Forbidden) {
+		t.Errorf("alice: %v, want forbidden", err)
+	}
+}
@@ -0,0 +1,94 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/repository"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/validation"
+)
+
+type Credential interface {
+	Put(c context.Context, credentialDTO *dto.Credential) (*dto.Credential, error)
+	Delete(c context.Context, accessKey string) error
+	CredentialByAccessKey(c context.Context, accessKey string) (*dto.Credential, error)
+}
+
+type credential struct {
+	credentialRepository repository.Credential
+}
+
+func NewCredential(credentialRepository repository.Credential) (Credential, error) {
+	switch {
+	case validation.IsNil(credentialRepository):
+		return nil, fmt.Errorf("CredentialRepository is nil")
+	}
+
+	return &credential{
+		credentialRepository: credentialRepository,
+	}, nil
+}
+
+func (s *credential) Put(c context.Context, credentialDTO *dto.Credential) (*dto.Credential, error) {
+	log.FromContext(c).Debugf("[credential.Put] accessKey: %s", credentialDTO.AccessKey)
+	switch {
+	case validation.IsEmpty(credentialDTO.AccessKey):
+		return nil, fmt.Errorf("access key is empty")
+	case validation.IsEmpty(credentialDTO.SecretKey):
+		return nil, fmt.Errorf("secret key is empty")
+	}
+
+	// a credential without a principal acts as the principal named by its access key
+	if validation.IsEmpty(credentialDTO.Principal) {
+		credentialDTO.Principal = credentialDTO.AccessKey
+	}
+
+	now := time.Now()
+	credentialDTO.CreatedAt = now
+	credentialDTO.ModifiedAt = now
+
+	credential := credentialDTO.ToEntity()
+	if err := s.credentialRepository.Put(c, &credential); err != nil {
+		return nil, err
+	}
+
+	return dto.NewCredentialFromModel(&credential), nil
+}
+
+func (s *credential) Delete(c context.Context, accessKey string) error {
+	log.FromContext(c).Debugf("[credential.Delete] accessKey: %s", accessKey)
+	return s.credentialRepository.Delete(c, accessKey)
+}
+
+func (s *credential) CredentialByAccessKey(c context.Context, accessKey string) (*dto.Credential, error) {
+	credential, err := s.credentialRepository.CredentialByAccessKey(c, accessKey)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewCredentialFromModel(credential), nil
+}
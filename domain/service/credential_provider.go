@@ -0,0 +1,101 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/message"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
+	"github.com/ISSuh/sos/internal/auth"
+	"github.com/ISSuh/sos/internal/validation"
+)
+
+// credentials are cached so a signed request does not cost a registry round trip,
+// a changed or deleted credential takes effect on the explorer after this long
+const credentialCacheTTL = time.Minute
+
+type cachedCredential struct {
+	credential auth.Credential
+	expiresAt  time.Time
+}
+
+// CredentialProvider looks up the credentials of signed requests on the metadata registry.
+type CredentialProvider struct {
+	metadataRequestor rpc.MetadataRegistryRequestor
+
+	mutex sync.Mutex
+	cache map[string]cachedCredential
+}
+
+func NewCredentialProvider(metadataRequestor rpc.MetadataRegistryRequestor) (*CredentialProvider, error) {
+	switch {
+	case validation.IsNil(metadataRequestor):
+		return nil, errors.New("MetadataRegistry requestor is nil")
+	}
+
+	return &CredentialProvider{
+		metadataRequestor: metadataRequestor,
+		cache:             map[string]cachedCredential{},
+	}, nil
+}
+
+func (p *CredentialProvider) Credential(c context.Context, accessKey string) (auth.Credential, error) {
+	now := time.Now()
+
+	p.mutex.Lock()
+	cached, ok := p.cache[accessKey]
+	p.mutex.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.credential, nil
+	}
+
+	msg, err := p.metadataRequestor.GetCredential(c, &rpcmessage.CredentialRequest{AccessKey: accessKey})
+	if err != nil {
+		return auth.Credential{}, err
+	}
+
+	credentialDTO := message.ToCredentialDTO(msg)
+	credential := auth.Credential{
+		AccessKey: credentialDTO.AccessKey,
+		SecretKey: credentialDTO.SecretKey,
+		Principal: credentialDTO.Principal,
+	}
+
+	p.mutex.Lock()
+	for key, cached := range p.cache {
+		if now.After(cached.expiresAt) {
+			delete(p.cache, key)
+		}
+	}
+	p.cache[accessKey] = cachedCredential{
+		credential: credential,
+		expiresAt:  now.Add(credentialCacheTTL),
+	}
+	p.mutex.Unlock()
+	return credential, nil
+}
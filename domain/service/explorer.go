@@ -25,7 +25,12 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"math"
+	gohttp "net/http"
+	"sort"
+	"time"
 
 	"github.com/ISSuh/sos/domain/model/dto"
 	"github.com/ISSuh/sos/domain/model/entity"
@@ -33,40 +38,93 @@ import (
 	"github.com/ISSuh/sos/domain/service/object"
 	"github.com/ISSuh/sos/infrastructure/transport/rpc"
 	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
+	"github.com/ISSuh/sos/internal/auth"
+	"github.com/ISSuh/sos/internal/checksum"
 	"github.com/ISSuh/sos/internal/empty"
+	"github.com/ISSuh/sos/internal/encryption"
 	soserror "github.com/ISSuh/sos/internal/error"
 	"github.com/ISSuh/sos/internal/http"
+	"github.com/ISSuh/sos/internal/kms"
+	"github.com/ISSuh/sos/internal/log"
 	"github.com/ISSuh/sos/internal/validation"
 )
 
 type Explorer interface {
 	GetObjectMetadata(c context.Context, req dto.Request) (dto.Item, error)
-	FindObjectMetadataOnPath(c context.Context, req dto.Request) (dto.Items, error)
+	GetObjectMetadataByName(c context.Context, req dto.Request) (dto.Item, error)
+	FindObjectMetadataOnPath(c context.Context, req dto.Request) (dto.ItemsPage, error)
+	ListChildren(c context.Context, req dto.Request) (dto.Children, error)
 	Upload(c context.Context, req dto.Request, bodyStream io.ReadCloser) (dto.Item, error)
 	Download(
 		c context.Context, req dto.Request, writer http.Writer, lastVersion bool,
 	) error
+	Head(c context.Context, req dto.Request, writer http.Writer, lastVersion bool) error
 	Delete(c context.Context, req dto.Request, deleteVersion bool) error
+	Copy(c context.Context, req dto.Request, lastVersion bool) (dto.Item, error)
+	Move(c context.Context, req dto.Request) (dto.Item, error)
+	GetTags(c context.Context, req dto.Request) (dto.ObjectTags, error)
+	PutTags(c context.Context, req dto.Request) (dto.ObjectTags, error)
+	DeleteTags(c context.Context, req dto.Request) error
+	FindObjectsByTags(c context.Context, req dto.Request) (dto.ItemsPage, error)
+	InitiateUpload(c context.Context, req dto.Request) (dto.UploadSession, error)
+	UploadPart(c context.Context, req dto.Request, bodyStream io.ReadCloser) (dto.UploadPart, error)
+	CompleteUpload(c context.Context, req dto.Request) (dto.Item, error)
+	AbortUpload(c context.Context, req dto.Request) error
+	GetPolicy(c context.Context, req dto.Request) (dto.Policy, error)
+	PutPolicy(c context.Context, req dto.Request, policy dto.Policy) (dto.Policy, error)
+	DeletePolicy(c context.Context, req dto.Request) error
+	Presign(c context.Context, req dto.Request) (auth.PresignedURL, error)
+	CollectGarbage(c context.Context, req dto.Request) (dto.GarbageReport, error)
+	GetGarbageReport(c context.Context) (dto.GarbageReport, error)
+	GetDedupReport(c context.Context) (dto.DedupReport, error)
+	RotateDataKeys(c context.Context) (dto.RotationReport, error)
 }
 
+const (
+	MaxUploadPartNumber = 10000
+	MaxListLimit        = 1000
+
+	// MaxUploadPartBlocks is the range of block indices reserved for every part number, so the blocks
+	// of the parts keep their index in the object the upload is completed as
+	MaxUploadPartBlocks = math.MaxInt32 / MaxUploadPartNumber
+)
+
 type explorer struct {
 	metadataRequestor rpc.MetadataRegistryRequestor
-	storageRequestor  rpc.BlockStorageRequestor
+	storageCluster    *object.StorageCluster
+	sessionExpiry     time.Duration
+	authorizer        Authorizer
+	presigner         *auth.Presigner
+	collector         GarbageCollector
+	kms               kms.KMS
 }
 
+// NewExplorer returns an explorer that issues no presigned URL when presigner is nil,
+// collects no garbage when collector is nil and encrypts no object when keyService is nil.
 func NewExplorer(
-	metadataRequestor rpc.MetadataRegistryRequestor, storageRequestor rpc.BlockStorageRequestor,
+	metadataRequestor rpc.MetadataRegistryRequestor, storageCluster *object.StorageCluster,
+	sessionExpiry time.Duration, authorizer Authorizer, presigner *auth.Presigner, collector GarbageCollector,
+	keyService kms.KMS,
 ) (Explorer, error) {
 	switch {
 	case validation.IsNil(metadataRequestor):
 		return nil, errors.New("MetadataRegistry requestor is nil")
-	case validation.IsNil(storageRequestor):
-		return nil, errors.New("BlockStorage requestor is nil")
+	case validation.IsNil(storageCluster):
+		return nil, errors.New("BlockStorage cluster is nil")
+	case sessionExpiry <= 0:
+		return nil, errors.New("upload session expiry is invalid")
+	case validation.IsNil(authorizer):
+		return nil, errors.New("authorizer is nil")
 	}
 
 	return &explorer{
 		metadataRequestor: metadataRequestor,
-		storageRequestor:  storageRequestor,
+		storageCluster:    storageCluster,
+		sessionExpiry:     sessionExpiry,
+		authorizer:        authorizer,
+		presigner:         presigner,
+		collector:         collector,
+		kms:               keyService,
 	}, nil
 }
 
@@ -88,17 +146,94 @@ func (s *explorer) GetObjectMetadata(c context.Context, req dto.Request) (dto.It
 		return empty.Struct[dto.Item](), err
 	}
 
+	if err := s.authorizeObject(c, entity.PolicyActionGet, metadata); err != nil {
+		return empty.Struct[dto.Item](), err
+	}
+
 	return dto.NewItemFromMetadata(*metadata), nil
 }
 
-func (s *explorer) FindObjectMetadataOnPath(c context.Context, req dto.Request) (dto.Items, error) {
+func (s *explorer) GetObjectMetadataByName(c context.Context, req dto.Request) (dto.Item, error) {
 	switch {
 	case validation.IsEmpty(req.Group):
-		return nil, errors.New("group is empty")
+		return empty.Struct[dto.Item](), errors.New("group is empty")
 	case validation.IsEmpty(req.Partition):
-		return nil, errors.New("partition is empty")
+		return empty.Struct[dto.Item](), errors.New("partition is empty")
 	case validation.IsEmpty(req.Path):
-		return nil, errors.New("path is empty")
+		return empty.Struct[dto.Item](), errors.New("path is empty")
+	}
+
+	resource := entity.PolicyResource(req.Path, req.Name)
+	if err := s.authorizer.Authorize(c, entity.PolicyActionGet, req.Group, req.Partition, resource); err != nil {
+		return empty.Struct[dto.Item](), err
+	}
+
+	metadata, err := s.getObjectMetadataByNameOnPath(c, req.Group, req.Partition, req.Path, req.Name)
+	if err != nil {
+		return empty.Struct[dto.Item](), err
+	}
+
+	if metadata == nil || !metadata.ID.IsValid() {
+		return empty.Struct[dto.Item](), soserror.NewNotFoundError(errors.New("object not exist"))
+	}
+
+	return dto.NewItemFromMetadata(*metadata), nil
+}
+
+func (s *explorer) FindObjectMetadataOnPath(c context.Context, req dto.Request) (dto.ItemsPage, error) {
+	switch {
+	case validation.IsEmpty(req.Group):
+		return empty.Struct[dto.ItemsPage](), errors.New("group is empty")
+	case validation.IsEmpty(req.Partition):
+		return empty.Struct[dto.ItemsPage](), errors.New("partition is empty")
+	case validation.IsEmpty(req.Path):
+		return empty.Struct[dto.ItemsPage](), errors.New("path is empty")
+	case req.Limit < 0:
+		return empty.Struct[dto.ItemsPage](), errors.New("limit is invalid")
+	}
+
+	resource := entity.PolicyResource(req.Path, "")
+	if err := s.authorizer.Authorize(c, entity.PolicyActionList, req.Group, req.Partition, resource); err != nil {
+		return empty.Struct[dto.ItemsPage](), err
+	}
+
+	return s.findObjectMetadataOnPath(c, req)
+}
+
+func (s *explorer) findObjectMetadataOnPath(c context.Context, req dto.Request) (dto.ItemsPage, error) {
+	// a listing is always paged so a large path can not be returned in one response
+	limit := req.Limit
+	if limit == 0 || limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	msg := rpcmessage.ObjectMetadataRequest{
+		Group:        req.Group,
+		Partition:    req.Partition,
+		Path:         req.Path,
+		Limit:        int32(limit),
+		LastObjectID: req.LastObjectID.ToInt64(),
+	}
+
+	resp, err := s.metadataRequestor.FindMetadataOnPath(c, &msg)
+	if err != nil {
+		return empty.Struct[dto.ItemsPage](), err
+	}
+
+	return message.ToItemsPageDTO(resp), nil
+}
+
+func (s *explorer) ListChildren(c context.Context, req dto.Request) (dto.Children, error) {
+	switch {
+	case validation.IsEmpty(req.Group):
+		return empty.Struct[dto.Children](), errors.New("group is empty")
+	case validation.IsEmpty(req.Partition):
+		return empty.Struct[dto.Children](), errors.New("partition is empty")
+	}
+
+	resource := entity.PolicyResource(req.Path, "")
+	if err := s.authorizer.Authorize(c, entity.PolicyActionList, req.Group, req.Partition, resource); err != nil {
+		return empty.Struct[dto.Children](), err
 	}
 
 	msg := rpcmessage.ObjectMetadataRequest{
@@ -107,12 +242,30 @@ func (s *explorer) FindObjectMetadataOnPath(c context.Context, req dto.Request)
 		Path:      req.Path,
 	}
 
-	resp, err := s.metadataRequestor.FindMetadataOnPath(c, &msg)
+	resp, err := s.metadataRequestor.GetDirectory(c, &msg)
 	if err != nil {
-		return nil, err
+		return empty.Struct[dto.Children](), err
+	}
+
+	directory := message.ToDirectoryDTO(resp)
+	children := dto.Children{
+		Group:       directory.Group,
+		Partition:   directory.Partition,
+		Path:        directory.Path,
+		Directories: append([]string{}, directory.SubDirectories...),
+		Items:       dto.Items{},
 	}
+	sort.Strings(children.Directories)
 
-	return message.ToItemsDTO(resp), nil
+	if len(directory.Objects) > 0 {
+		page, err := s.findObjectMetadataOnPath(c, req)
+		if err != nil {
+			return empty.Struct[dto.Children](), err
+		}
+		children.Items = page.Items
+		children.NextCursor = page.NextCursor
+	}
+	return children, nil
 }
 
 func (s *explorer) Upload(c context.Context, req dto.Request, bodyStream io.ReadCloser) (dto.Item, error) {
@@ -125,39 +278,57 @@ func (s *explorer) Upload(c context.Context, req dto.Request, bodyStream io.Read
 		return empty.Struct[dto.Item](), errors.New("path is empty")
 	case validation.IsEmpty(req.Name):
 		return empty.Struct[dto.Item](), errors.New("name is empty")
-	case req.Size <= 0:
+	case req.Size < 0:
 		return empty.Struct[dto.Item](), errors.New("size is invalid")
 	case validation.IsNil(bodyStream):
 		return empty.Struct[dto.Item](), errors.New("body stream is nil")
 	}
 
-	metadata, err := s.getObjectMetadataByNameOnPath(c, req.Group, req.Partition, req.Path, req.Name)
-	if err != nil && !errors.Is(err, soserror.NotFound) {
+	if err := req.ContentMetadata.Validate(); err != nil {
+		return empty.Struct[dto.Item](), err
+	}
+
+	resource := entity.PolicyResource(req.Path, req.Name)
+	if err := s.authorizer.Authorize(c, entity.PolicyActionPut, req.Group, req.Partition, resource); err != nil {
 		return empty.Struct[dto.Item](), err
 	}
 
-	objectID := entity.NewObjectID()
-	if metadata != nil && metadata.ID.IsValid() {
-		objectID = metadata.ID
+	if err := s.checkWritePreconditions(c, req); err != nil {
+		return empty.Struct[dto.Item](), err
 	}
 
-	uploader := object.NewUploader(s.storageRequestor)
-	blockheaders, err := uploader.Upload(c, objectID, bodyStream)
+	encryption, keys, err := s.uploadEncryption(req)
 	if err != nil {
 		return empty.Struct[dto.Item](), err
 	}
 
-	newObject := &dto.Object{
-		ID:           objectID,
-		Group:        req.Group,
-		Partition:    req.Partition,
-		Name:         req.Name,
-		Path:         req.Path,
-		Size:         req.Size,
-		BlockHeaders: blockheaders,
+	// the version is reserved before any block is written and only becomes visible once committed,
+	// so a failed upload never leaves a half written version behind
+	reserved, err := s.reserveVersion(c, req, time.Now().Add(s.sessionExpiry))
+	if err != nil {
+		return empty.Struct[dto.Item](), err
+	}
+
+	body := checksum.NewReader(bodyStream)
+	scheme := s.storageCluster.ErasureCoding(req.Group, req.Partition)
+	codec := s.storageCluster.Compression(req.Group, req.Partition, req.ContentType)
+	uploader := object.NewUploader(s.storageCluster, scheme, codec, keys)
+	uploader.RecordTo(s.recorder(reserved))
+	blockheaders, err := uploader.Upload(c, reserved.ID, body)
+	if err != nil {
+		return empty.Struct[dto.Item](), errors.Join(err, s.abortVersion(c, reserved))
 	}
 
-	resp, err := s.upsertObjectMetadata(c, newObject)
+	// the size the client announced is not trusted, ranges are served from the size actually stored
+	reserved.Size = blockheaders.Size()
+	reserved.BlockHeaders = blockheaders
+	reserved.ETag = body.Checksum()
+	reserved.IfMatch = req.IfMatch
+	reserved.IfNoneMatch = req.IfNoneMatch
+	reserved.ContentMetadata = req.ContentMetadata
+	reserved.Encryption = encryption
+
+	resp, err := s.commitVersion(c, reserved)
 	if err != nil {
 		return empty.Struct[dto.Item](), err
 	}
@@ -166,46 +337,67 @@ func (s *explorer) Upload(c context.Context, req dto.Request, bodyStream io.Read
 }
 
 func (s *explorer) Download(c context.Context, req dto.Request, writer http.Writer, lastVersion bool) error {
-	switch {
-	case validation.IsEmpty(req.Group):
-		return errors.New("group is empty")
-	case validation.IsEmpty(req.Partition):
-		return errors.New("partition is empty")
-	case validation.IsEmpty(req.Path):
-		return errors.New("path is empty")
-	case !req.ObjectID.IsValid():
-		return errors.New("object id is invalid")
-	case !lastVersion && req.Version < 0:
-		return errors.New("version is invalid")
+	metadata, version, err := s.objectVersion(c, req, lastVersion)
+	if err != nil {
+		return err
 	}
 
-	metadata, err :=
-		s.getObjectMetadataByObjectID(c, req.ObjectID, req.Group, req.Partition, req.Path)
+	keys, err := s.keys(version.Encryption, req.CustomerKey)
 	if err != nil {
 		return err
 	}
 
-	var version dto.Version
-	if lastVersion {
-		version, err = metadata.Versions.LastVersion()
-		if err != nil {
+	if notModified, err := s.writeObjectHeader(req, version, writer); notModified || err != nil {
+		return err
+	}
+
+	downloader := object.NewDownloader(s.storageCluster, keys)
+
+	ranges, err := s.requestedRanges(req, version)
+	switch {
+	case errors.Is(err, http.ErrRangeNotSatisfiable):
+		writer.Unsatisfiable(version.Size)
+		return nil
+	case err != nil || len(ranges) == 0:
+		// an invalid range header is ignored and the whole object is sent
+		writer.Header(metadata.Name, version.Size)
+		return downloader.Download(c, version, writer.Body)
+	}
+
+	writer.RangeHeader(metadata.Name, version.Size, ranges)
+	if len(ranges) == 1 {
+		return downloader.DownloadRange(c, version, ranges[0], writer.Body)
+	}
+
+	for _, r := range ranges {
+		if err := writer.Part(r, version.Size); err != nil {
 			return err
 		}
-	} else {
-		version, err = metadata.Versions.Version(req.Version)
-		if err != nil {
+
+		if err := downloader.DownloadRange(c, version, r, writer.Body); err != nil {
 			return err
 		}
 	}
 
-	writer.Header(metadata.Name, version.Size)
+	return writer.Close()
+}
 
-	downloader := object.NewDownloader(s.storageRequestor)
-	err = downloader.Download(c, version, writer.Body)
+// Head writes the header a download of the version is sent with, without reading any block.
+func (s *explorer) Head(c context.Context, req dto.Request, writer http.Writer, lastVersion bool) error {
+	metadata, version, err := s.objectVersion(c, req, lastVersion)
 	if err != nil {
 		return err
 	}
 
+	if _, err := s.keys(version.Encryption, req.CustomerKey); err != nil {
+		return err
+	}
+
+	if notModified, err := s.writeObjectHeader(req, version, writer); notModified || err != nil {
+		return err
+	}
+
+	writer.Header(metadata.Name, version.Size)
 	return nil
 }
 
@@ -233,13 +425,30 @@ func (s *explorer) Delete(c context.Context, req dto.Request, deleteVersion bool
 		return errors.New("object not exist")
 	}
 
+	action := entity.PolicyActionDelete
 	if deleteVersion {
-		if !metadata.Versions.HasVersion(req.Version) {
-			return errors.New("version not exist")
-		}
+		action = entity.PolicyActionDeleteVersion
+	}
+
+	if err := s.authorizeObject(c, action, metadata); err != nil {
+		return err
+	}
+
+	current, err := metadata.Versions.LastVersion()
+	if deleteVersion {
+		current, err = metadata.Versions.Version(req.Version)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// the preconditions are evaluated against the version to delete, or the latest one for the object
+	if err := http.CheckWritePreconditions(req.IfMatch, req.IfNoneMatch, current.ETag, true); err != nil {
+		return err
 	}
 
-	deleter := object.NewDeleter(s.metadataRequestor, s.storageRequestor)
+	deleter := object.NewDeleter(s.metadataRequestor, s.storageCluster)
 	if deleteVersion {
 		if err := deleter.DeleteVersion(c, *metadata, req.Version); err != nil {
 			return err
@@ -253,58 +462,1084 @@ func (s *explorer) Delete(c context.Context, req dto.Request, deleteVersion bool
 	return nil
 }
 
-func (s *explorer) getObjectMetadataByObjectID(
-	c context.Context, objectID entity.ObjectID, group, partition, path string,
-) (*dto.Metadata, error) {
-	msg := rpcmessage.ObjectMetadataRequest{
-		ObjectID:  objectID.ToInt64(),
-		Group:     group,
-		Partition: partition,
-		Path:      path,
+// Copy writes the last or requested version of the object, or every version of it in order, to the
+// destination as new versions. The blocks are copied inside the block storage, so the data never
+// passes through the client. The versions copied before a failure are kept.
+func (s *explorer) Copy(c context.Context, req dto.Request, lastVersion bool) (dto.Item, error) {
+	metadata, version, err := s.objectVersion(c, req, lastVersion || req.AllVersions)
+	if err != nil {
+		return empty.Struct[dto.Item](), err
 	}
 
-	resp, err := s.metadataRequestor.GetByObjectID(c, &msg)
+	versions := dto.Versions{version}
+	if req.AllVersions {
+		versions = metadata.Versions
+	}
+
+	target := s.destination(req, metadata)
+	resource := entity.PolicyResource(target.Path, target.Name)
+	if err := s.authorizer.Authorize(c, entity.PolicyActionPut, target.Group, target.Partition, resource); err != nil {
+		return empty.Struct[dto.Item](), err
+	}
+
+	if err := s.checkWritePreconditions(c, target); err != nil {
+		return empty.Struct[dto.Item](), err
+	}
+
+	var resp *dto.Metadata
+	for i, version := range versions {
+		// the preconditions hold for the destination before the copy, not for the versions it copies
+		if i > 0 {
+			target.IfMatch = ""
+			target.IfNoneMatch = ""
+		}
+
+		resp, err = s.copyVersion(c, target, version, req.CustomerKey)
+		if err != nil {
+			return empty.Struct[dto.Item](), err
+		}
+	}
+
+	return dto.NewItemFromMetadata(*resp), nil
+}
+
+// Move renames the object and moves it on its partition. Only the metadata changes, so every version
+// keeps its blocks and the object keeps its id.
+func (s *explorer) Move(c context.Context, req dto.Request) (dto.Item, error) {
+	switch {
+	case !req.ObjectID.IsValid():
+		return empty.Struct[dto.Item](), errors.New("object id is invalid")
+	case validation.IsEmpty(req.Group):
+		return empty.Struct[dto.Item](), errors.New("group is empty")
+	case validation.IsEmpty(req.Partition):
+		return empty.Struct[dto.Item](), errors.New("partition is empty")
+	case validation.IsEmpty(req.Path):
+		return empty.Struct[dto.Item](), errors.New("path is empty")
+	}
+
+	metadata, err := s.getObjectMetadataByObjectID(c, req.ObjectID, req.Group, req.Partition, req.Path)
 	if err != nil {
-		return nil, err
+		return empty.Struct[dto.Item](), err
 	}
 
-	//  need not found
-	if resp.Id.Id <= 0 {
-		return nil, nil
+	// the object leaves its name, which takes the right to delete it
+	if err := s.authorizeObject(c, entity.PolicyActionDelete, metadata); err != nil {
+		return empty.Struct[dto.Item](), err
 	}
 
-	return message.ToObjectMetadataDTO(resp), nil
+	target := s.destination(req, metadata)
+	resource := entity.PolicyResource(target.Path, target.Name)
+	if err := s.authorizer.Authorize(c, entity.PolicyActionPut, target.Group, target.Partition, resource); err != nil {
+		return empty.Struct[dto.Item](), err
+	}
+
+	request := dto.Metadata{
+		ID:        metadata.ID,
+		Group:     metadata.Group,
+		Partition: metadata.Partition,
+		Path:      metadata.Path,
+		Name:      metadata.Name,
+	}
+
+	msg := rpcmessage.MoveRequest{
+		Metadata: message.FromObjectMetadataDTO(&request),
+		Path:     target.Path,
+		Name:     target.Name,
+	}
+
+	resp, err := s.metadataRequestor.Move(c, &msg)
+	if err != nil {
+		return empty.Struct[dto.Item](), err
+	}
+
+	return dto.NewItemFromMetadata(*message.ToObjectMetadataDTO(resp)), nil
 }
 
-func (s *explorer) getObjectMetadataByNameOnPath(
-	c context.Context, group, partition, path, name string,
-) (*dto.Metadata, error) {
+func (s *explorer) GetTags(c context.Context, req dto.Request) (dto.ObjectTags, error) {
+	item, err := s.GetObjectMetadata(c, req)
+	if err != nil {
+		return empty.Struct[dto.ObjectTags](), err
+	}
+
+	return dto.NewObjectTags(item.ID, item.Tags), nil
+}
+
+// PutTags replaces the tags of the object. No version is created, so the ETag of the object is kept.
+func (s *explorer) PutTags(c context.Context, req dto.Request) (dto.ObjectTags, error) {
+	if err := req.Tags.Validate(); err != nil {
+		return empty.Struct[dto.ObjectTags](), err
+	}
+
+	metadata, err := s.putTags(c, req)
+	if err != nil {
+		return empty.Struct[dto.ObjectTags](), err
+	}
+
+	return dto.NewObjectTags(metadata.ID, metadata.Tags), nil
+}
+
+func (s *explorer) DeleteTags(c context.Context, req dto.Request) error {
+	req.Tags = nil
+	_, err := s.putTags(c, req)
+	return err
+}
+
+// FindObjectsByTags returns a page of the objects in the partition whose tags match the expression of
+// the request. The search lists the whole partition, so it is authorized as a listing of its root.
+func (s *explorer) FindObjectsByTags(c context.Context, req dto.Request) (dto.ItemsPage, error) {
 	switch {
-	case validation.IsEmpty(name):
-		return nil, errors.New("name is empty")
+	case validation.IsEmpty(req.Group):
+		return empty.Struct[dto.ItemsPage](), errors.New("group is empty")
+	case validation.IsEmpty(req.Partition):
+		return empty.Struct[dto.ItemsPage](), errors.New("partition is empty")
+	case req.Limit < 0:
+		return empty.Struct[dto.ItemsPage](), errors.New("limit is invalid")
+	}
+
+	expression, err := entity.ParseTagExpression(req.TagExpression)
+	if err != nil {
+		return empty.Struct[dto.ItemsPage](), err
+	}
+
+	resource := entity.PolicyResource(entity.RootDirectoryPath, "")
+	if err := s.authorizer.Authorize(c, entity.PolicyActionList, req.Group, req.Partition, resource); err != nil {
+		return empty.Struct[dto.ItemsPage](), err
+	}
+
+	limit := req.Limit
+	if limit == 0 || limit > MaxListLimit {
+		limit = MaxListLimit
 	}
 
 	msg := rpcmessage.ObjectMetadataRequest{
-		Group:     group,
-		Partition: partition,
-		Path:      path,
-		Name:      name,
+		Group:         req.Group,
+		Partition:     req.Partition,
+		Limit:         int32(limit),
+		LastObjectID:  req.LastObjectID.ToInt64(),
+		TagExpression: expression.Terms(),
 	}
 
-	resp, err := s.metadataRequestor.GetByObjectName(c, &msg)
+	resp, err := s.metadataRequestor.FindMetadataByTags(c, &msg)
 	if err != nil {
-		return nil, err
+		return empty.Struct[dto.ItemsPage](), err
 	}
 
-	return message.ToObjectMetadataDTO(resp), nil
+	// the matches span the whole partition, so an object under a denied prefix is left out of the page
+	page := message.ToItemsPageDTO(resp)
+	items := make(dto.Items, 0, len(page.Items))
+	for _, item := range page.Items {
+		resource := entity.PolicyResource(item.Path, item.Name)
+		err := s.authorizer.Authorize(c, entity.PolicyActionList, item.Group, item.Partition, resource)
+		switch {
+		case errors.Is(err, soserror.Forbidden):
+			continue
+		case err != nil:
+			return empty.Struct[dto.ItemsPage](), err
+		}
+		items = append(items, item)
+	}
+	page.Items = items
+	return page, nil
 }
 
-func (s *explorer) upsertObjectMetadata(c context.Context, object *dto.Object) (*dto.Metadata, error) {
-	msg := message.FromObjectDTO(object)
-	resp, err := s.metadataRequestor.Put(c, msg)
+func (s *explorer) InitiateUpload(c context.Context, req dto.Request) (dto.UploadSession, error) {
+	switch {
+	case validation.IsEmpty(req.Group):
+		return empty.Struct[dto.UploadSession](), errors.New("group is empty")
+	case validation.IsEmpty(req.Partition):
+		return empty.Struct[dto.UploadSession](), errors.New("partition is empty")
+	case validation.IsEmpty(req.Path):
+		return empty.Struct[dto.UploadSession](), errors.New("path is empty")
+	case validation.IsEmpty(req.Name):
+		return empty.Struct[dto.UploadSession](), errors.New("name is empty")
+	}
+
+	if err := req.ContentMetadata.Validate(); err != nil {
+		return empty.Struct[dto.UploadSession](), err
+	}
+
+	resource := entity.PolicyResource(req.Path, req.Name)
+	if err := s.authorizer.Authorize(c, entity.PolicyActionPut, req.Group, req.Partition, resource); err != nil {
+		return empty.Struct[dto.UploadSession](), err
+	}
+
+	// the parts are encrypted with the keys of the version they are committed as
+	encryption, _, err := s.uploadEncryption(req)
 	if err != nil {
-		return nil, err
+		return empty.Struct[dto.UploadSession](), err
 	}
 
-	return message.ToObjectMetadataDTO(resp), nil
+	// the reservation expires with the session, the sweeper releases both
+	expiresAt := time.Now().Add(s.sessionExpiry)
+	reserved, err := s.reserveVersion(c, req, expiresAt)
+	if err != nil {
+		return empty.Struct[dto.UploadSession](), err
+	}
+
+	session := &dto.UploadSession{
+		ID:        entity.NewUploadID(),
+		ObjectID:  reserved.ID,
+		Group:     req.Group,
+		Partition: req.Partition,
+		Path:      req.Path,
+		Name:      req.Name,
+		Version:   reserved.VersionNum,
+		ExpiresAt: expiresAt,
+
+		ContentMetadata: req.ContentMetadata,
+		Encryption:      encryption,
+	}
+
+	resp, err := s.metadataRequestor.CreateUploadSession(c, message.FromUploadSessionDTO(session))
+	if err != nil {
+		return empty.Struct[dto.UploadSession](), errors.Join(err, s.abortVersion(c, reserved))
+	}
+
+	return *message.ToUploadSessionDTO(resp), nil
+}
+
+func (s *explorer) UploadPart(c context.Context, req dto.Request, bodyStream io.ReadCloser) (dto.UploadPart, error) {
+	switch {
+	case !req.UploadID.IsValid():
+		return empty.Struct[dto.UploadPart](), errors.New("upload id is invalid")
+	case req.PartNumber < 1 || req.PartNumber > MaxUploadPartNumber:
+		return empty.Struct[dto.UploadPart](), errors.New("part number is invalid")
+	case req.Size <= 0:
+		return empty.Struct[dto.UploadPart](), errors.New("size is invalid")
+	case validation.IsNil(bodyStream):
+		return empty.Struct[dto.UploadPart](), errors.New("body stream is nil")
+	}
+
+	session, err := s.getUploadSession(c, req)
+	if err != nil {
+		return empty.Struct[dto.UploadPart](), err
+	}
+
+	keys, err := s.keys(session.Encryption, req.CustomerKey)
+	if err != nil {
+		return empty.Struct[dto.UploadPart](), err
+	}
+
+	body := checksum.NewReader(bodyStream)
+	scheme := s.storageCluster.ErasureCoding(session.Group, session.Partition)
+	codec := s.storageCluster.Compression(session.Group, session.Partition, session.ContentType)
+	// the blocks of a part are owned by the session rather than the version it reserved, and are
+	// deleted with the session when it is aborted or expires
+	uploader := object.NewUploader(s.storageCluster, scheme, codec, keys)
+	first := (req.PartNumber - 1) * MaxUploadPartBlocks
+	blockHeaders, err := uploader.UploadFrom(c, session.ObjectID, first, MaxUploadPartBlocks, body)
+	if err != nil {
+		return empty.Struct[dto.UploadPart](), err
+	}
+
+	part := &dto.UploadPart{
+		Number:       req.PartNumber,
+		Size:         blockHeaders.Size(),
+		BlockHeaders: blockHeaders,
+		Checksum:     body.Checksum(),
+	}
+
+	deleter := object.NewDeleter(s.metadataRequestor, s.storageCluster)
+	msg := &rpcmessage.UploadPartRequest{
+		UploadID: session.ID.ToInt64(),
+		Part:     message.FromUploadPartDTO(part),
+	}
+
+	if _, err := s.metadataRequestor.PutUploadPart(c, msg); err != nil {
+		// the session is gone or expired, nothing will ever reference these blocks
+		if deleteErr := deleter.DeleteBlocks(c, blockHeaders); deleteErr != nil {
+			return empty.Struct[dto.UploadPart](), errors.Join(err, deleteErr)
+		}
+		return empty.Struct[dto.UploadPart](), err
+	}
+
+	// a re-uploaded part replaces the previous one, so its blocks are not needed anymore
+	if previous, exist := session.Parts.Part(req.PartNumber); exist {
+		if err := deleter.DeleteBlocks(c, previous.BlockHeaders); err != nil {
+			return empty.Struct[dto.UploadPart](), err
+		}
+	}
+
+	return *part, nil
+}
+
+func (s *explorer) CompleteUpload(c context.Context, req dto.Request) (dto.Item, error) {
+	switch {
+	case !req.UploadID.IsValid():
+		return empty.Struct[dto.Item](), errors.New("upload id is invalid")
+	}
+
+	session, err := s.getUploadSession(c, req)
+	if err != nil {
+		return empty.Struct[dto.Item](), err
+	}
+
+	if session.Parts.Empty() {
+		return empty.Struct[dto.Item](), errors.New("upload session has no parts")
+	}
+
+	if err := s.checkWritePreconditions(c, dto.Request{
+		Group:       session.Group,
+		Partition:   session.Partition,
+		Path:        session.Path,
+		Name:        session.Name,
+		IfMatch:     req.IfMatch,
+		IfNoneMatch: req.IfNoneMatch,
+	}); err != nil {
+		return empty.Struct[dto.Item](), err
+	}
+
+	// the blocks of every part are numbered within the range of its part number, so the version
+	// takes them as they are, in the order of the parts
+	blockHeaders := session.Parts.BlockHeaders()
+	reserved := &dto.Object{
+		ID:           session.ObjectID,
+		Group:        session.Group,
+		Partition:    session.Partition,
+		Name:         session.Name,
+		Path:         session.Path,
+		Size:         blockHeaders.Size(),
+		VersionNum:   session.Version,
+		BlockHeaders: blockHeaders,
+		ETag:         session.Parts.ETag(),
+		IfMatch:      req.IfMatch,
+		IfNoneMatch:  req.IfNoneMatch,
+
+		ContentMetadata: session.ContentMetadata,
+		Encryption:      session.Encryption,
+	}
+
+	// the session keeps the blocks of the parts until the version is committed, so a failed commit
+	// can be completed again or is reclaimed once the session expires
+	resp, err := s.metadataRequestor.CommitVersion(c, message.FromObjectDTO(reserved))
+	if err != nil {
+		if errors.Is(err, soserror.PreconditionFailed) || errors.Is(err, soserror.NotFound) {
+			err = errors.Join(err, releaseUploadSession(c, s.metadataRequestor, s.storageCluster, session))
+		}
+		return empty.Struct[dto.Item](), err
+	}
+
+	if err := s.deleteUploadSession(c, session.ID); err != nil && !errors.Is(err, soserror.NotFound) {
+		log.FromContext(c).Errorf("[explorer.CompleteUpload] can not delete upload session %d: %s", session.ID, err.Error())
+	}
+
+	return dto.NewItemFromMetadata(*message.ToObjectMetadataDTO(resp)), nil
+}
+
+func (s *explorer) AbortUpload(c context.Context, req dto.Request) error {
+	switch {
+	case !req.UploadID.IsValid():
+		return errors.New("upload id is invalid")
+	}
+
+	session, err := s.getUploadSession(c, req)
+	if err != nil {
+		return err
+	}
+	return releaseUploadSession(c, s.metadataRequestor, s.storageCluster, session)
+}
+
+func (s *explorer) GetPolicy(c context.Context, req dto.Request) (dto.Policy, error) {
+	switch {
+	case validation.IsEmpty(req.Group):
+		return empty.Struct[dto.Policy](), errors.New("group is empty")
+	}
+
+	if err := s.authorizer.AuthorizeAdmin(c); err != nil {
+		return empty.Struct[dto.Policy](), err
+	}
+
+	msg := rpcmessage.PolicyRequest{
+		Group:     req.Group,
+		Partition: req.Partition,
+	}
+
+	resp, err := s.metadataRequestor.GetPolicy(c, &msg)
+	if err != nil {
+		return empty.Struct[dto.Policy](), err
+	}
+
+	return *message.ToPolicyDTO(resp), nil
+}
+
+func (s *explorer) PutPolicy(c context.Context, req dto.Request, policy dto.Policy) (dto.Policy, error) {
+	// the policy belongs to the group and partition it is put on
+	policy.Group = req.Group
+	policy.Partition = req.Partition
+	if err := policy.Validate(); err != nil {
+		return empty.Struct[dto.Policy](), err
+	}
+
+	if err := s.authorizer.AuthorizeAdmin(c); err != nil {
+		return empty.Struct[dto.Policy](), err
+	}
+
+	resp, err := s.metadataRequestor.PutPolicy(c, message.FromPolicyDTO(&policy))
+	if err != nil {
+		return empty.Struct[dto.Policy](), err
+	}
+
+	return *message.ToPolicyDTO(resp), nil
+}
+
+func (s *explorer) DeletePolicy(c context.Context, req dto.Request) error {
+	switch {
+	case validation.IsEmpty(req.Group):
+		return errors.New("group is empty")
+	}
+
+	if err := s.authorizer.AuthorizeAdmin(c); err != nil {
+		return err
+	}
+
+	msg := rpcmessage.PolicyRequest{
+		Group:     req.Group,
+		Partition: req.Partition,
+	}
+	return s.metadataRequestor.DeletePolicy(c, &msg)
+}
+
+// Presign signs a URL that downloads the object or a version of it with GET, or uploads
+// an object of the name on the path with PUT, on behalf of the requesting principal.
+func (s *explorer) Presign(c context.Context, req dto.Request) (auth.PresignedURL, error) {
+	switch {
+	case s.presigner == nil:
+		return auth.PresignedURL{}, errors.New("presigned url is disabled")
+	case validation.IsEmpty(req.Group):
+		return auth.PresignedURL{}, errors.New("group is empty")
+	case validation.IsEmpty(req.Partition):
+		return auth.PresignedURL{}, errors.New("partition is empty")
+	case validation.IsEmpty(req.Path):
+		return auth.PresignedURL{}, errors.New("path is empty")
+	}
+
+	u := auth.PresignedURL{
+		Method:    req.Method,
+		Group:     req.Group,
+		Partition: req.Partition,
+		Path:      req.Path,
+		Version:   -1,
+	}
+
+	switch req.Method {
+	case gohttp.MethodGet:
+		if !req.ObjectID.IsValid() {
+			return auth.PresignedURL{}, errors.New("object id is invalid")
+		}
+
+		metadata, err :=
+			s.getObjectMetadataByObjectID(c, req.ObjectID, req.Group, req.Partition, req.Path)
+		if err != nil {
+			return auth.PresignedURL{}, err
+		}
+
+		if err := s.authorizeObject(c, entity.PolicyActionGet, metadata); err != nil {
+			return auth.PresignedURL{}, err
+		}
+
+		if req.Version >= 0 {
+			if _, err := metadata.Versions.Version(req.Version); err != nil {
+				return auth.PresignedURL{}, soserror.NewNotFoundError(err)
+			}
+		}
+
+		u.ObjectID = metadata.ID.ToInt64()
+		u.Version = req.Version
+	case gohttp.MethodPut:
+		if validation.IsEmpty(req.Name) {
+			return auth.PresignedURL{}, errors.New("name is empty")
+		}
+
+		resource := entity.PolicyResource(req.Path, req.Name)
+		if err := s.authorizer.Authorize(c, entity.PolicyActionPut, req.Group, req.Partition, resource); err != nil {
+			return auth.PresignedURL{}, err
+		}
+
+		u.Name = req.Name
+	default:
+		return auth.PresignedURL{}, fmt.Errorf("method %s can not be presigned", req.Method)
+	}
+
+	if principal, ok := auth.PrincipalFromContext(c); ok {
+		u.Principal = principal.Name
+	}
+	return s.presigner.Sign(u, req.Expiry)
+}
+
+func (s *explorer) authorizeObject(c context.Context, action entity.PolicyAction, metadata *dto.Metadata) error {
+	resource := entity.PolicyResource(metadata.Path, metadata.Name)
+	return s.authorizer.Authorize(c, action, metadata.Group, metadata.Partition, resource)
+}
+
+// objectVersion returns the object the request is allowed to read, with its last or requested version.
+func (s *explorer) objectVersion(
+	c context.Context, req dto.Request, lastVersion bool,
+) (*dto.Metadata, dto.Version, error) {
+	switch {
+	case validation.IsEmpty(req.Group):
+		return nil, dto.Version{}, errors.New("group is empty")
+	case validation.IsEmpty(req.Partition):
+		return nil, dto.Version{}, errors.New("partition is empty")
+	case validation.IsEmpty(req.Path):
+		return nil, dto.Version{}, errors.New("path is empty")
+	case !req.ObjectID.IsValid():
+		return nil, dto.Version{}, errors.New("object id is invalid")
+	case !lastVersion && req.Version < 0:
+		return nil, dto.Version{}, errors.New("version is invalid")
+	}
+
+	metadata, err :=
+		s.getObjectMetadataByObjectID(c, req.ObjectID, req.Group, req.Partition, req.Path)
+	if err != nil {
+		return nil, dto.Version{}, err
+	}
+
+	if err := s.authorizeObject(c, entity.PolicyActionGet, metadata); err != nil {
+		return nil, dto.Version{}, err
+	}
+
+	var version dto.Version
+	if lastVersion {
+		version, err = metadata.Versions.LastVersion()
+	} else {
+		version, err = metadata.Versions.Version(req.Version)
+	}
+
+	if err != nil {
+		return nil, dto.Version{}, soserror.NewNotFoundError(err)
+	}
+	return metadata, version, nil
+}
+
+func (s *explorer) putTags(c context.Context, req dto.Request) (*dto.Metadata, error) {
+	switch {
+	case !req.ObjectID.IsValid():
+		return nil, errors.New("object id is invalid")
+	case validation.IsEmpty(req.Group):
+		return nil, errors.New("group is empty")
+	case validation.IsEmpty(req.Partition):
+		return nil, errors.New("partition is empty")
+	case validation.IsEmpty(req.Path):
+		return nil, errors.New("path is empty")
+	}
+
+	metadata, err := s.getObjectMetadataByObjectID(c, req.ObjectID, req.Group, req.Partition, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeObject(c, entity.PolicyActionPut, metadata); err != nil {
+		return nil, err
+	}
+
+	// only the tags are written, the versions are not sent along
+	request := dto.Metadata{
+		ID:        metadata.ID,
+		Group:     metadata.Group,
+		Partition: metadata.Partition,
+		Path:      metadata.Path,
+		Name:      metadata.Name,
+		Tags:      req.Tags,
+	}
+
+	resp, err := s.metadataRequestor.PutTags(c, message.FromObjectMetadataDTO(&request))
+	if err != nil {
+		return nil, err
+	}
+
+	return message.ToObjectMetadataDTO(resp), nil
+}
+
+// destination returns the request writing to the destination of a copy or a move. It is on the partition
+// of the source, and an empty path or name of the destination keeps the one of the source.
+func (s *explorer) destination(req dto.Request, source *dto.Metadata) dto.Request {
+	target := dto.Request{
+		Group:       source.Group,
+		Partition:   source.Partition,
+		Path:        source.Path,
+		Name:        source.Name,
+		IfMatch:     req.IfMatch,
+		IfNoneMatch: req.IfNoneMatch,
+	}
+
+	if !validation.IsEmpty(req.Destination.Path) {
+		target.Path = entity.CleanDirectoryPath(req.Destination.Path)
+	}
+	if !validation.IsEmpty(req.Destination.Name) {
+		target.Name = req.Destination.Name
+	}
+	return target
+}
+
+// copyVersion commits a copy of the version as the next version of the target. The copy is encrypted
+// with the keys of the version, so the encrypted blocks are copied as they are.
+func (s *explorer) copyVersion(
+	c context.Context, target dto.Request, version dto.Version, customerKey dto.CustomerKey,
+) (*dto.Metadata, error) {
+	keys, err := s.keys(version.Encryption, customerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved, err := s.reserveVersion(c, target, time.Now().Add(s.sessionExpiry))
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := s.storageCluster.ErasureCoding(target.Group, target.Partition)
+	codec := s.storageCluster.Compression(target.Group, target.Partition, version.ContentType)
+	copier := object.NewCopier(s.storageCluster, scheme, codec, keys)
+	copier.RecordTo(s.recorder(reserved))
+	blockHeaders, err := copier.Copy(c, reserved.ID, version.BlockHeaders)
+	if err != nil {
+		return nil, errors.Join(err, s.abortVersion(c, reserved))
+	}
+
+	reserved.Size = version.Size
+	reserved.BlockHeaders = blockHeaders
+	reserved.ETag = version.ETag
+	reserved.IfMatch = target.IfMatch
+	reserved.IfNoneMatch = target.IfNoneMatch
+	reserved.ContentMetadata = version.ContentMetadata
+	reserved.Encryption = version.Encryption
+	return s.commitVersion(c, reserved)
+}
+
+// uploadEncryption returns the keys the blocks of a new version are encrypted with and how they are kept
+// with the version. A key of the client is only kept as its digest, so the client sends it with every read.
+func (s *explorer) uploadEncryption(req dto.Request) (entity.Encryption, object.Keys, error) {
+	if req.CustomerKey.Empty() {
+		encryption, dataKey, err := s.newEncryption()
+		return encryption, object.Keys{DataKey: dataKey}, err
+	}
+
+	customerKey, keyMD5, err := req.CustomerKey.Parse()
+	if err != nil {
+		return entity.Encryption{}, object.Keys{}, err
+	}
+
+	return entity.Encryption{
+		Algorithm:      encryption.Algorithm,
+		CustomerKeyMD5: keyMD5,
+	}, object.Keys{CustomerKey: customerKey}, nil
+}
+
+// keys returns the keys the blocks of a version are encrypted with. A version encrypted with a key of
+// the client is only accessed with the same key.
+func (s *explorer) keys(versionEncryption entity.Encryption, customerKey dto.CustomerKey) (object.Keys, error) {
+	switch {
+	case !versionEncryption.CustomerProvided() && !customerKey.Empty():
+		return object.Keys{}, fmt.Errorf("%w. object is not encrypted with a customer key", encryption.ErrInvalidCustomerKey)
+	case !versionEncryption.CustomerProvided():
+		dataKey, err := s.dataKey(versionEncryption)
+		return object.Keys{DataKey: dataKey}, err
+	case customerKey.Empty():
+		return object.Keys{}, soserror.NewForbiddenError(errors.New("object is encrypted with a customer key"))
+	}
+
+	key, keyMD5, err := customerKey.Parse()
+	if err != nil {
+		return object.Keys{}, err
+	}
+
+	if keyMD5 != versionEncryption.CustomerKeyMD5 {
+		return object.Keys{}, soserror.NewForbiddenError(errors.New("customer key does not match the object"))
+	}
+	return object.Keys{CustomerKey: key}, nil
+}
+
+// newEncryption returns a new data key and how it is kept with the version, wrapped by the master key.
+// Nothing is encrypted when encryption is disabled.
+func (s *explorer) newEncryption() (entity.Encryption, []byte, error) {
+	if validation.IsNil(s.kms) {
+		return entity.Encryption{}, nil, nil
+	}
+
+	dataKey, keyID, wrapped, err := s.kms.GenerateDataKey()
+	if err != nil {
+		return entity.Encryption{}, nil, err
+	}
+
+	return entity.Encryption{
+		Algorithm: encryption.Algorithm,
+		KeyID:     keyID,
+		DataKey:   wrapped,
+	}, dataKey, nil
+}
+
+// dataKey unwraps the data key the blocks are encrypted with. It is nil for blocks stored as they are.
+func (s *explorer) dataKey(encryption entity.Encryption) ([]byte, error) {
+	switch {
+	case !encryption.Enabled():
+		return nil, nil
+	case validation.IsNil(s.kms):
+		return nil, errors.New("object is encrypted, but encryption is disabled")
+	}
+	return s.kms.Unwrap(encryption.KeyID, encryption.DataKey)
+}
+
+// writeObjectHeader writes the header of the version and evaluates the conditional headers of the
+// request against it. It reports whether a not modified response was written in place of the object.
+func (s *explorer) writeObjectHeader(req dto.Request, version dto.Version, writer http.Writer) (bool, error) {
+	writer.ObjectHeader(http.ObjectHeader{
+		Version:         version.Number,
+		ETag:            version.ETag,
+		ModifiedAt:      version.ModifiedAt,
+		ContentType:     version.ContentType,
+		ContentEncoding: version.ContentEncoding,
+		CacheControl:    version.CacheControl,
+		UserMetadata:    version.UserMetadata,
+	})
+
+	err := http.CheckReadPreconditions(req.IfMatch, req.IfNoneMatch, req.IfModifiedSince, version.ETag, version.ModifiedAt)
+	switch {
+	case errors.Is(err, http.ErrNotModified):
+		writer.NotModified()
+		return true, nil
+	case err != nil:
+		return false, err
+	}
+	return false, nil
+}
+
+func (s *explorer) requestedRanges(req dto.Request, version dto.Version) (http.Ranges, error) {
+	if validation.IsEmpty(req.Range) || !http.IfRangeMatch(req.IfRange, version.ETag, version.ModifiedAt) {
+		return nil, nil
+	}
+
+	ranges, err := http.ParseRange(req.Range, version.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	// multiple ranges that add up to more than the object are not worth serving as parts
+	if len(ranges) > 1 && ranges.Size() > version.Size {
+		return nil, nil
+	}
+	return ranges, nil
+}
+
+func (s *explorer) getObjectMetadataByObjectID(
+	c context.Context, objectID entity.ObjectID, group, partition, path string,
+) (*dto.Metadata, error) {
+	msg := rpcmessage.ObjectMetadataRequest{
+		ObjectID:  objectID.ToInt64(),
+		Group:     group,
+		Partition: partition,
+		Path:      path,
+	}
+
+	resp, err := s.metadataRequestor.GetByObjectID(c, &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := message.ToObjectMetadataDTO(resp)
+	if !metadata.ID.IsValid() {
+		return nil, soserror.NewNotFoundError(errors.New("object not exist"))
+	}
+	return metadata, nil
+}
+
+func (s *explorer) getObjectMetadataByNameOnPath(
+	c context.Context, group, partition, path, name string,
+) (*dto.Metadata, error) {
+	switch {
+	case validation.IsEmpty(name):
+		return nil, errors.New("name is empty")
+	}
+
+	msg := rpcmessage.ObjectMetadataRequest{
+		Group:     group,
+		Partition: partition,
+		Path:      path,
+		Name:      name,
+	}
+
+	resp, err := s.metadataRequestor.GetByObjectName(c, &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.ToObjectMetadataDTO(resp), nil
+}
+
+func (s *explorer) getUploadSession(c context.Context, req dto.Request) (*dto.UploadSession, error) {
+	msg := rpcmessage.UploadSessionRequest{
+		UploadID: req.UploadID.ToInt64(),
+	}
+
+	resp, err := s.metadataRequestor.GetUploadSession(c, &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	session := message.ToUploadSessionDTO(resp)
+	switch {
+	case session.Group != req.Group || session.Partition != req.Partition || session.Path != req.Path:
+		return nil, soserror.NewNotFoundError(errors.New("upload session not exist on path"))
+	case session.IsExpired(time.Now()):
+		return nil, soserror.NewNotFoundError(errors.New("upload session is expired"))
+	}
+
+	resource := entity.PolicyResource(session.Path, session.Name)
+	if err := s.authorizer.Authorize(c, entity.PolicyActionPut, session.Group, session.Partition, resource); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *explorer) deleteUploadSession(c context.Context, uploadID entity.UploadID) error {
+	msg := rpcmessage.UploadSessionRequest{
+		UploadID: uploadID.ToInt64(),
+	}
+	return s.metadataRequestor.DeleteUploadSession(c, &msg)
+}
+
+// reserveVersion reserves the next version of the named object, which is created with a new id
+// when it does not exist yet.
+func (s *explorer) reserveVersion(c context.Context, req dto.Request, expiresAt time.Time) (*dto.Object, error) {
+	object := &dto.Object{
+		ID:        entity.NewObjectID(),
+		Group:     req.Group,
+		Partition: req.Partition,
+		Name:      req.Name,
+		Path:      req.Path,
+		ExpiresAt: expiresAt,
+	}
+
+	resp, err := s.metadataRequestor.ReserveVersion(c, message.FromObjectDTO(object))
+	if err != nil {
+		return nil, err
+	}
+	return message.ToObjectDTO(resp), nil
+}
+
+// commitVersion makes the reserved version visible. The blocks are recorded on the reservation, so
+// when it is gone, e.g. released after it expired or deleted with the object, they were deleted with
+// it. When the preconditions failed the reservation is aborted, which deletes them.
+func (s *explorer) commitVersion(c context.Context, reserved *dto.Object) (*dto.Metadata, error) {
+	resp, err := s.metadataRequestor.CommitVersion(c, message.FromObjectDTO(reserved))
+	switch {
+	case errors.Is(err, soserror.PreconditionFailed):
+		return nil, errors.Join(err, s.abortVersion(c, reserved))
+	case err != nil:
+		return nil, err
+	}
+
+	return message.ToObjectMetadataDTO(resp), nil
+}
+
+// checkWritePreconditions fails an upload early, before any block is written, when the current
+// version of the named object does not meet the preconditions. They are checked again on commit.
+func (s *explorer) checkWritePreconditions(c context.Context, req dto.Request) error {
+	if validation.IsEmpty(req.IfMatch) && validation.IsEmpty(req.IfNoneMatch) {
+		return nil
+	}
+
+	metadata, err := s.getObjectMetadataByNameOnPath(c, req.Group, req.Partition, req.Path, req.Name)
+	if err != nil && !errors.Is(err, soserror.NotFound) {
+		return err
+	}
+
+	var current dto.Version
+	exist := false
+	if err == nil && metadata != nil && metadata.ID.IsValid() {
+		current, err = metadata.Versions.LastVersion()
+		exist = err == nil
+	}
+	return http.CheckWritePreconditions(req.IfMatch, req.IfNoneMatch, current.ETag, exist)
+}
+
+// abortVersion releases the reservation and deletes the blocks recorded on it. A reservation that
+// is already released, e.g. after it expired, had its blocks deleted by whoever released it.
+func (s *explorer) abortVersion(c context.Context, reserved *dto.Object) error {
+	resp, err := s.metadataRequestor.AbortVersion(context.WithoutCancel(c), message.FromObjectDTO(reserved))
+	switch {
+	case errors.Is(err, soserror.NotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	deleter := object.NewDeleter(s.metadataRequestor, s.storageCluster)
+	return deleter.DeleteBlocks(context.WithoutCancel(c), message.ToBlockHeaderListDTO(resp))
+}
+
+// recorder records the blocks written for the reserved version on it, so they are deleted with the
+// reservation when the upload never commits.
+func (s *explorer) recorder(reserved *dto.Object) object.Recorder {
+	return func(c context.Context, blockHeader dto.BlockHeader) error {
+		record := *reserved
+		record.BlockHeaders = dto.BlockHeaders{blockHeader}
+		return s.metadataRequestor.RecordBlocks(c, message.FromObjectDTO(&record))
+	}
+}
+
+// CollectGarbage runs a garbage collection now, deleting nothing in a dry run.
+func (s *explorer) CollectGarbage(c context.Context, req dto.Request) (dto.GarbageReport, error) {
+	if err := s.authorizer.AuthorizeAdmin(c); err != nil {
+		return empty.Struct[dto.GarbageReport](), err
+	}
+
+	if validation.IsNil(s.collector) {
+		return empty.Struct[dto.GarbageReport](), soserror.NewNotFoundError(errors.New("garbage collection is disabled"))
+	}
+	return s.collector.Collect(c, req.DryRun)
+}
+
+func (s *explorer) GetGarbageReport(c context.Context) (dto.GarbageReport, error) {
+	if err := s.authorizer.AuthorizeAdmin(c); err != nil {
+		return empty.Struct[dto.GarbageReport](), err
+	}
+
+	if validation.IsNil(s.collector) {
+		return empty.Struct[dto.GarbageReport](), soserror.NewNotFoundError(errors.New("garbage collection is disabled"))
+	}
+
+	report, exist := s.collector.LastReport()
+	if !exist {
+		return empty.Struct[dto.GarbageReport](), soserror.NewNotFoundError(errors.New("garbage has not been collected yet"))
+	}
+	return report, nil
+}
+
+// GetDedupReport sums the deduplicated content of every node, and a node that can not be
+// reached is recorded in the report without failing it.
+func (s *explorer) GetDedupReport(c context.Context) (dto.DedupReport, error) {
+	if err := s.authorizer.AuthorizeAdmin(c); err != nil {
+		return empty.Struct[dto.DedupReport](), err
+	}
+
+	report := dto.DedupReport{
+		Enabled: s.storageCluster.Deduplication(),
+	}
+
+	if report.Enabled {
+		for _, rule := range s.storageCluster.ErasureCodingRules() {
+			scope := rule.Group
+			if !validation.IsEmpty(rule.Partition) {
+				scope += "/" + rule.Partition
+			}
+			report.Excluded = append(report.Excluded, fmt.Sprintf("objects erasure coded on %s", scope))
+		}
+		report.Excluded = append(report.Excluded, "objects encrypted with a customer key")
+	}
+
+	for _, node := range s.storageCluster.Nodes() {
+		resp, err := node.Requestor.GetDedupStats(c)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", node.Node.Host, err.Error()))
+			continue
+		}
+
+		stats := message.ToDedupStatsDTO(resp)
+		report.Total.Add(stats)
+		report.Nodes = append(report.Nodes, dto.DedupNodeStat{
+			Node:  node.Node.Host,
+			Stats: stats,
+		})
+	}
+	return report, nil
+}
+
+// RotateDataKeys reloads the master keys and wraps every data key again with the primary one. Only the
+// metadata is written, as the data keys and so the blocks do not change. A failed object is recorded
+// in the report and rotated again by the next run.
+func (s *explorer) RotateDataKeys(c context.Context) (dto.RotationReport, error) {
+	if err := s.authorizer.AuthorizeAdmin(c); err != nil {
+		return empty.Struct[dto.RotationReport](), err
+	}
+
+	if validation.IsNil(s.kms) {
+		return empty.Struct[dto.RotationReport](), soserror.NewNotFoundError(errors.New("encryption is disabled"))
+	}
+
+	if err := s.kms.Reload(); err != nil {
+		return empty.Struct[dto.RotationReport](), err
+	}
+
+	report := dto.RotationReport{
+		KeyID: s.kms.PrimaryKeyID(),
+	}
+
+	var cursor int64
+	for {
+		msg := rpcmessage.ObjectMetadataRequest{
+			KeyID:        report.KeyID,
+			Limit:        MaxListLimit,
+			LastObjectID: cursor,
+		}
+
+		resp, err := s.metadataRequestor.FindMetadataByStaleDataKey(c, &msg)
+		if err != nil {
+			return report, err
+		}
+
+		for _, item := range resp.Metadata {
+			metadata := message.ToObjectMetadataDTO(item)
+			report.ScannedObjects++
+
+			rewrapped, err := s.rewrapDataKeys(c, metadata, report.KeyID)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", metadata.ID, err.Error()))
+				continue
+			}
+			report.RewrappedVersions += rewrapped
+		}
+
+		if resp.NextCursor == 0 {
+			return report, nil
+		}
+		cursor = resp.NextCursor
+	}
+}
+
+// rewrapDataKeys wraps the data keys of the versions that are not wrapped by the master key yet,
+// and returns how many were.
+func (s *explorer) rewrapDataKeys(c context.Context, metadata *dto.Metadata, keyID string) (int, error) {
+	request := dto.Metadata{
+		ID:        metadata.ID,
+		Group:     metadata.Group,
+		Partition: metadata.Partition,
+		Path:      metadata.Path,
+		Name:      metadata.Name,
+	}
+
+	for _, version := range metadata.Versions {
+		// a key of the client is not wrapped by the master key
+		current := version.Encryption
+		if !current.Enabled() || current.CustomerProvided() || current.KeyID == keyID {
+			continue
+		}
+
+		dataKey, err := s.kms.Unwrap(current.KeyID, current.DataKey)
+		if err != nil {
+			return 0, err
+		}
+
+		wrappedKeyID, wrapped, err := s.kms.Wrap(dataKey)
+		if err != nil {
+			return 0, err
+		}
+
+		request.Versions = append(request.Versions, dto.Version{
+			Number: version.Number,
+			Encryption: entity.Encryption{
+				Algorithm: current.Algorithm,
+				KeyID:     wrappedKeyID,
+				DataKey:   wrapped,
+			},
+		})
+	}
+
+	if request.Versions.Empty() {
+		return 0, nil
+	}
+
+	if _, err := s.metadataRequestor.RewrapDataKeys(c, message.FromObjectMetadataDTO(&request)); err != nil {
+		return 0, err
+	}
+	return len(request.Versions), nil
 }
@@ -0,0 +1,268 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/message"
+	"github.com/ISSuh/sos/domain/service"
+	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
+	"github.com/ISSuh/sos/internal/encryption"
+	"github.com/ISSuh/sos/internal/http"
+	"github.com/ISSuh/sos/internal/kms"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeKMS keeps its master keys in memory. Reload makes the staged key the primary one, as adding
+// a key to the key file and reloading it does.
+type fakeKMS struct {
+	primary string
+	staged  string
+	keys    map[string][]byte
+}
+
+func newFakeKMS(t *testing.T, ids ...string) *fakeKMS {
+	t.Helper()
+
+	k := &fakeKMS{primary: ids[0], staged: ids[0], keys: map[string][]byte{}}
+	for _, id := range ids {
+		key, err := encryption.NewKey()
+		must(t, err)
+		k.keys[id] = key
+	}
+	return k
+}
+
+func (k *fakeKMS) PrimaryKeyID() string {
+	return k.primary
+}
+
+func (k *fakeKMS) GenerateDataKey() ([]byte, string, []byte, error) {
+	dataKey, err := encryption.NewKey()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	keyID, wrapped, err := k.Wrap(dataKey)
+	return dataKey, keyID, wrapped, err
+}
+
+func (k *fakeKMS) Wrap(dataKey []byte) (string, []byte, error) {
+	wrapped, err := encryption.Seal(k.keys[k.primary], dataKey)
+	return k.primary, wrapped, err
+}
+
+func (k *fakeKMS) Unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	masterKey, exist := k.keys[keyID]
+	if !exist {
+		return nil, fmt.Errorf("%w. %s", kms.ErrUnknownKey, keyID)
+	}
+	return encryption.Open(masterKey, wrapped)
+}
+
+func (k *fakeKMS) Reload() error {
+	k.primary = k.staged
+	return nil
+}
+
+func newEncryptingExplorer(t *testing.T, keys kms.KMS) (service.Explorer, *sweepCluster) {
+	t.Helper()
+
+	s := newSweepCluster(t)
+	authorizer, err := service.NewAuthorizer(s.registry, nil)
+	must(t, err)
+
+	explorer, err := service.NewExplorer(s.registry, s.cluster, time.Hour, authorizer, nil, nil, keys)
+	must(t, err)
+	return explorer, s
+}
+
+func putEncrypted(t *testing.T, explorer service.Explorer, name string, body []byte) dto.Request {
+	t.Helper()
+
+	req := dto.Request{
+		Group:     "group",
+		Partition: "partition",
+		Path:      "keys",
+		Name:      name,
+		Size:      len(body),
+	}
+
+	item, err := explorer.Upload(context.Background(), req, io.NopCloser(bytes.NewReader(body)))
+	must(t, err)
+
+	req.ObjectID = item.ID
+	return req
+}
+
+func get(explorer service.Explorer, req dto.Request) ([]byte, error) {
+	var body bytes.Buffer
+	writer := http.Writer{
+		ObjectHeader: func(http.ObjectHeader) {},
+		NotModified:  func() {},
+		Header:       func(string, int) {},
+		Body: func(buffer []byte) error {
+			_, err := body.Write(buffer)
+			return err
+		},
+	}
+
+	err := explorer.Download(context.Background(), req, writer, true)
+	return body.Bytes(), err
+}
+
+// versionEncryption reads the last version as the registry keeps it, with the wrapped data key.
+func versionEncryption(t *testing.T, s *sweepCluster, req dto.Request) dto.Version {
+	t.Helper()
+
+	resp, err := s.registry.GetByObjectID(context.Background(), &rpcmessage.ObjectMetadataRequest{
+		ObjectID:  req.ObjectID.ToInt64(),
+		Group:     req.Group,
+		Partition: req.Partition,
+		Path:      req.Path,
+	})
+	must(t, err)
+
+	versions := message.ToObjectMetadataDTO(resp).Versions
+	return versions[len(versions)-1]
+}
+
+func TestDataKeySealedUpload(t *testing.T) {
+	keys := newFakeKMS(t, "k1")
+	explorer, s := newEncryptingExplorer(t, keys)
+
+	body := []byte("sealed with a data key..")
+	req := putEncrypted(t, explorer, "a.txt", body)
+
+	version := versionEncryption(t, s, req)
+	if version.Encryption.KeyID != "k1" || len(version.Encryption.DataKey) == 0 {
+		t.Fatalf("encryption %+v, want a data key wrapped by k1", version.Encryption)
+	}
+	// the node seals the blocks, so only the headers it stores say so
+	if size := version.BlockHeaders.Size(); size != len(body) {
+		t.Errorf("version size %d, want %d", size, len(body))
+	}
+
+	list := &rpcmessage.ListBlocksRequest{Before: timestamppb.New(time.Now().Add(time.Hour))}
+	err := s.storage.ListBlocks(context.Background(), list, func(header *message.BlockHeader) error {
+		if !header.DataKeySealed || header.Size != testBlockSize+encryption.Overhead {
+			t.Errorf("stored block %d: data key sealed %v, %d bytes", header.Index, header.DataKeySealed, header.Size)
+		}
+		return nil
+	})
+	must(t, err)
+
+	got, err := get(explorer, req)
+	if err != nil || !bytes.Equal(got, body) {
+		t.Fatalf("download: %q, %v", got, err)
+	}
+
+	// without its master key the data key, and so the object, can not be read
+	delete(keys.keys, "k1")
+	if _, err := get(explorer, req); err == nil {
+		t.Error("download without the master key succeeded")
+	}
+}
+
+func TestRotateDataKeys(t *testing.T) {
+	keys := newFakeKMS(t, "k1", "k2")
+	explorer, s := newEncryptingExplorer(t, keys)
+
+	bodies := map[string][]byte{
+		"a.txt": []byte("first object"),
+		"b.txt": bytes.Repeat([]byte("second object spans a few blocks "), 3),
+	}
+	requests := map[string]dto.Request{}
+	for name, body := range bodies {
+		requests[name] = putEncrypted(t, explorer, name, body)
+	}
+	dataKeys := map[string][]byte{}
+	for name, req := range requests {
+		encryption := versionEncryption(t, s, req).Encryption
+		dataKeys[name], _ = keys.Unwrap(encryption.KeyID, encryption.DataKey)
+	}
+
+	keys.staged = "k2"
+	report, err := explorer.RotateDataKeys(context.Background())
+	must(t, err)
+	if report.KeyID != "k2" || report.RewrappedVersions != 2 || len(report.Errors) != 0 {
+		t.Fatalf("report %+v, want 2 versions rewrapped by k2", report)
+	}
+
+	// the data keys are the same, only wrapped by the new master key, so the blocks are not rewritten
+	for name, req := range requests {
+		encryption := versionEncryption(t, s, req).Encryption
+		dataKey, err := keys.Unwrap(encryption.KeyID, encryption.DataKey)
+		if encryption.KeyID != "k2" || err != nil || !bytes.Equal(dataKey, dataKeys[name]) {
+			t.Errorf("%s: wrapped by %s, %v, want the same data key wrapped by k2", name, encryption.KeyID, err)
+		}
+	}
+
+	// the retired master key is no longer needed
+	delete(keys.keys, "k1")
+	for name, req := range requests {
+		got, err := get(explorer, req)
+		if err != nil || !bytes.Equal(got, bodies[name]) {
+			t.Errorf("%s after rotation: %q, %v", name, got, err)
+		}
+	}
+
+	report, err = explorer.RotateDataKeys(context.Background())
+	must(t, err)
+	if report.ScannedObjects != 0 || report.RewrappedVersions != 0 {
+		t.Errorf("second rotation %+v, want nothing left to rewrap", report)
+	}
+}
+
+// A data key whose master key is gone can not be rewrapped. The object is reported and the others
+// are rotated all the same.
+func TestRotateDataKeysReportsUnknownMasterKey(t *testing.T) {
+	keys := newFakeKMS(t, "k1", "k2", "k3")
+	explorer, _ := newEncryptingExplorer(t, keys)
+
+	lost := putEncrypted(t, explorer, "lost.txt", []byte("wrapped by k1"))
+	keys.primary, keys.staged = "k2", "k2"
+	kept := putEncrypted(t, explorer, "kept.txt", []byte("wrapped by k2"))
+
+	delete(keys.keys, "k1")
+	keys.staged = "k3"
+	report, err := explorer.RotateDataKeys(context.Background())
+	must(t, err)
+
+	if report.ScannedObjects != 2 || report.RewrappedVersions != 1 || len(report.Errors) != 1 {
+		t.Fatalf("report %+v, want 1 of 2 objects rewrapped and 1 error", report)
+	}
+	if _, err := get(explorer, kept); err != nil {
+		t.Errorf("rotated object: %v", err)
+	}
+	if _, err := get(explorer, lost); err == nil {
+		t.Error("object of the lost master key is readable")
+	}
+}
@@ -0,0 +1,168 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/service"
+	"github.com/ISSuh/sos/internal/http"
+)
+
+// downloadRecorder records what a download writes, the way the REST handler would send it.
+type downloadRecorder struct {
+	status        string
+	ranges        http.Ranges
+	parts         []http.Range
+	unsatisfiable int
+	body          bytes.Buffer
+}
+
+func (d *downloadRecorder) writer() http.Writer {
+	return http.Writer{
+		ObjectHeader: func(http.ObjectHeader) {},
+		NotModified:  func() { d.status = "not modified" },
+		Header:       func(string, int) { d.status = "whole" },
+		RangeHeader: func(_ string, _ int, ranges http.Ranges) {
+			d.status = "partial"
+			d.ranges = ranges
+		},
+		Part: func(r http.Range, _ int) error {
+			d.parts = append(d.parts, r)
+			d.body.WriteString("|")
+			return nil
+		},
+		Body: func(buffer []byte) error {
+			_, err := d.body.Write(buffer)
+			return err
+		},
+		Close: func() error { return nil },
+		Unsatisfiable: func(size int) {
+			d.status = "unsatisfiable"
+			d.unsatisfiable = size
+		},
+	}
+}
+
+func download(t *testing.T, explorer service.Explorer, req dto.Request, rangeHeader, ifRange string) *downloadRecorder {
+	t.Helper()
+
+	req.Range = rangeHeader
+	req.IfRange = ifRange
+
+	d := &downloadRecorder{}
+	if err := explorer.Download(context.Background(), req, d.writer(), true); err != nil {
+		t.Fatalf("download %q: %v", rangeHeader, err)
+	}
+	return d
+}
+
+func TestDownloadRange(t *testing.T) {
+	explorer, s := newEncryptingExplorer(t, nil)
+
+	// blocks of testBlockSize bytes, so most ranges start and end inside a block
+	body := "0123456789abcdefghijklmnopqrstuvwxyz"
+	req := putEncrypted(t, explorer, "range.txt", []byte(body))
+	etag := versionEncryption(t, s, req).ETag
+
+	t.Run("single range across blocks", func(t *testing.T) {
+		d := download(t, explorer, req, "bytes=3-13", "")
+		if d.status != "partial" || d.body.String() != body[3:14] {
+			t.Fatalf("%s %q, want partial %q", d.status, d.body.String(), body[3:14])
+		}
+		if len(d.parts) != 0 {
+			t.Errorf("a single range is sent as %d parts", len(d.parts))
+		}
+	})
+
+	t.Run("suffix range", func(t *testing.T) {
+		d := download(t, explorer, req, "bytes=-5", "")
+		if d.body.String() != body[len(body)-5:] {
+			t.Errorf("got %q, want the last 5 bytes", d.body.String())
+		}
+	})
+
+	t.Run("open ended range clamped to the object", func(t *testing.T) {
+		d := download(t, explorer, req, "bytes=30-1000", "")
+		want := http.Range{Start: 30, Length: len(body) - 30}
+		if len(d.ranges) != 1 || d.ranges[0] != want || d.body.String() != body[30:] {
+			t.Errorf("ranges %v body %q, want %v", d.ranges, d.body.String(), want)
+		}
+	})
+
+	t.Run("multiple ranges as parts", func(t *testing.T) {
+		d := download(t, explorer, req, "bytes=0-1,9-10,-2", "")
+		if len(d.parts) != 3 {
+			t.Fatalf("%d parts, want 3", len(d.parts))
+		}
+		if got := d.body.String(); got != "|01|9a|yz" {
+			t.Errorf("parts %q", got)
+		}
+	})
+
+	t.Run("overlapping ranges larger than the object", func(t *testing.T) {
+		d := download(t, explorer, req, "bytes=0-,0-", "")
+		if d.status != "whole" || d.body.String() != body {
+			t.Errorf("%s %q, want the whole object", d.status, d.body.String())
+		}
+	})
+
+	t.Run("range beyond the end", func(t *testing.T) {
+		d := download(t, explorer, req, "bytes=100-200", "")
+		if d.status != "unsatisfiable" || d.unsatisfiable != len(body) || d.body.Len() != 0 {
+			t.Errorf("%s of %d, body %q", d.status, d.unsatisfiable, d.body.String())
+		}
+	})
+
+	t.Run("malformed range ignored", func(t *testing.T) {
+		d := download(t, explorer, req, "items=0-1", "")
+		if d.status != "whole" || d.body.String() != body {
+			t.Errorf("%s %q, want the whole object", d.status, d.body.String())
+		}
+	})
+
+	t.Run("if-range of the current etag", func(t *testing.T) {
+		d := download(t, explorer, req, "bytes=0-3", http.QuoteETag(etag))
+		if d.status != "partial" || d.body.String() != "0123" {
+			t.Errorf("%s %q, want the range", d.status, d.body.String())
+		}
+	})
+
+	t.Run("if-range of a stale etag", func(t *testing.T) {
+		d := download(t, explorer, req, "bytes=0-3", `"stale"`)
+		if d.status != "whole" || d.body.String() != body {
+			t.Errorf("%s %q, want the whole object", d.status, d.body.String())
+		}
+	})
+
+	t.Run("if-range of another date", func(t *testing.T) {
+		d := download(t, explorer, req, "bytes=0-3", "Mon, 02 Jan 2006 15:04:05 GMT")
+		if d.status != "whole" || !strings.HasPrefix(d.body.String(), "0123456789") {
+			t.Errorf("%s %q, want the whole object", d.status, d.body.String())
+		}
+	})
+}
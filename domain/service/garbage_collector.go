@@ -0,0 +1,378 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/model/message"
+	"github.com/ISSuh/sos/domain/service/object"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/validation"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	garbageBatchSize     = 256
+	maxGarbageReportSize = 1000
+)
+
+var ErrGarbageCollectionRunning = errors.New("garbage collection is already running")
+
+// GarbageCollector deletes the blocks on block storage nodes that no object metadata or upload
+// session references, e.g. left behind by a failed upload or delete. Blocks younger than the
+// grace period are kept because their metadata may not be written yet. The reference counts of
+// deduplicated content are reconciled with the block headers placing the content on the node,
+// unless they changed within the grace period.
+type GarbageCollector interface {
+	Run(c context.Context, interval time.Duration)
+	Collect(c context.Context, dryRun bool) (dto.GarbageReport, error)
+	LastReport() (dto.GarbageReport, bool)
+}
+
+type garbageCollector struct {
+	metadataRequestor rpc.MetadataRegistryRequestor
+	storageCluster    *object.StorageCluster
+	gracePeriod       time.Duration
+	dryRun            bool
+
+	running sync.Mutex
+
+	mutex      sync.Mutex
+	lastReport *dto.GarbageReport
+}
+
+// NewGarbageCollector only reports the orphans on the periodic runs when dryRun is set.
+func NewGarbageCollector(
+	metadataRequestor rpc.MetadataRegistryRequestor, storageCluster *object.StorageCluster,
+	gracePeriod time.Duration, dryRun bool,
+) (GarbageCollector, error) {
+	switch {
+	case validation.IsNil(metadataRequestor):
+		return nil, errors.New("MetadataRegistry requestor is nil")
+	case validation.IsNil(storageCluster):
+		return nil, errors.New("BlockStorage cluster is nil")
+	case gracePeriod < 0:
+		return nil, errors.New("garbage collection grace period is invalid")
+	}
+
+	return &garbageCollector{
+		metadataRequestor: metadataRequestor,
+		storageCluster:    storageCluster,
+		gracePeriod:       gracePeriod,
+		dryRun:            dryRun,
+	}, nil
+}
+
+// Run collects garbage every interval until the context is done.
+func (g *garbageCollector) Run(c context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+			report, err := g.Collect(c, g.dryRun)
+			if err != nil {
+				log.FromContext(c).Errorf("[garbageCollector.Run] collect fail. %s", err.Error())
+				continue
+			}
+
+			log.FromContext(c).Infof("[garbageCollector.Run] scanned %d blocks, found %d orphans, deleted %d, "+
+				"found %d miscounted contents, reconciled %d. dry run: %t",
+				report.ScannedBlocks, report.OrphanBlocks, report.DeletedBlocks,
+				report.MiscountedContents, report.ReconciledContents, report.DryRun)
+		}
+	}
+}
+
+// Collect walks every node, and a node that can not be walked is recorded in the report
+// without stopping the others.
+func (g *garbageCollector) Collect(c context.Context, dryRun bool) (dto.GarbageReport, error) {
+	if !g.running.TryLock() {
+		return dto.GarbageReport{}, ErrGarbageCollectionRunning
+	}
+	defer g.running.Unlock()
+
+	report := dto.GarbageReport{
+		DryRun:      dryRun,
+		GracePeriod: g.gracePeriod.String(),
+		StartedAt:   time.Now(),
+	}
+
+	before := report.StartedAt.Add(-g.gracePeriod)
+	for _, node := range g.storageCluster.Nodes() {
+		if err := g.collectNode(c, node, before, &report); err != nil {
+			log.FromContext(c).Errorf("[garbageCollector.Collect] node %s fail. %s", node.Node.Host, err.Error())
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", node.Node.Host, err.Error()))
+		}
+	}
+
+	report.FinishedAt = time.Now()
+
+	g.mutex.Lock()
+	g.lastReport = &report
+	g.mutex.Unlock()
+	return report, c.Err()
+}
+
+func (g *garbageCollector) LastReport() (dto.GarbageReport, bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.lastReport == nil {
+		return dto.GarbageReport{}, false
+	}
+	return *g.lastReport, true
+}
+
+func (g *garbageCollector) collectNode(
+	c context.Context, node object.StorageNode, before time.Time, report *dto.GarbageReport,
+) error {
+	batch := make([]*message.BlockHeader, 0, garbageBatchSize)
+	contents := make([]*message.BlockHeader, 0, garbageBatchSize)
+	flush := func() error {
+		if len(batch) > 0 {
+			if err := g.collectBatch(c, node, batch, report); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+
+		if len(contents) > 0 {
+			if err := g.reconcileContents(c, node, before, contents, report); err != nil {
+				return err
+			}
+			contents = contents[:0]
+		}
+		return nil
+	}
+
+	req := rpcmessage.ListBlocksRequest{
+		Before: timestamppb.New(before),
+	}
+
+	err := node.Requestor.ListBlocks(c, &req, func(header *message.BlockHeader) error {
+		report.ScannedBlocks++
+		// deduplicated content is shared by objects, so it is matched by its hash rather than its block
+		if header.Hash != "" {
+			contents = append(contents, header)
+		} else {
+			batch = append(batch, header)
+		}
+
+		if len(batch) < garbageBatchSize && len(contents) < garbageBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+func (g *garbageCollector) collectBatch(
+	c context.Context, node object.StorageNode, batch []*message.BlockHeader, report *dto.GarbageReport,
+) error {
+	referenced, err := g.referencedBlocks(c, batch)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range batch {
+		header := message.ToBlockHeaderDTO(msg)
+		if referenced[g.blockKey(header.ObjectID, header.BlockID, header.Index)] {
+			continue
+		}
+
+		orphan := dto.OrphanBlock{
+			Node:      node.Node.Host,
+			ObjectID:  header.ObjectID,
+			BlockID:   header.BlockID,
+			Index:     header.Index,
+			Size:      header.Size,
+			Timestamp: header.Timestamp,
+		}
+
+		if !report.DryRun {
+			if _, err := node.Requestor.Delete(c, msg); err != nil {
+				orphan.Error = err.Error()
+			} else {
+				orphan.Deleted = true
+				report.DeletedBlocks++
+			}
+		}
+
+		log.FromContext(c).Debugf("[garbageCollector.collectBatch] orphan block: %+v", orphan)
+		g.reportOrphan(report, orphan)
+	}
+	return nil
+}
+
+// reconcileContents sets the reference count of the content on the node to the number of block headers
+// placing it there. The node keeps a count that changed since before, as its metadata may not be
+// written yet, and deletes the content that is no longer referenced.
+func (g *garbageCollector) reconcileContents(
+	c context.Context, node object.StorageNode, before time.Time, batch []*message.BlockHeader, report *dto.GarbageReport,
+) error {
+	hashes := make([]string, 0, len(batch))
+	for _, header := range batch {
+		hashes = append(hashes, header.Hash)
+	}
+
+	req := rpcmessage.BlockHeaderRequest{
+		Hashes: hashes,
+	}
+
+	resp, err := g.metadataRequestor.FindBlockHeaders(c, &req)
+	if err != nil {
+		return err
+	}
+
+	references := map[string]int64{}
+	for _, header := range message.ToBlockHeaderListDTO(resp) {
+		if slices.Contains(header.Replicas.Hosts(), node.Node.Host) {
+			references[header.Hash]++
+		}
+	}
+
+	for _, msg := range batch {
+		expected := references[msg.Hash]
+		if expected == msg.References {
+			continue
+		}
+
+		reconciled, err := g.reconcileContent(c, node, msg.Hash, expected, before, report.DryRun)
+		if expected > 0 {
+			log.FromContext(c).Infof("[garbageCollector.reconcileContents] content %s on %s has %d references, metadata holds %d. reconciled: %t",
+				msg.Hash, node.Node.Host, msg.References, expected, reconciled)
+
+			report.MiscountedContents++
+			if reconciled {
+				report.ReconciledContents++
+			}
+			continue
+		}
+
+		header := message.ToBlockHeaderDTO(msg)
+		orphan := dto.OrphanBlock{
+			Node:      node.Node.Host,
+			ObjectID:  header.ObjectID,
+			BlockID:   header.BlockID,
+			Index:     header.Index,
+			Hash:      header.Hash,
+			Size:      header.Size,
+			Timestamp: header.Timestamp,
+			Deleted:   reconciled,
+		}
+		if err != nil {
+			orphan.Error = err.Error()
+		}
+
+		if reconciled {
+			report.DeletedBlocks++
+		}
+
+		log.FromContext(c).Debugf("[garbageCollector.reconcileContents] orphan content: %+v", orphan)
+		g.reportOrphan(report, orphan)
+	}
+	return nil
+}
+
+func (g *garbageCollector) reconcileContent(
+	c context.Context, node object.StorageNode, hash string, references int64, before time.Time, dryRun bool,
+) (bool, error) {
+	if dryRun {
+		return false, nil
+	}
+
+	req := rpcmessage.ReconcileRequest{
+		Hash:       hash,
+		References: references,
+		Before:     timestamppb.New(before),
+	}
+
+	resp, err := node.Requestor.Reconcile(c, &req)
+	if err != nil {
+		log.FromContext(c).Warnf("[garbageCollector.reconcileContent] node %s can not reconcile %s. %s", node.Node.Host, hash, err.Error())
+		return false, err
+	}
+	return resp.Reconciled, nil
+}
+
+func (g *garbageCollector) reportOrphan(report *dto.GarbageReport, orphan dto.OrphanBlock) {
+	report.OrphanBlocks++
+	report.OrphanBytes += int64(orphan.Size)
+	if len(report.Orphans) < maxGarbageReportSize {
+		report.Orphans = append(report.Orphans, orphan)
+	}
+}
+
+// referencedBlocks returns the blocks of the batch objects referenced by metadata or an upload
+// session, including the shards of erasure coded blocks.
+func (g *garbageCollector) referencedBlocks(c context.Context, batch []*message.BlockHeader) (map[string]bool, error) {
+	seen := map[int64]bool{}
+	objectIDs := make([]int64, 0, len(batch))
+	for _, header := range batch {
+		objectID := message.ToObjectID(header.ObjectID).ToInt64()
+		if !seen[objectID] {
+			seen[objectID] = true
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+
+	req := rpcmessage.BlockHeaderRequest{
+		ObjectIDs: objectIDs,
+	}
+
+	resp, err := g.metadataRequestor.FindBlockHeaders(c, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := map[string]bool{}
+	for _, header := range message.ToBlockHeaderListDTO(resp) {
+		referenced[g.blockKey(header.ObjectID, header.BlockID, header.Index)] = true
+		for _, shard := range header.Shards {
+			referenced[g.blockKey(header.ObjectID, shard.BlockID, header.Index)] = true
+		}
+	}
+	return referenced, nil
+}
+
+func (g *garbageCollector) blockKey(objectID entity.ObjectID, blockID entity.BlockID, index int) string {
+	return fmt.Sprintf("%d:%d:%d", objectID, blockID, index)
+}
@@ -0,0 +1,317 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package object
+
+import (
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	"github.com/ISSuh/sos/internal/compression"
+	"github.com/ISSuh/sos/internal/validation"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	unhealthyNodeCooldown = 30 * time.Second
+)
+
+type StorageNode struct {
+	Node      entity.Node
+	Requestor rpc.BlockStorageRequestor
+}
+
+// ErasureCodingRule applies an erasure coding scheme to a group, or to one partition of it
+// when the partition is set.
+type ErasureCodingRule struct {
+	Group     string
+	Partition string
+	Scheme    entity.ErasureCoding
+}
+
+// CompressionRule compresses the blocks of the objects matching every criterion it sets, a group, a partition
+// of it and the content types, where "text/*" matches every text type.
+type CompressionRule struct {
+	Group        string
+	Partition    string
+	ContentTypes []string
+	Codec        compression.Codec
+}
+
+func (r CompressionRule) matches(group, partition, contentType string) bool {
+	switch {
+	case !validation.IsEmpty(r.Group) && r.Group != group:
+		return false
+	case !validation.IsEmpty(r.Partition) && r.Partition != partition:
+		return false
+	case len(r.ContentTypes) == 0:
+		return true
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, ruleType := range r.ContentTypes {
+		ruleType = strings.ToLower(strings.TrimSpace(ruleType))
+		prefix, wildcard := strings.CutSuffix(ruleType, "*")
+		if ruleType == mediaType || (wildcard && strings.HasPrefix(mediaType, prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// StorageCluster places block replicas on block storage nodes and tracks which nodes are reachable.
+type StorageCluster struct {
+	nodes         []StorageNode
+	replication   int
+	rules         []ErasureCodingRule
+	deduplication bool
+	compression   []CompressionRule
+	concurrency   int
+	buffers       sync.Pool
+
+	mutex          sync.RWMutex
+	unhealthyUntil map[string]time.Time
+}
+
+func NewStorageCluster(
+	nodes []StorageNode, replication int, rules []ErasureCodingRule, deduplication bool, compressionRules []CompressionRule,
+	blockSize int, concurrency int,
+) (*StorageCluster, error) {
+	switch {
+	case len(nodes) == 0:
+		return nil, errors.New("block storage nodes are empty")
+	case replication <= 0:
+		return nil, errors.New("replication factor is invalid")
+	case replication > len(nodes):
+		return nil, errors.New("replication factor is larger than the number of block storage nodes")
+	case blockSize <= 0 || blockSize > entity.MaxBlockSize:
+		return nil, errors.New("block size is invalid")
+	case concurrency <= 0:
+		return nil, errors.New("concurrency is invalid")
+	}
+
+	for _, node := range nodes {
+		switch {
+		case node.Node.Empty():
+			return nil, errors.New("block storage node host is empty")
+		case validation.IsNil(node.Requestor):
+			return nil, errors.New("BlockStorage requestor is nil")
+		}
+	}
+
+	for _, rule := range rules {
+		switch {
+		case validation.IsEmpty(rule.Group):
+			return nil, errors.New("erasure coding group is empty")
+		case rule.Scheme.DataShards <= 0 || rule.Scheme.ParityShards <= 0:
+			return nil, errors.New("erasure coding shards are invalid")
+		case rule.Scheme.TotalShards() > len(nodes):
+			return nil, errors.New("erasure coding shards are more than the number of block storage nodes")
+		}
+	}
+
+	for _, rule := range compressionRules {
+		if _, err := compression.ParseCodec(string(rule.Codec)); err != nil {
+			return nil, err
+		}
+	}
+
+	cluster := &StorageCluster{
+		nodes:          nodes,
+		replication:    replication,
+		rules:          rules,
+		deduplication:  deduplication,
+		compression:    compressionRules,
+		concurrency:    concurrency,
+		unhealthyUntil: map[string]time.Time{},
+	}
+
+	cluster.buffers.New = func() any {
+		buffer := make([]byte, blockSize)
+		return &buffer
+	}
+	return cluster, nil
+}
+
+func (c *StorageCluster) Replication() int {
+	return c.replication
+}
+
+// Concurrency is the number of blocks of an object sent or fetched at once.
+func (c *StorageCluster) Concurrency() int {
+	return c.concurrency
+}
+
+// acquireBuffer returns a buffer of the block size objects are split into, which is reused by later uploads once released.
+func (c *StorageCluster) acquireBuffer() *[]byte {
+	return c.buffers.Get().(*[]byte)
+}
+
+func (c *StorageCluster) releaseBuffer(buffer *[]byte) {
+	c.buffers.Put(buffer)
+}
+
+// Deduplication reports whether replicated blocks are stored once per content. Erasure coded
+// blocks are never deduplicated.
+func (c *StorageCluster) Deduplication() bool {
+	return c.deduplication
+}
+
+// ErasureCodingRules returns the rules of the erasure coded groups and partitions.
+func (c *StorageCluster) ErasureCodingRules() []ErasureCodingRule {
+	return c.rules
+}
+
+// ErasureCoding returns the scheme for objects on the partition. A partition rule takes
+// precedence over a group rule, and objects without a rule are replicated.
+func (c *StorageCluster) ErasureCoding(group, partition string) entity.ErasureCoding {
+	var scheme entity.ErasureCoding
+	for _, rule := range c.rules {
+		if rule.Group != group {
+			continue
+		}
+
+		switch rule.Partition {
+		case partition:
+			return rule.Scheme
+		case "":
+			scheme = rule.Scheme
+		}
+	}
+	return scheme
+}
+
+// Compression returns the codec for objects of the content type on the partition. The first matching
+// rule applies, and objects without a rule are stored raw.
+func (c *StorageCluster) Compression(group, partition, contentType string) compression.Codec {
+	for _, rule := range c.compression {
+		if rule.matches(group, partition, contentType) {
+			return rule.Codec
+		}
+	}
+	return compression.None
+}
+
+// Placement returns every node ordered by preference for the block. The order is stable for
+// a block id so replicas spread evenly and healthy nodes are tried first.
+func (c *StorageCluster) Placement(blockID entity.BlockID) []StorageNode {
+	nodes := make([]StorageNode, len(c.nodes))
+	copy(nodes, c.nodes)
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return c.score(nodes[i].Node, blockID) > c.score(nodes[j].Node, blockID)
+	})
+	return c.healthyFirst(nodes)
+}
+
+// Replicas returns the nodes holding the block, healthy nodes first. Blocks written
+// before replication do not record their nodes, so every node is a candidate.
+func (c *StorageCluster) Replicas(blockHeader dto.BlockHeader) []StorageNode {
+	if blockHeader.Replicas.Empty() {
+		return c.Placement(blockHeader.BlockID)
+	}
+
+	nodes := make([]StorageNode, 0, len(blockHeader.Replicas))
+	for _, replica := range blockHeader.Replicas {
+		if node, exist := c.Node(replica); exist {
+			nodes = append(nodes, node)
+		}
+	}
+	return c.healthyFirst(nodes)
+}
+
+// Report records the result of a request to the node. Only transport failures mark a node
+// unhealthy, a missing block on a reachable node says nothing about its health.
+func (c *StorageCluster) Report(node entity.Node, err error) {
+	switch {
+	case err == nil:
+		c.MarkHealthy(node)
+	case status.Code(err) == codes.Unavailable || status.Code(err) == codes.DeadlineExceeded:
+		c.MarkUnhealthy(node)
+	}
+}
+
+func (c *StorageCluster) MarkHealthy(node entity.Node) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.unhealthyUntil, node.Host)
+}
+
+func (c *StorageCluster) MarkUnhealthy(node entity.Node) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.unhealthyUntil[node.Host] = time.Now().Add(unhealthyNodeCooldown)
+}
+
+func (c *StorageCluster) IsHealthy(node entity.Node) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	until, exist := c.unhealthyUntil[node.Host]
+	return !exist || time.Now().After(until)
+}
+
+func (c *StorageCluster) Nodes() []StorageNode {
+	nodes := make([]StorageNode, len(c.nodes))
+	copy(nodes, c.nodes)
+	return nodes
+}
+
+func (c *StorageCluster) Node(node entity.Node) (StorageNode, bool) {
+	for _, n := range c.nodes {
+		if n.Node.Host == node.Host {
+			return n, true
+		}
+	}
+	return StorageNode{}, false
+}
+
+func (c *StorageCluster) healthyFirst(nodes []StorageNode) []StorageNode {
+	// unhealthy nodes stay at the end as a last resort instead of being dropped
+	healthy := make([]StorageNode, 0, len(nodes))
+	var unhealthy []StorageNode
+	for _, node := range nodes {
+		if c.IsHealthy(node.Node) {
+			healthy = append(healthy, node)
+		} else {
+			unhealthy = append(unhealthy, node)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (c *StorageCluster) score(node entity.Node, blockID entity.BlockID) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(node.Host))
+	h.Write([]byte(blockID.String()))
+	return h.Sum64()
+}
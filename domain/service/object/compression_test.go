@@ -0,0 +1,194 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package object_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/service/object"
+	"github.com/ISSuh/sos/internal/checksum"
+	"github.com/ISSuh/sos/internal/compression"
+	"github.com/ISSuh/sos/internal/encryption"
+	"github.com/ISSuh/sos/internal/http"
+)
+
+// compressibleBody alternates blocks of text, which shrink, with random blocks, which are stored raw,
+// and ends with a short random block.
+func compressibleBody() []byte {
+	text := bytes.Repeat([]byte("level=info msg=ok "), testBlockSize)[:testBlockSize]
+	random := make([]byte, testBlockSize+300)
+	rand.New(rand.NewSource(7)).Read(random)
+
+	var body []byte
+	body = append(body, text...)
+	body = append(body, random[:testBlockSize]...)
+	body = append(body, text...)
+	body = append(body, random[testBlockSize:]...)
+	return body
+}
+
+func upload(t *testing.T, cluster *object.StorageCluster, codec compression.Codec, keys object.Keys, body []byte) dto.Version {
+	t.Helper()
+
+	uploader := object.NewUploader(cluster, entity.ErasureCoding{}, codec, keys)
+	headers, err := uploader.Upload(context.Background(), entity.NewObjectID(), io.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dto.Version{BlockHeaders: headers}
+}
+
+func downloadRange(cluster *object.StorageCluster, keys object.Keys, version dto.Version, r http.Range) ([]byte, error) {
+	var body bytes.Buffer
+	downloader := object.NewDownloader(cluster, keys)
+	err := downloader.DownloadRange(context.Background(), version, r, func(buffer []byte) error {
+		_, err := body.Write(buffer)
+		return err
+	})
+	return body.Bytes(), err
+}
+
+// ranges cross the boundaries between compressed and raw blocks, which only line up when every block
+// accounts for the size of its content rather than its stored size.
+var ranges = []http.Range{
+	{Start: 0, Length: 10},
+	{Start: testBlockSize - 5, Length: 10},
+	{Start: testBlockSize + 100, Length: testBlockSize},
+	{Start: 2*testBlockSize - 1, Length: 2},
+	{Start: 3*testBlockSize + 299, Length: 1},
+	{Start: 0, Length: 3*testBlockSize + 300},
+}
+
+func TestCompressedUpload(t *testing.T) {
+	body := compressibleBody()
+	for _, codec := range []compression.Codec{compression.Zstd, compression.Snappy, compression.Gzip} {
+		t.Run(string(codec), func(t *testing.T) {
+			cluster, _ := newMemoryCluster(t, 1, false)
+			version := upload(t, cluster, codec, object.Keys{}, body)
+
+			headers := version.BlockHeaders
+			if len(headers) != 4 {
+				t.Fatalf("%d blocks, want 4", len(headers))
+			}
+
+			for i, want := range []compression.Codec{codec, compression.None, codec, compression.None} {
+				header := headers[i]
+				if header.Compression != string(want) {
+					t.Errorf("block %d compressed with %q, want %q", i, header.Compression, want)
+				}
+				if want != compression.None && (header.UncompressedSize != testBlockSize || header.Size >= testBlockSize) {
+					t.Errorf("block %d stored %d bytes of %d", i, header.Size, header.UncompressedSize)
+				}
+			}
+
+			if size := headers.Size(); size != len(body) {
+				t.Errorf("version size %d, want %d", size, len(body))
+			}
+
+			got, err := download(cluster, version)
+			if err != nil || !bytes.Equal(got, body) {
+				t.Fatalf("download: %d bytes, %v", len(got), err)
+			}
+
+			for _, r := range ranges {
+				got, err := downloadRange(cluster, object.Keys{}, version, r)
+				if err != nil || !bytes.Equal(got, body[r.Start:r.End()+1]) {
+					t.Errorf("range %d-%d: %d bytes, %v", r.Start, r.End(), len(got), err)
+				}
+			}
+		})
+	}
+}
+
+// A block is compressed before it is sealed, as sealed data does not compress. The stored size is
+// the sealed size, while the content size stays the size before compression.
+func TestCompressedUploadSealedWithCustomerKey(t *testing.T) {
+	body := compressibleBody()
+	keys := object.Keys{CustomerKey: bytes.Repeat([]byte{0x11}, 32)}
+
+	cluster, _ := newMemoryCluster(t, 1, false)
+	version := upload(t, cluster, compression.Zstd, keys, body)
+
+	for i, header := range version.BlockHeaders {
+		if !header.CustomerKeyed {
+			t.Errorf("block %d is not sealed", i)
+		}
+
+		content := min(testBlockSize, len(body)-i*testBlockSize)
+		if header.ContentSize() != content {
+			t.Errorf("block %d content size %d, want %d", i, header.ContentSize(), content)
+		}
+
+		if header.Compression == "" && header.Size != content+encryption.Overhead {
+			t.Errorf("raw block %d stored %d bytes, want %d", i, header.Size, content+encryption.Overhead)
+		}
+	}
+	if version.BlockHeaders[0].Size >= testBlockSize {
+		t.Errorf("compressed block stored %d bytes", version.BlockHeaders[0].Size)
+	}
+
+	for _, r := range ranges {
+		got, err := downloadRange(cluster, keys, version, r)
+		if err != nil || !bytes.Equal(got, body[r.Start:r.End()+1]) {
+			t.Errorf("range %d-%d: %d bytes, %v", r.Start, r.End(), len(got), err)
+		}
+	}
+
+	if _, err := downloadRange(cluster, object.Keys{}, version, ranges[0]); err == nil {
+		t.Error("download without the customer key succeeded")
+	}
+}
+
+// A deduplicated block is identified by the hash of the data it is stored as, so the same content
+// compressed the same way is stored once.
+func TestCompressedUploadDeduplicated(t *testing.T) {
+	body := compressibleBody()
+	cluster, _ := newMemoryCluster(t, 2, true)
+
+	first := upload(t, cluster, compression.Snappy, object.Keys{}, body)
+	second := upload(t, cluster, compression.Snappy, object.Keys{}, body)
+
+	compressed, err := compression.Compress(compression.Snappy, body[:testBlockSize])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash := first.BlockHeaders[0].Hash; hash != checksum.Calculate(compressed) {
+		t.Errorf("hash of the compressed block %s, want the hash of its stored data", hash)
+	}
+
+	for i := range first.BlockHeaders {
+		if first.BlockHeaders[i].BlockID != second.BlockHeaders[i].BlockID {
+			t.Errorf("block %d stored twice, as %d and %d", i, first.BlockHeaders[i].BlockID, second.BlockHeaders[i].BlockID)
+		}
+	}
+
+	got, err := download(cluster, second)
+	if err != nil || !bytes.Equal(got, body) {
+		t.Fatalf("download: %d bytes, %v", len(got), err)
+	}
+}
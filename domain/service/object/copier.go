@@ -0,0 +1,253 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/model/message"
+	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
+	"github.com/ISSuh/sos/internal/compression"
+	"github.com/ISSuh/sos/internal/log"
+)
+
+type Copier struct {
+	cluster  *StorageCluster
+	scheme   entity.ErasureCoding
+	codec    compression.Codec
+	keys     Keys
+	recorder Recorder
+}
+
+// NewCopier returns a copier for the blocks encrypted with the keys. The copy shares the keys of its
+// source, so the nodes copy the encrypted blocks as they are. The scheme and the codec only apply to
+// a block uploaded again.
+func NewCopier(cluster *StorageCluster, scheme entity.ErasureCoding, codec compression.Codec, keys Keys) Copier {
+	return Copier{
+		cluster: cluster,
+		scheme:  scheme,
+		codec:   codec,
+		keys:    keys,
+	}
+}
+
+// RecordTo records every copied block with the recorder, as the uploader does.
+func (o *Copier) RecordTo(recorder Recorder) {
+	o.recorder = recorder
+}
+
+// Copy duplicates the blocks for the object. Each block is copied by the nodes already holding it,
+// and only a block they fail to copy is read and uploaded again.
+func (o *Copier) Copy(
+	c context.Context, objectID entity.ObjectID, blockHeaders dto.BlockHeaders,
+) (dto.BlockHeaders, error) {
+	copied := make(dto.BlockHeaders, 0, len(blockHeaders))
+	var owned dto.BlockHeaders
+	for _, blockHeader := range blockHeaders {
+		header, err := o.copyBlock(c, objectID, blockHeader)
+		if err != nil {
+			return nil, o.abort(c, owned, err)
+		}
+
+		own, err := recordBlock(c, o.recorder, header)
+		if own {
+			owned = append(owned, header)
+		}
+		if err != nil {
+			return nil, o.abort(c, owned, err)
+		}
+		copied = append(copied, header)
+	}
+	return copied, nil
+}
+
+func (o *Copier) abort(c context.Context, blockHeaders dto.BlockHeaders, err error) error {
+	deleter := NewDeleter(nil, o.cluster)
+	if deleteErr := deleter.DeleteBlocks(context.WithoutCancel(c), blockHeaders); deleteErr != nil {
+		log.FromContext(c).Errorf("Copy Error. can not delete copied blocks: %s", deleteErr.Error())
+	}
+	return err
+}
+
+func (o *Copier) copyBlock(
+	c context.Context, objectID entity.ObjectID, source dto.BlockHeader,
+) (dto.BlockHeader, error) {
+	target := source
+	target.ObjectID = objectID
+	target.Timestamp = time.Now()
+
+	var err error
+	switch {
+	case source.Hash != "":
+		err = o.referenceReplicas(c, source, &target)
+	case source.Shards.Empty():
+		target.BlockID = entity.NewBlockID()
+		err = o.copyReplicas(c, source, &target)
+	default:
+		target.BlockID = entity.NewBlockID()
+		err = o.copyShards(c, source, &target)
+	}
+
+	if err == nil {
+		return target, nil
+	}
+
+	log.FromContext(c).Warnf("Copy Error. block %d is uploaded again, %s", source.BlockID, err.Error())
+	return o.transfer(c, objectID, source)
+}
+
+func (o *Copier) copyReplicas(c context.Context, source dto.BlockHeader, target *dto.BlockHeader) error {
+	req := &rpcmessage.CopyBlockRequest{
+		Source: message.FromBlockHeaderDTO(&source),
+		Target: message.FromBlockHeaderDTO(target),
+	}
+
+	var replicas entity.Nodes
+	for _, node := range o.cluster.Replicas(source) {
+		if err := o.copy(c, node, req); err != nil {
+			log.FromContext(c).Warnf("Copy Error. node: %s, %s", node.Node.Host, err.Error())
+			continue
+		}
+		replicas = append(replicas, node.Node)
+	}
+
+	target.Replicas = replicas
+	if len(replicas) < o.cluster.Replication() {
+		o.deletePartial(c, *target)
+		return fmt.Errorf("copy fail. replicated %d of %d", len(replicas), o.cluster.Replication())
+	}
+	return nil
+}
+
+// referenceReplicas shares the content of a deduplicated block, so the copy keeps its block id
+// and only takes another reference on every node holding it.
+func (o *Copier) referenceReplicas(c context.Context, source dto.BlockHeader, target *dto.BlockHeader) error {
+	uploader := NewUploader(o.cluster, o.scheme, o.codec, o.keys)
+	msg := message.FromBlockHeaderDTO(&source)
+
+	var replicas entity.Nodes
+	for _, node := range o.cluster.Replicas(source) {
+		exist, err := uploader.referenceBlock(c, node, msg)
+		switch {
+		case err != nil:
+			log.FromContext(c).Warnf("Copy Error. node: %s, %s", node.Node.Host, err.Error())
+			continue
+		case !exist:
+			log.FromContext(c).Warnf("Copy Error. node: %s, content of block %d is not stored", node.Node.Host, source.BlockID)
+			continue
+		}
+		replicas = append(replicas, node.Node)
+	}
+
+	target.Replicas = replicas
+	if len(replicas) < o.cluster.Replication() {
+		o.deletePartial(c, *target)
+		return fmt.Errorf("copy fail. referenced %d of %d", len(replicas), o.cluster.Replication())
+	}
+	return nil
+}
+
+func (o *Copier) copyShards(c context.Context, source dto.BlockHeader, target *dto.BlockHeader) error {
+	// a shard is only readable on its own node, so every shard has to be copied there
+	target.Shards = make(entity.Shards, 0, len(source.Shards))
+	for _, shard := range source.Shards {
+		node, exist := o.cluster.Node(shard.Node)
+		if !exist {
+			o.deletePartial(c, *target)
+			return fmt.Errorf("copy fail. unknown shard node: %s", shard.Node.Host)
+		}
+
+		copied := shard
+		copied.BlockID = entity.NewBlockID()
+		req := &rpcmessage.CopyBlockRequest{
+			Source: o.shardHeader(source, shard),
+			Target: o.shardHeader(*target, copied),
+		}
+
+		if err := o.copy(c, node, req); err != nil {
+			o.deletePartial(c, *target)
+			return fmt.Errorf("copy fail. node: %s, %w", node.Node.Host, err)
+		}
+		target.Shards = append(target.Shards, copied)
+	}
+	return nil
+}
+
+// transfer reads the block and uploads it again with the scheme of the destination.
+func (o *Copier) transfer(
+	c context.Context, objectID entity.ObjectID, source dto.BlockHeader,
+) (dto.BlockHeader, error) {
+	downloader := NewDownloader(o.cluster, o.keys)
+	block, err := downloader.downloadBlock(c, &source)
+	if err != nil {
+		return dto.BlockHeader{}, err
+	}
+
+	uploader := NewUploader(o.cluster, o.scheme, o.codec, o.keys)
+	copied := uploader.buildBlock(objectID, source.Index, block.Buffer())
+	if err := uploader.uploadBlock(c, &copied); err != nil {
+		return dto.BlockHeader{}, err
+	}
+	return copied.Header, nil
+}
+
+func (o *Copier) copy(c context.Context, node StorageNode, req *rpcmessage.CopyBlockRequest) error {
+	resp, err := node.Requestor.Copy(c, req)
+	o.cluster.Report(node.Node, err)
+	if err != nil {
+		return err
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("copy fail. %s", resp.Message)
+	}
+	return nil
+}
+
+func (o *Copier) deletePartial(c context.Context, blockHeader dto.BlockHeader) {
+	// nothing is copied yet, and an empty header would be looked up on every node
+	if blockHeader.Replicas.Empty() && blockHeader.Shards.Empty() {
+		return
+	}
+
+	deleter := NewDeleter(nil, o.cluster)
+	if err := deleter.DeleteBlocks(context.WithoutCancel(c), dto.BlockHeaders{blockHeader}); err != nil {
+		log.FromContext(c).Errorf("Copy Error. can not delete partial copies: %s", err.Error())
+	}
+}
+
+func (o *Copier) shardHeader(blockHeader dto.BlockHeader, shard entity.Shard) *message.BlockHeader {
+	return &message.BlockHeader{
+		ObjectID: &message.ObjectID{
+			Id: blockHeader.ObjectID.ToInt64(),
+		},
+		BlockID: &message.BlockID{
+			Id: shard.BlockID.ToInt64(),
+		},
+		Index: int32(blockHeader.Index),
+	}
+}
@@ -24,21 +24,23 @@ package object
 
 import (
 	"context"
+	"errors"
 
 	"github.com/ISSuh/sos/domain/model/dto"
 	"github.com/ISSuh/sos/domain/model/message"
 	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	"github.com/ISSuh/sos/internal/log"
 )
 
 type Deleter struct {
-	objectRequestor  rpc.MetadataRegistryRequestor
-	storageRequestor rpc.BlockStorageRequestor
+	objectRequestor rpc.MetadataRegistryRequestor
+	cluster         *StorageCluster
 }
 
-func NewDeleter(objectRequestor rpc.MetadataRegistryRequestor, storageRequestor rpc.BlockStorageRequestor) Deleter {
+func NewDeleter(objectRequestor rpc.MetadataRegistryRequestor, cluster *StorageCluster) Deleter {
 	return Deleter{
-		objectRequestor:  objectRequestor,
-		storageRequestor: storageRequestor,
+		objectRequestor: objectRequestor,
+		cluster:         cluster,
 	}
 }
 
@@ -49,12 +51,14 @@ func (o *Deleter) Delete(c context.Context, metadata dto.Metadata) error {
 		}
 	}
 
+	// the blocks recorded on the pending versions are only known once they are dropped with the object
 	metadata.Versions = nil
-	if err := o.deleteObjectMetadata(c, &metadata); err != nil {
+	recorded, err := o.deleteObjectMetadata(c, &metadata)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return o.DeleteBlocks(context.WithoutCancel(c), recorded)
 }
 
 func (o *Deleter) DeleteVersion(c context.Context, metadata dto.Metadata, deleteVersionNum int) error {
@@ -71,39 +75,112 @@ func (o *Deleter) DeleteVersion(c context.Context, metadata dto.Metadata, delete
 		version,
 	}
 
-	if err := o.deleteObjectMetadata(c, &metadata); err != nil {
+	if _, err := o.deleteObjectMetadata(c, &metadata); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (o *Deleter) deleteObjectMetadata(c context.Context, metadata *dto.Metadata) error {
+func (o *Deleter) deleteObjectMetadata(c context.Context, metadata *dto.Metadata) (dto.BlockHeaders, error) {
 	msg := message.FromObjectMetadataDTO(metadata)
-	if err := o.objectRequestor.Delete(c, msg); err != nil {
-		return err
+	resp, err := o.objectRequestor.Delete(c, msg)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return message.ToBlockHeaderListDTO(resp), nil
 }
 
-func (o *Deleter) deleteBlocks(c context.Context, version dto.Version) error {
-	blockHeaders := version.BlockHeaders
+func (o *Deleter) DeleteBlocks(c context.Context, blockHeaders dto.BlockHeaders) error {
 	for _, blockHeader := range blockHeaders {
+		if err := o.deleteBlock(c, blockHeader); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *Deleter) deleteBlock(c context.Context, blockHeader dto.BlockHeader) error {
+	if !blockHeader.Shards.Empty() {
+		return o.deleteShards(c, blockHeader)
+	}
+
+	// a deduplicated block without replicas holds no reference, and releasing it on every node
+	// would drop the references of other objects
+	if blockHeader.Hash != "" && blockHeader.Replicas.Empty() {
+		return nil
+	}
+
+	// a deduplicated block only drops its reference, the content is deleted with the last one
+	msg := &message.BlockHeader{
+		ObjectID: &message.ObjectID{
+			Id: blockHeader.ObjectID.ToInt64(),
+		},
+		BlockID: &message.BlockID{
+			Id: blockHeader.BlockID.ToInt64(),
+		},
+		Index: int32(blockHeader.Index),
+		Hash:  blockHeader.Hash,
+	}
+
+	// a replica on an unreachable node is left behind rather than failing the whole delete
+	var errs []error
+	deleted := 0
+	for _, node := range o.cluster.Replicas(blockHeader) {
+		_, err := node.Requestor.Delete(c, msg)
+		o.cluster.Report(node.Node, err)
+		if err != nil {
+			log.FromContext(c).Warnf("Delete Error. node: %s, %s", node.Node.Host, err.Error())
+			errs = append(errs, err)
+			continue
+		}
+		deleted++
+	}
+
+	if deleted == 0 && len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func (o *Deleter) deleteShards(c context.Context, blockHeader dto.BlockHeader) error {
+	var errs []error
+	deleted := 0
+	for _, shard := range blockHeader.Shards {
+		node, exist := o.cluster.Node(shard.Node)
+		if !exist {
+			log.FromContext(c).Warnf("Delete Error. unknown shard node: %s", shard.Node.Host)
+			continue
+		}
+
 		msg := &message.BlockHeader{
 			ObjectID: &message.ObjectID{
 				Id: blockHeader.ObjectID.ToInt64(),
 			},
 			BlockID: &message.BlockID{
-				Id: blockHeader.BlockID.ToInt64(),
+				Id: shard.BlockID.ToInt64(),
 			},
 			Index: int32(blockHeader.Index),
 		}
 
-		if _, err := o.storageRequestor.Delete(c, msg); err != nil {
-			return err
+		_, err := node.Requestor.Delete(c, msg)
+		o.cluster.Report(node.Node, err)
+		if err != nil {
+			log.FromContext(c).Warnf("Delete Error. node: %s, %s", node.Node.Host, err.Error())
+			errs = append(errs, err)
+			continue
 		}
+		deleted++
 	}
 
+	if deleted == 0 && len(errs) > 0 {
+		return errors.Join(errs...)
+	}
 	return nil
 }
+
+func (o *Deleter) deleteBlocks(c context.Context, version dto.Version) error {
+	return o.DeleteBlocks(c, version.BlockHeaders)
+}
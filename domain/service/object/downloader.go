@@ -23,93 +23,304 @@
 package object
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/ISSuh/sos/domain/model/dto"
 	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/model/message"
-	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	"github.com/ISSuh/sos/internal/compression"
 	"github.com/ISSuh/sos/internal/crc"
 	"github.com/ISSuh/sos/internal/empty"
+	"github.com/ISSuh/sos/internal/encryption"
 	"github.com/ISSuh/sos/internal/http"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/validation"
+	"github.com/klauspost/reedsolomon"
 )
 
 type Downloader struct {
-	storageRequestor rpc.BlockStorageRequestor
+	cluster *StorageCluster
+	keys    Keys
 }
 
-func NewDownloader(storageRequestor rpc.BlockStorageRequestor) Downloader {
+// NewDownloader returns a downloader for the blocks encrypted with the keys. The block storage nodes
+// decrypt with the data key, while a block sealed with the key of the client is opened once read.
+func NewDownloader(cluster *StorageCluster, keys Keys) Downloader {
 	return Downloader{
-		storageRequestor: storageRequestor,
+		cluster: cluster,
+		keys:    keys,
 	}
 }
 
 func (o *Downloader) Download(c context.Context, version dto.Version, writer http.DownloadBodyWriter) error {
-	blockHeaders := version.BlockHeaders
-	blockNum := len(version.BlockHeaders)
+	return o.downloadBlocks(c, version.BlockHeaders, writer)
+}
 
-	blockChan := make([]chan entity.Block, blockNum)
-	for i := range blockChan {
-		blockChan[i] = make(chan entity.Block)
-	}
+func (o *Downloader) DownloadRange(
+	c context.Context, version dto.Version, r http.Range, writer http.DownloadBodyWriter,
+) error {
+	var blockHeaders dto.BlockHeaders
+	var skip int
 
-	errChan := make([]chan error, blockNum)
-	for i := range errChan {
-		errChan[i] = make(chan error)
-	}
+	offset := 0
+	for _, blockHeader := range version.BlockHeaders {
+		blockStart := offset
+		offset += blockHeader.ContentSize()
 
-	stopChan := make([]chan struct{}, blockNum)
-	for i := range stopChan {
-		stopChan[i] = make(chan struct{})
+		if offset <= r.Start {
+			continue
+		}
+
+		if blockStart > r.End() {
+			break
+		}
+
+		if blockHeaders.Empty() {
+			skip = r.Start - blockStart
+		}
+		blockHeaders = append(blockHeaders, blockHeader)
 	}
 
-	for i := 0; i < blockNum; i++ {
-		go func(index int, blockHeader dto.BlockHeader, blockChan chan<- entity.Block, errChan chan<- error, stopChan <-chan struct{}) {
-			defer func() {
-				if r := recover(); r != nil {
-					return
-				}
-			}()
+	remain := r.Length
+	return o.downloadBlocks(c, blockHeaders, func(buffer []byte) error {
+		// trim the head of the first block and the tail of the last block
+		if skip > 0 {
+			buffer = buffer[min(skip, len(buffer)):]
+			skip = 0
+		}
 
-			block, err := o.downloadBlock(c, &blockHeader)
-			if err != nil {
-				return
-			}
+		if len(buffer) > remain {
+			buffer = buffer[:remain]
+		}
+		remain -= len(buffer)
 
-			header := block.Header()
-			if crc.Verify(block.Buffer(), header.Checksum()) {
-				errChan <- errors.New("Block checksum is invalid")
-				return
-			}
+		return writer(buffer)
+	})
+}
 
-			select {
-			case blockChan <- block:
-			case <-stopChan:
-			}
-		}(i, blockHeaders[i], blockChan[i], errChan[i], stopChan[i])
+type downloadResult struct {
+	block entity.Block
+	err   error
+}
+
+// downloadBlocks fetches the blocks ahead of the writer, at most the concurrency of the cluster at once,
+// and writes them in order. The first failure cancels the blocks in flight.
+func (o *Downloader) downloadBlocks(
+	c context.Context, blockHeaders dto.BlockHeaders, writer http.DownloadBodyWriter,
+) error {
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	results := make([]chan downloadResult, len(blockHeaders))
+	fetch := func(i int) {
+		results[i] = make(chan downloadResult, 1)
+		go func(blockHeader dto.BlockHeader, result chan<- downloadResult) {
+			block, err := o.downloadBlock(ctx, &blockHeader)
+			result <- downloadResult{block: block, err: err}
+		}(blockHeaders[i], results[i])
+	}
+
+	window := o.cluster.Concurrency()
+	for i := 0; i < window && i < len(blockHeaders); i++ {
+		fetch(i)
 	}
 
-	for i := 0; i < blockNum; i++ {
-		select {
-		case err := <-errChan[i]:
+	for i := range blockHeaders {
+		result := <-results[i]
+		if result.err != nil {
+			return result.err
+		}
+
+		if next := i + window; next < len(blockHeaders) {
+			fetch(next)
+		}
+
+		if err := writer(result.block.Buffer()); err != nil {
 			return err
-		case block := <-blockChan[i]:
-			err := writer(block.Buffer())
-			if err != nil {
-				return err
-			}
 		}
 	}
 	return nil
 }
 
 func (o *Downloader) downloadBlock(c context.Context, blockHeader *dto.BlockHeader) (entity.Block, error) {
+	block, err := o.fetchBlock(c, blockHeader)
+	if err != nil {
+		return empty.Struct[entity.Block](), err
+	}
+
+	if blockHeader.CustomerKeyed {
+		if block, err = o.openBlock(blockHeader, block); err != nil {
+			return empty.Struct[entity.Block](), err
+		}
+	}
+
+	if validation.IsEmpty(blockHeader.Compression) {
+		return block, nil
+	}
+	return o.decompressBlock(blockHeader, block)
+}
+
+// decompressBlock restores the content of a block compressed by the explorer.
+func (o *Downloader) decompressBlock(blockHeader *dto.BlockHeader, block entity.Block) (entity.Block, error) {
+	codec := compression.Codec(blockHeader.Compression)
+	content, err := compression.Decompress(codec, block.Buffer(), blockHeader.UncompressedSize)
+	if err != nil {
+		return empty.Struct[entity.Block](), fmt.Errorf("can not decompress block %d. %w", blockHeader.BlockID, err)
+	}
+
+	decompressed := entity.NewBlockBuilder().
+		Header(block.Header()).
+		Buffer(content).
+		Build()
+	return decompressed, nil
+}
+
+// openBlock decrypts the block sealed by the explorer with the key of the client.
+func (o *Downloader) openBlock(blockHeader *dto.BlockHeader, block entity.Block) (entity.Block, error) {
+	if o.keys.CustomerKey == nil {
+		return empty.Struct[entity.Block](), errors.New("block is encrypted with a customer key")
+	}
+
+	plain, err := encryption.Open(o.keys.CustomerKey, block.Buffer())
+	if err != nil {
+		return empty.Struct[entity.Block](), fmt.Errorf("can not decrypt block %d. %w", blockHeader.BlockID, err)
+	}
+
+	opened := entity.NewBlockBuilder().
+		Header(block.Header()).
+		Buffer(plain).
+		Build()
+	return opened, nil
+}
+
+func (o *Downloader) fetchBlock(c context.Context, blockHeader *dto.BlockHeader) (entity.Block, error) {
+	if !blockHeader.Shards.Empty() {
+		return o.downloadShards(c, blockHeader)
+	}
+
 	msg := message.FromBlockHeaderDTO(blockHeader)
-	resp, err := o.storageRequestor.GetBlock(c, msg)
+	msg.DataKey = o.keys.DataKey
+
+	// any replica will do, a failed or corrupted one falls through to the next
+	var errs []error
+	for _, node := range o.cluster.Replicas(*blockHeader) {
+		resp, err := node.Requestor.GetBlockStream(c, msg)
+		o.cluster.Report(node.Node, err)
+		if err != nil {
+			log.FromContext(c).Warnf("Download Error. node: %s, %s", node.Node.Host, err.Error())
+			errs = append(errs, err)
+			continue
+		}
+
+		block := message.ToBlock(resp)
+		if !crc.Verify(block.Buffer(), blockHeader.Checksum) {
+			log.FromContext(c).Warnf("Download Error. node: %s, block checksum is invalid", node.Node.Host)
+			errs = append(errs, errors.New("Block checksum is invalid"))
+			continue
+		}
+
+		return block, nil
+	}
+
+	if len(errs) == 0 {
+		return empty.Struct[entity.Block](), errors.New("no block storage node holds the block")
+	}
+	return empty.Struct[entity.Block](), errors.Join(errs...)
+}
+
+func (o *Downloader) downloadShards(c context.Context, blockHeader *dto.BlockHeader) (entity.Block, error) {
+	scheme := blockHeader.ErasureCoding
+	encoder, err := reedsolomon.New(scheme.DataShards, scheme.ParityShards)
 	if err != nil {
 		return empty.Struct[entity.Block](), err
 	}
 
-	return message.ToBlock(resp), nil
+	buffers := make([][]byte, len(blockHeader.Shards))
+
+	// the data shards are enough on the happy path, parity shards are only fetched to cover missing ones
+	var wg sync.WaitGroup
+	for i := 0; i < scheme.DataShards && i < len(buffers); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			buffers[i] = o.downloadShard(c, blockHeader, blockHeader.Shards[i])
+		}(i)
+	}
+	wg.Wait()
+
+	available := 0
+	for _, buffer := range buffers {
+		if buffer != nil {
+			available++
+		}
+	}
+
+	for i := scheme.DataShards; i < len(buffers) && available < scheme.DataShards; i++ {
+		buffers[i] = o.downloadShard(c, blockHeader, blockHeader.Shards[i])
+		if buffers[i] != nil {
+			available++
+		}
+	}
+
+	if available < scheme.DataShards {
+		return empty.Struct[entity.Block](),
+			fmt.Errorf("not enough shards to reconstruct the block. available %d of %d", available, scheme.DataShards)
+	}
+
+	if err := encoder.ReconstructData(buffers); err != nil {
+		return empty.Struct[entity.Block](), err
+	}
+
+	var buffer bytes.Buffer
+	if err := encoder.Join(&buffer, buffers, blockHeader.Size); err != nil {
+		return empty.Struct[entity.Block](), err
+	}
+
+	if !crc.Verify(buffer.Bytes(), blockHeader.Checksum) {
+		return empty.Struct[entity.Block](), errors.New("Block checksum is invalid")
+	}
+
+	block := entity.NewBlockBuilder().
+		Header(blockHeader.ToEntity()).
+		Buffer(buffer.Bytes()).
+		Build()
+	return block, nil
+}
+
+// downloadShard returns nil when the shard can not be used, the caller treats it as missing
+func (o *Downloader) downloadShard(c context.Context, blockHeader *dto.BlockHeader, shard entity.Shard) []byte {
+	node, exist := o.cluster.Node(shard.Node)
+	if !exist {
+		log.FromContext(c).Warnf("Download Error. unknown shard node: %s", shard.Node.Host)
+		return nil
+	}
+
+	msg := &message.BlockHeader{
+		ObjectID: &message.ObjectID{
+			Id: blockHeader.ObjectID.ToInt64(),
+		},
+		BlockID: &message.BlockID{
+			Id: shard.BlockID.ToInt64(),
+		},
+		Index:   int32(blockHeader.Index),
+		DataKey: o.keys.DataKey,
+	}
+
+	resp, err := node.Requestor.GetBlockStream(c, msg)
+	o.cluster.Report(node.Node, err)
+	if err != nil {
+		log.FromContext(c).Warnf("Download Error. node: %s, %s", node.Node.Host, err.Error())
+		return nil
+	}
+
+	block := message.ToBlock(resp)
+	if !crc.Verify(block.Buffer(), shard.Checksum) {
+		log.FromContext(c).Warnf("Download Error. node: %s, shard checksum is invalid", node.Node.Host)
+		return nil
+	}
+	return block.Buffer()
 }
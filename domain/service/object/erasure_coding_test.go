@@ -0,0 +1,194 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package object_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/model/message"
+	"github.com/ISSuh/sos/domain/service"
+	"github.com/ISSuh/sos/domain/service/object"
+	memorystorage "github.com/ISSuh/sos/infrastructure/persistence/objectstorage/memory"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	"github.com/ISSuh/sos/internal/app/standalone"
+	"github.com/ISSuh/sos/internal/generator"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testBlockSize = 1024
+
+var testScheme = entity.ErasureCoding{DataShards: 4, ParityShards: 2}
+
+// flakyNode is a block storage node that can be taken down or made to return damaged data.
+type flakyNode struct {
+	rpc.BlockStorageRequestor
+	down    bool
+	corrupt bool
+}
+
+func (n *flakyNode) GetBlockStream(c context.Context, header *message.BlockHeader) (*message.Block, error) {
+	if n.down {
+		return nil, status.Error(codes.Unavailable, "node is down")
+	}
+
+	block, err := n.BlockStorageRequestor.GetBlockStream(c, header)
+	if err == nil && n.corrupt {
+		block.Data = bytes.Clone(block.Data)
+		block.Data[0] ^= 0xff
+	}
+	return block, err
+}
+
+// newErasureCodedCluster has as many nodes as shards, so every node holds one shard of each block
+// and losing a node loses one shard of each block.
+func newErasureCodedCluster(t *testing.T) (*object.StorageCluster, []*flakyNode) {
+	return newMemoryCluster(t, testScheme.TotalShards(), false)
+}
+
+// newMemoryCluster keeps every node in memory and splits the objects into blocks of testBlockSize.
+func newMemoryCluster(t *testing.T, size int, deduplication bool) (*object.StorageCluster, []*flakyNode) {
+	t.Helper()
+	generator.InitIdentifier(1)
+
+	var nodes []object.StorageNode
+	var flaky []*flakyNode
+	for i := 0; i < size; i++ {
+		repository, err := memorystorage.NewLocalObjectStorage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		storage, err := service.NewObjectStorage(repository)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requestor, err := standalone.NewBlockStorage(storage)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		node := &flakyNode{BlockStorageRequestor: requestor}
+		flaky = append(flaky, node)
+		nodes = append(nodes, object.StorageNode{Node: entity.Node{Host: fmt.Sprintf("node-%d", i)}, Requestor: node})
+	}
+
+	cluster, err := object.NewStorageCluster(nodes, 1, nil, deduplication, nil, testBlockSize, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cluster, flaky
+}
+
+func uploadErasureCoded(t *testing.T, cluster *object.StorageCluster, body []byte) dto.Version {
+	t.Helper()
+
+	uploader := object.NewUploader(cluster, testScheme, "", object.Keys{})
+	headers, err := uploader.Upload(context.Background(), entity.NewObjectID(), io.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, header := range headers {
+		if len(header.Shards) != testScheme.TotalShards() {
+			t.Fatalf("block %d has %d shards, want %d", header.Index, len(header.Shards), testScheme.TotalShards())
+		}
+	}
+	return dto.Version{BlockHeaders: headers}
+}
+
+func download(cluster *object.StorageCluster, version dto.Version) ([]byte, error) {
+	var body bytes.Buffer
+	downloader := object.NewDownloader(cluster, object.Keys{})
+	err := downloader.Download(context.Background(), version, func(buffer []byte) error {
+		_, err := body.Write(buffer)
+		return err
+	})
+	return body.Bytes(), err
+}
+
+func TestErasureCodingSurvivesLostShards(t *testing.T) {
+	body := make([]byte, 5*1024+123)
+	rand.New(rand.NewSource(1)).Read(body)
+
+	// the lost nodes hold data shards of some blocks and parity shards of others
+	for _, lost := range [][]int{{}, {0}, {5}, {1, 4}, {0, 2}, {3, 5}} {
+		t.Run(fmt.Sprint(lost), func(t *testing.T) {
+			cluster, nodes := newErasureCodedCluster(t)
+			version := uploadErasureCoded(t, cluster, body)
+
+			for _, i := range lost {
+				nodes[i].down = true
+			}
+
+			got, err := download(cluster, version)
+			if err != nil {
+				t.Fatalf("download with %d of %d shards lost: %v", len(lost), testScheme.ParityShards, err)
+			}
+			if !bytes.Equal(got, body) {
+				t.Fatalf("downloaded %d bytes differ from the %d uploaded", len(got), len(body))
+			}
+		})
+	}
+}
+
+// A shard failing its checksum counts as lost, so damage and loss together are survived up to the
+// parity count as well.
+func TestErasureCodingSurvivesCorruptShards(t *testing.T) {
+	body := bytes.Repeat([]byte("erasure coded "), 300)
+	cluster, nodes := newErasureCodedCluster(t)
+	version := uploadErasureCoded(t, cluster, body)
+
+	nodes[2].corrupt = true
+	nodes[4].down = true
+
+	got, err := download(cluster, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Fatal("downloaded body differs from the uploaded one")
+	}
+}
+
+func TestErasureCodingFailsWithTooManyLostShards(t *testing.T) {
+	body := bytes.Repeat([]byte("lost "), 1000)
+	cluster, nodes := newErasureCodedCluster(t)
+	version := uploadErasureCoded(t, cluster, body)
+
+	for _, i := range []int{0, 3, 5} {
+		nodes[i].down = true
+	}
+
+	_, err := download(cluster, version)
+	if err == nil || !strings.Contains(err.Error(), "not enough shards") {
+		t.Fatalf("download with %d shards lost: %v, want not enough shards", testScheme.ParityShards+1, err)
+	}
+}
@@ -23,90 +23,204 @@
 package object
 
 import (
-	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"math"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/ISSuh/sos/domain/model/dto"
 	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/model/message"
-	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	"github.com/ISSuh/sos/internal/checksum"
+	"github.com/ISSuh/sos/internal/compression"
 	"github.com/ISSuh/sos/internal/crc"
+	"github.com/ISSuh/sos/internal/encryption"
+	soserror "github.com/ISSuh/sos/internal/error"
 	"github.com/ISSuh/sos/internal/log"
+	"github.com/klauspost/reedsolomon"
 )
 
-const (
-	kilobyte          = 1024
-	defaultBufferSize = 4 * kilobyte
-)
+// Recorder records a written block on the version it is written for, and fails with not found once
+// the version is no longer reserved.
+type Recorder func(c context.Context, blockHeader dto.BlockHeader) error
 
 type Uploader struct {
-	storageRequestor rpc.BlockStorageRequestor
+	cluster  *StorageCluster
+	scheme   entity.ErasureCoding
+	codec    compression.Codec
+	keys     Keys
+	recorder Recorder
 }
 
-func NewUploader(storageRequestor rpc.BlockStorageRequestor) Uploader {
+// NewUploader returns an uploader whose blocks are encrypted with the keys. The block storage nodes
+// encrypt with the data key, while the key of the client seals the blocks before they are sent.
+func NewUploader(cluster *StorageCluster, scheme entity.ErasureCoding, codec compression.Codec, keys Keys) Uploader {
 	return Uploader{
-		storageRequestor: storageRequestor,
+		cluster: cluster,
+		scheme:  scheme,
+		codec:   codec,
+		keys:    keys,
 	}
 }
 
+// RecordTo records every written block with the recorder. A recorded block is owned by the version
+// it is recorded on, so a failed upload leaves it to be deleted with the version.
+func (o *Uploader) RecordTo(recorder Recorder) {
+	o.recorder = recorder
+}
+
+// Upload reads the body into block sized buffers while the blocks read so far are sent concurrently,
+// at most the concurrency of the cluster at once. The first failure cancels the blocks in flight, and
+// the blocks written by then are deleted once they return.
 func (o *Uploader) Upload(c context.Context, objectID entity.ObjectID, bodyStream io.ReadCloser) (dto.BlockHeaders, error) {
-	var blockheaders dto.BlockHeaders
-	var totalReadSize int
-	var blockIndex int
-
-	var blockBuffer bytes.Buffer
-	for {
-		buf := make([]byte, defaultBufferSize)
-		n, err := bodyStream.Read(buf)
-		if err != nil && err != io.EOF {
-			return nil, err
-		}
+	return o.UploadFrom(c, objectID, 0, math.MaxInt32, bodyStream)
+}
 
-		if n == 0 {
-			blockBuffer.Truncate(totalReadSize)
+// UploadFrom uploads the body as Upload does, with the blocks numbered from the first index. A body
+// that needs more than the blocks is rejected, e.g. a part of a multipart upload outside its range.
+func (o *Uploader) UploadFrom(
+	c context.Context, objectID entity.ObjectID, first, blocks int, bodyStream io.ReadCloser,
+) (dto.BlockHeaders, error) {
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
 
-			block := o.buildBlock(objectID, blockIndex, blockBuffer.Bytes())
-			if err := o.uploadBlock(c, &block); err != nil {
-				return nil, err
-			}
+	var wg sync.WaitGroup
+	var mutex, recordMutex sync.Mutex
+	var blockheaders, owned dto.BlockHeaders
+	var uploadErr error
+	fail := func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if uploadErr == nil {
+			uploadErr = err
+		}
+		cancel()
+	}
+	failed := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return uploadErr != nil
+	}
 
-			blockheaders = append(blockheaders, block.Header)
+	// one more buffer than the workers is read ahead, so the body is read while every worker is busy
+	workers := make(chan struct{}, o.cluster.Concurrency())
+	for index := first; !failed(); index++ {
+		buffer := o.cluster.acquireBuffer()
+		n, err := io.ReadFull(bodyStream, *buffer)
+		if err == io.EOF {
+			o.cluster.releaseBuffer(buffer)
 			break
 		}
 
-		// resize buffer
-		buf = buf[:n]
+		last := err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			o.cluster.releaseBuffer(buffer)
+			fail(err)
+			break
+		}
 
-		totalReadSize += n
-		writeSize, err := blockBuffer.Write(buf)
-		if err != nil {
-			return nil, err
+		if index-first >= blocks {
+			o.cluster.releaseBuffer(buffer)
+			fail(fmt.Errorf("body is larger than %d blocks", blocks))
+			break
 		}
 
-		if writeSize != n {
-			return nil, errors.New("write size is not equal to read size")
+		select {
+		case workers <- struct{}{}:
+		case <-ctx.Done():
+			o.cluster.releaseBuffer(buffer)
+			fail(ctx.Err())
+			continue
 		}
 
-		if totalReadSize >= entity.BlockSize {
-			block := o.buildBlock(objectID, blockIndex, blockBuffer.Bytes())
-			if err := o.uploadBlock(c, &block); err != nil {
-				return nil, err
+		wg.Add(1)
+		go func(index int, buffer *[]byte, size int) {
+			defer func() {
+				o.cluster.releaseBuffer(buffer)
+				<-workers
+				wg.Done()
+			}()
+
+			block := o.buildBlock(objectID, index, (*buffer)[:size])
+			if err := o.uploadBlock(ctx, &block); err != nil {
+				fail(err)
+				return
+			}
+
+			// the records of a version are written one at a time, as they only conflict otherwise
+			recordMutex.Lock()
+			own, err := recordBlock(c, o.recorder, block.Header)
+			recordMutex.Unlock()
+
+			mutex.Lock()
+			if own {
+				owned = append(owned, block.Header)
 			}
+			if err == nil {
+				blockheaders = append(blockheaders, block.Header)
+			}
+			mutex.Unlock()
 
-			blockheaders = append(blockheaders, block.Header)
+			if err != nil {
+				fail(err)
+			}
+		}(index, buffer, n)
 
-			blockIndex++
-			totalReadSize = 0
+		if last {
+			break
 		}
 	}
+	wg.Wait()
+
+	if uploadErr != nil {
+		return nil, o.abort(c, owned, uploadErr)
+	}
+
+	sort.Slice(blockheaders, func(i, j int) bool {
+		return blockheaders[i].Index < blockheaders[j].Index
+	})
 	return blockheaders, nil
 }
 
+// abort deletes the blocks uploaded before the failure that are not recorded on a version, as nothing
+// will ever reference them. They are deleted even when the upload failed because the request was canceled.
+func (o *Uploader) abort(c context.Context, blockHeaders dto.BlockHeaders, err error) error {
+	deleter := NewDeleter(nil, o.cluster)
+	if deleteErr := deleter.DeleteBlocks(context.WithoutCancel(c), blockHeaders); deleteErr != nil {
+		log.FromContext(c).Errorf("Upload Error. can not delete uploaded blocks: %s", deleteErr.Error())
+	}
+	return err
+}
+
+// recordBlock records the written block and reports whether the caller still owns it, i.e. has to
+// delete it when the upload fails. Every block is owned without a recorder.
+func recordBlock(c context.Context, recorder Recorder, blockHeader dto.BlockHeader) (bool, error) {
+	if recorder == nil {
+		return true, nil
+	}
+
+	// the block is written already, so it is recorded even when the upload is canceled meanwhile
+	err := recorder(context.WithoutCancel(c), blockHeader)
+	switch {
+	case err == nil:
+		return false, nil
+	case errors.Is(err, soserror.NotFound):
+		return true, err
+	default:
+		// the block may be recorded anyway, and deleting it here could release it twice once the
+		// version is deleted as well, so it is left to the garbage collection
+		log.FromContext(c).Errorf("Upload Error. can not record block %d: %s", blockHeader.BlockID, err.Error())
+		return false, err
+	}
+}
+
+// buildBlock returns a block over the buffer, which must not be reused until the block is uploaded.
 func (o *Uploader) buildBlock(objectID entity.ObjectID, index int, buffer []byte) dto.Block {
-	block := dto.Block{
+	return dto.Block{
 		Header: dto.BlockHeader{
 			ObjectID:  objectID,
 			BlockID:   entity.NewBlockID(),
@@ -115,24 +229,232 @@ func (o *Uploader) buildBlock(objectID entity.ObjectID, index int, buffer []byte
 			Timestamp: time.Now(),
 			Checksum:  crc.Checksum(buffer),
 		},
-		Data: make([]byte, len(buffer)),
+		Data: buffer,
 	}
+}
 
-	copy(block.Data, buffer)
-	return block
+// deduplication is off for erasure coded objects and for the ones encrypted with a key of their own, which
+// the deduplication report lists. The configuration rejects deduplication along with encryption at rest.
+func (o *Uploader) deduplication() bool {
+	return o.cluster.Deduplication() && !o.scheme.Enabled() && o.keys.Empty()
 }
 
 func (o *Uploader) uploadBlock(c context.Context, block *dto.Block) error {
+	if err := o.compressBlock(block); err != nil {
+		return err
+	}
+
+	// a deduplicated block is identified by its stored content, so identical blocks land on the same nodes
+	if o.deduplication() {
+		block.Header.Hash = checksum.Calculate(block.Data)
+		block.Header.BlockID = entity.NewBlockIDFromHash(block.Header.Hash)
+	}
+
+	if err := o.sealBlock(block); err != nil {
+		return err
+	}
+
+	if o.scheme.Enabled() {
+		return o.uploadShards(c, block)
+	}
+	return o.uploadReplicas(c, block)
+}
+
+// compressBlock compresses the block with the codec, and keeps it raw when it does not shrink.
+func (o *Uploader) compressBlock(block *dto.Block) error {
+	if o.codec == compression.None {
+		return nil
+	}
+
+	compressed, err := compression.Compress(o.codec, block.Data)
+	switch {
+	case err != nil:
+		return err
+	case len(compressed) >= len(block.Data):
+		return nil
+	}
+
+	block.Header.Compression = string(o.codec)
+	block.Header.UncompressedSize = len(block.Data)
+	block.Data = compressed
+	block.Header.Size = len(compressed)
+	block.Header.Checksum = crc.Checksum(compressed)
+	return nil
+}
+
+// sealBlock encrypts the block with the key of the client before it leaves the explorer, and the
+// header describes the sealed block from then on.
+func (o *Uploader) sealBlock(block *dto.Block) error {
+	if o.keys.CustomerKey == nil {
+		return nil
+	}
+
+	sealed, err := encryption.Seal(o.keys.CustomerKey, block.Data)
+	if err != nil {
+		return err
+	}
+
+	block.Data = sealed
+	block.Header.Size = len(sealed)
+	block.Header.Checksum = crc.Checksum(sealed)
+	block.Header.CustomerKeyed = true
+	return nil
+}
+
+func (o *Uploader) uploadReplicas(c context.Context, block *dto.Block) error {
 	msg := message.FromBlockDTO(block)
-	resp, err := o.storageRequestor.Put(c, msg)
+	msg.Header.DataKey = o.keys.DataKey
+
+	var replicas, canceled entity.Nodes
+	for _, node := range o.cluster.Placement(block.Header.BlockID) {
+		if len(replicas) == o.cluster.Replication() || c.Err() != nil {
+			break
+		}
+
+		if err := o.storeBlock(c, node, msg); err != nil {
+			log.FromContext(c).Errorf("Upload Error. node: %s, %s", node.Node.Host, err.Error())
+			// the node may have written the block just before the cancel arrived. A reference on a
+			// deduplicated block can not be told apart from the references of other objects, so it
+			// is left to be reconciled.
+			if c.Err() != nil && msg.Header.Hash == "" {
+				canceled = append(canceled, node.Node)
+			}
+			continue
+		}
+		replicas = append(replicas, node.Node)
+	}
+
+	block.Header.Replicas = replicas
+	if len(replicas) < o.cluster.Replication() {
+		// a partially replicated block is never referenced, so the written copies are dropped
+		// even when the upload was canceled by the failure of another block
+		written := block.Header
+		written.Replicas = append(replicas, canceled...)
+		deleter := NewDeleter(nil, o.cluster)
+		if err := deleter.DeleteBlocks(context.WithoutCancel(c), dto.BlockHeaders{written}); err != nil {
+			log.FromContext(c).Errorf("Upload Error. can not delete partial replicas: %s", err.Error())
+		}
+		return fmt.Errorf("upload fail. replicated %d of %d", len(replicas), o.cluster.Replication())
+	}
+	return nil
+}
+
+func (o *Uploader) uploadShards(c context.Context, block *dto.Block) error {
+	encoder, err := reedsolomon.New(o.scheme.DataShards, o.scheme.ParityShards)
+	if err != nil {
+		return err
+	}
+
+	buffers, err := encoder.Split(block.Data)
+	if err != nil {
+		return err
+	}
+
+	if err := encoder.Encode(buffers); err != nil {
+		return err
+	}
+
+	// every shard goes to a distinct node so losing one node costs at most one shard
+	nodes := o.cluster.Placement(block.Header.BlockID)
+	shards := make(entity.Shards, 0, len(buffers))
+	var canceled entity.Shards
+	for _, buffer := range buffers {
+		stored := false
+		for len(nodes) > 0 && !stored && c.Err() == nil {
+			node := nodes[0]
+			nodes = nodes[1:]
+
+			shard, err := o.putShard(c, node, block.Header, buffer)
+			if err != nil {
+				log.FromContext(c).Errorf("Upload Error. node: %s, %s", node.Node.Host, err.Error())
+				// the node may have written the shard just before the cancel arrived
+				if c.Err() != nil {
+					canceled = append(canceled, shard)
+				}
+				continue
+			}
+
+			shards = append(shards, shard)
+			stored = true
+		}
+	}
+
+	block.Header.ErasureCoding = o.scheme
+	block.Header.Shards = shards
+	if len(shards) < o.scheme.TotalShards() {
+		written := block.Header
+		written.Shards = append(shards, canceled...)
+		deleter := NewDeleter(nil, o.cluster)
+		if err := deleter.DeleteBlocks(context.WithoutCancel(c), dto.BlockHeaders{written}); err != nil {
+			log.FromContext(c).Errorf("Upload Error. can not delete partial shards: %s", err.Error())
+		}
+		return fmt.Errorf("upload fail. stored %d of %d shards", len(shards), o.scheme.TotalShards())
+	}
+	return nil
+}
+
+func (o *Uploader) putShard(
+	c context.Context, node StorageNode, blockHeader dto.BlockHeader, buffer []byte,
+) (entity.Shard, error) {
+	shard := dto.Block{
+		Header: dto.BlockHeader{
+			ObjectID:  blockHeader.ObjectID,
+			BlockID:   entity.NewBlockID(),
+			Index:     blockHeader.Index,
+			Size:      len(buffer),
+			Timestamp: time.Now(),
+			Checksum:  crc.Checksum(buffer),
+		},
+		Data: buffer,
+	}
+
+	msg := message.FromBlockDTO(&shard)
+	msg.Header.DataKey = o.keys.DataKey
+	// the shard is returned with the error as well, so a shard canceled in flight can be deleted
+	stored := entity.Shard{
+		BlockID:  shard.Header.BlockID,
+		Node:     node.Node,
+		Size:     shard.Header.Size,
+		Checksum: shard.Header.Checksum,
+	}
+	return stored, o.putBlock(c, node, msg)
+}
+
+// storeBlock only sends the data of a deduplicated block when the node does not hold the
+// content yet, otherwise the node just takes another reference on it.
+func (o *Uploader) storeBlock(c context.Context, node StorageNode, msg *message.Block) error {
+	if msg.Header.Hash != "" {
+		exist, err := o.referenceBlock(c, node, msg.Header)
+		if err != nil {
+			return err
+		}
+
+		if exist {
+			return nil
+		}
+	}
+	return o.putBlock(c, node, msg)
+}
+
+func (o *Uploader) referenceBlock(c context.Context, node StorageNode, header *message.BlockHeader) (bool, error) {
+	resp, err := node.Requestor.Reference(c, header)
+	o.cluster.Report(node.Node, err)
+	if err != nil {
+		return false, err
+	}
+	return resp.Exist, nil
+}
+
+func (o *Uploader) putBlock(c context.Context, node StorageNode, msg *message.Block) error {
+	resp, err := node.Requestor.PutStream(c, msg)
+	o.cluster.Report(node.Node, err)
 	if err != nil {
-		log.FromContext(c).Errorf("Upload Error: %s", err.Error())
 		return err
 	}
 
 	if !resp.Success {
 		log.FromContext(c).Errorf("Upload fail. message : %s", resp.Message)
-		return err
+		return fmt.Errorf("upload fail. %s", resp.Message)
 	}
 	return nil
 }
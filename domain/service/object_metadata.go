@@ -26,22 +26,48 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/ISSuh/sos/domain/model/dto"
 	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/repository"
 	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/http"
 	"github.com/ISSuh/sos/internal/log"
 	"github.com/ISSuh/sos/internal/validation"
 )
 
+const (
+	maxMetadataWriteAttempts = 16
+	putReservationExpiry     = time.Minute
+)
+
 type ObjectMetadata interface {
 	Put(c context.Context, objectDTO *dto.Object) (*dto.Metadata, error)
-	Delete(c context.Context, metadataDTO *dto.Metadata) error
+	ReserveVersion(c context.Context, objectDTO *dto.Object) (*dto.Object, error)
+	CommitVersion(c context.Context, objectDTO *dto.Object) (*dto.Metadata, error)
+	RecordBlocks(c context.Context, objectDTO *dto.Object) error
+	AbortVersion(c context.Context, objectDTO *dto.Object) (dto.BlockHeaders, error)
+	ReleaseExpiredVersions(c context.Context, now time.Time) (dto.BlockHeaders, error)
+	Delete(c context.Context, metadataDTO *dto.Metadata) (dto.BlockHeaders, error)
+	PutTags(c context.Context, metadataDTO *dto.Metadata) (*dto.Metadata, error)
+	Move(c context.Context, metadataDTO *dto.Metadata, destination dto.Destination) (*dto.Metadata, error)
 	MetadataByObjectName(c context.Context, group, partition, path, objectName string) (*dto.Metadata, error)
 	MetadataByObjectID(c context.Context, group, partition, path string, objectID int64) (*dto.Metadata, error)
-	MetadataListOnPath(c context.Context, group, partition, path string) (dto.MetadataList, error)
+	MetadataListOnPath(
+		c context.Context, group, partition, path string, lastObjectID entity.ObjectID, limit int,
+	) (dto.MetadataList, entity.ObjectID, error)
+	MetadataListByTags(
+		c context.Context, group, partition string, expression entity.TagExpression, lastObjectID entity.ObjectID, limit int,
+	) (dto.MetadataList, entity.ObjectID, error)
+	MetadataListByStaleDataKey(
+		c context.Context, keyID string, lastObjectID entity.ObjectID, limit int,
+	) (dto.MetadataList, entity.ObjectID, error)
+	RewrapDataKeys(c context.Context, metadataDTO *dto.Metadata) (*dto.Metadata, error)
+	Directory(c context.Context, group, partition, path string) (*dto.Directory, error)
+	BlockHeadersOfObjects(c context.Context, objectIDs []int64) (dto.BlockHeaders, error)
+	BlockHeadersOfContents(c context.Context, hashes []string) (dto.BlockHeaders, error)
 }
 
 type objectMetadata struct {
@@ -50,82 +76,315 @@ type objectMetadata struct {
 	tempID              uint64
 }
 
-func NewObjectMetadata(metadataRepository repository.ObjectMetadata) (ObjectMetadata, error) {
+func NewObjectMetadata(
+	metadataRepository repository.ObjectMetadata, directoryRepository repository.ObjectDirectory,
+) (ObjectMetadata, error) {
 	switch {
 	case validation.IsNil(metadataRepository):
 		return nil, fmt.Errorf("MetadataRepository is nil")
+	case validation.IsNil(directoryRepository):
+		return nil, fmt.Errorf("DirectoryRepository is nil")
 	}
 
 	return &objectMetadata{
-		metadataRepository: metadataRepository,
-		tempID:             0,
+		metadataRepository:  metadataRepository,
+		directoryRepository: directoryRepository,
+		tempID:              0,
 	}, nil
 }
 
+// Put reserves a version of the object and commits it at once.
 func (s *objectMetadata) Put(c context.Context, objectDTO *dto.Object) (*dto.Metadata, error) {
 	log.FromContext(c).Debugf("[objectMetadata.Put] request: %+v", objectDTO)
-	metadata, err :=
-		s.metadataRepository.MetadataByObjectID(
-			c, objectDTO.Group, objectDTO.Partition, objectDTO.Path, objectDTO.ID.ToInt64(),
-		)
-	if err != nil && !errors.Is(err, soserror.NotFound) {
+	request := *objectDTO
+	request.ExpiresAt = time.Now().Add(putReservationExpiry)
+
+	reserved, err := s.ReserveVersion(c, &request)
+	if err != nil {
 		return nil, err
 	}
 
-	now := time.Now()
-	if metadata == nil {
-		metadata, err = s.createMetadata(c, objectDTO, now)
-		if err != nil {
-			return nil, err
+	reserved.Size = objectDTO.Size
+	reserved.BlockHeaders = objectDTO.BlockHeaders
+	reserved.ETag = objectDTO.ETag
+	reserved.IfMatch = objectDTO.IfMatch
+	reserved.IfNoneMatch = objectDTO.IfNoneMatch
+	reserved.ContentMetadata = objectDTO.ContentMetadata
+	reserved.Encryption = objectDTO.Encryption
+	return s.CommitVersion(c, reserved)
+}
+
+// ReserveVersion reserves the next version number of the object named on the path, and creates the
+// object with the proposed id when it does not exist yet. The reserved version stays pending and
+// invisible until it is committed, aborted or released once it expires.
+func (s *objectMetadata) ReserveVersion(c context.Context, objectDTO *dto.Object) (*dto.Object, error) {
+	log.FromContext(c).Debugf("[objectMetadata.ReserveVersion] request: %+v", objectDTO)
+	for attempt := 0; attempt < maxMetadataWriteAttempts; attempt++ {
+		reserved, err := s.reserveVersion(c, objectDTO, time.Now())
+		if errors.Is(err, soserror.Conflict) {
+			continue
 		}
-	} else {
-		metadata.ModifiedAt = now
+		return reserved, err
+	}
+	return nil, soserror.NewConflictError(fmt.Errorf("can not reserve a version of %s. too many concurrent writes", objectDTO.Name))
+}
 
-		metadata, err = s.updateMetadata(c, metadata, objectDTO, now)
-		if err != nil {
+// CommitVersion makes the reserved version visible with its blocks. It fails with not found when
+// the reservation was aborted, released or deleted with the object.
+func (s *objectMetadata) CommitVersion(c context.Context, objectDTO *dto.Object) (*dto.Metadata, error) {
+	log.FromContext(c).Debugf("[objectMetadata.CommitVersion] request: %+v", objectDTO)
+	now := time.Now()
+	var linked bool
+	metadata, err := s.modify(c, objectDTO.Group, objectDTO.Partition, objectDTO.Path, objectDTO.ID.ToInt64(),
+		func(metadata *entity.ObjectMetadata) error {
+			// the preconditions are evaluated with the write, so no other commit can slip in between
+			current, exist := metadata.LastCommittedVersion()
+			err := http.CheckWritePreconditions(objectDTO.IfMatch, objectDTO.IfNoneMatch, current.ETag(), exist)
+			if err != nil {
+				return err
+			}
+
+			linked = exist
+			version := s.newVersion(
+				objectDTO.VersionNum, objectDTO.Size, objectDTO.BlockHeaders.ToEntity(), objectDTO.ETag,
+				objectDTO.ContentMetadata, objectDTO.Encryption, now,
+			)
+			if err := metadata.CommitVersion(version); err != nil {
+				return soserror.NewNotFoundError(err)
+			}
+
+			metadata.ModifiedAt = now
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// the object appears in its directory with its first visible version
+	if !linked {
+		if err := s.linkDirectory(c, metadata, now); err != nil {
 			return nil, err
 		}
 	}
 
-	resp := dto.NewMetadataFromModel(metadata)
-	return resp, nil
+	return dto.NewMetadataFromModel(metadata), nil
 }
 
-func (s *objectMetadata) Delete(c context.Context, metadataDTO *dto.Metadata) error {
-	log.FromContext(c).Debugf("[objectMetadata.Delete] request: %+v", metadataDTO)
-	if metadataDTO.Versions.Empty() {
-		deletedMetadata := metadataDTO.ToEntity()
-		if err := s.metadataRepository.Delete(c, &deletedMetadata); err != nil {
-			return err
-		}
-		return nil
+// RecordBlocks records blocks written for the reserved version. It fails with not found once the
+// reservation is gone, as nothing would delete the blocks with it anymore.
+func (s *objectMetadata) RecordBlocks(c context.Context, objectDTO *dto.Object) error {
+	log.FromContext(c).Debugf("[objectMetadata.RecordBlocks] request: %+v", objectDTO)
+	_, err := s.modify(c, objectDTO.Group, objectDTO.Partition, objectDTO.Path, objectDTO.ID.ToInt64(),
+		func(metadata *entity.ObjectMetadata) error {
+			if err := metadata.RecordBlocks(objectDTO.VersionNum, objectDTO.BlockHeaders.ToEntity()); err != nil {
+				return soserror.NewNotFoundError(err)
+			}
+			return nil
+		},
+	)
+	return err
+}
+
+// AbortVersion removes the reservation and returns the blocks recorded on it, which the caller
+// owns from then on.
+func (s *objectMetadata) AbortVersion(c context.Context, objectDTO *dto.Object) (dto.BlockHeaders, error) {
+	log.FromContext(c).Debugf("[objectMetadata.AbortVersion] request: %+v", objectDTO)
+	var recorded entity.BlockHeaders
+	_, err := s.modify(c, objectDTO.Group, objectDTO.Partition, objectDTO.Path, objectDTO.ID.ToInt64(),
+		func(metadata *entity.ObjectMetadata) error {
+			var err error
+			recorded, err = metadata.AbortVersion(objectDTO.VersionNum)
+			if err != nil {
+				return soserror.NewNotFoundError(err)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
 	}
+	return dto.NewBlockHeadersFromModel(recorded), nil
+}
 
-	metadata, err :=
-		s.metadataRepository.MetadataByObjectID(
-			c, metadataDTO.Group, metadataDTO.Partition, metadataDTO.Path, metadataDTO.ID.ToInt64(),
+// ReleaseExpiredVersions removes the reservations of uploads that neither committed nor aborted
+// in time, e.g. because the explorer stopped in the middle of an upload, and returns the blocks
+// recorded on them. A metadata that fails to be written is logged and released with the next call,
+// so the blocks of the ones already released are never lost.
+func (s *objectMetadata) ReleaseExpiredVersions(c context.Context, now time.Time) (dto.BlockHeaders, error) {
+	log.FromContext(c).Debugf("[objectMetadata.ReleaseExpiredVersions] now: %s", now)
+	metadataList, err := s.metadataRepository.MetadataWithExpiredVersions(c, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var released dto.BlockHeaders
+	for _, expired := range metadataList {
+		var recorded entity.BlockHeaders
+		_, err := s.modify(c, expired.Group(), expired.Partition(), expired.Path(), expired.ID().ToInt64(),
+			func(metadata *entity.ObjectMetadata) error {
+				recorded, _ = metadata.ReleaseExpiredVersions(now)
+				return nil
+			},
 		)
+		switch {
+		case errors.Is(err, soserror.NotFound):
+			continue
+		case err != nil:
+			log.FromContext(c).Errorf("[objectMetadata.ReleaseExpiredVersions] can not release versions of %d. %s", expired.ID(), err.Error())
+			continue
+		}
+		released = append(released, dto.NewBlockHeadersFromModel(recorded)...)
+	}
+	return released, nil
+}
+
+// Delete removes the versions, or the whole object when no version is given, and returns the blocks
+// recorded on the pending versions dropped with the object.
+func (s *objectMetadata) Delete(c context.Context, metadataDTO *dto.Metadata) (dto.BlockHeaders, error) {
+	log.FromContext(c).Debugf("[objectMetadata.Delete] request: %+v", metadataDTO)
+	var recorded entity.BlockHeaders
+	metadata, err := s.modify(c, metadataDTO.Group, metadataDTO.Partition, metadataDTO.Path, metadataDTO.ID.ToInt64(),
+		func(metadata *entity.ObjectMetadata) error {
+			// deleting the object drops the pending versions as well, so their uploads fail to commit
+			if metadataDTO.Versions.Empty() {
+				recorded = metadata.DeleteVersions()
+				return nil
+			}
+
+			for _, version := range metadataDTO.Versions {
+				if err := metadata.DeleteVersion(version.Number); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, version := range metadataDTO.Versions {
-		if err := metadata.DeleteVersion(version.Number); err != nil {
-			return err
+	if !metadata.HasCommittedVersion() {
+		if err := s.unlinkDirectory(c, metadata); err != nil {
+			return nil, err
 		}
 	}
+	return dto.NewBlockHeadersFromModel(recorded), nil
+}
 
-	if metadata.Versions().Empty() {
-		if err := s.metadataRepository.Delete(c, metadata); err != nil {
-			return err
+// PutTags replaces the tags of the object. The versions are left as they are, so no version is created.
+func (s *objectMetadata) PutTags(c context.Context, metadataDTO *dto.Metadata) (*dto.Metadata, error) {
+	log.FromContext(c).Debugf("[objectMetadata.PutTags] request: %+v", metadataDTO)
+	if err := metadataDTO.Tags.Validate(); err != nil {
+		return nil, err
+	}
+
+	metadata, err := s.modify(c, metadataDTO.Group, metadataDTO.Partition, metadataDTO.Path, metadataDTO.ID.ToInt64(),
+		func(metadata *entity.ObjectMetadata) error {
+			if !metadata.HasCommittedVersion() {
+				return soserror.NewNotFoundError(fmt.Errorf("object is not committed yet"))
+			}
+
+			metadata.SetTags(metadataDTO.Tags)
+			metadata.ModifiedAt = time.Now()
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewMetadataFromModel(metadata), nil
+}
+
+// RewrapDataKeys replaces the wrapped data keys of the versions. The data keys themselves do not change,
+// so the blocks are not rewritten.
+func (s *objectMetadata) RewrapDataKeys(c context.Context, metadataDTO *dto.Metadata) (*dto.Metadata, error) {
+	log.FromContext(c).Debugf("[objectMetadata.RewrapDataKeys] request: %+v", metadataDTO)
+	metadata, err := s.modify(c, metadataDTO.Group, metadataDTO.Partition, metadataDTO.Path, metadataDTO.ID.ToInt64(),
+		func(metadata *entity.ObjectMetadata) error {
+			for _, version := range metadataDTO.Versions {
+				if !version.Encryption.Enabled() {
+					continue
+				}
+
+				if !metadata.RewrapVersion(version.Number, version.Encryption) {
+					return soserror.NewNotFoundError(fmt.Errorf("encrypted version %d not found", version.Number))
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewMetadataFromModel(metadata), nil
+}
+
+// Move renames the object and moves it to the path on the same partition. Only the metadata is written,
+// the versions keep their blocks. An object is not moved while an upload to it is in progress, since
+// the upload commits to the path it was reserved on.
+func (s *objectMetadata) Move(
+	c context.Context, metadataDTO *dto.Metadata, destination dto.Destination,
+) (*dto.Metadata, error) {
+	log.FromContext(c).Debugf("[objectMetadata.Move] request: %+v, destination: %+v", metadataDTO, destination)
+	path := entity.CleanDirectoryPath(destination.Path)
+	for attempt := 0; attempt < maxMetadataWriteAttempts; attempt++ {
+		metadata, err := s.metadataRepository.MetadataByObjectID(
+			c, metadataDTO.Group, metadataDTO.Partition, metadataDTO.Path, metadataDTO.ID.ToInt64(),
+		)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		if err := s.metadataRepository.Update(c, metadata); err != nil {
-			return err
+
+		switch {
+		case !metadata.HasCommittedVersion():
+			return nil, soserror.NewNotFoundError(fmt.Errorf("object is not committed yet"))
+		case metadata.HasPendingVersion():
+			return nil, soserror.NewConflictError(fmt.Errorf("object has an upload in progress"))
+		case metadata.Path() == path && metadata.Name() == destination.Name:
+			return dto.NewMetadataFromModel(metadata), nil
 		}
-	}
 
-	return nil
+		exist, err := s.metadataRepository.MetadataByObjectName(
+			c, metadataDTO.Group, metadataDTO.Partition, path, destination.Name,
+		)
+		if err != nil && !errors.Is(err, soserror.NotFound) {
+			return nil, err
+		}
+
+		if exist != nil {
+			return nil, soserror.NewConflictError(fmt.Errorf("object already exist. %s", destination.Name))
+		}
+
+		now := time.Now()
+		previous := metadata.Clone()
+		metadata.ModifiedAt = now
+
+		// a conflict is either a concurrent write or a concurrent move to the name, read again to tell
+		err = s.metadataRepository.Move(c, metadata, path, destination.Name)
+		if errors.Is(err, soserror.Conflict) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// the object is linked to its new directory first, so it never disappears from the listings
+		if entity.CleanDirectoryPath(previous.Path()) != path {
+			if err := s.linkDirectory(c, metadata, now); err != nil {
+				return nil, err
+			}
+
+			if err := s.unlinkDirectory(c, &previous); err != nil {
+				return nil, err
+			}
+		}
+		return dto.NewMetadataFromModel(metadata), nil
+	}
+	return nil, soserror.NewConflictError(fmt.Errorf("can not move metadata of %d. too many concurrent writes", metadataDTO.ID))
 }
 
 func (s *objectMetadata) MetadataByObjectName(
@@ -141,6 +400,10 @@ func (s *objectMetadata) MetadataByObjectName(
 		metadata = &entity.ObjectMetadata{}
 	}
 
+	if metadata.IsValid() && !metadata.HasCommittedVersion() {
+		return nil, soserror.NewNotFoundError(fmt.Errorf("object is not committed yet"))
+	}
+
 	return dto.NewMetadataFromModel(metadata), nil
 }
 
@@ -152,68 +415,375 @@ func (s *objectMetadata) MetadataByObjectID(
 	if err != nil {
 		return nil, err
 	}
+
+	if !metadata.HasCommittedVersion() {
+		return nil, soserror.NewNotFoundError(fmt.Errorf("object is not committed yet"))
+	}
 	return dto.NewMetadataFromModel(metadata), nil
 }
 
-func (s *objectMetadata) MetadataListOnPath(c context.Context, group, partition, path string) (dto.MetadataList, error) {
+// MetadataListOnPath returns a page of the metadata on the path and the cursor of the next page.
+// The cursor is empty on the last page.
+func (s *objectMetadata) MetadataListOnPath(
+	c context.Context, group, partition, path string, lastObjectID entity.ObjectID, limit int,
+) (dto.MetadataList, entity.ObjectID, error) {
+	switch {
+	case limit < 0:
+		return nil, 0, fmt.Errorf("limit is invalid. %d", limit)
+	}
+
+	// one more item than asked tells whether a next page exists
+	queryLimit := limit
+	if limit > 0 {
+		queryLimit = limit + 1
+	}
+
 	items, err :=
-		s.metadataRepository.FindMetadata(c, group, partition, path)
+		s.metadataRepository.FindMetadata(c, group, partition, path, lastObjectID.ToInt64(), queryLimit)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	list := make(dto.MetadataList, len(items))
-	for i, item := range items {
-		list[i] = *dto.NewMetadataFromModel(&item)
+	var nextCursor entity.ObjectID
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+		nextCursor = items[limit-1].ID()
 	}
-	return list, nil
+
+	// an object only reserved by its first upload is not listed, the cursor still moves past it
+	list := make(dto.MetadataList, 0, len(items))
+	for _, item := range items {
+		if item.HasCommittedVersion() {
+			list = append(list, *dto.NewMetadataFromModel(&item))
+		}
+	}
+	return list, nextCursor, nil
 }
 
-func (s *objectMetadata) createMetadata(
-	c context.Context, object *dto.Object, now time.Time,
-) (*entity.ObjectMetadata, error) {
-	metadata := object.ToEntity()
-	metadata.CreatedAt = now
-	metadata.ModifiedAt = now
+// MetadataListByTags returns a page of the metadata in the partition whose tags match the expression,
+// and the cursor of the next page. The cursor is empty on the last page.
+func (s *objectMetadata) MetadataListByTags(
+	c context.Context, group, partition string, expression entity.TagExpression, lastObjectID entity.ObjectID, limit int,
+) (dto.MetadataList, entity.ObjectID, error) {
+	switch {
+	case len(expression) == 0:
+		return nil, 0, fmt.Errorf("tag expression is empty")
+	case limit < 0:
+		return nil, 0, fmt.Errorf("limit is invalid. %d", limit)
+	}
+
+	queryLimit := limit
+	if limit > 0 {
+		queryLimit = limit + 1
+	}
 
-	versionNumber := 0
-	blockHeaders := object.BlockHeaders.ToEntity()
-	version := s.newVersion(versionNumber, object.Size, blockHeaders, now)
-	metadata.AppendVersion(version)
+	items, err := s.metadataRepository.FindMetadataByTags(
+		c, group, partition, expression, lastObjectID.ToInt64(), queryLimit,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	if err := s.metadataRepository.Create(c, &metadata); err != nil {
-		return nil, err
+	var nextCursor entity.ObjectID
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+		nextCursor = items[limit-1].ID()
 	}
 
-	return &metadata, nil
+	list := make(dto.MetadataList, 0, len(items))
+	for _, item := range items {
+		if item.HasCommittedVersion() {
+			list = append(list, *dto.NewMetadataFromModel(&item))
+		}
+	}
+	return list, nextCursor, nil
 }
 
-func (s *objectMetadata) updateMetadata(
-	c context.Context, metadata *entity.ObjectMetadata, object *dto.Object, now time.Time,
-) (*entity.ObjectMetadata, error) {
-	versionNumber := metadata.LastVersion() + 1
-	blockHeaders := object.BlockHeaders.ToEntity()
-	version := s.newVersion(versionNumber, object.Size, blockHeaders, now)
-	metadata.AppendVersion(version)
+// MetadataListByStaleDataKey returns a page of the metadata holding a version whose data key is wrapped
+// by another master key than keyID, and the cursor of the next page. The cursor is empty on the last page.
+func (s *objectMetadata) MetadataListByStaleDataKey(
+	c context.Context, keyID string, lastObjectID entity.ObjectID, limit int,
+) (dto.MetadataList, entity.ObjectID, error) {
+	switch {
+	case keyID == "":
+		return nil, 0, fmt.Errorf("key id is empty")
+	case limit < 0:
+		return nil, 0, fmt.Errorf("limit is invalid. %d", limit)
+	}
+
+	queryLimit := limit
+	if limit > 0 {
+		queryLimit = limit + 1
+	}
+
+	items, err := s.metadataRepository.FindMetadataByStaleDataKey(c, keyID, lastObjectID.ToInt64(), queryLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var nextCursor entity.ObjectID
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+		nextCursor = items[limit-1].ID()
+	}
+
+	list := make(dto.MetadataList, 0, len(items))
+	for _, item := range items {
+		list = append(list, *dto.NewMetadataFromModel(&item))
+	}
+	return list, nextCursor, nil
+}
+
+func (s *objectMetadata) Directory(c context.Context, group, partition, path string) (*dto.Directory, error) {
+	path = entity.CleanDirectoryPath(path)
+	directory, err := s.directoryRepository.FindMetadata(c, group, partition, path)
+	if err != nil {
+		// the root always exists, it is only empty before the first upload
+		if errors.Is(err, soserror.NotFound) && path == entity.RootDirectoryPath {
+			directory = entity.NewDirectoryBuilder().
+				Group(group).
+				Partition(partition).
+				Path(path).
+				Build()
+			return dto.NewDirectoryFromModel(directory), nil
+		}
+		return nil, err
+	}
+	return dto.NewDirectoryFromModel(directory), nil
+}
+
+func (s *objectMetadata) reserveVersion(
+	c context.Context, object *dto.Object, now time.Time,
+) (*dto.Object, error) {
+	metadata, err :=
+		s.metadataRepository.MetadataByObjectName(c, object.Group, object.Partition, object.Path, object.Name)
+	if err != nil && !errors.Is(err, soserror.NotFound) {
+		return nil, err
+	}
+
+	reserved := *object
+	if metadata == nil {
+		created := object.ToEntity()
+		created.CreatedAt = now
+		created.ModifiedAt = now
+
+		reserved.VersionNum = 0
+		created.AppendVersion(s.newPendingVersion(reserved.VersionNum, object.ExpiresAt, now))
+
+		// a concurrent upload created the object first, the caller retries on it
+		if err := s.metadataRepository.Create(c, &created); err != nil {
+			return nil, err
+		}
+		return &reserved, nil
+	}
+
+	reserved.ID = metadata.ID()
+	reserved.VersionNum = metadata.NextVersion()
+	metadata.AppendVersion(s.newPendingVersion(reserved.VersionNum, object.ExpiresAt, now))
 
 	if err := s.metadataRepository.Update(c, metadata); err != nil {
 		return nil, err
 	}
+	return &reserved, nil
+}
+
+// modify applies change to the stored metadata and writes it back, reading it again whenever a
+// concurrent write won. The metadata is deleted once change leaves it without any version.
+func (s *objectMetadata) modify(
+	c context.Context, group, partition, path string, objectID int64,
+	change func(metadata *entity.ObjectMetadata) error,
+) (*entity.ObjectMetadata, error) {
+	for attempt := 0; attempt < maxMetadataWriteAttempts; attempt++ {
+		metadata, err := s.metadataRepository.MetadataByObjectID(c, group, partition, path, objectID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := change(metadata); err != nil {
+			return nil, err
+		}
+
+		if metadata.Versions().Empty() {
+			err = s.metadataRepository.Delete(c, metadata)
+		} else {
+			err = s.metadataRepository.Update(c, metadata)
+		}
 
-	return metadata, nil
+		if errors.Is(err, soserror.Conflict) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		return metadata, nil
+	}
+	return nil, soserror.NewConflictError(fmt.Errorf("can not write metadata of %d. too many concurrent writes", objectID))
+}
+
+// linkDirectory adds the object to its directory and links every missing parent directory up to the root.
+// It works from the bottom so a directory is never reachable from its parent before it holds an entry.
+// Linking a sub directory always writes the parent, which fails a concurrent unlink read before it.
+func (s *objectMetadata) linkDirectory(c context.Context, metadata *entity.ObjectMetadata, now time.Time) error {
+	group, partition := metadata.Group(), metadata.Partition()
+	path := entity.CleanDirectoryPath(metadata.Path())
+	if err := s.directoryRepository.AddObject(c, group, partition, path, metadata.ID(), now); err != nil {
+		return err
+	}
+
+	for path != entity.RootDirectoryPath {
+		parent, name := entity.ParentDirectoryPath(path)
+		if err := s.directoryRepository.AddSubDirectory(c, group, partition, parent, name, now); err != nil {
+			return err
+		}
+		path = parent
+	}
+	return nil
+}
+
+// unlinkDirectory removes the object from its directory and prunes the directories left empty.
+// Each pruned directory is unlinked from its parent by unlinkSubDirectory.
+func (s *objectMetadata) unlinkDirectory(c context.Context, metadata *entity.ObjectMetadata) error {
+	now := time.Now()
+	group, partition := metadata.Group(), metadata.Partition()
+	path := entity.CleanDirectoryPath(metadata.Path())
+	err := s.directoryRepository.DeleteObject(c, group, partition, path, metadata.ID(), now)
+	if err != nil {
+		if errors.Is(err, soserror.NotFound) {
+			return nil
+		}
+		return err
+	}
+
+	for path != entity.RootDirectoryPath {
+		deleted, err := s.directoryRepository.DeleteIfEmpty(c, group, partition, path)
+		if err != nil {
+			return err
+		}
+
+		if !deleted {
+			break
+		}
+
+		unlinked, err := s.unlinkSubDirectory(c, group, partition, path, now)
+		if err != nil {
+			return err
+		}
+
+		if !unlinked {
+			break
+		}
+		path, _ = entity.ParentDirectoryPath(path)
+	}
+	return nil
+}
+
+// unlinkSubDirectory removes the deleted directory from its parent. The directory may be linked
+// again between its deletion and the removal, so the removal is checked against the parent revision
+// it was read at, and the name is kept once the directory exists again.
+func (s *objectMetadata) unlinkSubDirectory(c context.Context, group, partition, path string, now time.Time) (bool, error) {
+	parentPath, name := entity.ParentDirectoryPath(path)
+	for attempt := 0; attempt < maxMetadataWriteAttempts; attempt++ {
+		parent, err := s.directoryRepository.FindMetadata(c, group, partition, parentPath)
+		if err != nil {
+			if errors.Is(err, soserror.NotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+
+		if !slices.Contains(parent.SubDirectory(), name) {
+			return false, nil
+		}
+
+		_, err = s.directoryRepository.FindMetadata(c, group, partition, path)
+		switch {
+		case err == nil:
+			return false, nil
+		case !errors.Is(err, soserror.NotFound):
+			return false, err
+		}
+
+		err = s.directoryRepository.DeleteSubDirectory(c, group, partition, parentPath, name, parent.Revision(), now)
+		if errors.Is(err, soserror.Conflict) {
+			continue
+		}
+
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, soserror.NewConflictError(fmt.Errorf("can not unlink directory %s. too many concurrent writes", path))
 }
 
 func (s *objectMetadata) newVersion(
-	versionNum int, size int, blockHeaders entity.BlockHeaders, now time.Time,
+	versionNum int, size int, blockHeaders entity.BlockHeaders, etag string, content entity.ContentMetadata,
+	encryption entity.Encryption, now time.Time,
 ) entity.Version {
 	versionNumber := versionNum
 	version := entity.NewVersionBuilder().
 		Number(versionNumber).
 		Size(size).
 		BlockHeaders(blockHeaders).
+		ETag(etag).
+		Content(content).
+		Encryption(encryption).
 		Build()
 
 	version.CreatedAt = now
 	version.ModifiedAt = now
 	return version
 }
+
+func (s *objectMetadata) newPendingVersion(versionNum int, expiresAt time.Time, now time.Time) entity.Version {
+	return entity.NewVersionBuilder().
+		Number(versionNum).
+		Pending(true).
+		ExpiresAt(expiresAt).
+		CreatedAt(now).
+		ModifiedAt(now).
+		Build()
+}
+
+// BlockHeadersOfObjects returns the block headers referenced by every version of the objects.
+func (s *objectMetadata) BlockHeadersOfObjects(c context.Context, objectIDs []int64) (dto.BlockHeaders, error) {
+	metadataList, err := s.metadataRepository.MetadataByObjectIDs(c, objectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers dto.BlockHeaders
+	for _, metadata := range metadataList {
+		for _, version := range metadata.Versions() {
+			for _, header := range version.BlockHeaders() {
+				headers = append(headers, dto.NewBlockHeaderFromModel(header))
+			}
+		}
+	}
+	return headers, nil
+}
+
+// BlockHeadersOfContents returns the block headers of every version referencing the deduplicated content.
+func (s *objectMetadata) BlockHeadersOfContents(c context.Context, hashes []string) (dto.BlockHeaders, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	metadataList, err := s.metadataRepository.MetadataByContentHashes(c, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers dto.BlockHeaders
+	for _, metadata := range metadataList {
+		for _, version := range metadata.Versions() {
+			for _, header := range version.BlockHeaders() {
+				if slices.Contains(hashes, header.Hash()) {
+					headers = append(headers, dto.NewBlockHeaderFromModel(header))
+				}
+			}
+		}
+	}
+	return headers, nil
+}
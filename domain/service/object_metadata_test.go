@@ -0,0 +1,104 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/repository"
+	"github.com/ISSuh/sos/domain/service"
+	local "github.com/ISSuh/sos/infrastructure/persistence/database/local"
+	"github.com/ISSuh/sos/internal/generator"
+)
+
+// interleavedDirectory runs beforeUnlink once, right before the first sub directory is removed,
+// so a concurrent writer can be placed between the reads of an unlink and its write.
+type interleavedDirectory struct {
+	repository.ObjectDirectory
+	beforeUnlink func()
+}
+
+func (d *interleavedDirectory) DeleteSubDirectory(
+	c context.Context, group, partition, path, name string, revision int64, now time.Time,
+) error {
+	if hook := d.beforeUnlink; hook != nil {
+		d.beforeUnlink = nil
+		hook()
+	}
+	return d.ObjectDirectory.DeleteSubDirectory(c, group, partition, path, name, revision, now)
+}
+
+func putObject(t *testing.T, metadata service.ObjectMetadata, path, name string) *dto.Metadata {
+	t.Helper()
+
+	put, err := metadata.Put(context.Background(), &dto.Object{
+		ID:        entity.NewObjectID(),
+		Group:     "group",
+		Partition: "partition",
+		Path:      path,
+		Name:      name,
+	})
+	must(t, err)
+	return put
+}
+
+// Deleting the last object of a directory prunes it from its parent. An object put into the same
+// directory meanwhile links it again, and the parent has to keep listing it.
+func TestDeleteKeepsDirectoryLinkedMeanwhile(t *testing.T) {
+	generator.InitIdentifier(1)
+
+	metadataRepo, err := local.NewLocalObjectMetadata()
+	must(t, err)
+	directoryRepo, err := local.NewLocalObjectDirectory()
+	must(t, err)
+
+	directory := &interleavedDirectory{ObjectDirectory: directoryRepo}
+	metadata, err := service.NewObjectMetadata(metadataRepo, directory)
+	must(t, err)
+
+	first := putObject(t, metadata, "/a/b", "first")
+
+	var second *dto.Metadata
+	directory.beforeUnlink = func() {
+		second = putObject(t, metadata, "/a/b", "second")
+	}
+
+	_, err = metadata.Delete(context.Background(), first)
+	must(t, err)
+
+	parent, err := metadata.Directory(context.Background(), "group", "partition", "a")
+	must(t, err)
+	if !slices.Contains(parent.SubDirectories, "b") {
+		t.Errorf("sub directories of a = %v, want b kept", parent.SubDirectories)
+	}
+
+	child, err := metadata.Directory(context.Background(), "group", "partition", "a/b")
+	must(t, err)
+	if !slices.Equal(child.Objects, []entity.ObjectID{second.ID}) {
+		t.Errorf("objects of a/b = %v, want [%d]", child.Objects, second.ID)
+	}
+}
@@ -0,0 +1,115 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/repository"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/validation"
+)
+
+type Policy interface {
+	Put(c context.Context, policyDTO *dto.Policy) (*dto.Policy, error)
+	Delete(c context.Context, group, partition string) error
+	PolicyByPartition(c context.Context, group, partition string) (*dto.Policy, error)
+	FindPolicies(c context.Context, group, partition string) (dto.Policies, error)
+}
+
+type policy struct {
+	policyRepository repository.Policy
+}
+
+func NewPolicy(policyRepository repository.Policy) (Policy, error) {
+	switch {
+	case validation.IsNil(policyRepository):
+		return nil, fmt.Errorf("Policy repository is nil")
+	}
+
+	return &policy{
+		policyRepository: policyRepository,
+	}, nil
+}
+
+func (s *policy) Put(c context.Context, policyDTO *dto.Policy) (*dto.Policy, error) {
+	log.FromContext(c).Debugf("[policy.Put] group: %s, partition: %s", policyDTO.Group, policyDTO.Partition)
+	if err := policyDTO.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	policyDTO.CreatedAt = now
+	policyDTO.ModifiedAt = now
+
+	policy := policyDTO.ToEntity()
+	if err := s.policyRepository.Put(c, &policy); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.policyRepository.PolicyByPartition(c, policy.Group(), policy.Partition())
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewPolicyFromModel(stored), nil
+}
+
+func (s *policy) Delete(c context.Context, group, partition string) error {
+	log.FromContext(c).Debugf("[policy.Delete] group: %s, partition: %s", group, partition)
+	return s.policyRepository.Delete(c, group, partition)
+}
+
+func (s *policy) PolicyByPartition(c context.Context, group, partition string) (*dto.Policy, error) {
+	policy, err := s.policyRepository.PolicyByPartition(c, group, partition)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewPolicyFromModel(policy), nil
+}
+
+// FindPolicies returns the policies that apply to a partition, the one of its group first.
+func (s *policy) FindPolicies(c context.Context, group, partition string) (dto.Policies, error) {
+	partitions := []string{""}
+	if !validation.IsEmpty(partition) {
+		partitions = append(partitions, partition)
+	}
+
+	policies := dto.Policies{}
+	for _, p := range partitions {
+		policy, err := s.policyRepository.PolicyByPartition(c, group, p)
+		switch {
+		case errors.Is(err, soserror.NotFound):
+			continue
+		case err != nil:
+			return nil, err
+		}
+
+		policies = append(policies, *dto.NewPolicyFromModel(policy))
+	}
+	return policies, nil
+}
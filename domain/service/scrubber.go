@@ -0,0 +1,477 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/model/message"
+	"github.com/ISSuh/sos/domain/repository"
+	"github.com/ISSuh/sos/domain/service/object"
+	"github.com/ISSuh/sos/internal/checksum"
+	"github.com/ISSuh/sos/internal/crc"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/validation"
+)
+
+const (
+	scrubPageSize       = 8
+	maxScrubCorruptions = 100
+)
+
+var ErrScrubRunning = errors.New("scrub is already running")
+
+// Scrubber walks every stored block and deduplicated content, recomputes its checksum and
+// repairs corrupted blocks from a peer node holding a healthy replica.
+type Scrubber interface {
+	Run(c context.Context, interval time.Duration)
+	Scrub(c context.Context) error
+	Trigger() bool
+	Status() dto.ScrubStatus
+}
+
+type scrubber struct {
+	storageRepository repository.ObjectStorage
+	peers             []object.StorageNode
+	bytesPerSecond    int64
+	quarantine        bool
+
+	trigger chan struct{}
+
+	mutex  sync.Mutex
+	status dto.ScrubStatus
+}
+
+// NewScrubber reads at most bytesPerSecond while scrubbing, without a limit when it is zero.
+// Corrupted blocks are moved aside when quarantine is set, and are only reported otherwise.
+func NewScrubber(
+	storageRepository repository.ObjectStorage, peers []object.StorageNode, bytesPerSecond int64, quarantine bool,
+) (Scrubber, error) {
+	switch {
+	case validation.IsNil(storageRepository):
+		return nil, errors.New("StorageRepository is nil")
+	case bytesPerSecond < 0:
+		return nil, errors.New("scrub rate is invalid")
+	}
+
+	for _, peer := range peers {
+		switch {
+		case peer.Node.Empty():
+			return nil, errors.New("scrub peer host is empty")
+		case validation.IsNil(peer.Requestor):
+			return nil, errors.New("BlockStorage requestor is nil")
+		}
+	}
+
+	return &scrubber{
+		storageRepository: storageRepository,
+		peers:             peers,
+		bytesPerSecond:    bytesPerSecond,
+		quarantine:        quarantine,
+		trigger:           make(chan struct{}, 1),
+	}, nil
+}
+
+// Run scrubs once at start, then every interval or when triggered until the context is done.
+func (s *scrubber) Run(c context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Scrub(c); err != nil && !errors.Is(err, ErrScrubRunning) {
+			log.FromContext(c).Errorf("[scrubber.Run] scrub fail. %s", err.Error())
+		}
+
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+		case <-s.trigger:
+		}
+	}
+}
+
+func (s *scrubber) Scrub(c context.Context) error {
+	if !s.begin() {
+		return ErrScrubRunning
+	}
+
+	err := s.scrub(c)
+	s.finish(err)
+	return err
+}
+
+// Trigger asks Run to start a pass and reports false when one is already running.
+func (s *scrubber) Trigger() bool {
+	s.mutex.Lock()
+	running := s.status.Running
+	s.mutex.Unlock()
+	if running {
+		return false
+	}
+
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+func (s *scrubber) Status() dto.ScrubStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	status := s.status
+	status.Corruptions = make(dto.ScrubCorruptions, len(s.status.Corruptions))
+	copy(status.Corruptions, s.status.Corruptions)
+	return status
+}
+
+func (s *scrubber) scrub(c context.Context) error {
+	if err := s.scrubStored(c); err != nil {
+		return err
+	}
+
+	if len(s.peers) == 0 {
+		return nil
+	}
+	return s.repairQuarantined(c)
+}
+
+func (s *scrubber) scrubStored(c context.Context) error {
+	startedAt := time.Now()
+	scannedBytes := int64(0)
+	cursor := ""
+	for {
+		blocks, next, err := s.storageRepository.Scan(c, cursor, scrubPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, scanned := range blocks {
+			if err := s.verify(c, scanned); err != nil {
+				return err
+			}
+
+			scannedBytes += int64(scanned.Size)
+			if err := s.throttle(c, startedAt, scannedBytes); err != nil {
+				return err
+			}
+		}
+
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// repairQuarantined retries the blocks a previous pass could not repair.
+func (s *scrubber) repairQuarantined(c context.Context) error {
+	cursor := ""
+	for {
+		blocks, next, err := s.storageRepository.ScanQuarantined(c, cursor, scrubPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, scanned := range blocks {
+			host, err := s.repair(c, scanned.ObjectID, scanned.BlockID, scanned.Index)
+			if err != nil {
+				log.FromContext(c).Warnf("[scrubber.repairQuarantined] can not repair block. objectID: %s, blockID: %d, index: %d. %s",
+					scanned.ObjectID, scanned.BlockID, scanned.Index, err.Error())
+				continue
+			}
+
+			corruption := dto.ScrubCorruption{
+				ObjectID:     scanned.ObjectID,
+				BlockID:      scanned.BlockID,
+				Index:        scanned.Index,
+				Reason:       "block is quarantined",
+				Quarantined:  true,
+				Repaired:     true,
+				RepairedFrom: host,
+				DetectedAt:   time.Now(),
+			}
+
+			err = s.storageRepository.DeleteQuarantined(c, scanned.ObjectID, scanned.BlockID, scanned.Index)
+			if err != nil {
+				corruption.Error = err.Error()
+			}
+			s.report(corruption)
+		}
+
+		if next == "" {
+			return c.Err()
+		}
+		cursor = next
+	}
+}
+
+func (s *scrubber) verify(c context.Context, scanned repository.ScannedBlock) error {
+	reason := ""
+	switch {
+	case scanned.Err != nil:
+		reason = fmt.Sprintf("block can not be decoded. %s", scanned.Err.Error())
+	default:
+		header := scanned.Block.Header()
+		switch {
+		case !crc.Verify(scanned.Block.Buffer(), header.Checksum()):
+			crcValue := crc.Checksum(scanned.Block.Buffer())
+			reason = fmt.Sprintf("Block checksum is invalid(%d / %d)", crcValue, header.Checksum())
+		case scanned.Hash != "" && !checksum.Verify(scanned.Hash, scanned.Block.Buffer()):
+			reason = "content does not match its hash"
+		}
+	}
+
+	s.mutex.Lock()
+	s.status.Current.ScannedBlocks++
+	s.status.Current.ScannedBytes += int64(scanned.Size)
+	if reason != "" {
+		s.status.Current.CorruptedBlocks++
+	}
+	s.mutex.Unlock()
+
+	if reason == "" {
+		return nil
+	}
+
+	log.FromContext(c).Warnf("[scrubber.verify] corrupted block. objectID: %s, blockID: %d, index: %d, hash: %s. %s",
+		scanned.ObjectID, scanned.BlockID, scanned.Index, scanned.Hash, reason)
+
+	corruption := dto.ScrubCorruption{
+		ObjectID:   scanned.ObjectID,
+		BlockID:    scanned.BlockID,
+		Index:      scanned.Index,
+		Hash:       scanned.Hash,
+		Reason:     reason,
+		DetectedAt: time.Now(),
+	}
+
+	if err := s.handleCorruption(c, &corruption); err != nil {
+		log.FromContext(c).Errorf("[scrubber.verify] can not recover block. %s", err.Error())
+		corruption.Error = err.Error()
+	}
+
+	s.report(corruption)
+	return c.Err()
+}
+
+func (s *scrubber) handleCorruption(c context.Context, corruption *dto.ScrubCorruption) error {
+	if corruption.Hash != "" {
+		return s.handleContentCorruption(c, corruption)
+	}
+
+	if s.quarantine {
+		err := s.storageRepository.Quarantine(c, corruption.ObjectID, corruption.BlockID, corruption.Index)
+		if err != nil {
+			return err
+		}
+		corruption.Quarantined = true
+	}
+
+	if len(s.peers) == 0 {
+		return nil
+	}
+
+	host, err := s.repair(c, corruption.ObjectID, corruption.BlockID, corruption.Index)
+	if err != nil {
+		return err
+	}
+
+	corruption.Repaired = true
+	corruption.RepairedFrom = host
+
+	if corruption.Quarantined {
+		return s.storageRepository.DeleteQuarantined(c, corruption.ObjectID, corruption.BlockID, corruption.Index)
+	}
+	return nil
+}
+
+// handleContentCorruption repairs deduplicated content in place. It is never quarantined, as it is
+// shared by every object referencing it.
+func (s *scrubber) handleContentCorruption(c context.Context, corruption *dto.ScrubCorruption) error {
+	if len(s.peers) == 0 {
+		return nil
+	}
+
+	host, err := s.repairContent(c, corruption.Hash)
+	if err != nil {
+		return err
+	}
+
+	corruption.Repaired = true
+	corruption.RepairedFrom = host
+	return nil
+}
+
+// repairContent replaces the content with the first copy held by a peer that matches the hash.
+func (s *scrubber) repairContent(c context.Context, hash string) (string, error) {
+	msg := &message.BlockHeader{
+		ObjectID: &message.ObjectID{},
+		BlockID:  &message.BlockID{},
+		Hash:     hash,
+	}
+
+	var errs []error
+	for _, peer := range s.peers {
+		resp, err := peer.Requestor.GetBlockStream(c, msg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", peer.Node.Host, err))
+			continue
+		}
+
+		block := message.ToBlock(resp)
+		header := block.Header()
+		switch {
+		case header.Hash() != hash || !checksum.Verify(hash, block.Buffer()):
+			errs = append(errs, fmt.Errorf("%s: copy does not match the hash", peer.Node.Host))
+			continue
+		case !crc.Verify(block.Buffer(), header.Checksum()):
+			errs = append(errs, fmt.Errorf("%s: copy checksum is invalid", peer.Node.Host))
+			continue
+		}
+
+		if err := s.storageRepository.ReplaceContent(c, &block); err != nil {
+			return "", err
+		}
+
+		log.FromContext(c).Infof("[scrubber.repairContent] repaired content from %s. hash: %s", peer.Node.Host, hash)
+		return peer.Node.Host, nil
+	}
+
+	return "", fmt.Errorf("no healthy copy. %w", errors.Join(errs...))
+}
+
+// repair stores the first replica held by a peer that matches its own checksum.
+func (s *scrubber) repair(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) (string, error) {
+	header := entity.NewBlockHeaderBuilder().
+		ObjectID(objectID).
+		BlockID(blockID).
+		Index(index).
+		Build()
+	msg := message.FromBlockHeader(&header)
+
+	var errs []error
+	for _, peer := range s.peers {
+		resp, err := peer.Requestor.GetBlockStream(c, msg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", peer.Node.Host, err))
+			continue
+		}
+
+		block := message.ToBlock(resp)
+		replicaHeader := block.Header()
+		switch {
+		case block.ObjectID() != objectID || block.BlockID() != blockID || block.Index() != index:
+			errs = append(errs, fmt.Errorf("%s: replica is a different block", peer.Node.Host))
+			continue
+		case !crc.Verify(block.Buffer(), replicaHeader.Checksum()):
+			errs = append(errs, fmt.Errorf("%s: replica checksum is invalid", peer.Node.Host))
+			continue
+		}
+
+		if err := s.storageRepository.Put(c, &block); err != nil {
+			return "", err
+		}
+
+		log.FromContext(c).Infof("[scrubber.repair] repaired block from %s. objectID: %s, blockID: %d, index: %d",
+			peer.Node.Host, objectID, blockID, index)
+		return peer.Node.Host, nil
+	}
+
+	return "", fmt.Errorf("no healthy replica. %w", errors.Join(errs...))
+}
+
+func (s *scrubber) throttle(c context.Context, startedAt time.Time, scannedBytes int64) error {
+	if s.bytesPerSecond == 0 {
+		return nil
+	}
+
+	expected := time.Duration(float64(scannedBytes) / float64(s.bytesPerSecond) * float64(time.Second))
+	wait := expected - time.Since(startedAt)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-c.Done():
+		return c.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (s *scrubber) begin() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.status.Running {
+		return false
+	}
+
+	s.status.Running = true
+	s.status.Current = dto.ScrubPass{
+		StartedAt: time.Now(),
+	}
+	return true
+}
+
+func (s *scrubber) finish(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pass := s.status.Current
+	pass.FinishedAt = time.Now()
+	if err != nil {
+		pass.Error = err.Error()
+	}
+
+	s.status.Running = false
+	s.status.Passes++
+	s.status.Current = dto.ScrubPass{}
+	s.status.Last = pass
+}
+
+func (s *scrubber) report(corruption dto.ScrubCorruption) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if corruption.Repaired {
+		s.status.Current.RepairedBlocks++
+	}
+
+	s.status.Corruptions = append(s.status.Corruptions, corruption)
+	if len(s.status.Corruptions) > maxScrubCorruptions {
+		s.status.Corruptions = s.status.Corruptions[len(s.status.Corruptions)-maxScrubCorruptions:]
+	}
+}
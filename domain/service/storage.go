@@ -25,18 +25,25 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/ISSuh/sos/domain/model/dto"
 	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/repository"
+	"github.com/ISSuh/sos/internal/checksum"
 	"github.com/ISSuh/sos/internal/crc"
+	"github.com/ISSuh/sos/internal/encryption"
 	"github.com/ISSuh/sos/internal/validation"
 )
 
 type ObjectStorage interface {
-	Put(c context.Context, block *entity.Block) error
+	// Put stores the block, encrypted with the data key when one is given.
+	Put(c context.Context, block *entity.Block, dataKey []byte) error
 
+	// GetBlock returns the block, decrypted with the data key when one is given and the block is
+	// encrypted. Without a data key the block is returned as stored.
 	GetBlock(
-		c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int,
+		c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int, dataKey []byte,
 	) (*entity.Block, error)
 
 	GetBlockHeader(
@@ -44,8 +51,31 @@ type ObjectStorage interface {
 	) (*entity.BlockHeader, error)
 
 	Delete(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) error
+
+	Copy(c context.Context, source, target entity.BlockHeader) error
+
+	// ListBlocks calls fn with the header of every stored block written before the time, and with
+	// the deduplicated content whose references did not change since, along with its reference count.
+	ListBlocks(c context.Context, before time.Time, fn func(header *entity.BlockHeader, references int64) error) error
+
+	// Reference takes another reference on deduplicated content and reports whether the
+	// content is stored, so the data is only sent when it is not.
+	Reference(c context.Context, hash string) (bool, error)
+
+	GetContent(c context.Context, hash string) (*entity.Block, error)
+
+	// Release drops a reference on deduplicated content, the last one deletes it.
+	Release(c context.Context, hash string) error
+
+	// Reconcile sets the reference count of deduplicated content to the references its metadata
+	// holds, unless the count changed since before. It returns the count found and whether it was set.
+	Reconcile(c context.Context, hash string, references int64, before time.Time) (int64, bool, error)
+
+	DedupStats(c context.Context) (dto.DedupStats, error)
 }
 
+const listBlocksPageSize = 64
+
 type objectStorage struct {
 	storageRepository repository.ObjectStorage
 }
@@ -61,7 +91,7 @@ func NewObjectStorage(storageRepository repository.ObjectStorage) (ObjectStorage
 	}, nil
 }
 
-func (s *objectStorage) Put(c context.Context, block *entity.Block) error {
+func (s *objectStorage) Put(c context.Context, block *entity.Block, dataKey []byte) error {
 	if err := block.Validate(); err != nil {
 		return err
 	}
@@ -72,6 +102,28 @@ func (s *objectStorage) Put(c context.Context, block *entity.Block) error {
 		return fmt.Errorf("Block checksum is invalid(%d / %d)", crcValue, header.Checksum())
 	}
 
+	// a deduplicated block is stored once per content, whichever object it belongs to
+	if header.Hash() != "" {
+		if dataKey != nil {
+			return fmt.Errorf("deduplicated block can not be encrypted")
+		}
+		if !checksum.Verify(header.Hash(), block.Buffer()) {
+			return fmt.Errorf("Block hash is invalid(%s)", header.Hash())
+		}
+		return s.storageRepository.PutContent(c, block)
+	}
+
+	// the data key is never stored, only the sealed data with its own checksum
+	if dataKey != nil {
+		sealed, err := encryption.Seal(dataKey, block.Buffer())
+		if err != nil {
+			return err
+		}
+
+		encrypted := s.rebuildBlock(header, sealed, true)
+		block = &encrypted
+	}
+
 	if err := s.storageRepository.Put(c, block); err != nil {
 		return err
 	}
@@ -79,13 +131,25 @@ func (s *objectStorage) Put(c context.Context, block *entity.Block) error {
 }
 
 func (s *objectStorage) GetBlock(
-	c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int,
+	c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int, dataKey []byte,
 ) (*entity.Block, error) {
 	block, err := s.storageRepository.GetBlock(c, objectID, blockID, index)
 	if err != nil {
 		return nil, err
 	}
-	return block, nil
+
+	header := block.Header()
+	if dataKey == nil || !header.DataKeySealed() {
+		return block, nil
+	}
+
+	plain, err := encryption.Open(dataKey, block.Buffer())
+	if err != nil {
+		return nil, fmt.Errorf("can not decrypt block %d of object %d. %w", blockID, objectID, err)
+	}
+
+	decrypted := s.rebuildBlock(header, plain, false)
+	return &decrypted, nil
 }
 
 func (s *objectStorage) GetBlockHeader(
@@ -106,3 +170,135 @@ func (s *objectStorage) Delete(
 	}
 	return nil
 }
+
+// Copy stores the data of the source block again under the object id, block id and index of the
+// target, so a block is duplicated without leaving the node.
+func (s *objectStorage) Copy(c context.Context, source, target entity.BlockHeader) error {
+	block, err := s.storageRepository.GetBlock(c, source.ObjectID(), source.BlockID(), source.Index())
+	if err != nil {
+		return err
+	}
+
+	header := block.Header()
+	if !crc.Verify(block.Buffer(), header.Checksum()) {
+		return fmt.Errorf("Block checksum is invalid(%d / %d)", crc.Checksum(block.Buffer()), header.Checksum())
+	}
+
+	copied := entity.NewBlockBuilder().
+		Header(
+			entity.NewBlockHeaderBuilder().
+				ObjectID(target.ObjectID()).
+				BlockID(target.BlockID()).
+				Index(target.Index()).
+				Size(header.Size()).
+				Checksum(header.Checksum()).
+				CustomerKeyed(header.CustomerKeyed()).
+				DataKeySealed(header.DataKeySealed()).
+				Compression(header.Compression()).
+				UncompressedSize(header.UncompressedSize()).
+				Timestamp(time.Now()).
+				Build(),
+		).
+		Buffer(block.Buffer()).
+		Build()
+	return s.Put(c, &copied, nil)
+}
+
+// rebuildBlock replaces the data of the block, with the size and checksum of the new data, which is
+// sealed with the data key or not.
+func (s *objectStorage) rebuildBlock(header entity.BlockHeader, buffer []byte, dataKeySealed bool) entity.Block {
+	return entity.NewBlockBuilder().
+		Header(
+			entity.NewBlockHeaderBuilder().
+				ObjectID(header.ObjectID()).
+				BlockID(header.BlockID()).
+				Index(header.Index()).
+				Node(header.Node()).
+				Size(len(buffer)).
+				Checksum(crc.Checksum(buffer)).
+				CustomerKeyed(header.CustomerKeyed()).
+				DataKeySealed(dataKeySealed).
+				Compression(header.Compression()).
+				UncompressedSize(header.UncompressedSize()).
+				Timestamp(header.Timestamp()).
+				Build(),
+		).
+		Buffer(buffer).
+		Build()
+}
+
+// ListBlocks skips the blocks that can not be decoded, as they are left to the scrubber.
+func (s *objectStorage) ListBlocks(
+	c context.Context, before time.Time, fn func(header *entity.BlockHeader, references int64) error,
+) error {
+	cursor := ""
+	for {
+		blocks, next, err := s.storageRepository.Scan(c, cursor, listBlocksPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, scanned := range blocks {
+			if scanned.Err != nil {
+				continue
+			}
+
+			header := scanned.Block.Header()
+			if scanned.Hash != "" {
+				// the content is known by its key, whichever header it was first stored with
+				header = entity.NewBlockHeaderBuilder().
+					Hash(scanned.Hash).
+					Size(header.Size()).
+					Timestamp(scanned.ReferencedAt).
+					Build()
+			}
+
+			if !header.Timestamp().Before(before) {
+				continue
+			}
+
+			if err := fn(&header, scanned.References); err != nil {
+				return err
+			}
+		}
+
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func (s *objectStorage) Reference(c context.Context, hash string) (bool, error) {
+	return s.storageRepository.ReferenceContent(c, hash)
+}
+
+func (s *objectStorage) GetContent(c context.Context, hash string) (*entity.Block, error) {
+	return s.storageRepository.GetContent(c, hash)
+}
+
+func (s *objectStorage) Release(c context.Context, hash string) error {
+	return s.storageRepository.ReleaseContent(c, hash)
+}
+
+func (s *objectStorage) Reconcile(
+	c context.Context, hash string, references int64, before time.Time,
+) (int64, bool, error) {
+	return s.storageRepository.ReconcileContent(c, hash, references, before)
+}
+
+func (s *objectStorage) DedupStats(c context.Context) (dto.DedupStats, error) {
+	contents, err := s.storageRepository.ContentStats(c)
+	if err != nil {
+		return dto.DedupStats{}, err
+	}
+
+	stats := dto.DedupStats{}
+	stats.Add(dto.DedupStats{
+		Blocks:        contents.Blocks,
+		References:    contents.References,
+		PhysicalBytes: contents.PhysicalBytes,
+		LogicalBytes:  contents.LogicalBytes,
+	})
+	return stats, nil
+}
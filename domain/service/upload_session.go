@@ -0,0 +1,143 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/repository"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/validation"
+)
+
+type UploadSession interface {
+	Create(c context.Context, sessionDTO *dto.UploadSession) (*dto.UploadSession, error)
+	Delete(c context.Context, uploadID int64) error
+	PutPart(c context.Context, uploadID int64, partDTO *dto.UploadPart) error
+	SessionByID(c context.Context, uploadID int64) (*dto.UploadSession, error)
+	FindExpired(c context.Context, now time.Time) (dto.UploadSessions, error)
+	BlockHeadersOfObjects(c context.Context, objectIDs []int64) (dto.BlockHeaders, error)
+	BlockHeadersOfContents(c context.Context, hashes []string) (dto.BlockHeaders, error)
+}
+
+type uploadSession struct {
+	sessionRepository repository.UploadSession
+}
+
+func NewUploadSession(sessionRepository repository.UploadSession) (UploadSession, error) {
+	switch {
+	case validation.IsNil(sessionRepository):
+		return nil, fmt.Errorf("UploadSessionRepository is nil")
+	}
+
+	return &uploadSession{
+		sessionRepository: sessionRepository,
+	}, nil
+}
+
+func (s *uploadSession) Create(c context.Context, sessionDTO *dto.UploadSession) (*dto.UploadSession, error) {
+	log.FromContext(c).Debugf("[uploadSession.Create] request: %+v", sessionDTO)
+	now := time.Now()
+	sessionDTO.CreatedAt = now
+	sessionDTO.ModifiedAt = now
+
+	session := sessionDTO.ToEntity()
+	if err := s.sessionRepository.Create(c, &session); err != nil {
+		return nil, err
+	}
+
+	return dto.NewUploadSessionFromModel(&session), nil
+}
+
+func (s *uploadSession) Delete(c context.Context, uploadID int64) error {
+	log.FromContext(c).Debugf("[uploadSession.Delete] uploadID: %d", uploadID)
+	return s.sessionRepository.Delete(c, uploadID)
+}
+
+func (s *uploadSession) PutPart(c context.Context, uploadID int64, partDTO *dto.UploadPart) error {
+	log.FromContext(c).Debugf("[uploadSession.PutPart] uploadID: %d, part: %d", uploadID, partDTO.Number)
+	now := time.Now()
+	partDTO.CreatedAt = now
+	partDTO.ModifiedAt = now
+
+	part := partDTO.ToEntity()
+	return s.sessionRepository.PutPart(c, uploadID, &part, now)
+}
+
+func (s *uploadSession) SessionByID(c context.Context, uploadID int64) (*dto.UploadSession, error) {
+	session, err := s.sessionRepository.SessionByID(c, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewUploadSessionFromModel(session), nil
+}
+
+func (s *uploadSession) FindExpired(c context.Context, now time.Time) (dto.UploadSessions, error) {
+	sessions, err := s.sessionRepository.FindExpired(c, now)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewUploadSessionsFromModel(sessions), nil
+}
+
+// BlockHeadersOfObjects returns the block headers of the parts uploaded to sessions of the objects.
+func (s *uploadSession) BlockHeadersOfObjects(c context.Context, objectIDs []int64) (dto.BlockHeaders, error) {
+	log.FromContext(c).Debugf("[uploadSession.BlockHeadersOfObjects] objectIDs: %v", objectIDs)
+	sessions, err := s.sessionRepository.SessionsByObjectIDs(c, objectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers dto.BlockHeaders
+	for _, session := range dto.NewUploadSessionsFromModel(sessions) {
+		headers = append(headers, session.Parts.BlockHeaders()...)
+	}
+	return headers, nil
+}
+
+// BlockHeadersOfContents returns the block headers of the parts referencing the deduplicated content.
+func (s *uploadSession) BlockHeadersOfContents(c context.Context, hashes []string) (dto.BlockHeaders, error) {
+	log.FromContext(c).Debugf("[uploadSession.BlockHeadersOfContents] hashes: %v", hashes)
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	sessions, err := s.sessionRepository.SessionsByContentHashes(c, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers dto.BlockHeaders
+	for _, session := range dto.NewUploadSessionsFromModel(sessions) {
+		for _, header := range session.Parts.BlockHeaders() {
+			if slices.Contains(hashes, header.Hash) {
+				headers = append(headers, header)
+			}
+		}
+	}
+	return headers, nil
+}
@@ -0,0 +1,178 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/message"
+	"github.com/ISSuh/sos/domain/service/object"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/validation"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type UploadSweeper interface {
+	Run(c context.Context, interval time.Duration)
+	Sweep(c context.Context) error
+}
+
+type uploadSweeper struct {
+	metadataRequestor rpc.MetadataRegistryRequestor
+	storageCluster    *object.StorageCluster
+}
+
+func NewUploadSweeper(
+	metadataRequestor rpc.MetadataRegistryRequestor, storageCluster *object.StorageCluster,
+) (UploadSweeper, error) {
+	switch {
+	case validation.IsNil(metadataRequestor):
+		return nil, errors.New("MetadataRegistry requestor is nil")
+	case validation.IsNil(storageCluster):
+		return nil, errors.New("BlockStorage cluster is nil")
+	}
+
+	return &uploadSweeper{
+		metadataRequestor: metadataRequestor,
+		storageCluster:    storageCluster,
+	}, nil
+}
+
+// Run sweeps expired upload sessions and version reservations every interval until the context is done.
+func (s *uploadSweeper) Run(c context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+			if err := s.Sweep(c); err != nil {
+				log.FromContext(c).Errorf("[uploadSweeper.Run] sweep fail. %s", err.Error())
+			}
+		}
+	}
+}
+
+func (s *uploadSweeper) Sweep(c context.Context) error {
+	msg := rpcmessage.UploadSessionRequest{
+		Now: timestamppb.New(time.Now()),
+	}
+
+	resp, err := s.metadataRequestor.FindExpiredUploadSessions(c, &msg)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range message.ToUploadSessionListDTO(resp) {
+		log.FromContext(c).Debugf("[uploadSweeper.Sweep] expired upload session: %d", session.ID)
+
+		// a concurrent complete or abort may have removed the session first
+		err := releaseUploadSession(c, s.metadataRequestor, s.storageCluster, &session)
+		if err != nil && !errors.Is(err, soserror.NotFound) {
+			return err
+		}
+	}
+
+	// the versions reserved by the expired sessions and by uploads that never finished
+	req := rpcmessage.ExpiredVersionRequest{
+		Now: msg.Now,
+	}
+
+	// the blocks recorded on the released versions are owned by the sweeper from then on
+	released, err := s.metadataRequestor.ReleaseExpiredVersions(c, &req)
+	if err != nil {
+		return err
+	}
+
+	deleter := object.NewDeleter(s.metadataRequestor, s.storageCluster)
+	return deleter.DeleteBlocks(context.WithoutCancel(c), message.ToBlockHeaderListDTO(released))
+}
+
+// releaseUploadSession removes the session and deletes the blocks of its parts. Whoever removes the session
+// owns its blocks, and they are kept when the version reserved by the session was committed with them.
+func releaseUploadSession(
+	c context.Context, metadataRequestor rpc.MetadataRegistryRequestor, storageCluster *object.StorageCluster,
+	session *dto.UploadSession,
+) error {
+	req := rpcmessage.UploadSessionRequest{
+		UploadID: session.ID.ToInt64(),
+	}
+
+	if err := metadataRequestor.DeleteUploadSession(c, &req); err != nil {
+		return err
+	}
+
+	reserved := &dto.Object{
+		ID:         session.ObjectID,
+		Group:      session.Group,
+		Partition:  session.Partition,
+		Name:       session.Name,
+		Path:       session.Path,
+		VersionNum: session.Version,
+	}
+
+	// a reservation that is gone was either released once expired, or committed by a complete
+	_, err := metadataRequestor.AbortVersion(context.WithoutCancel(c), message.FromObjectDTO(reserved))
+	switch {
+	case errors.Is(err, soserror.NotFound):
+		committed, err := versionCommitted(c, metadataRequestor, reserved)
+		if err != nil || committed {
+			return err
+		}
+	case err != nil:
+		return err
+	}
+
+	deleter := object.NewDeleter(metadataRequestor, storageCluster)
+	return deleter.DeleteBlocks(context.WithoutCancel(c), session.Parts.BlockHeaders())
+}
+
+func versionCommitted(c context.Context, metadataRequestor rpc.MetadataRegistryRequestor, reserved *dto.Object) (bool, error) {
+	msg := rpcmessage.ObjectMetadataRequest{
+		ObjectID:  reserved.ID.ToInt64(),
+		Group:     reserved.Group,
+		Partition: reserved.Partition,
+		Path:      reserved.Path,
+	}
+
+	resp, err := metadataRequestor.GetByObjectID(c, &msg)
+	switch {
+	case errors.Is(err, soserror.NotFound):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	// only the committed versions are visible
+	metadata := message.ToObjectMetadataDTO(resp)
+	return metadata.ID.IsValid() && metadata.Versions.HasVersion(reserved.VersionNum), nil
+}
@@ -0,0 +1,229 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/model/message"
+	"github.com/ISSuh/sos/domain/service"
+	"github.com/ISSuh/sos/domain/service/object"
+	local "github.com/ISSuh/sos/infrastructure/persistence/database/local"
+	memorystorage "github.com/ISSuh/sos/infrastructure/persistence/objectstorage/memory"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
+	"github.com/ISSuh/sos/internal/app/standalone"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/generator"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// testBlockSize splits the bodies of the tests into a few blocks.
+const testBlockSize = 4
+
+// sweepCluster is a metadata registry and a single block storage node kept in memory, wired as
+// the standalone server does.
+type sweepCluster struct {
+	registry rpc.MetadataRegistryRequestor
+	storage  rpc.BlockStorageRequestor
+	cluster  *object.StorageCluster
+	sweeper  service.UploadSweeper
+}
+
+func newSweepCluster(t *testing.T) *sweepCluster {
+	t.Helper()
+	generator.InitIdentifier(1)
+
+	metadataRepo, err := local.NewLocalObjectMetadata()
+	must(t, err)
+	directoryRepo, err := local.NewLocalObjectDirectory()
+	must(t, err)
+	sessionRepo, err := local.NewLocalUploadSession()
+	must(t, err)
+	credentialRepo, err := local.NewLocalCredential()
+	must(t, err)
+	policyRepo, err := local.NewLocalPolicy()
+	must(t, err)
+	storageRepo, err := memorystorage.NewLocalObjectStorage()
+	must(t, err)
+
+	metadataService, err := service.NewObjectMetadata(metadataRepo, directoryRepo)
+	must(t, err)
+	sessionService, err := service.NewUploadSession(sessionRepo)
+	must(t, err)
+	credentialService, err := service.NewCredential(credentialRepo)
+	must(t, err)
+	policyService, err := service.NewPolicy(policyRepo)
+	must(t, err)
+	storageService, err := service.NewObjectStorage(storageRepo)
+	must(t, err)
+
+	registry, err := standalone.NewMetadataRegistry(metadataService, sessionService, credentialService, policyService)
+	must(t, err)
+	storage, err := standalone.NewBlockStorage(storageService)
+	must(t, err)
+
+	nodes := []object.StorageNode{
+		{Node: entity.Node{Host: "memory"}, Requestor: storage},
+	}
+	cluster, err := object.NewStorageCluster(nodes, 1, nil, false, nil, testBlockSize, 2)
+	must(t, err)
+
+	sweeper, err := service.NewUploadSweeper(registry, cluster)
+	must(t, err)
+
+	return &sweepCluster{
+		registry: registry,
+		storage:  storage,
+		cluster:  cluster,
+		sweeper:  sweeper,
+	}
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (s *sweepCluster) reserve(t *testing.T, name string, expiresAt time.Time) *dto.Object {
+	t.Helper()
+
+	object := &dto.Object{
+		ID:        entity.NewObjectID(),
+		Group:     "group",
+		Partition: "partition",
+		Path:      "/sweep",
+		Name:      name,
+		ExpiresAt: expiresAt,
+	}
+
+	resp, err := s.registry.ReserveVersion(context.Background(), message.FromObjectDTO(object))
+	must(t, err)
+	return message.ToObjectDTO(resp)
+}
+
+// upload writes the body for the reserved version and records every block on it, as the explorer does.
+func (s *sweepCluster) upload(reserved *dto.Object, body []byte) (dto.BlockHeaders, error) {
+	uploader := object.NewUploader(s.cluster, entity.ErasureCoding{}, "", object.Keys{})
+	uploader.RecordTo(func(c context.Context, blockHeader dto.BlockHeader) error {
+		record := *reserved
+		record.BlockHeaders = dto.BlockHeaders{blockHeader}
+		return s.registry.RecordBlocks(c, message.FromObjectDTO(&record))
+	})
+	return uploader.Upload(context.Background(), reserved.ID, io.NopCloser(bytes.NewReader(body)))
+}
+
+func (s *sweepCluster) storedBlocks(t *testing.T) int {
+	t.Helper()
+
+	req := &rpcmessage.ListBlocksRequest{
+		Before: timestamppb.New(time.Now().Add(time.Hour)),
+	}
+
+	stored := 0
+	err := s.storage.ListBlocks(context.Background(), req, func(*message.BlockHeader) error {
+		stored++
+		return nil
+	})
+	must(t, err)
+	return stored
+}
+
+// An explorer that stops between writing the blocks and committing leaves a pending version
+// behind. Once it expires, the sweeper releases it and deletes the blocks recorded on it.
+func TestSweepDeletesBlocksOfExpiredVersion(t *testing.T) {
+	s := newSweepCluster(t)
+	reserved := s.reserve(t, "expired", time.Now().Add(-time.Second))
+
+	headers, err := s.upload(reserved, []byte("ten bytes!"))
+	must(t, err)
+	if len(headers) != 3 {
+		t.Fatalf("uploaded %d blocks, want 3", len(headers))
+	}
+	if stored := s.storedBlocks(t); stored != 3 {
+		t.Fatalf("%d blocks stored before the sweep, want 3", stored)
+	}
+
+	must(t, s.sweeper.Sweep(context.Background()))
+
+	if stored := s.storedBlocks(t); stored != 0 {
+		t.Errorf("%d blocks stored after the sweep, want 0", stored)
+	}
+
+	req := &rpcmessage.ObjectMetadataRequest{
+		ObjectID:  reserved.ID.ToInt64(),
+		Group:     reserved.Group,
+		Partition: reserved.Partition,
+		Path:      reserved.Path,
+	}
+	if _, err := s.registry.GetByObjectID(context.Background(), req); !errors.Is(err, soserror.NotFound) {
+		t.Errorf("metadata of the released version: err = %v, want not found", err)
+	}
+}
+
+// The blocks of a committed version belong to it, so the sweep leaves them alone.
+func TestSweepKeepsBlocksOfCommittedVersion(t *testing.T) {
+	s := newSweepCluster(t)
+	reserved := s.reserve(t, "committed", time.Now().Add(time.Hour))
+
+	headers, err := s.upload(reserved, []byte("ten bytes!"))
+	must(t, err)
+
+	reserved.Size = headers.Size()
+	reserved.BlockHeaders = headers
+	_, err = s.registry.CommitVersion(context.Background(), message.FromObjectDTO(reserved))
+	must(t, err)
+
+	must(t, s.sweeper.Sweep(context.Background()))
+
+	if stored := s.storedBlocks(t); stored != 3 {
+		t.Errorf("%d blocks stored after the sweep, want 3", stored)
+	}
+}
+
+// A block written after the version was released can not be recorded on it anymore, so the
+// upload fails and deletes the block itself.
+func TestUploadDeletesBlocksOfReleasedVersion(t *testing.T) {
+	s := newSweepCluster(t)
+	reserved := s.reserve(t, "released", time.Now().Add(-time.Second))
+	must(t, s.sweeper.Sweep(context.Background()))
+
+	_, err := s.upload(reserved, []byte("ten bytes!"))
+	if !errors.Is(err, soserror.NotFound) {
+		t.Fatalf("upload for the released version: err = %v, want not found", err)
+	}
+
+	if stored := s.storedBlocks(t); stored != 0 {
+		t.Errorf("%d blocks stored after the failed upload, want 0", stored)
+	}
+}
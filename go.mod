@@ -7,7 +7,10 @@ toolchain go1.22.9
 require (
 	github.com/alexflint/go-arg v1.4.3
 	github.com/bwmarrin/snowflake v0.3.0
+	github.com/golang/snappy v0.0.4
 	github.com/gorilla/mux v1.8.1
+	github.com/klauspost/compress v1.17.11
+	github.com/klauspost/reedsolomon v1.10.0
 	github.com/syndtr/goleveldb v1.0.0
 	go.elastic.co/apm v1.15.0
 	go.elastic.co/apm/module/apmgrpc v1.15.0
@@ -27,10 +30,9 @@ require (
 	github.com/elastic/go-licenser v0.3.1 // indirect
 	github.com/elastic/go-sysinfo v1.7.1 // indirect
 	github.com/elastic/go-windows v1.0.0 // indirect
-	github.com/golang/snappy v0.0.4 // indirect
 	github.com/jcchavezs/porto v0.1.0 // indirect
 	github.com/joeshaw/multierror v0.0.0-20140124173710-69b34d4ec901 // indirect
-	github.com/klauspost/compress v1.17.11 // indirect
+	github.com/klauspost/cpuid/v2 v2.2.5 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/montanaflynn/stats v0.7.1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
@@ -0,0 +1,86 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/repository"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
+)
+
+type localCredential struct {
+	mutex sync.Mutex
+	db    map[string]*entity.Credential
+}
+
+func NewLocalCredential() (repository.Credential, error) {
+	return &localCredential{
+		db: make(map[string]*entity.Credential),
+	}, nil
+}
+
+func (d *localCredential) Put(c context.Context, credential *entity.Credential) error {
+	log.FromContext(c).Debugf("[localCredential.Put] accessKey: %s", credential.AccessKey())
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	copied := *credential
+	if stored, exist := d.db[credential.AccessKey()]; exist {
+		copied.CreatedAt = stored.CreatedAt
+	}
+
+	d.db[credential.AccessKey()] = &copied
+	return nil
+}
+
+func (d *localCredential) Delete(c context.Context, accessKey string) error {
+	log.FromContext(c).Debugf("[localCredential.Delete] accessKey: %s", accessKey)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, exist := d.db[accessKey]; !exist {
+		return soserror.NewNotFoundError(fmt.Errorf("can not find credential"))
+	}
+
+	delete(d.db, accessKey)
+	return nil
+}
+
+func (d *localCredential) CredentialByAccessKey(c context.Context, accessKey string) (*entity.Credential, error) {
+	log.FromContext(c).Debugf("[localCredential.CredentialByAccessKey] accessKey: %s", accessKey)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	credential, exist := d.db[accessKey]
+	if !exist {
+		return nil, soserror.NewNotFoundError(fmt.Errorf("can not find credential"))
+	}
+
+	copied := *credential
+	return &copied, nil
+}
@@ -25,44 +25,148 @@ package database
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/repository"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
 )
 
 type localObjectDirectory struct {
+	mutex sync.Mutex
+	db    map[string]*entity.Directory
 }
 
 func NewLocalObjectDirectory() (repository.ObjectDirectory, error) {
-	return &localObjectDirectory{}, nil
+	return &localObjectDirectory{
+		db: make(map[string]*entity.Directory),
+	}, nil
 }
 
-func (r *localObjectDirectory) Create(c context.Context, metadata *entity.Directory) error {
+func (d *localObjectDirectory) AddObject(
+	c context.Context, group, partition, path string, objectID entity.ObjectID, now time.Time,
+) error {
+	log.FromContext(c).Debugf("[localObjectDirectory.AddObject] group: %s, partition: %s, path: %s, objectID: %d", group, partition, path, objectID)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	directory := d.directory(group, partition, path, now)
+	directory.AddObjectID(objectID)
+	directory.IncreaseRevision()
+	directory.ModifiedAt = now
 	return nil
 }
 
-func (r *localObjectDirectory) Delete(c context.Context, metadata *entity.Directory) error {
+func (d *localObjectDirectory) DeleteObject(
+	c context.Context, group, partition, path string, objectID entity.ObjectID, now time.Time,
+) error {
+	log.FromContext(c).Debugf("[localObjectDirectory.DeleteObject] group: %s, partition: %s, path: %s, objectID: %d", group, partition, path, objectID)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	directory, exist := d.db[d.makeKey(group, partition, path)]
+	if !exist {
+		return soserror.NewNotFoundError(fmt.Errorf("can not find directory"))
+	}
+
+	directory.DeleteObjectID(objectID)
+	directory.IncreaseRevision()
+	directory.ModifiedAt = now
 	return nil
 }
 
-func (r *localObjectDirectory) AddObject(c context.Context, metadata *entity.Directory) error {
+func (d *localObjectDirectory) AddSubDirectory(
+	c context.Context, group, partition, path, name string, now time.Time,
+) error {
+	log.FromContext(c).Debugf("[localObjectDirectory.AddSubDirectory] group: %s, partition: %s, path: %s, name: %s", group, partition, path, name)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	directory := d.directory(group, partition, path, now)
+	directory.AddChild(name)
+	directory.IncreaseRevision()
+	directory.ModifiedAt = now
 	return nil
 }
 
-func (r *localObjectDirectory) DeleteObject(c context.Context, metadata *entity.Directory) error {
+func (d *localObjectDirectory) DeleteSubDirectory(
+	c context.Context, group, partition, path, name string, revision int64, now time.Time,
+) error {
+	log.FromContext(c).Debugf("[localObjectDirectory.DeleteSubDirectory] group: %s, partition: %s, path: %s, name: %s, revision: %d", group, partition, path, name, revision)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	directory, exist := d.db[d.makeKey(group, partition, path)]
+	if !exist {
+		return soserror.NewNotFoundError(fmt.Errorf("can not find directory"))
+	}
+
+	if directory.Revision() != revision {
+		return soserror.NewConflictError(fmt.Errorf("directory is changed. revision %d, expected %d", directory.Revision(), revision))
+	}
+
+	directory.DeleteChild(name)
+	directory.IncreaseRevision()
+	directory.ModifiedAt = now
 	return nil
 }
 
-func (r *localObjectDirectory) AddSubDirectory(c context.Context, metadata *entity.Directory) error {
-	return nil
+func (d *localObjectDirectory) DeleteIfEmpty(c context.Context, group, partition, path string) (bool, error) {
+	log.FromContext(c).Debugf("[localObjectDirectory.DeleteIfEmpty] group: %s, partition: %s, path: %s", group, partition, path)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	key := d.makeKey(group, partition, path)
+	directory, exist := d.db[key]
+	if !exist || !directory.Empty() {
+		return false, nil
+	}
+
+	delete(d.db, key)
+	return true, nil
 }
 
-func (r *localObjectDirectory) DeleteSubDirectory(c context.Context, metadata *entity.Directory) error {
-	return nil
+func (d *localObjectDirectory) FindMetadata(c context.Context, group, partition, path string) (*entity.Directory, error) {
+	log.FromContext(c).Debugf("[localObjectDirectory.FindMetadata] group: %s, partition: %s, path: %s", group, partition, path)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	directory, exist := d.db[d.makeKey(group, partition, path)]
+	if !exist {
+		return nil, soserror.NewNotFoundError(fmt.Errorf("can not find directory"))
+	}
+
+	// the stored directory is changed in place, so the caller gets its own copy
+	copied := entity.NewDirectoryBuilder().
+		Group(directory.Group()).
+		Partition(directory.Partition()).
+		Path(directory.Path()).
+		Objects(slices.Clone(directory.Objects())).
+		SubDirectory(slices.Clone(directory.SubDirectory())).
+		Revision(directory.Revision()).
+		CreatedAt(directory.CreatedAt).
+		ModifiedAt(directory.ModifiedAt).
+		Build()
+	return copied, nil
 }
 
-func (r *localObjectDirectory) FindMetadata(c context.Context, group, partition, path string) (*entity.Directory, error) {
-	return nil, nil
+func (d *localObjectDirectory) directory(group, partition, path string, now time.Time) *entity.Directory {
+	key := d.makeKey(group, partition, path)
+	directory, exist := d.db[key]
+	if !exist {
+		directory = entity.NewDirectoryBuilder().
+			Group(group).
+			Partition(partition).
+			Path(path).
+			CreatedAt(now).
+			ModifiedAt(now).
+			Build()
+		d.db[key] = directory
+	}
+	return directory
 }
 
 func (d *localObjectDirectory) makeKey(group, partition, path string) string {
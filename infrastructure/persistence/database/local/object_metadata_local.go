@@ -23,16 +23,23 @@
 package database
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/repository"
+	soserror "github.com/ISSuh/sos/internal/error"
 	"github.com/ISSuh/sos/internal/log"
 )
 
+// localObjectMetadata stores copies of the metadata, so a change is only seen once it is written.
 type localObjectMetadata struct {
-	db map[string]map[int64]*entity.ObjectMetadata
+	mutex sync.Mutex
+	db    map[string]map[int64]*entity.ObjectMetadata
 }
 
 func NewLocalObjectMetadata() (repository.ObjectMetadata, error) {
@@ -43,6 +50,9 @@ func NewLocalObjectMetadata() (repository.ObjectMetadata, error) {
 
 func (d *localObjectMetadata) Create(c context.Context, metadata *entity.ObjectMetadata) error {
 	log.FromContext(c).Debugf("[localObjectMetadata.Create] metadata: %+v", metadata)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	key := d.makeKey(
 		metadata.Group(), metadata.Partition(), metadata.Path(),
 	)
@@ -51,40 +61,101 @@ func (d *localObjectMetadata) Create(c context.Context, metadata *entity.ObjectM
 		d.db[key] = make(map[int64]*entity.ObjectMetadata)
 	}
 
-	d.db[key][metadata.ID().ToInt64()] = metadata
+	for _, v := range d.db[key] {
+		if v.Name() == metadata.Name() {
+			return soserror.NewConflictError(fmt.Errorf("object already exist. %s", metadata.Name()))
+		}
+	}
+
+	stored := metadata.Clone()
+	d.db[key][metadata.ID().ToInt64()] = &stored
 	return nil
 }
 
 func (d *localObjectMetadata) Update(c context.Context, metadata *entity.ObjectMetadata) error {
 	log.FromContext(c).Debugf("[localObjectMetadata.Update] metadata: %+v", metadata)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	key := d.makeKey(
 		metadata.Group(), metadata.Partition(), metadata.Path(),
 	)
-	_, exist := d.db[key]
+	previous, exist := d.db[key][metadata.ID().ToInt64()]
 	if !exist {
-		return fmt.Errorf("metadata not exist")
+		return soserror.NewNotFoundError(fmt.Errorf("can not find metadata"))
 	}
 
-	d.db[key][metadata.ID().ToInt64()] = metadata
+	if previous.Revision() != metadata.Revision() {
+		return soserror.NewConflictError(fmt.Errorf("metadata is modified. revision %d", previous.Revision()))
+	}
+
+	metadata.IncreaseRevision()
+	stored := metadata.Clone()
+	d.db[key][metadata.ID().ToInt64()] = &stored
 	return nil
 }
 
 func (d *localObjectMetadata) Delete(c context.Context, metadata *entity.ObjectMetadata) error {
 	log.FromContext(c).Debugf("[localObjectMetadata.Delete] metadata: %+v", metadata)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	key := d.makeKey(
 		metadata.Group(), metadata.Partition(), metadata.Path(),
 	)
-	_, exist := d.db[key]
+	previous, exist := d.db[key][metadata.ID().ToInt64()]
 	if !exist {
-		return fmt.Errorf("metadata not exist")
+		return soserror.NewNotFoundError(fmt.Errorf("can not find metadata"))
+	}
+
+	if previous.Revision() != metadata.Revision() {
+		return soserror.NewConflictError(fmt.Errorf("metadata is modified. revision %d", previous.Revision()))
 	}
 
 	delete(d.db[key], metadata.ID().ToInt64())
 	return nil
 }
 
+func (d *localObjectMetadata) Move(c context.Context, metadata *entity.ObjectMetadata, path, name string) error {
+	log.FromContext(c).Debugf("[localObjectMetadata.Move] metadata: %+v, path: %s, name: %s", metadata, path, name)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	key := d.makeKey(
+		metadata.Group(), metadata.Partition(), metadata.Path(),
+	)
+	previous, exist := d.db[key][metadata.ID().ToInt64()]
+	if !exist {
+		return soserror.NewNotFoundError(fmt.Errorf("can not find metadata"))
+	}
+
+	if previous.Revision() != metadata.Revision() {
+		return soserror.NewConflictError(fmt.Errorf("metadata is modified. revision %d", previous.Revision()))
+	}
+
+	movedKey := d.makeKey(metadata.Group(), metadata.Partition(), path)
+	for _, v := range d.db[movedKey] {
+		if v.Name() == name && v.ID() != metadata.ID() {
+			return soserror.NewConflictError(fmt.Errorf("object already exist. %s", name))
+		}
+	}
+
+	if _, exist := d.db[movedKey]; !exist {
+		d.db[movedKey] = make(map[int64]*entity.ObjectMetadata)
+	}
+
+	delete(d.db[key], metadata.ID().ToInt64())
+	metadata.Move(path, name)
+	metadata.IncreaseRevision()
+	stored := metadata.Clone()
+	d.db[movedKey][metadata.ID().ToInt64()] = &stored
+	return nil
+}
+
 func (d *localObjectMetadata) MetadataByObjectName(c context.Context, group, partition, path, name string) (*entity.ObjectMetadata, error) {
 	log.FromContext(c).Debugf("[localObjectMetadata.MetadataByObjectID] group: %s, partition: %s, path: %s, name: %s", group, partition, path, name)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
 
 	key := d.makeKey(group, partition, path)
 	subDB, exist := d.db[key]
@@ -94,7 +165,8 @@ func (d *localObjectMetadata) MetadataByObjectName(c context.Context, group, par
 
 	for _, v := range subDB {
 		if v.Name() == name {
-			return v, nil
+			metadata := v.Clone()
+			return &metadata, nil
 		}
 	}
 	return nil, nil
@@ -102,23 +174,166 @@ func (d *localObjectMetadata) MetadataByObjectName(c context.Context, group, par
 
 func (d *localObjectMetadata) MetadataByObjectID(c context.Context, group, partition, path string, objectID int64) (*entity.ObjectMetadata, error) {
 	log.FromContext(c).Debugf("[localObjectMetadata.MetadataByObjectID] group: %s, partition: %s, path: %s, objectID: %d", group, partition, path, objectID)
-	key := d.makeKey(group, partition, path)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
 
-	_, exist := d.db[key]
+	key := d.makeKey(group, partition, path)
+	stored, exist := d.db[key][objectID]
 	if !exist {
-		return nil, nil
+		return nil, soserror.NewNotFoundError(fmt.Errorf("can not find metadata"))
 	}
-	return d.db[key][objectID], nil
+
+	metadata := stored.Clone()
+	return &metadata, nil
 }
 
-func (d *localObjectMetadata) FindMetadata(c context.Context, group, partition, path string) (entity.ObjectMetadataList, error) {
-	log.FromContext(c).Debugf("[localObjectMetadata.FindMetadata] group: %s, partition: %s, path: %s", group, partition, path)
+func (d *localObjectMetadata) FindMetadata(
+	c context.Context, group, partition, path string, lastObjectID int64, limit int,
+) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[localObjectMetadata.FindMetadata] group: %s, partition: %s, path: %s, lastObjectID: %d, limit: %d", group, partition, path, lastObjectID, limit)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	key := d.makeKey(group, partition, path)
 	list := d.db[key]
 
-	var metadataList []entity.ObjectMetadata
-	for _, v := range list {
-		metadataList = append(metadataList, *v)
+	ids := make([]int64, 0, len(list))
+	for id := range list {
+		if id > lastObjectID {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	metadataList := make(entity.ObjectMetadataList, 0, len(ids))
+	for _, id := range ids {
+		metadataList = append(metadataList, list[id].Clone())
+	}
+	return metadataList, nil
+}
+
+func (d *localObjectMetadata) FindMetadataByTags(
+	c context.Context, group, partition string, expression entity.TagExpression, lastObjectID int64, limit int,
+) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[localObjectMetadata.FindMetadataByTags] group: %s, partition: %s, expression: %v, lastObjectID: %d, limit: %d", group, partition, expression.Terms(), lastObjectID, limit)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var matched []*entity.ObjectMetadata
+	for _, list := range d.db {
+		for id, metadata := range list {
+			if id <= lastObjectID || metadata.Group() != group || metadata.Partition() != partition {
+				continue
+			}
+
+			if expression.Match(metadata.Tags()) {
+				matched = append(matched, metadata)
+			}
+		}
+	}
+
+	slices.SortFunc(matched, func(a, b *entity.ObjectMetadata) int {
+		return cmp.Compare(a.ID().ToInt64(), b.ID().ToInt64())
+	})
+
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+
+	metadataList := make(entity.ObjectMetadataList, 0, len(matched))
+	for _, metadata := range matched {
+		metadataList = append(metadataList, metadata.Clone())
+	}
+	return metadataList, nil
+}
+
+func (d *localObjectMetadata) FindMetadataByStaleDataKey(
+	c context.Context, keyID string, lastObjectID int64, limit int,
+) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[localObjectMetadata.FindMetadataByStaleDataKey] keyID: %s, lastObjectID: %d, limit: %d", keyID, lastObjectID, limit)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var matched []*entity.ObjectMetadata
+	for _, list := range d.db {
+		for id, metadata := range list {
+			if id > lastObjectID && metadata.HasStaleDataKey(keyID) {
+				matched = append(matched, metadata)
+			}
+		}
+	}
+
+	slices.SortFunc(matched, func(a, b *entity.ObjectMetadata) int {
+		return cmp.Compare(a.ID().ToInt64(), b.ID().ToInt64())
+	})
+
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+
+	metadataList := make(entity.ObjectMetadataList, 0, len(matched))
+	for _, metadata := range matched {
+		metadataList = append(metadataList, metadata.Clone())
+	}
+	return metadataList, nil
+}
+
+func (d *localObjectMetadata) MetadataByObjectIDs(c context.Context, objectIDs []int64) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[localObjectMetadata.MetadataByObjectIDs] objectIDs: %v", objectIDs)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var metadataList entity.ObjectMetadataList
+	for _, list := range d.db {
+		for _, objectID := range objectIDs {
+			if metadata, exist := list[objectID]; exist {
+				metadataList = append(metadataList, metadata.Clone())
+			}
+		}
+	}
+	return metadataList, nil
+}
+
+func (d *localObjectMetadata) MetadataByContentHashes(c context.Context, hashes []string) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[localObjectMetadata.MetadataByContentHashes] hashes: %v", hashes)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var metadataList entity.ObjectMetadataList
+	for _, list := range d.db {
+		for _, metadata := range list {
+			referenced := slices.ContainsFunc(metadata.Versions(), func(version entity.Version) bool {
+				return slices.ContainsFunc(version.BlockHeaders(), func(header entity.BlockHeader) bool {
+					return slices.Contains(hashes, header.Hash())
+				})
+			})
+			if referenced {
+				metadataList = append(metadataList, metadata.Clone())
+			}
+		}
+	}
+	return metadataList, nil
+}
+
+func (d *localObjectMetadata) MetadataWithExpiredVersions(c context.Context, now time.Time) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[localObjectMetadata.MetadataWithExpiredVersions] now: %s", now)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var metadataList entity.ObjectMetadataList
+	for _, list := range d.db {
+		for _, metadata := range list {
+			expired := slices.ContainsFunc(metadata.Versions(), func(version entity.Version) bool {
+				return version.IsExpired(now)
+			})
+			if expired {
+				metadataList = append(metadataList, metadata.Clone())
+			}
+		}
 	}
 	return metadataList, nil
 }
@@ -0,0 +1,94 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/repository"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
+)
+
+type policyKey struct {
+	group     string
+	partition string
+}
+
+type localPolicy struct {
+	mutex sync.Mutex
+	db    map[policyKey]*entity.Policy
+}
+
+func NewLocalPolicy() (repository.Policy, error) {
+	return &localPolicy{
+		db: make(map[policyKey]*entity.Policy),
+	}, nil
+}
+
+func (d *localPolicy) Put(c context.Context, policy *entity.Policy) error {
+	log.FromContext(c).Debugf("[localPolicy.Put] group: %s, partition: %s", policy.Group(), policy.Partition())
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	key := policyKey{group: policy.Group(), partition: policy.Partition()}
+
+	copied := *policy
+	if stored, exist := d.db[key]; exist {
+		copied.CreatedAt = stored.CreatedAt
+	}
+
+	d.db[key] = &copied
+	return nil
+}
+
+func (d *localPolicy) Delete(c context.Context, group, partition string) error {
+	log.FromContext(c).Debugf("[localPolicy.Delete] group: %s, partition: %s", group, partition)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	key := policyKey{group: group, partition: partition}
+	if _, exist := d.db[key]; !exist {
+		return soserror.NewNotFoundError(fmt.Errorf("can not find policy"))
+	}
+
+	delete(d.db, key)
+	return nil
+}
+
+func (d *localPolicy) PolicyByPartition(c context.Context, group, partition string) (*entity.Policy, error) {
+	log.FromContext(c).Debugf("[localPolicy.PolicyByPartition] group: %s, partition: %s", group, partition)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	policy, exist := d.db[policyKey{group: group, partition: partition}]
+	if !exist {
+		return nil, soserror.NewNotFoundError(fmt.Errorf("can not find policy"))
+	}
+
+	copied := *policy
+	return &copied, nil
+}
@@ -0,0 +1,149 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/repository"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
+)
+
+type localUploadSession struct {
+	mutex sync.Mutex
+	db    map[int64]*entity.UploadSession
+}
+
+func NewLocalUploadSession() (repository.UploadSession, error) {
+	return &localUploadSession{
+		db: make(map[int64]*entity.UploadSession),
+	}, nil
+}
+
+func (d *localUploadSession) Create(c context.Context, session *entity.UploadSession) error {
+	log.FromContext(c).Debugf("[localUploadSession.Create] session: %+v", session)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, exist := d.db[session.ID().ToInt64()]; exist {
+		return fmt.Errorf("upload session already exist")
+	}
+
+	d.db[session.ID().ToInt64()] = session
+	return nil
+}
+
+func (d *localUploadSession) Delete(c context.Context, uploadID int64) error {
+	log.FromContext(c).Debugf("[localUploadSession.Delete] uploadID: %d", uploadID)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, exist := d.db[uploadID]; !exist {
+		return soserror.NewNotFoundError(fmt.Errorf("can not find upload session"))
+	}
+
+	delete(d.db, uploadID)
+	return nil
+}
+
+func (d *localUploadSession) PutPart(c context.Context, uploadID int64, part *entity.UploadPart, now time.Time) error {
+	log.FromContext(c).Debugf("[localUploadSession.PutPart] uploadID: %d, part: %d", uploadID, part.Number())
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	session, exist := d.db[uploadID]
+	if !exist || session.IsExpired(now) {
+		return soserror.NewNotFoundError(fmt.Errorf("can not find upload session"))
+	}
+
+	session.PutPart(*part)
+	session.ModifiedAt = now
+	return nil
+}
+
+func (d *localUploadSession) SessionByID(c context.Context, uploadID int64) (*entity.UploadSession, error) {
+	log.FromContext(c).Debugf("[localUploadSession.SessionByID] uploadID: %d", uploadID)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	session, exist := d.db[uploadID]
+	if !exist {
+		return nil, soserror.NewNotFoundError(fmt.Errorf("can not find upload session"))
+	}
+
+	copied := *session
+	return &copied, nil
+}
+
+func (d *localUploadSession) FindExpired(c context.Context, now time.Time) (entity.UploadSessions, error) {
+	log.FromContext(c).Debugf("[localUploadSession.FindExpired] now: %s", now)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var sessions entity.UploadSessions
+	for _, session := range d.db {
+		if session.IsExpired(now) {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (d *localUploadSession) SessionsByObjectIDs(c context.Context, objectIDs []int64) (entity.UploadSessions, error) {
+	log.FromContext(c).Debugf("[localUploadSession.SessionsByObjectIDs] objectIDs: %v", objectIDs)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var sessions entity.UploadSessions
+	for _, session := range d.db {
+		if slices.Contains(objectIDs, session.ObjectID().ToInt64()) {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (d *localUploadSession) SessionsByContentHashes(c context.Context, hashes []string) (entity.UploadSessions, error) {
+	log.FromContext(c).Debugf("[localUploadSession.SessionsByContentHashes] hashes: %v", hashes)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var sessions entity.UploadSessions
+	for _, session := range d.db {
+		referenced := slices.ContainsFunc(session.Parts(), func(part entity.UploadPart) bool {
+			return slices.ContainsFunc(part.BlockHeaders(), func(header entity.BlockHeader) bool {
+				return slices.Contains(hashes, header.Hash())
+			})
+		})
+		if referenced {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
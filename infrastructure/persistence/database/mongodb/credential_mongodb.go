@@ -0,0 +1,152 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/repository"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	credentialCollectionName = "credential"
+)
+
+type mongoDBCredential struct {
+	db *persistence.MongoDB
+}
+
+func NewMongoDBCredential(db *persistence.MongoDB) (repository.Credential, error) {
+	return &mongoDBCredential{
+		db: db,
+	}, nil
+}
+
+func (d *mongoDBCredential) Put(c context.Context, credential *entity.Credential) error {
+	log.FromContext(c).Debugf("[mongoDBCredential.Put] accessKey: %s", credential.AccessKey())
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case credential.AccessKey() == "":
+		return fmt.Errorf("access key is empty")
+	}
+
+	collection, err := d.db.Collection(credentialCollectionName)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		{Key: "access_key", Value: credential.AccessKey()},
+	}
+
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "secret_key", Value: credential.SecretKey()},
+			{Key: "principal", Value: credential.Principal()},
+			{Key: "modified_at", Value: credential.ModifiedAt},
+		}},
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "created_at", Value: credential.CreatedAt},
+		}},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := collection.UpdateOne(c, filter, update, opts); err != nil {
+		return fmt.Errorf("failed to upsert data: %w", err)
+	}
+
+	return nil
+}
+
+func (d *mongoDBCredential) Delete(c context.Context, accessKey string) error {
+	log.FromContext(c).Debugf("[mongoDBCredential.Delete] accessKey: %s", accessKey)
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case accessKey == "":
+		return fmt.Errorf("access key is empty")
+	}
+
+	collection, err := d.db.Collection(credentialCollectionName)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		{Key: "access_key", Value: accessKey},
+	}
+
+	res, err := collection.DeleteOne(c, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete data: %w", err)
+	}
+
+	if res.DeletedCount == 0 {
+		return soserror.NewNotFoundError(fmt.Errorf("can not find credential"))
+	}
+
+	return nil
+}
+
+func (d *mongoDBCredential) CredentialByAccessKey(c context.Context, accessKey string) (*entity.Credential, error) {
+	log.FromContext(c).Debugf("[mongoDBCredential.CredentialByAccessKey] accessKey: %s", accessKey)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	case accessKey == "":
+		return nil, fmt.Errorf("access key is empty")
+	}
+
+	collection, err := d.db.Collection(credentialCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "access_key", Value: accessKey},
+	}
+
+	res := collection.FindOne(c, filter)
+	if res.Err() != nil {
+		if res.Err() == mongo.ErrNoDocuments {
+			return nil, soserror.NewNotFoundError(fmt.Errorf("can not find credential"))
+		}
+		return nil, fmt.Errorf("failed to find credential: %w", res.Err())
+	}
+
+	var credential entity.Credential
+	if err := res.Decode(&credential); err != nil {
+		return nil, fmt.Errorf("failed to decode credential: %w", err)
+	}
+
+	return &credential, nil
+}
@@ -0,0 +1,239 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/repository"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	objectDirectoryCollectionName = "object_directory"
+)
+
+type mongoDBObjectDirectory struct {
+	db *persistence.MongoDB
+}
+
+func NewMongoDBObjectDirectory(db *persistence.MongoDB) (repository.ObjectDirectory, error) {
+	return &mongoDBObjectDirectory{
+		db: db,
+	}, nil
+}
+
+func (d *mongoDBObjectDirectory) AddObject(
+	c context.Context, group, partition, path string, objectID entity.ObjectID, now time.Time,
+) error {
+	log.FromContext(c).Debugf("[mongoDBObjectDirectory.AddObject] group: %s, partition: %s, path: %s, objectID: %d", group, partition, path, objectID)
+	switch {
+	case !objectID.IsValid():
+		return fmt.Errorf("objectID is invalid. %d", objectID)
+	}
+
+	update := bson.D{
+		{Key: "$addToSet", Value: bson.D{{Key: "objects", Value: objectID}}},
+		{Key: "$set", Value: bson.D{{Key: "modified_at", Value: now}}},
+		{Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}},
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "sub_directory", Value: bson.A{}},
+			{Key: "created_at", Value: now},
+		}},
+	}
+	return d.update(c, group, partition, path, update, true)
+}
+
+func (d *mongoDBObjectDirectory) DeleteObject(
+	c context.Context, group, partition, path string, objectID entity.ObjectID, now time.Time,
+) error {
+	log.FromContext(c).Debugf("[mongoDBObjectDirectory.DeleteObject] group: %s, partition: %s, path: %s, objectID: %d", group, partition, path, objectID)
+	update := bson.D{
+		{Key: "$pull", Value: bson.D{{Key: "objects", Value: objectID}}},
+		{Key: "$set", Value: bson.D{{Key: "modified_at", Value: now}}},
+		{Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}},
+	}
+	return d.update(c, group, partition, path, update, false)
+}
+
+func (d *mongoDBObjectDirectory) AddSubDirectory(
+	c context.Context, group, partition, path, name string, now time.Time,
+) error {
+	log.FromContext(c).Debugf("[mongoDBObjectDirectory.AddSubDirectory] group: %s, partition: %s, path: %s, name: %s", group, partition, path, name)
+	switch {
+	case name == "":
+		return fmt.Errorf("directory name is empty")
+	}
+
+	update := bson.D{
+		{Key: "$addToSet", Value: bson.D{{Key: "sub_directory", Value: name}}},
+		{Key: "$set", Value: bson.D{{Key: "modified_at", Value: now}}},
+		{Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}},
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "objects", Value: bson.A{}},
+			{Key: "created_at", Value: now},
+		}},
+	}
+	return d.update(c, group, partition, path, update, true)
+}
+
+func (d *mongoDBObjectDirectory) DeleteSubDirectory(
+	c context.Context, group, partition, path, name string, revision int64, now time.Time,
+) error {
+	log.FromContext(c).Debugf("[mongoDBObjectDirectory.DeleteSubDirectory] group: %s, partition: %s, path: %s, name: %s, revision: %d", group, partition, path, name, revision)
+	update := bson.D{
+		{Key: "$pull", Value: bson.D{{Key: "sub_directory", Value: name}}},
+		{Key: "$set", Value: bson.D{{Key: "modified_at", Value: now}}},
+		{Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}},
+	}
+	return d.update(c, group, partition, path, update, false, d.revisionCondition(revision))
+}
+
+func (d *mongoDBObjectDirectory) DeleteIfEmpty(c context.Context, group, partition, path string) (bool, error) {
+	log.FromContext(c).Debugf("[mongoDBObjectDirectory.DeleteIfEmpty] group: %s, partition: %s, path: %s", group, partition, path)
+	switch {
+	case c == nil:
+		return false, fmt.Errorf("context is nil")
+	case group == "":
+		return false, fmt.Errorf("group is invalid")
+	case partition == "":
+		return false, fmt.Errorf("partition is empty")
+	}
+
+	collection, err := d.db.Collection(objectDirectoryCollectionName)
+	if err != nil {
+		return false, err
+	}
+
+	// the emptiness check is part of the filter so an entry added meanwhile keeps the directory
+	filter := bson.D{
+		{Key: "group", Value: group},
+		{Key: "partition", Value: partition},
+		{Key: "path", Value: path},
+		{Key: "objects", Value: bson.D{{Key: "$size", Value: 0}}},
+		{Key: "sub_directory", Value: bson.D{{Key: "$size", Value: 0}}},
+	}
+
+	res, err := collection.DeleteOne(c, filter)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete data: %w", err)
+	}
+
+	return res.DeletedCount > 0, nil
+}
+
+func (d *mongoDBObjectDirectory) FindMetadata(c context.Context, group, partition, path string) (*entity.Directory, error) {
+	log.FromContext(c).Debugf("[mongoDBObjectDirectory.FindMetadata] group: %s, partition: %s, path: %s", group, partition, path)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	case group == "":
+		return nil, fmt.Errorf("group is invalid")
+	case partition == "":
+		return nil, fmt.Errorf("partition is empty")
+	}
+
+	collection, err := d.db.Collection(objectDirectoryCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "group", Value: group},
+		{Key: "partition", Value: partition},
+		{Key: "path", Value: path},
+	}
+
+	res := collection.FindOne(c, filter)
+	if res.Err() != nil {
+		if res.Err() == mongo.ErrNoDocuments {
+			return nil, soserror.NewNotFoundError(fmt.Errorf("can not find directory"))
+		}
+		return nil, fmt.Errorf("failed to find directory: %w", res.Err())
+	}
+
+	var directory entity.Directory
+	if err := res.Decode(&directory); err != nil {
+		return nil, fmt.Errorf("failed to decode directory: %w", err)
+	}
+
+	return &directory, nil
+}
+
+// update applies the update to the directory. With conditions, a directory that does not match them
+// is reported as conflict, as the caller can not tell a changed directory from a deleted one.
+func (d *mongoDBObjectDirectory) update(
+	c context.Context, group, partition, path string, update bson.D, upsert bool, conditions ...bson.E,
+) error {
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case group == "":
+		return fmt.Errorf("group is invalid")
+	case partition == "":
+		return fmt.Errorf("partition is empty")
+	}
+
+	collection, err := d.db.Collection(objectDirectoryCollectionName)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		{Key: "group", Value: group},
+		{Key: "partition", Value: partition},
+		{Key: "path", Value: path},
+	}
+	filter = append(filter, conditions...)
+
+	res, err := collection.UpdateOne(c, filter, update, options.Update().SetUpsert(upsert))
+	if err != nil {
+		return fmt.Errorf("failed to update data: %w", err)
+	}
+
+	if res.MatchedCount == 0 && res.UpsertedCount == 0 {
+		if len(conditions) > 0 {
+			return soserror.NewConflictError(fmt.Errorf("directory is changed or deleted"))
+		}
+		return soserror.NewNotFoundError(fmt.Errorf("can not find directory"))
+	}
+
+	return nil
+}
+
+// revisionCondition matches the directory only at the revision it was read at. The directories
+// written before revisions were counted have no revision, which is read as 0.
+func (d *mongoDBObjectDirectory) revisionCondition(revision int64) bson.E {
+	if revision == 0 {
+		return bson.E{Key: "revision", Value: bson.D{{Key: "$in", Value: bson.A{0, nil}}}}
+	}
+	return bson.E{Key: "revision", Value: revision}
+}
@@ -25,6 +25,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/repository"
@@ -33,6 +34,7 @@ import (
 	"github.com/ISSuh/sos/internal/persistence"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
@@ -44,9 +46,82 @@ type mongoDBObjectMetadata struct {
 }
 
 func NewMongoDBObjectMetadata(db *persistence.MongoDB) (repository.ObjectMetadata, error) {
-	return &mongoDBObjectMetadata{
+	collection, err := db.Collection(objectMetadataCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	// listing walks a path in object id order
+	index := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "group", Value: 1},
+			{Key: "partition", Value: 1},
+			{Key: "path", Value: 1},
+			{Key: "object_id", Value: 1},
+		},
+	}
+
+	if _, err := collection.Indexes().CreateOne(context.Background(), index); err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// a name is unique on a path, so concurrent first uploads of an object can not create it twice
+	nameIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "group", Value: 1},
+			{Key: "partition", Value: 1},
+			{Key: "path", Value: 1},
+			{Key: "name", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+
+	// the objects stored before names were unique may share a name, and the index can not be built
+	// over them. The registry still starts without the guarantee, and builds the index on the first
+	// start after the duplicates are resolved.
+	if _, err := collection.Indexes().CreateOne(context.Background(), nameIndex); err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("failed to create index: %w", err)
+		}
+
+		if err := logDuplicateNames(context.Background(), collection); err != nil {
+			return nil, err
+		}
+	}
+
+	// garbage collection looks objects up by id alone
+	objectIDIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "object_id", Value: 1},
+		},
+	}
+
+	if _, err := collection.Indexes().CreateOne(context.Background(), objectIDIndex); err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// a tag search looks for objects of a partition by their tags
+	tagIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "group", Value: 1},
+			{Key: "partition", Value: 1},
+			{Key: "tags.key", Value: 1},
+			{Key: "tags.value", Value: 1},
+		},
+	}
+
+	if _, err := collection.Indexes().CreateOne(context.Background(), tagIndex); err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	objectMetadata := &mongoDBObjectMetadata{
 		db: db,
-	}, nil
+	}
+
+	if err := objectMetadata.cleanPaths(context.Background(), collection); err != nil {
+		return nil, err
+	}
+	return objectMetadata, nil
 }
 
 func (d *mongoDBObjectMetadata) Create(c context.Context, metadata *entity.ObjectMetadata) error {
@@ -71,6 +146,9 @@ func (d *mongoDBObjectMetadata) Create(c context.Context, metadata *entity.Objec
 
 	res, err := collection.InsertOne(c, metadata)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return soserror.NewConflictError(fmt.Errorf("object already exist. %s", metadata.Name()))
+		}
 		return fmt.Errorf("failed to insert data: %w", err)
 	}
 
@@ -101,12 +179,8 @@ func (d *mongoDBObjectMetadata) Update(c context.Context, metadata *entity.Objec
 		return err
 	}
 
-	filter := bson.D{
-		{Key: "group", Value: metadata.Group()},
-		{Key: "partition", Value: metadata.Partition()},
-		{Key: "path", Value: metadata.Path()},
-		{Key: "object_id", Value: metadata.ID()},
-	}
+	filter := d.revisionFilter(metadata)
+	metadata.IncreaseRevision()
 
 	res, err := collection.ReplaceOne(c, filter, metadata)
 	if err != nil {
@@ -116,8 +190,9 @@ func (d *mongoDBObjectMetadata) Update(c context.Context, metadata *entity.Objec
 		return fmt.Errorf("failed to update data: %w", err)
 	}
 
-	if res.ModifiedCount == 0 {
-		return fmt.Errorf("can not find metadata")
+	// the metadata is gone or was written since it was read, the caller reads it again to tell
+	if res.MatchedCount == 0 {
+		return soserror.NewConflictError(fmt.Errorf("metadata is modified or deleted"))
 	}
 
 	return nil
@@ -143,14 +218,7 @@ func (d *mongoDBObjectMetadata) Delete(c context.Context, metadata *entity.Objec
 		return err
 	}
 
-	filter := bson.D{
-		{Key: "group", Value: metadata.Group()},
-		{Key: "partition", Value: metadata.Partition()},
-		{Key: "path", Value: metadata.Path()},
-		{Key: "object_id", Value: metadata.ID()},
-	}
-
-	res, err := collection.DeleteOne(c, filter)
+	res, err := collection.DeleteOne(c, d.revisionFilter(metadata))
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return soserror.NewNotFoundError(fmt.Errorf("can not find metadata"))
@@ -159,7 +227,45 @@ func (d *mongoDBObjectMetadata) Delete(c context.Context, metadata *entity.Objec
 	}
 
 	if res.DeletedCount == 0 {
-		return fmt.Errorf("can not find metadata")
+		return soserror.NewConflictError(fmt.Errorf("metadata is modified or deleted"))
+	}
+
+	return nil
+}
+
+func (d *mongoDBObjectMetadata) Move(c context.Context, metadata *entity.ObjectMetadata, path, name string) error {
+	log.FromContext(c).Debugf("[mongoDBObjectMetadata.Move] metadata: %+v, path: %s, name: %s", metadata, path, name)
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case !metadata.ID().IsValid():
+		return fmt.Errorf("objectID is invalid. %d", metadata.ID())
+	case path == "":
+		return fmt.Errorf("path is empty")
+	case name == "":
+		return fmt.Errorf("name is empty")
+	}
+
+	collection, err := d.db.Collection(objectMetadataCollectionName)
+	if err != nil {
+		return err
+	}
+
+	// the filter holds the current path, so it is built before the metadata moves
+	filter := d.revisionFilter(metadata)
+	metadata.Move(path, name)
+	metadata.IncreaseRevision()
+
+	res, err := collection.ReplaceOne(c, filter, metadata)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return soserror.NewConflictError(fmt.Errorf("object already exist. %s", name))
+		}
+		return fmt.Errorf("failed to move data: %w", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return soserror.NewConflictError(fmt.Errorf("metadata is modified or deleted"))
 	}
 
 	return nil
@@ -178,17 +284,32 @@ func (d *mongoDBObjectMetadata) MetadataByObjectName(c context.Context, group, p
 		return nil, fmt.Errorf("path is empty")
 	}
 
-	list, err := d.FindMetadata(c, group, partition, path)
+	collection, err := d.db.Collection(objectMetadataCollectionName)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, metadata := range list {
-		if metadata.Name() == name {
-			return &metadata, nil
+	filter := bson.D{
+		{Key: "group", Value: group},
+		{Key: "partition", Value: partition},
+		{Key: "path", Value: path},
+		{Key: "name", Value: name},
+	}
+
+	res := collection.FindOne(c, filter)
+	if res.Err() != nil {
+		if res.Err() == mongo.ErrNoDocuments {
+			return nil, soserror.NewNotFoundError(fmt.Errorf("can not find metadata"))
 		}
+		return nil, fmt.Errorf("failed to find metadata: %w", res.Err())
+	}
+
+	var metadata entity.ObjectMetadata
+	if err := res.Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
 	}
-	return nil, soserror.NewNotFoundError(fmt.Errorf("can not find metadata"))
+
+	return &metadata, nil
 }
 
 func (d *mongoDBObjectMetadata) MetadataByObjectID(c context.Context, group, partition, path string, objectID int64) (*entity.ObjectMetadata, error) {
@@ -234,8 +355,10 @@ func (d *mongoDBObjectMetadata) MetadataByObjectID(c context.Context, group, par
 	return &metadata, nil
 }
 
-func (d *mongoDBObjectMetadata) FindMetadata(c context.Context, group, partition, path string) (entity.ObjectMetadataList, error) {
-	log.FromContext(c).Debugf("[mongoDBObjectMetadata.FindMetadata] group: %s, partition: %s, path: %s", group, partition, path)
+func (d *mongoDBObjectMetadata) FindMetadata(
+	c context.Context, group, partition, path string, lastObjectID int64, limit int,
+) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[mongoDBObjectMetadata.FindMetadata] group: %s, partition: %s, path: %s, lastObjectID: %d, limit: %d", group, partition, path, lastObjectID, limit)
 	switch {
 	case c == nil:
 		return nil, fmt.Errorf("context is nil")
@@ -245,6 +368,8 @@ func (d *mongoDBObjectMetadata) FindMetadata(c context.Context, group, partition
 		return nil, fmt.Errorf("partition is empty")
 	case path == "":
 		return nil, fmt.Errorf("path is empty")
+	case limit < 0:
+		return nil, fmt.Errorf("limit is invalid. %d", limit)
 	}
 
 	collection, err := d.db.Collection(objectMetadataCollectionName)
@@ -256,13 +381,119 @@ func (d *mongoDBObjectMetadata) FindMetadata(c context.Context, group, partition
 		{Key: "group", Value: group},
 		{Key: "partition", Value: partition},
 		{Key: "path", Value: path},
+		{Key: "object_id", Value: bson.D{{Key: "$gt", Value: lastObjectID}}},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "object_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	res, err := collection.Find(c, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find metadata: %w", err)
+	}
+
+	var metadataList entity.ObjectMetadataList
+	if err := res.All(c, &metadataList); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+
+	return metadataList, nil
+}
+
+func (d *mongoDBObjectMetadata) FindMetadataByTags(
+	c context.Context, group, partition string, expression entity.TagExpression, lastObjectID int64, limit int,
+) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[mongoDBObjectMetadata.FindMetadataByTags] group: %s, partition: %s, expression: %v, lastObjectID: %d, limit: %d", group, partition, expression.Terms(), lastObjectID, limit)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	case group == "":
+		return nil, fmt.Errorf("group is invalid")
+	case partition == "":
+		return nil, fmt.Errorf("partition is empty")
+	case len(expression) == 0:
+		return nil, fmt.Errorf("tag expression is empty")
+	case limit < 0:
+		return nil, fmt.Errorf("limit is invalid. %d", limit)
+	}
+
+	collection, err := d.db.Collection(objectMetadataCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := d.tagExpressionFilter(group, partition, expression, lastObjectID)
+	opts := options.Find().
+		SetSort(bson.D{{Key: "object_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	res, err := collection.Find(c, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find metadata: %w", err)
+	}
+
+	var metadataList entity.ObjectMetadataList
+	if err := res.All(c, &metadataList); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+
+	return metadataList, nil
+}
+
+// tagExpressionFilter matches the objects of the partition after lastObjectID meeting every condition.
+func (d *mongoDBObjectMetadata) tagExpressionFilter(
+	group, partition string, expression entity.TagExpression, lastObjectID int64,
+) bson.D {
+	conditions := make(bson.A, 0, len(expression))
+	for _, condition := range expression {
+		conditions = append(conditions, d.tagFilter(condition))
+	}
+
+	return bson.D{
+		{Key: "group", Value: group},
+		{Key: "partition", Value: partition},
+		{Key: "object_id", Value: bson.D{{Key: "$gt", Value: lastObjectID}}},
+		{Key: "$and", Value: conditions},
+	}
+}
+
+// tagFilter matches the condition against the tags, which are stored as a list of key and value.
+func (d *mongoDBObjectMetadata) tagFilter(condition entity.TagCondition) bson.D {
+	tag := bson.D{{Key: "$elemMatch", Value: bson.D{
+		{Key: "key", Value: condition.Key},
+		{Key: "value", Value: bson.D{{Key: "$in", Value: condition.Values}}},
+	}}}
+
+	switch condition.Operator {
+	case entity.TagOperatorEqual:
+		return bson.D{{Key: "tags", Value: tag}}
+	case entity.TagOperatorNotEqual:
+		return bson.D{{Key: "tags", Value: bson.D{{Key: "$not", Value: tag}}}}
+	case entity.TagOperatorNotExists:
+		return bson.D{{Key: "tags.key", Value: bson.D{{Key: "$ne", Value: condition.Key}}}}
+	}
+	return bson.D{{Key: "tags.key", Value: condition.Key}}
+}
+
+func (d *mongoDBObjectMetadata) MetadataByObjectIDs(c context.Context, objectIDs []int64) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[mongoDBObjectMetadata.MetadataByObjectIDs] objectIDs: %v", objectIDs)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	}
+
+	collection, err := d.db.Collection(objectMetadataCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "object_id", Value: bson.D{{Key: "$in", Value: objectIDs}}},
 	}
 
 	res, err := collection.Find(c, filter)
 	if err != nil {
-		if res.Err() == mongo.ErrNoDocuments {
-			return nil, soserror.NewNotFoundError(fmt.Errorf("can not find metadata"))
-		}
 		return nil, fmt.Errorf("failed to find metadata: %w", err)
 	}
 
@@ -273,3 +504,227 @@ func (d *mongoDBObjectMetadata) FindMetadata(c context.Context, group, partition
 
 	return metadataList, nil
 }
+
+func (d *mongoDBObjectMetadata) MetadataByContentHashes(c context.Context, hashes []string) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[mongoDBObjectMetadata.MetadataByContentHashes] hashes: %v", hashes)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	}
+
+	collection, err := d.db.Collection(objectMetadataCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "versions.block_headers.hash", Value: bson.D{{Key: "$in", Value: hashes}}},
+	}
+
+	res, err := collection.Find(c, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find metadata: %w", err)
+	}
+
+	var metadataList entity.ObjectMetadataList
+	if err := res.All(c, &metadataList); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+
+	return metadataList, nil
+}
+
+func (d *mongoDBObjectMetadata) FindMetadataByStaleDataKey(
+	c context.Context, keyID string, lastObjectID int64, limit int,
+) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[mongoDBObjectMetadata.FindMetadataByStaleDataKey] keyID: %s, lastObjectID: %d, limit: %d", keyID, lastObjectID, limit)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	case limit < 0:
+		return nil, fmt.Errorf("limit is invalid. %d", limit)
+	}
+
+	collection, err := d.db.Collection(objectMetadataCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "object_id", Value: bson.D{{Key: "$gt", Value: lastObjectID}}},
+		{Key: "versions", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+			{Key: "pending", Value: bson.D{{Key: "$ne", Value: true}}},
+			{Key: "encryption.algorithm", Value: bson.D{{Key: "$exists", Value: true}}},
+			{Key: "encryption.key_id", Value: bson.D{{Key: "$ne", Value: keyID}}},
+			{Key: "encryption.customer_key_md5", Value: bson.D{{Key: "$exists", Value: false}}},
+		}}}},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "object_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	res, err := collection.Find(c, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find metadata: %w", err)
+	}
+
+	var metadataList entity.ObjectMetadataList
+	if err := res.All(c, &metadataList); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+
+	return metadataList, nil
+}
+
+func (d *mongoDBObjectMetadata) MetadataWithExpiredVersions(c context.Context, now time.Time) (entity.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[mongoDBObjectMetadata.MetadataWithExpiredVersions] now: %s", now)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	}
+
+	collection, err := d.db.Collection(objectMetadataCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "versions", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+			{Key: "pending", Value: true},
+			{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}},
+		}}}},
+	}
+
+	res, err := collection.Find(c, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find metadata: %w", err)
+	}
+
+	var metadataList entity.ObjectMetadataList
+	if err := res.All(c, &metadataList); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+
+	return metadataList, nil
+}
+
+// cleanPaths rewrites the paths stored before paths were cleaned, e.g. "/a/b/" to "a/b", as the
+// requests only look the objects up by the clean path. An object whose clean path already holds an
+// object of the same name is left on its old path and logged for the operator to resolve.
+func (d *mongoDBObjectMetadata) cleanPaths(c context.Context, collection *mongo.Collection) error {
+	filter := bson.D{
+		{Key: "path", Value: bson.D{
+			{Key: "$regex", Value: `^/|/$|//|(^|/)\.\.?(/|$)`},
+			{Key: "$ne", Value: entity.RootDirectoryPath},
+		}},
+	}
+
+	res, err := collection.Find(c, filter)
+	if err != nil {
+		return fmt.Errorf("failed to find metadata to clean: %w", err)
+	}
+	defer res.Close(c)
+
+	for res.Next(c) {
+		var metadata entity.ObjectMetadata
+		if err := res.Decode(&metadata); err != nil {
+			return fmt.Errorf("failed to decode metadata: %w", err)
+		}
+
+		// the unique name index may be missing, so the clean path is checked for the name first
+		path := entity.CleanDirectoryPath(metadata.Path())
+		taken, err := collection.CountDocuments(c, bson.D{
+			{Key: "group", Value: metadata.Group()},
+			{Key: "partition", Value: metadata.Partition()},
+			{Key: "path", Value: path},
+			{Key: "name", Value: metadata.Name()},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to find metadata on %s: %w", path, err)
+		}
+
+		if taken > 0 {
+			log.FromContext(c).Warnf("[cleanPaths] can not clean path of %d. %s already holds %s on %s/%s",
+				metadata.ID(), path, metadata.Name(), metadata.Group(), metadata.Partition())
+			continue
+		}
+
+		update := bson.D{
+			{Key: "$set", Value: bson.D{{Key: "path", Value: path}}},
+			{Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}},
+		}
+
+		_, err = collection.UpdateOne(c, d.revisionFilter(&metadata), update)
+		switch {
+		case mongo.IsDuplicateKeyError(err):
+			log.FromContext(c).Warnf("[cleanPaths] can not clean path of %d. %s already holds %s on %s/%s",
+				metadata.ID(), path, metadata.Name(), metadata.Group(), metadata.Partition())
+		case err != nil:
+			return fmt.Errorf("failed to clean path of %d: %w", metadata.ID(), err)
+		default:
+			log.FromContext(c).Infof("[cleanPaths] cleaned path of %d. %s to %s", metadata.ID(), metadata.Path(), path)
+		}
+	}
+	return res.Err()
+}
+
+// logDuplicateNames logs every name held by more than one object on a path, for the operator to
+// rename or delete all but one of them.
+func logDuplicateNames(c context.Context, collection *mongo.Collection) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "group", Value: "$group"},
+				{Key: "partition", Value: "$partition"},
+				{Key: "path", Value: "$path"},
+				{Key: "name", Value: "$name"},
+			}},
+			{Key: "object_ids", Value: bson.D{{Key: "$push", Value: "$object_id"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+	}
+
+	res, err := collection.Aggregate(c, pipeline)
+	if err != nil {
+		return fmt.Errorf("failed to find duplicate names: %w", err)
+	}
+	defer res.Close(c)
+
+	for res.Next(c) {
+		var duplicate struct {
+			Name struct {
+				Group     string `bson:"group"`
+				Partition string `bson:"partition"`
+				Path      string `bson:"path"`
+				Name      string `bson:"name"`
+			} `bson:"_id"`
+			ObjectIDs []int64 `bson:"object_ids"`
+		}
+		if err := res.Decode(&duplicate); err != nil {
+			return fmt.Errorf("failed to decode duplicate names: %w", err)
+		}
+
+		log.FromContext(c).Warnf("[logDuplicateNames] %s is held by objects %v on %s/%s/%s. names are not unique until they are resolved",
+			duplicate.Name.Name, duplicate.ObjectIDs, duplicate.Name.Group, duplicate.Name.Partition, duplicate.Name.Path)
+	}
+	return res.Err()
+}
+
+// revisionFilter matches the metadata only at the revision it was read at. The metadata written
+// before revisions were counted has no revision, which is read as 0.
+func (d *mongoDBObjectMetadata) revisionFilter(metadata *entity.ObjectMetadata) bson.D {
+	revision := bson.E{Key: "revision", Value: metadata.Revision()}
+	if metadata.Revision() == 0 {
+		revision.Value = bson.D{{Key: "$in", Value: bson.A{0, nil}}}
+	}
+
+	return bson.D{
+		{Key: "group", Value: metadata.Group()},
+		{Key: "partition", Value: metadata.Partition()},
+		{Key: "path", Value: metadata.Path()},
+		{Key: "object_id", Value: metadata.ID()},
+		revision,
+	}
+}
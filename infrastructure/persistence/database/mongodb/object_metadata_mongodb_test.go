@@ -0,0 +1,100 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package database
+
+import (
+	"testing"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTagExpressionFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		terms []string
+		want  string
+	}{
+		{
+			name:  "equal to one of the values",
+			terms: []string{"env=prod|stage"},
+			want:  `{"tags":{"$elemMatch":{"key":"env","value":{"$in":["prod","stage"]}}}}`,
+		},
+		{
+			name:  "not equal",
+			terms: []string{"env!=prod"},
+			want:  `{"tags":{"$not":{"$elemMatch":{"key":"env","value":{"$in":["prod"]}}}}}`,
+		},
+		{
+			name:  "exists",
+			terms: []string{"team"},
+			want:  `{"tags.key":"team"}`,
+		},
+		{
+			name:  "not exists",
+			terms: []string{"!archived"},
+			want:  `{"tags.key":{"$ne":"archived"}}`,
+		},
+	}
+
+	d := &mongoDBObjectMetadata{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expression, err := entity.ParseTagExpression(test.terms)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := bson.MarshalExtJSON(d.tagFilter(expression[0]), false, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("filter\n got %s\nwant %s", got, test.want)
+			}
+		})
+	}
+}
+
+// Every term is a separate condition of the $and, so a term is never merged with another one on the
+// same key, and the query keeps to the partition and the page after the last object.
+func TestTagExpressionFilterJoinsTerms(t *testing.T) {
+	expression, err := entity.ParseTagExpression([]string{"env=prod", "env!=stage", "!archived"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &mongoDBObjectMetadata{}
+	got, err := bson.MarshalExtJSON(d.tagExpressionFilter("group", "partition", expression, 42), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"group":"group","partition":"partition","object_id":{"$gt":42},"$and":[` +
+		`{"tags":{"$elemMatch":{"key":"env","value":{"$in":["prod"]}}}},` +
+		`{"tags":{"$not":{"$elemMatch":{"key":"env","value":{"$in":["stage"]}}}}},` +
+		`{"tags.key":{"$ne":"archived"}}]}`
+	if string(got) != want {
+		t.Errorf("filter\n got %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,154 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/repository"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	policyCollectionName = "policy"
+)
+
+type mongoDBPolicy struct {
+	db *persistence.MongoDB
+}
+
+func NewMongoDBPolicy(db *persistence.MongoDB) (repository.Policy, error) {
+	return &mongoDBPolicy{
+		db: db,
+	}, nil
+}
+
+func (d *mongoDBPolicy) Put(c context.Context, policy *entity.Policy) error {
+	log.FromContext(c).Debugf("[mongoDBPolicy.Put] group: %s, partition: %s", policy.Group(), policy.Partition())
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case policy.Group() == "":
+		return fmt.Errorf("group is empty")
+	}
+
+	collection, err := d.db.Collection(policyCollectionName)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		{Key: "group", Value: policy.Group()},
+		{Key: "partition", Value: policy.Partition()},
+	}
+
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "statements", Value: policy.Statements()},
+			{Key: "modified_at", Value: policy.ModifiedAt},
+		}},
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "created_at", Value: policy.CreatedAt},
+		}},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := collection.UpdateOne(c, filter, update, opts); err != nil {
+		return fmt.Errorf("failed to upsert data: %w", err)
+	}
+
+	return nil
+}
+
+func (d *mongoDBPolicy) Delete(c context.Context, group, partition string) error {
+	log.FromContext(c).Debugf("[mongoDBPolicy.Delete] group: %s, partition: %s", group, partition)
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case group == "":
+		return fmt.Errorf("group is empty")
+	}
+
+	collection, err := d.db.Collection(policyCollectionName)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		{Key: "group", Value: group},
+		{Key: "partition", Value: partition},
+	}
+
+	res, err := collection.DeleteOne(c, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete data: %w", err)
+	}
+
+	if res.DeletedCount == 0 {
+		return soserror.NewNotFoundError(fmt.Errorf("can not find policy"))
+	}
+
+	return nil
+}
+
+func (d *mongoDBPolicy) PolicyByPartition(c context.Context, group, partition string) (*entity.Policy, error) {
+	log.FromContext(c).Debugf("[mongoDBPolicy.PolicyByPartition] group: %s, partition: %s", group, partition)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	case group == "":
+		return nil, fmt.Errorf("group is empty")
+	}
+
+	collection, err := d.db.Collection(policyCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "group", Value: group},
+		{Key: "partition", Value: partition},
+	}
+
+	res := collection.FindOne(c, filter)
+	if res.Err() != nil {
+		if res.Err() == mongo.ErrNoDocuments {
+			return nil, soserror.NewNotFoundError(fmt.Errorf("can not find policy"))
+		}
+		return nil, fmt.Errorf("failed to find policy: %w", res.Err())
+	}
+
+	var policy entity.Policy
+	if err := res.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to decode policy: %w", err)
+	}
+
+	return &policy, nil
+}
@@ -0,0 +1,282 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/repository"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	uploadSessionCollectionName = "upload_session"
+)
+
+type mongoDBUploadSession struct {
+	db *persistence.MongoDB
+}
+
+func NewMongoDBUploadSession(db *persistence.MongoDB) (repository.UploadSession, error) {
+	return &mongoDBUploadSession{
+		db: db,
+	}, nil
+}
+
+func (d *mongoDBUploadSession) Create(c context.Context, session *entity.UploadSession) error {
+	log.FromContext(c).Debugf("[mongoDBUploadSession.Create] session: %+v", session)
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case !session.ID().IsValid():
+		return fmt.Errorf("uploadID is invalid. %d", session.ID())
+	case !session.ObjectID().IsValid():
+		return fmt.Errorf("objectID is invalid. %d", session.ObjectID())
+	}
+
+	collection, err := d.db.Collection(uploadSessionCollectionName)
+	if err != nil {
+		return err
+	}
+
+	res, err := collection.InsertOne(c, session)
+	if err != nil {
+		return fmt.Errorf("failed to insert data: %w", err)
+	}
+
+	if res.InsertedID == nil {
+		return fmt.Errorf("invakid insertedID")
+	}
+
+	return nil
+}
+
+func (d *mongoDBUploadSession) Delete(c context.Context, uploadID int64) error {
+	log.FromContext(c).Debugf("[mongoDBUploadSession.Delete] uploadID: %d", uploadID)
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case uploadID <= 0:
+		return fmt.Errorf("uploadID is invalid. %d", uploadID)
+	}
+
+	collection, err := d.db.Collection(uploadSessionCollectionName)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		{Key: "upload_id", Value: uploadID},
+	}
+
+	res, err := collection.DeleteOne(c, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete data: %w", err)
+	}
+
+	if res.DeletedCount == 0 {
+		return soserror.NewNotFoundError(fmt.Errorf("can not find upload session"))
+	}
+
+	return nil
+}
+
+func (d *mongoDBUploadSession) PutPart(c context.Context, uploadID int64, part *entity.UploadPart, now time.Time) error {
+	log.FromContext(c).Debugf("[mongoDBUploadSession.PutPart] uploadID: %d, part: %d", uploadID, part.Number())
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case uploadID <= 0:
+		return fmt.Errorf("uploadID is invalid. %d", uploadID)
+	case part.Number() <= 0:
+		return fmt.Errorf("part number is invalid. %d", part.Number())
+	}
+
+	collection, err := d.db.Collection(uploadSessionCollectionName)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		{Key: "upload_id", Value: uploadID},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: now}}},
+	}
+
+	// each part is set under its own key, so concurrent part uploads do not overwrite each other
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "parts." + strconv.Itoa(part.Number()), Value: part},
+			{Key: "modified_at", Value: now},
+		}},
+	}
+
+	res, err := collection.UpdateOne(c, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update data: %w", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return soserror.NewNotFoundError(fmt.Errorf("can not find upload session"))
+	}
+
+	return nil
+}
+
+func (d *mongoDBUploadSession) SessionByID(c context.Context, uploadID int64) (*entity.UploadSession, error) {
+	log.FromContext(c).Debugf("[mongoDBUploadSession.SessionByID] uploadID: %d", uploadID)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	case uploadID <= 0:
+		return nil, fmt.Errorf("uploadID is invalid. %d", uploadID)
+	}
+
+	collection, err := d.db.Collection(uploadSessionCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "upload_id", Value: uploadID},
+	}
+
+	res := collection.FindOne(c, filter)
+	if res.Err() != nil {
+		if res.Err() == mongo.ErrNoDocuments {
+			return nil, soserror.NewNotFoundError(fmt.Errorf("can not find upload session"))
+		}
+		return nil, fmt.Errorf("failed to find upload session: %w", res.Err())
+	}
+
+	var session entity.UploadSession
+	if err := res.Decode(&session); err != nil {
+		return nil, fmt.Errorf("failed to decode upload session: %w", err)
+	}
+
+	return &session, nil
+}
+
+func (d *mongoDBUploadSession) FindExpired(c context.Context, now time.Time) (entity.UploadSessions, error) {
+	log.FromContext(c).Debugf("[mongoDBUploadSession.FindExpired] now: %s", now)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	}
+
+	collection, err := d.db.Collection(uploadSessionCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}},
+	}
+
+	res, err := collection.Find(c, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find upload session: %w", err)
+	}
+
+	var sessions entity.UploadSessions
+	if err := res.All(c, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode upload session: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (d *mongoDBUploadSession) SessionsByObjectIDs(c context.Context, objectIDs []int64) (entity.UploadSessions, error) {
+	log.FromContext(c).Debugf("[mongoDBUploadSession.SessionsByObjectIDs] objectIDs: %v", objectIDs)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	}
+
+	collection, err := d.db.Collection(uploadSessionCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "object_id", Value: bson.D{{Key: "$in", Value: objectIDs}}},
+	}
+
+	res, err := collection.Find(c, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find upload session: %w", err)
+	}
+
+	var sessions entity.UploadSessions
+	if err := res.All(c, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode upload session: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (d *mongoDBUploadSession) SessionsByContentHashes(c context.Context, hashes []string) (entity.UploadSessions, error) {
+	log.FromContext(c).Debugf("[mongoDBUploadSession.SessionsByContentHashes] hashes: %v", hashes)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	}
+
+	collection, err := d.db.Collection(uploadSessionCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	// the parts are stored by part number, so they are matched as an array of the part documents
+	partHashes := bson.D{{Key: "$ifNull", Value: bson.A{"$$this.v.block_headers.hash", bson.A{}}}}
+	filter := bson.D{
+		{Key: "$expr", Value: bson.D{{Key: "$anyElementTrue", Value: bson.A{
+			bson.D{{Key: "$map", Value: bson.D{
+				{Key: "input", Value: bson.D{{Key: "$objectToArray", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$parts", bson.D{}}}}}}},
+				{Key: "in", Value: bson.D{{Key: "$gt", Value: bson.A{
+					bson.D{{Key: "$size", Value: bson.D{{Key: "$setIntersection", Value: bson.A{partHashes, hashes}}}}},
+					0,
+				}}}},
+			}}},
+		}}}},
+	}
+
+	res, err := collection.Find(c, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find upload session: %w", err)
+	}
+
+	var sessions entity.UploadSessions
+	if err := res.All(c, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode upload session: %w", err)
+	}
+
+	return sessions, nil
+}
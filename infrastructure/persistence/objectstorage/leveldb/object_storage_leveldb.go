@@ -27,16 +27,35 @@ import (
 	"context"
 	"encoding/gob"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/repository"
 	"github.com/ISSuh/sos/internal/log"
 	"github.com/ISSuh/sos/internal/persistence"
+	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// quarantined blocks are kept under a prefix that sorts after every block key
+const quarantinePrefix = "quarantine:"
+
+// deduplicated content and its reference counts sort after the quarantined blocks, and the
+// content is scanned once the blocks are done
+const (
+	contentPrefix   = "~content:"
+	referencePrefix = "~ref:"
 )
 
 type LevelDBObjectStorage struct {
 	storage *persistence.LevelDB
+
+	// serializes the read-modify-write of reference counts
+	referenceMutex sync.Mutex
 }
 
 func NewLevelDBObjectStorage(storage *persistence.LevelDB) (repository.ObjectStorage, error) {
@@ -156,11 +175,476 @@ func (s *LevelDBObjectStorage) Delete(c context.Context, objectID entity.ObjectI
 	return nil
 }
 
+func (s *LevelDBObjectStorage) Scan(c context.Context, cursor string, limit int) ([]repository.ScannedBlock, string, error) {
+	log.FromContext(c).Debugf("[LevelDBObjectStorage.Scan] cursor: %s, limit: %d", cursor, limit)
+	if cursor < contentPrefix {
+		blocks, next, err := s.scan(c, &util.Range{Limit: []byte(quarantinePrefix)}, "", cursor, limit)
+		if err != nil || next != "" {
+			return blocks, next, err
+		}
+		return blocks, contentPrefix, nil
+	}
+	return s.scan(c, util.BytesPrefix([]byte(contentPrefix)), contentPrefix, cursor, limit)
+}
+
+func (s *LevelDBObjectStorage) ScanQuarantined(c context.Context, cursor string, limit int) ([]repository.ScannedBlock, string, error) {
+	log.FromContext(c).Debugf("[LevelDBObjectStorage.ScanQuarantined] cursor: %s, limit: %d", cursor, limit)
+	return s.scan(c, util.BytesPrefix([]byte(quarantinePrefix)), quarantinePrefix, cursor, limit)
+}
+
+func (s *LevelDBObjectStorage) scan(
+	c context.Context, scanRange *util.Range, prefix, cursor string, limit int,
+) ([]repository.ScannedBlock, string, error) {
+	switch {
+	case c == nil:
+		return nil, "", fmt.Errorf("context is nil")
+	case limit <= 0:
+		return nil, "", fmt.Errorf("limit is invalid")
+	}
+
+	storage, err := s.storage.Engin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if cursor != "" {
+		scanRange.Start = append([]byte(cursor), 0)
+	}
+
+	iter := storage.NewIterator(scanRange, nil)
+	defer iter.Release()
+
+	blocks := make([]repository.ScannedBlock, 0, limit)
+	next := ""
+	for len(blocks) < limit && iter.Next() {
+		next = string(iter.Key())
+		scanned := repository.ScannedBlock{
+			Size: len(iter.Value()),
+		}
+
+		var err error
+		if prefix == contentPrefix {
+			scanned.Hash = strings.TrimPrefix(next, prefix)
+			scanned.References, _, scanned.ReferencedAt, err = s.getReference(storage, scanned.Hash)
+		} else {
+			scanned.ObjectID, scanned.BlockID, scanned.Index, err = s.parseKey(strings.TrimPrefix(next, prefix))
+		}
+
+		if err != nil {
+			log.FromContext(c).Warnf("[LevelDBObjectStorage.Scan] skip unknown key %s. %s", next, err.Error())
+			continue
+		}
+
+		scanned.Block, scanned.Err = s.decodeBlock(iter.Value())
+		blocks = append(blocks, scanned)
+	}
+
+	if err := iter.Error(); err != nil {
+		return nil, "", err
+	}
+
+	if len(blocks) < limit {
+		next = ""
+	}
+	return blocks, next, nil
+}
+
+func (s *LevelDBObjectStorage) Quarantine(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) error {
+	log.FromContext(c).Debugf("[LevelDBObjectStorage.Quarantine] objectID: %s, blockID : %d, index: %d", objectID, blockID, index)
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case !objectID.IsValid():
+		return fmt.Errorf("objectID is invalid")
+	case blockID < 0:
+		return fmt.Errorf("blockID is invalid")
+	case index < 0:
+		return fmt.Errorf("index is invalid")
+	}
+
+	storage, err := s.storage.Engin()
+	if err != nil {
+		return err
+	}
+
+	key := s.makeKey(objectID, blockID, index)
+	data, err := storage.Get(key, nil)
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Put(s.makeQuarantineKey(key), data)
+	batch.Delete(key)
+	if err := storage.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *LevelDBObjectStorage) DeleteQuarantined(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) error {
+	log.FromContext(c).Debugf("[LevelDBObjectStorage.DeleteQuarantined] objectID: %s, blockID : %d, index: %d", objectID, blockID, index)
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case !objectID.IsValid():
+		return fmt.Errorf("objectID is invalid")
+	case blockID < 0:
+		return fmt.Errorf("blockID is invalid")
+	case index < 0:
+		return fmt.Errorf("index is invalid")
+	}
+
+	storage, err := s.storage.Engin()
+	if err != nil {
+		return err
+	}
+
+	key := s.makeQuarantineKey(s.makeKey(objectID, blockID, index))
+	if err := storage.Delete(key, &opt.WriteOptions{Sync: true}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *LevelDBObjectStorage) PutContent(c context.Context, block *entity.Block) error {
+	log.FromContext(c).Debugf("[LevelDBObjectStorage.PutContent] block header: %+v", block.Header())
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case block == nil:
+		return fmt.Errorf("block is nil")
+	}
+
+	header := block.Header()
+	if header.Hash() == "" {
+		return fmt.Errorf("hash is empty")
+	}
+
+	if err := block.Validate(); err != nil {
+		return err
+	}
+
+	storage, err := s.storage.Engin()
+	if err != nil {
+		return err
+	}
+
+	s.referenceMutex.Lock()
+	defer s.referenceMutex.Unlock()
+
+	count, _, _, err := s.getReference(storage, header.Hash())
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	if count == 0 {
+		data, err := s.encodeBlock(block)
+		if err != nil {
+			return err
+		}
+		batch.Put(s.makeContentKey(header.Hash()), data)
+	}
+	batch.Put(s.makeReferenceKey(header.Hash()), s.encodeReference(count+1, len(block.Buffer()), time.Now()))
+	return storage.Write(batch, &opt.WriteOptions{Sync: true})
+}
+
+func (s *LevelDBObjectStorage) ReferenceContent(c context.Context, hash string) (bool, error) {
+	log.FromContext(c).Debugf("[LevelDBObjectStorage.ReferenceContent] hash: %s", hash)
+	switch {
+	case c == nil:
+		return false, fmt.Errorf("context is nil")
+	case hash == "":
+		return false, fmt.Errorf("hash is empty")
+	}
+
+	storage, err := s.storage.Engin()
+	if err != nil {
+		return false, err
+	}
+
+	s.referenceMutex.Lock()
+	defer s.referenceMutex.Unlock()
+
+	count, size, _, err := s.getReference(storage, hash)
+	if err != nil {
+		return false, err
+	}
+
+	if count == 0 {
+		return false, nil
+	}
+
+	err = storage.Put(s.makeReferenceKey(hash), s.encodeReference(count+1, size, time.Now()), &opt.WriteOptions{Sync: true})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *LevelDBObjectStorage) GetContent(c context.Context, hash string) (*entity.Block, error) {
+	log.FromContext(c).Debugf("[LevelDBObjectStorage.GetContent] hash: %s", hash)
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("context is nil")
+	case hash == "":
+		return nil, fmt.Errorf("hash is empty")
+	}
+
+	storage, err := s.storage.Engin()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := storage.Get(s.makeContentKey(hash), nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.decodeBlock(data)
+}
+
+func (s *LevelDBObjectStorage) ReleaseContent(c context.Context, hash string) error {
+	log.FromContext(c).Debugf("[LevelDBObjectStorage.ReleaseContent] hash: %s", hash)
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case hash == "":
+		return fmt.Errorf("hash is empty")
+	}
+
+	storage, err := s.storage.Engin()
+	if err != nil {
+		return err
+	}
+
+	s.referenceMutex.Lock()
+	defer s.referenceMutex.Unlock()
+
+	count, size, _, err := s.getReference(storage, hash)
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	switch {
+	case count == 0:
+		return nil
+	case count == 1:
+		batch.Delete(s.makeContentKey(hash))
+		batch.Delete(s.makeReferenceKey(hash))
+	default:
+		batch.Put(s.makeReferenceKey(hash), s.encodeReference(count-1, size, time.Now()))
+	}
+	return storage.Write(batch, &opt.WriteOptions{Sync: true})
+}
+
+func (s *LevelDBObjectStorage) ReplaceContent(c context.Context, block *entity.Block) error {
+	log.FromContext(c).Debugf("[LevelDBObjectStorage.ReplaceContent] block header: %+v", block.Header())
+	switch {
+	case c == nil:
+		return fmt.Errorf("context is nil")
+	case block == nil:
+		return fmt.Errorf("block is nil")
+	}
+
+	header := block.Header()
+	if header.Hash() == "" {
+		return fmt.Errorf("hash is empty")
+	}
+
+	storage, err := s.storage.Engin()
+	if err != nil {
+		return err
+	}
+
+	data, err := s.encodeBlock(block)
+	if err != nil {
+		return err
+	}
+
+	s.referenceMutex.Lock()
+	defer s.referenceMutex.Unlock()
+
+	count, _, _, err := s.getReference(storage, header.Hash())
+	switch {
+	case err != nil:
+		return err
+	case count == 0:
+		return fmt.Errorf("content is not stored")
+	}
+	return storage.Put(s.makeContentKey(header.Hash()), data, &opt.WriteOptions{Sync: true})
+}
+
+func (s *LevelDBObjectStorage) ReconcileContent(
+	c context.Context, hash string, references int64, before time.Time,
+) (int64, bool, error) {
+	log.FromContext(c).Debugf("[LevelDBObjectStorage.ReconcileContent] hash: %s, references: %d, before: %s", hash, references, before)
+	switch {
+	case c == nil:
+		return 0, false, fmt.Errorf("context is nil")
+	case hash == "":
+		return 0, false, fmt.Errorf("hash is empty")
+	case references < 0:
+		return 0, false, fmt.Errorf("references is invalid")
+	}
+
+	storage, err := s.storage.Engin()
+	if err != nil {
+		return 0, false, err
+	}
+
+	s.referenceMutex.Lock()
+	defer s.referenceMutex.Unlock()
+
+	count, size, referencedAt, err := s.getReference(storage, hash)
+	switch {
+	case err != nil:
+		return 0, false, err
+	case count == 0, count == references, !referencedAt.Before(before):
+		return count, false, nil
+	}
+
+	batch := new(leveldb.Batch)
+	if references == 0 {
+		batch.Delete(s.makeContentKey(hash))
+		batch.Delete(s.makeReferenceKey(hash))
+	} else {
+		batch.Put(s.makeReferenceKey(hash), s.encodeReference(references, size, referencedAt))
+	}
+
+	if err := storage.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+		return 0, false, err
+	}
+	return count, true, nil
+}
+
+func (s *LevelDBObjectStorage) ContentStats(c context.Context) (repository.ContentStats, error) {
+	log.FromContext(c).Debugf("[LevelDBObjectStorage.ContentStats]")
+	stats := repository.ContentStats{}
+	if c == nil {
+		return stats, fmt.Errorf("context is nil")
+	}
+
+	storage, err := s.storage.Engin()
+	if err != nil {
+		return stats, err
+	}
+
+	iter := storage.NewIterator(util.BytesPrefix([]byte(referencePrefix)), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		count, size, _, err := s.decodeReference(iter.Value())
+		if err != nil {
+			log.FromContext(c).Warnf("[LevelDBObjectStorage.ContentStats] skip reference %s. %s", iter.Key(), err.Error())
+			continue
+		}
+
+		stats.Blocks++
+		stats.References += count
+		stats.PhysicalBytes += int64(size)
+		stats.LogicalBytes += count * int64(size)
+	}
+
+	if err := iter.Error(); err != nil {
+		return repository.ContentStats{}, err
+	}
+	return stats, nil
+}
+
+// getReference returns a zero count for content that is not stored
+func (s *LevelDBObjectStorage) getReference(storage *leveldb.DB, hash string) (int64, int, time.Time, error) {
+	data, err := storage.Get(s.makeReferenceKey(hash), nil)
+	switch {
+	case err == leveldb.ErrNotFound:
+		return 0, 0, time.Time{}, nil
+	case err != nil:
+		return 0, 0, time.Time{}, err
+	}
+	return s.decodeReference(data)
+}
+
+func (s *LevelDBObjectStorage) makeContentKey(hash string) []byte {
+	return []byte(contentPrefix + hash)
+}
+
+func (s *LevelDBObjectStorage) makeReferenceKey(hash string) []byte {
+	return []byte(referencePrefix + hash)
+}
+
+func (s *LevelDBObjectStorage) encodeReference(count int64, size int, referencedAt time.Time) []byte {
+	nanos := int64(0)
+	if !referencedAt.IsZero() {
+		nanos = referencedAt.UnixNano()
+	}
+	return []byte(fmt.Sprintf("%d:%d:%d", count, size, nanos))
+}
+
+// decodeReference also reads a reference written before the time of the last change was kept,
+// which is then treated as long unchanged.
+func (s *LevelDBObjectStorage) decodeReference(data []byte) (int64, int, time.Time, error) {
+	fields := strings.Split(string(data), ":")
+	if len(fields) != 2 && len(fields) != 3 {
+		return 0, 0, time.Time{}, fmt.Errorf("reference is invalid")
+	}
+
+	count, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+
+	size, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+
+	referencedAt := time.Time{}
+	if len(fields) == 3 {
+		nanos, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return 0, 0, time.Time{}, err
+		}
+		referencedAt = time.Unix(0, nanos)
+	}
+	return count, size, referencedAt, nil
+}
+
 func (s *LevelDBObjectStorage) makeKey(objectID entity.ObjectID, blockID entity.BlockID, index int) []byte {
 	key := fmt.Sprintf("%s:%d:%d", objectID, blockID, index)
 	return []byte(key)
 }
 
+func (s *LevelDBObjectStorage) makeQuarantineKey(key []byte) []byte {
+	return append([]byte(quarantinePrefix), key...)
+}
+
+func (s *LevelDBObjectStorage) parseKey(key string) (entity.ObjectID, entity.BlockID, int, error) {
+	fields := strings.Split(key, ":")
+	if len(fields) != 3 {
+		return 0, 0, 0, fmt.Errorf("key is invalid")
+	}
+
+	objectID, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	blockID, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	index, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return entity.NewObjectIDFrom(objectID), entity.NewBlockIDFrom(blockID), index, nil
+}
+
 func (s *LevelDBObjectStorage) encodeBlock(block *entity.Block) ([]byte, error) {
 	var buffer bytes.Buffer
 	encoder := gob.NewEncoder(&buffer)
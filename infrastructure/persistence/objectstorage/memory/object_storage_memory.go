@@ -25,20 +25,35 @@ package objectstorage
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/repository"
 	"github.com/ISSuh/sos/internal/log"
 )
 
+// the cursor of a scan over the deduplicated content, which sorts after every block key
+const contentPrefix = "~content:"
+
 type localObjectStorage struct {
-	storage map[string]entity.Block
+	mutex        sync.RWMutex
+	storage      map[string]entity.Block
+	quarantined  map[string]entity.Block
+	contents     map[string]entity.Block
+	references   map[string]int64
+	referencedAt map[string]time.Time
 }
 
 func NewLocalObjectStorage() (repository.ObjectStorage, error) {
 	return &localObjectStorage{
-			storage: make(map[string]entity.Block),
+			storage:      make(map[string]entity.Block),
+			quarantined:  make(map[string]entity.Block),
+			contents:     make(map[string]entity.Block),
+			references:   make(map[string]int64),
+			referencedAt: make(map[string]time.Time),
 		},
 		nil
 }
@@ -47,6 +62,9 @@ func (s *localObjectStorage) Put(c context.Context, block *entity.Block) error {
 	log.FromContext(c).Debugf("[localObjectStorage.Put] block header: %+v", block.Header())
 	header := block.Header()
 	key := s.makeKey(header.ObjectID(), header.BlockID(), header.Index())
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.storage[key] = *block
 	return nil
 }
@@ -54,6 +72,9 @@ func (s *localObjectStorage) Put(c context.Context, block *entity.Block) error {
 func (s *localObjectStorage) GetBlock(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) (*entity.Block, error) {
 	log.FromContext(c).Debugf("[localObjectStorage.GetBlock] objectID: %s, blockID : %d, index: %d", objectID, blockID, index)
 	key := s.makeKey(objectID, blockID, index)
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	block, exist := s.storage[key]
 	if !exist {
 		return nil, fmt.Errorf("block not found")
@@ -64,6 +85,9 @@ func (s *localObjectStorage) GetBlock(c context.Context, objectID entity.ObjectI
 func (s *localObjectStorage) GetBlockHeader(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) (*entity.BlockHeader, error) {
 	log.FromContext(c).Debugf("[localObjectStorage.GetBlockHeader] objectID: %s, blockID : %d, index: %d", objectID, blockID, index)
 	key := s.makeKey(objectID, blockID, index)
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	block, exist := s.storage[key]
 	if !exist {
 		return nil, fmt.Errorf("block not found")
@@ -76,10 +100,253 @@ func (s *localObjectStorage) GetBlockHeader(c context.Context, objectID entity.O
 func (s *localObjectStorage) Delete(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) error {
 	log.FromContext(c).Debugf("[localObjectStorage.Delete] objectID: %s, blockID : %d, index: %d", objectID, blockID, index)
 	key := s.makeKey(objectID, blockID, index)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.storage, key)
+	return nil
+}
+
+func (s *localObjectStorage) Scan(c context.Context, cursor string, limit int) ([]repository.ScannedBlock, string, error) {
+	log.FromContext(c).Debugf("[localObjectStorage.Scan] cursor: %s, limit: %d", cursor, limit)
+	if cursor < contentPrefix {
+		blocks, next, err := s.scan(s.storage, cursor, limit)
+		if err != nil || next != "" {
+			return blocks, next, err
+		}
+		return blocks, contentPrefix, nil
+	}
+	return s.scanContents(cursor, limit)
+}
+
+func (s *localObjectStorage) ScanQuarantined(c context.Context, cursor string, limit int) ([]repository.ScannedBlock, string, error) {
+	log.FromContext(c).Debugf("[localObjectStorage.ScanQuarantined] cursor: %s, limit: %d", cursor, limit)
+	return s.scan(s.quarantined, cursor, limit)
+}
+
+func (s *localObjectStorage) scan(
+	storage map[string]entity.Block, cursor string, limit int,
+) ([]repository.ScannedBlock, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit is invalid")
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := make([]string, 0, len(storage))
+	for key := range storage {
+		if key > cursor {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	blocks := make([]repository.ScannedBlock, 0, limit)
+	next := ""
+	for _, key := range keys {
+		if len(blocks) == limit {
+			break
+		}
+
+		block := storage[key]
+		blocks = append(blocks, repository.ScannedBlock{
+			ObjectID: block.ObjectID(),
+			BlockID:  block.BlockID(),
+			Index:    block.Index(),
+			Size:     len(block.Buffer()),
+			Block:    &block,
+		})
+		next = key
+	}
+
+	if len(blocks) < limit {
+		next = ""
+	}
+	return blocks, next, nil
+}
+
+func (s *localObjectStorage) scanContents(cursor string, limit int) ([]repository.ScannedBlock, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit is invalid")
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := make([]string, 0, len(s.contents))
+	for hash := range s.contents {
+		if key := contentPrefix + hash; key > cursor {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	blocks := make([]repository.ScannedBlock, 0, limit)
+	next := ""
+	for _, key := range keys {
+		if len(blocks) == limit {
+			break
+		}
+
+		hash := key[len(contentPrefix):]
+		block := s.contents[hash]
+		blocks = append(blocks, repository.ScannedBlock{
+			Size:         len(block.Buffer()),
+			Block:        &block,
+			Hash:         hash,
+			References:   s.references[hash],
+			ReferencedAt: s.referencedAt[hash],
+		})
+		next = key
+	}
+
+	if len(blocks) < limit {
+		next = ""
+	}
+	return blocks, next, nil
+}
+
+func (s *localObjectStorage) Quarantine(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) error {
+	log.FromContext(c).Debugf("[localObjectStorage.Quarantine] objectID: %s, blockID : %d, index: %d", objectID, blockID, index)
+	key := s.makeKey(objectID, blockID, index)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	block, exist := s.storage[key]
+	if !exist {
+		return fmt.Errorf("block not found")
+	}
+
+	s.quarantined[key] = block
 	delete(s.storage, key)
 	return nil
 }
 
+func (s *localObjectStorage) DeleteQuarantined(c context.Context, objectID entity.ObjectID, blockID entity.BlockID, index int) error {
+	log.FromContext(c).Debugf("[localObjectStorage.DeleteQuarantined] objectID: %s, blockID : %d, index: %d", objectID, blockID, index)
+	key := s.makeKey(objectID, blockID, index)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.quarantined, key)
+	return nil
+}
+
+func (s *localObjectStorage) PutContent(c context.Context, block *entity.Block) error {
+	log.FromContext(c).Debugf("[localObjectStorage.PutContent] block header: %+v", block.Header())
+	header := block.Header()
+	if header.Hash() == "" {
+		return fmt.Errorf("hash is empty")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, exist := s.contents[header.Hash()]; !exist {
+		s.contents[header.Hash()] = *block
+	}
+	s.references[header.Hash()]++
+	s.referencedAt[header.Hash()] = time.Now()
+	return nil
+}
+
+func (s *localObjectStorage) ReferenceContent(c context.Context, hash string) (bool, error) {
+	log.FromContext(c).Debugf("[localObjectStorage.ReferenceContent] hash: %s", hash)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, exist := s.contents[hash]; !exist {
+		return false, nil
+	}
+	s.references[hash]++
+	s.referencedAt[hash] = time.Now()
+	return true, nil
+}
+
+func (s *localObjectStorage) GetContent(c context.Context, hash string) (*entity.Block, error) {
+	log.FromContext(c).Debugf("[localObjectStorage.GetContent] hash: %s", hash)
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	block, exist := s.contents[hash]
+	if !exist {
+		return nil, fmt.Errorf("content not found")
+	}
+	return &block, nil
+}
+
+func (s *localObjectStorage) ReleaseContent(c context.Context, hash string) error {
+	log.FromContext(c).Debugf("[localObjectStorage.ReleaseContent] hash: %s", hash)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.references[hash] > 1 {
+		s.references[hash]--
+		s.referencedAt[hash] = time.Now()
+		return nil
+	}
+	delete(s.contents, hash)
+	delete(s.references, hash)
+	delete(s.referencedAt, hash)
+	return nil
+}
+
+func (s *localObjectStorage) ReplaceContent(c context.Context, block *entity.Block) error {
+	log.FromContext(c).Debugf("[localObjectStorage.ReplaceContent] block header: %+v", block.Header())
+	header := block.Header()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, exist := s.contents[header.Hash()]; !exist {
+		return fmt.Errorf("content not found")
+	}
+	s.contents[header.Hash()] = *block
+	return nil
+}
+
+func (s *localObjectStorage) ReconcileContent(
+	c context.Context, hash string, references int64, before time.Time,
+) (int64, bool, error) {
+	log.FromContext(c).Debugf("[localObjectStorage.ReconcileContent] hash: %s, references: %d, before: %s", hash, references, before)
+	if references < 0 {
+		return 0, false, fmt.Errorf("references is invalid")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	count := s.references[hash]
+	if count == 0 || count == references || !s.referencedAt[hash].Before(before) {
+		return count, false, nil
+	}
+
+	if references == 0 {
+		delete(s.contents, hash)
+		delete(s.references, hash)
+		delete(s.referencedAt, hash)
+		return count, true, nil
+	}
+	s.references[hash] = references
+	return count, true, nil
+}
+
+func (s *localObjectStorage) ContentStats(c context.Context) (repository.ContentStats, error) {
+	log.FromContext(c).Debugf("[localObjectStorage.ContentStats]")
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	stats := repository.ContentStats{}
+	for hash, block := range s.contents {
+		count := s.references[hash]
+		size := int64(len(block.Buffer()))
+
+		stats.Blocks++
+		stats.References += count
+		stats.PhysicalBytes += size
+		stats.LogicalBytes += count * size
+	}
+	return stats, nil
+}
+
 func (s *localObjectStorage) makeKey(objectID entity.ObjectID, blockID entity.BlockID, index int) string {
 	return objectID.String() + ":" + blockID.String() + ":" + strconv.Itoa(index)
 }
@@ -29,7 +29,27 @@ import (
 type Explorer interface {
 	Find() http.Handler
 	List() http.Handler
+	ListChildren() http.Handler
 	Upload() http.Handler
 	Download(lastVersion bool) http.Handler
+	Head(lastVersion bool) http.Handler
 	Delete(deleteObject bool) http.Handler
+	Copy(lastVersion bool) http.Handler
+	Move() http.Handler
+	GetTags() http.Handler
+	PutTags() http.Handler
+	DeleteTags() http.Handler
+	FindByTags() http.Handler
+	InitiateUpload() http.Handler
+	UploadPart() http.Handler
+	CompleteUpload() http.Handler
+	AbortUpload() http.Handler
+	GetPolicy() http.Handler
+	PutPolicy() http.Handler
+	DeletePolicy() http.Handler
+	Presign() http.Handler
+	CollectGarbage() http.Handler
+	GetGarbageReport() http.Handler
+	GetDedupReport() http.Handler
+	RotateDataKeys() http.Handler
 }
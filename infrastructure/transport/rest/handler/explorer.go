@@ -23,18 +23,33 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	gohttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/service"
 	"github.com/ISSuh/sos/infrastructure/transport/rest"
 	"github.com/ISSuh/sos/internal/apm"
+	"github.com/ISSuh/sos/internal/auth"
+	"github.com/ISSuh/sos/internal/encryption"
+	soserror "github.com/ISSuh/sos/internal/error"
 	"github.com/ISSuh/sos/internal/http"
 	"github.com/ISSuh/sos/internal/log"
 	"github.com/ISSuh/sos/internal/validation"
 )
 
+const (
+	octetStreamContentType = "application/octet-stream"
+	presignURLSuffix       = "/presign"
+)
+
 type explorer struct {
 	explorerService service.Explorer
 }
@@ -56,6 +71,7 @@ func (h *explorer) Find() http.Handler {
 		log.FromContext(c).Debugf("[explorer.Find]")
 
 		dto := dto.RequestFromContext(c, http.RequestContextKey)
+		h.conditionalRequest(r, &dto)
 		log.FromContext(c).Debugf("Request: %+v\n", dto)
 
 		span := apm.SpanStart(c, "List", "explorer", nil)
@@ -69,7 +85,7 @@ func (h *explorer) Find() http.Handler {
 		item, err := h.explorerService.GetObjectMetadata(c, dto)
 		if err != nil {
 			log.FromContext(c).Errorf("Find Error: %s\n", err.Error())
-			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
 			return
 		}
 
@@ -78,6 +94,26 @@ func (h *explorer) Find() http.Handler {
 			return
 		}
 
+		// the metadata is validated by the latest version
+		if version, err := item.Versions.LastVersion(); err == nil {
+			h.objectHeaderWriter(w)(http.ObjectHeader{
+				Version:    version.Number,
+				ETag:       version.ETag,
+				ModifiedAt: version.ModifiedAt,
+			})
+			err := http.CheckReadPreconditions(
+				dto.IfMatch, dto.IfNoneMatch, dto.IfModifiedSince, version.ETag, version.ModifiedAt,
+			)
+			switch {
+			case errors.Is(err, http.ErrNotModified):
+				http.NotModified(w)
+				return
+			case err != nil:
+				gohttp.Error(w, err.Error(), h.errorStatus(err))
+				return
+			}
+		}
+
 		if err := http.Json(w, item); err != nil {
 			log.FromContext(c).Errorf("Find Error: %s\n", err.Error())
 			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
@@ -103,14 +139,14 @@ func (h *explorer) List() http.Handler {
 		span.Context.SetLabel("partition", req.Partition)
 		span.Context.SetLabel("path", req.Path)
 
-		items, err := h.explorerService.FindObjectMetadataOnPath(c, req)
+		page, err := h.explorerService.FindObjectMetadataOnPath(c, req)
 		if err != nil {
 			log.FromContext(c).Errorf("List Error: %s\n", err.Error())
-			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
 			return
 		}
 
-		if err := http.Json(w, items); err != nil {
+		if err := http.Json(w, page); err != nil {
 			log.FromContext(c).Errorf("List Error: %s\n", err.Error())
 			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
 			return
@@ -118,12 +154,44 @@ func (h *explorer) List() http.Handler {
 	}
 }
 
+func (h *explorer) ListChildren() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.ListChildren]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		span := apm.SpanStart(c, "ListChildren", "explorer", nil)
+		defer span.End()
+
+		span.Context.SetLabel("group", req.Group)
+		span.Context.SetLabel("partition", req.Partition)
+		span.Context.SetLabel("path", req.Path)
+
+		children, err := h.explorerService.ListChildren(c, req)
+		if err != nil {
+			log.FromContext(c).Errorf("ListChildren Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, children); err != nil {
+			log.FromContext(c).Errorf("ListChildren Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
 func (h *explorer) Upload() http.Handler {
 	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
 		c := r.Context()
 		log.FromContext(c).Debugf("[explorer.Upload]")
 
 		req := dto.RequestFromContext(c, http.RequestContextKey)
+		req.CustomerKey = h.customerKey(r.Header)
+		h.conditionalRequest(r, &req)
 		log.FromContext(c).Debugf("Request: %+v\n", req)
 		log.FromContext(c).Debugf("content type: %s\n", r.Header.Get("Content-Type"))
 
@@ -136,6 +204,7 @@ func (h *explorer) Upload() http.Handler {
 		}
 
 		multipartForm := r.MultipartForm
+		requestContent := h.contentMetadata(r.Header)
 
 		var items []dto.Item
 		for key, fileHeaders := range multipartForm.File {
@@ -152,6 +221,13 @@ func (h *explorer) Upload() http.Handler {
 
 				req.Name = fileHeader.Filename
 				req.Size = int(fileHeader.Size)
+				req.ContentMetadata = h.partContentMetadata(gohttp.Header(fileHeader.Header), requestContent)
+
+				if presigned, ok := auth.PresignedURLFromContext(c); ok && req.Name != presigned.Name {
+					f.Close()
+					gohttp.Error(w, fmt.Sprintf("presigned url uploads %s only", presigned.Name), gohttp.StatusForbidden)
+					return
+				}
 
 				subSpan := apm.SpanStart(c, "List", "explorer", span)
 
@@ -164,7 +240,7 @@ func (h *explorer) Upload() http.Handler {
 				item, err := h.explorerService.Upload(c, req, f)
 				if err != nil {
 					log.FromContext(c).Errorf("Upload Error: %s\n", err.Error())
-					gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+					gohttp.Error(w, err.Error(), h.errorStatus(err))
 					return
 				}
 
@@ -195,18 +271,55 @@ func (h *explorer) Download(lastVersion bool) http.Handler {
 		c := r.Context()
 
 		dto := dto.RequestFromContext(c, http.RequestContextKey)
+		dto.Range = r.Header.Get(http.RangeHeader)
+		dto.IfRange = r.Header.Get(http.IfRangeHeader)
+		dto.CustomerKey = h.customerKey(r.Header)
+		h.conditionalRequest(r, &dto)
+
 		log.FromContext(c).Debugf("[explorer.Download]")
 		log.FromContext(c).Debugf("Request: %+v\n", dto)
 
+		byteRanges := http.NewByteRangesWriter(w, octetStreamContentType)
 		writer := http.Writer{
-			Header: h.headerWriter(w),
-			Body:   h.bodyWriter(w),
+			ObjectHeader:  h.objectHeaderWriter(w),
+			NotModified:   func() { http.NotModified(w) },
+			Header:        h.headerWriter(w),
+			RangeHeader:   h.rangeHeaderWriter(w, byteRanges),
+			Part:          byteRanges.WritePart,
+			Body:          h.bodyWriter(w),
+			Close:         byteRanges.Close,
+			Unsatisfiable: h.unsatisfiableWriter(w),
 		}
 
 		err := h.explorerService.Download(c, dto, writer, lastVersion)
 		if err != nil {
 			log.FromContext(c).Errorf("Delete Error: %s\n", err.Error())
-			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+	}
+}
+
+// Head responds with the header of a download, with no body.
+func (h *explorer) Head(lastVersion bool) http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.Head]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		req.CustomerKey = h.customerKey(r.Header)
+		h.conditionalRequest(r, &req)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		writer := http.Writer{
+			ObjectHeader: h.objectHeaderWriter(w),
+			NotModified:  func() { http.NotModified(w) },
+			Header:       h.headerWriter(w),
+		}
+
+		if err := h.explorerService.Head(c, req, writer, lastVersion); err != nil {
+			log.FromContext(c).Errorf("Head Error: %s\n", err.Error())
+			w.WriteHeader(h.errorStatus(err))
 			return
 		}
 	}
@@ -218,21 +331,515 @@ func (h *explorer) Delete(deleteVersion bool) http.Handler {
 		log.FromContext(c).Debugf("[explorer.Delete]")
 
 		dto := dto.RequestFromContext(c, http.RequestContextKey)
+		h.conditionalRequest(r, &dto)
 		err := h.explorerService.Delete(c, dto, deleteVersion)
 		if err != nil {
 			log.FromContext(c).Errorf("Delete Error: %s\n", err.Error())
+			status := gohttp.StatusBadRequest
+			switch {
+			case errors.Is(err, soserror.Forbidden):
+				status = gohttp.StatusForbidden
+			case errors.Is(err, soserror.PreconditionFailed):
+				status = gohttp.StatusPreconditionFailed
+			}
+			gohttp.Error(w, err.Error(), status)
+			return
+		}
+
+		http.NoContent(w)
+	}
+}
+
+// Copy copies the object to the destination of the request body. The preconditions of the request
+// are evaluated against the destination.
+func (h *explorer) Copy(lastVersion bool) http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.Copy]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		req.CustomerKey = h.customerKey(r.Header)
+		h.conditionalRequest(r, &req)
+
+		// without a body the version is copied onto the object itself, which restores it as the latest
+		if err := json.NewDecoder(r.Body).Decode(&req.Destination); err != nil && err != io.EOF {
+			gohttp.Error(w, err.Error(), gohttp.StatusBadRequest)
+			return
+		}
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		item, err := h.explorerService.Copy(c, req, lastVersion)
+		if err != nil {
+			log.FromContext(c).Errorf("Copy Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, item); err != nil {
+			log.FromContext(c).Errorf("Copy Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// Move renames the object or moves it to the destination of the request body.
+func (h *explorer) Move() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.Move]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		if err := json.NewDecoder(r.Body).Decode(&req.Destination); err != nil {
+			gohttp.Error(w, err.Error(), gohttp.StatusBadRequest)
+			return
+		}
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		item, err := h.explorerService.Move(c, req)
+		if err != nil {
+			log.FromContext(c).Errorf("Move Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, item); err != nil {
+			log.FromContext(c).Errorf("Move Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (h *explorer) GetTags() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.GetTags]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		tags, err := h.explorerService.GetTags(c, req)
+		if err != nil {
+			log.FromContext(c).Errorf("GetTags Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, tags); err != nil {
+			log.FromContext(c).Errorf("GetTags Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// PutTags replaces the tags of the object with the ones of the request body.
+func (h *explorer) PutTags() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.PutTags]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		var body dto.ObjectTags
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			gohttp.Error(w, err.Error(), gohttp.StatusBadRequest)
+			return
+		}
+
+		req.Tags = body.Tags
+		tags, err := h.explorerService.PutTags(c, req)
+		if err != nil {
+			log.FromContext(c).Errorf("PutTags Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, tags); err != nil {
+			log.FromContext(c).Errorf("PutTags Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (h *explorer) DeleteTags() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.DeleteTags]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		if err := h.explorerService.DeleteTags(c, req); err != nil {
+			log.FromContext(c).Errorf("DeleteTags Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		http.NoContent(w)
+	}
+}
+
+func (h *explorer) FindByTags() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.FindByTags]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		span := apm.SpanStart(c, "FindByTags", "explorer", nil)
+		defer span.End()
+
+		span.Context.SetLabel("group", req.Group)
+		span.Context.SetLabel("partition", req.Partition)
+
+		page, err := h.explorerService.FindObjectsByTags(c, req)
+		if err != nil {
+			log.FromContext(c).Errorf("FindByTags Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, page); err != nil {
+			log.FromContext(c).Errorf("FindByTags Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (h *explorer) InitiateUpload() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.InitiateUpload]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		req.ContentMetadata = h.contentMetadata(r.Header)
+		req.CustomerKey = h.customerKey(r.Header)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		span := apm.SpanStart(c, "InitiateUpload", "explorer", nil)
+		defer span.End()
+
+		span.Context.SetLabel("group", req.Group)
+		span.Context.SetLabel("partition", req.Partition)
+		span.Context.SetLabel("path", req.Path)
+		span.Context.SetLabel("name", req.Name)
+
+		session, err := h.explorerService.InitiateUpload(c, req)
+		if err != nil {
+			log.FromContext(c).Errorf("InitiateUpload Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, session); err != nil {
+			log.FromContext(c).Errorf("InitiateUpload Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (h *explorer) UploadPart() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.UploadPart]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		req.CustomerKey = h.customerKey(r.Header)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		span := apm.SpanStart(c, "UploadPart", "explorer", nil)
+		defer span.End()
+
+		span.Context.SetLabel("uploadID", req.UploadID)
+		span.Context.SetLabel("partNumber", req.PartNumber)
+		span.Context.SetLabel("size", req.Size)
+
+		part, err := h.explorerService.UploadPart(c, req, r.Body)
+		if err != nil {
+			log.FromContext(c).Errorf("UploadPart Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, part); err != nil {
+			log.FromContext(c).Errorf("UploadPart Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (h *explorer) CompleteUpload() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.CompleteUpload]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		h.conditionalRequest(r, &req)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		span := apm.SpanStart(c, "CompleteUpload", "explorer", nil)
+		defer span.End()
+
+		span.Context.SetLabel("uploadID", req.UploadID)
+
+		item, err := h.explorerService.CompleteUpload(c, req)
+		if err != nil {
+			log.FromContext(c).Errorf("CompleteUpload Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, item); err != nil {
+			log.FromContext(c).Errorf("CompleteUpload Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (h *explorer) AbortUpload() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.AbortUpload]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		span := apm.SpanStart(c, "AbortUpload", "explorer", nil)
+		defer span.End()
+
+		span.Context.SetLabel("uploadID", req.UploadID)
+
+		if err := h.explorerService.AbortUpload(c, req); err != nil {
+			log.FromContext(c).Errorf("AbortUpload Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		http.NoContent(w)
+	}
+}
+
+func (h *explorer) GetPolicy() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.GetPolicy]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		policy, err := h.explorerService.GetPolicy(c, req)
+		if err != nil {
+			log.FromContext(c).Errorf("GetPolicy Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, policy); err != nil {
+			log.FromContext(c).Errorf("GetPolicy Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (h *explorer) PutPolicy() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.PutPolicy]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		var policy dto.Policy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
 			gohttp.Error(w, err.Error(), gohttp.StatusBadRequest)
 			return
 		}
 
+		policy.Group = req.Group
+		policy.Partition = req.Partition
+		if err := policy.Validate(); err != nil {
+			gohttp.Error(w, err.Error(), gohttp.StatusBadRequest)
+			return
+		}
+
+		stored, err := h.explorerService.PutPolicy(c, req, policy)
+		if err != nil {
+			log.FromContext(c).Errorf("PutPolicy Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, stored); err != nil {
+			log.FromContext(c).Errorf("PutPolicy Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (h *explorer) DeletePolicy() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.DeletePolicy]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		if err := h.explorerService.DeletePolicy(c, req); err != nil {
+			log.FromContext(c).Errorf("DeletePolicy Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
 		http.NoContent(w)
 	}
 }
+
+func (h *explorer) errorStatus(err error) int {
+	switch {
+	case errors.Is(err, soserror.NotFound):
+		return gohttp.StatusNotFound
+	case errors.Is(err, soserror.Forbidden):
+		return gohttp.StatusForbidden
+	case errors.Is(err, auth.ErrPresignExpiryOutOfRange):
+		return gohttp.StatusBadRequest
+	case errors.Is(err, entity.ErrUserMetadataTooLarge):
+		return gohttp.StatusBadRequest
+	case errors.Is(err, entity.ErrInvalidTags), errors.Is(err, entity.ErrInvalidTagExpression):
+		return gohttp.StatusBadRequest
+	case errors.Is(err, encryption.ErrInvalidCustomerKey):
+		return gohttp.StatusBadRequest
+	case errors.Is(err, soserror.Conflict):
+		return gohttp.StatusConflict
+	case errors.Is(err, soserror.PreconditionFailed):
+		return gohttp.StatusPreconditionFailed
+	case errors.Is(err, service.ErrGarbageCollectionRunning):
+		return gohttp.StatusConflict
+	}
+	return gohttp.StatusInternalServerError
+}
+
+func (h *explorer) conditionalRequest(r *gohttp.Request, req *dto.Request) {
+	req.IfMatch = r.Header.Get(http.IfMatchHeader)
+	req.IfNoneMatch = r.Header.Get(http.IfNoneMatchHeader)
+	req.IfModifiedSince = r.Header.Get(http.IfModifiedSinceHeader)
+}
+
+// customerKey reads the key the client encrypts the object with from the request headers.
+func (h *explorer) customerKey(header gohttp.Header) dto.CustomerKey {
+	return dto.CustomerKey{
+		Algorithm: header.Get(http.CustomerKeyAlgorithmHeader),
+		Key:       header.Get(http.CustomerKeyHeader),
+		KeyMD5:    header.Get(http.CustomerKeyMD5Header),
+	}
+}
+
+func (h *explorer) objectHeaderWriter(w gohttp.ResponseWriter) http.DownloadObjectHeaderWriter {
+	return func(header http.ObjectHeader) {
+		w.Header().Set(http.VersionHeader, strconv.Itoa(header.Version))
+		w.Header().Set(http.ETagHeader, http.QuoteETag(header.ETag))
+		w.Header().Set(http.LastModifiedHeader, header.ModifiedAt.UTC().Format(gohttp.TimeFormat))
+
+		if !validation.IsEmpty(header.ContentType) {
+			w.Header().Set(http.ContentTypeHeader, header.ContentType)
+		}
+		if !validation.IsEmpty(header.ContentEncoding) {
+			w.Header().Set(http.ContentEncodingHeader, header.ContentEncoding)
+		}
+		if !validation.IsEmpty(header.CacheControl) {
+			w.Header().Set(http.CacheControlHeader, header.CacheControl)
+		}
+		for key, value := range header.UserMetadata {
+			w.Header().Set(http.UserMetadataHeaderPrefix+key, value)
+		}
+	}
+}
+
+// contentMetadata reads the content metadata of an upload from the request headers.
+func (h *explorer) contentMetadata(header gohttp.Header) entity.ContentMetadata {
+	content := entity.ContentMetadata{
+		ContentType:     header.Get(http.ContentTypeHeader),
+		ContentEncoding: header.Get(http.ContentEncodingHeader),
+		CacheControl:    header.Get(http.CacheControlHeader),
+	}
+
+	for key, values := range header {
+		name, found := strings.CutPrefix(gohttp.CanonicalHeaderKey(key), http.UserMetadataHeaderPrefix)
+		if !found || validation.IsEmpty(name) || len(values) == 0 {
+			continue
+		}
+
+		if content.UserMetadata == nil {
+			content.UserMetadata = map[string]string{}
+		}
+		content.UserMetadata[strings.ToLower(name)] = values[0]
+	}
+	return content
+}
+
+// partContentMetadata reads the content metadata of a multipart form file. The type comes from the
+// part, the other values fall back to the ones of the request when the part has none.
+func (h *explorer) partContentMetadata(header gohttp.Header, request entity.ContentMetadata) entity.ContentMetadata {
+	content := h.contentMetadata(header)
+	if validation.IsEmpty(content.ContentEncoding) {
+		content.ContentEncoding = request.ContentEncoding
+	}
+	if validation.IsEmpty(content.CacheControl) {
+		content.CacheControl = request.CacheControl
+	}
+	if content.UserMetadata == nil {
+		content.UserMetadata = request.UserMetadata
+	}
+	return content
+}
+
 func (h *explorer) headerWriter(w gohttp.ResponseWriter) http.DownloadHeaderWriter {
 	return func(name string, size int) {
 		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", name))
-		w.Header().Set("Content-Type", "application/octet-stream")
+		if validation.IsEmpty(w.Header().Get(http.ContentTypeHeader)) {
+			w.Header().Set(http.ContentTypeHeader, octetStreamContentType)
+		}
 		w.Header().Set("Content-Length", fmt.Sprintf("%d", size))
+		w.Header().Set("Accept-Ranges", "bytes")
+	}
+}
+
+func (h *explorer) rangeHeaderWriter(
+	w gohttp.ResponseWriter, byteRanges *http.ByteRangesWriter,
+) http.DownloadRangeHeaderWriter {
+	return func(name string, size int, ranges http.Ranges) {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", name))
+		w.Header().Set("Accept-Ranges", "bytes")
+
+		if len(ranges) == 1 {
+			if validation.IsEmpty(w.Header().Get(http.ContentTypeHeader)) {
+				w.Header().Set(http.ContentTypeHeader, octetStreamContentType)
+			}
+			w.Header().Set("Content-Range", ranges[0].ContentRange(size))
+			w.Header().Set("Content-Length", fmt.Sprintf("%d", ranges[0].Length))
+		} else {
+			// the object type written with its header moves into the parts
+			if contentType := w.Header().Get(http.ContentTypeHeader); !validation.IsEmpty(contentType) {
+				byteRanges.SetContentType(contentType)
+			}
+			w.Header().Set("Content-Type", byteRanges.ContentType())
+			w.Header().Set("Content-Length", fmt.Sprintf("%d", byteRanges.ContentLength(ranges, size)))
+		}
+
+		w.WriteHeader(gohttp.StatusPartialContent)
+	}
+}
+
+func (h *explorer) unsatisfiableWriter(w gohttp.ResponseWriter) http.DownloadUnsatisfiableWriter {
+	return func(size int) {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		gohttp.Error(w, http.ErrRangeNotSatisfiable.Error(), gohttp.StatusRequestedRangeNotSatisfiable)
 	}
 }
 
@@ -245,3 +852,142 @@ func (h *explorer) bodyWriter(w gohttp.ResponseWriter) http.DownloadBodyWriter {
 		return nil
 	}
 }
+
+func (h *explorer) Presign() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.Presign]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		log.FromContext(c).Debugf("Request: %+v\n", req)
+
+		presigned, err := h.explorerService.Presign(c, req)
+		if err != nil {
+			log.FromContext(c).Errorf("Presign Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		// the presigned url is the object url next to the presign url, e.g. .../{path}/{objectID}
+		path := strings.TrimSuffix(r.URL.EscapedPath(), presignURLSuffix)
+		if presigned.Method == gohttp.MethodGet {
+			path += fmt.Sprintf("/%d", presigned.ObjectID)
+			if presigned.Version >= 0 {
+				path += fmt.Sprintf("/version/%d", presigned.Version)
+			}
+		}
+
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+
+		unescapedPath, err := url.PathUnescape(path)
+		if err != nil {
+			gohttp.Error(w, err.Error(), gohttp.StatusBadRequest)
+			return
+		}
+
+		u := url.URL{
+			Scheme:   scheme,
+			Host:     r.Host,
+			Path:     unescapedPath,
+			RawPath:  path,
+			RawQuery: presigned.Query().Encode(),
+		}
+
+		resp := dto.PresignedURL{
+			Method:    presigned.Method,
+			URL:       u.String(),
+			ExpiresAt: presigned.Expires,
+		}
+
+		if err := http.Json(w, resp); err != nil {
+			log.FromContext(c).Errorf("Presign Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (h *explorer) CollectGarbage() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.CollectGarbage]")
+
+		req := dto.RequestFromContext(c, http.RequestContextKey)
+		report, err := h.explorerService.CollectGarbage(c, req)
+		if err != nil {
+			log.FromContext(c).Errorf("CollectGarbage Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, report); err != nil {
+			log.FromContext(c).Errorf("CollectGarbage Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (h *explorer) GetGarbageReport() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.GetGarbageReport]")
+
+		report, err := h.explorerService.GetGarbageReport(c)
+		if err != nil {
+			log.FromContext(c).Errorf("GetGarbageReport Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, report); err != nil {
+			log.FromContext(c).Errorf("GetGarbageReport Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (h *explorer) GetDedupReport() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.GetDedupReport]")
+
+		report, err := h.explorerService.GetDedupReport(c)
+		if err != nil {
+			log.FromContext(c).Errorf("GetDedupReport Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, report); err != nil {
+			log.FromContext(c).Errorf("GetDedupReport Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// RotateDataKeys wraps the data keys of every object version again with the primary master key.
+func (h *explorer) RotateDataKeys() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[explorer.RotateDataKeys]")
+
+		report, err := h.explorerService.RotateDataKeys(c)
+		if err != nil {
+			log.FromContext(c).Errorf("RotateDataKeys Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), h.errorStatus(err))
+			return
+		}
+
+		if err := http.Json(w, report); err != nil {
+			log.FromContext(c).Errorf("RotateDataKeys Error: %s\n", err.Error())
+			gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package middleware
+
+import (
+	"errors"
+	gohttp "net/http"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/internal/auth"
+	"github.com/ISSuh/sos/internal/http"
+	"github.com/ISSuh/sos/internal/log"
+)
+
+type ErrorWriter func(w gohttp.ResponseWriter, r *gohttp.Request, err error)
+
+// Authenticate rejects requests without a valid signature and puts the authenticated principal
+// into the request context.
+func Authenticate(verifier *auth.Verifier, writeError ErrorWriter) http.MiddlewareFunc {
+	return func(next gohttp.HandlerFunc) gohttp.HandlerFunc {
+		return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+			c := r.Context()
+			principal, chunkVerifier, err := verifier.Verify(r)
+			if err != nil {
+				log.FromContext(c).Infof("[Authenticate] rejected %s %s from %s: %s", r.Method, r.URL.Path, r.RemoteAddr, err.Error())
+				writeError(w, r, err)
+				return
+			}
+
+			log.FromContext(c).Infof("[Authenticate] principal %s (%s): %s %s", principal.Name, principal.AccessKey, r.Method, r.URL.Path)
+
+			c = auth.WithPrincipal(c, principal)
+			if chunkVerifier != nil {
+				c = auth.WithChunkVerifier(c, chunkVerifier)
+			}
+			next.ServeHTTP(w, r.WithContext(c))
+		}
+	}
+}
+
+// Anonymous passes every request through, in place of Authenticate when authentication is disabled.
+func Anonymous(next gohttp.HandlerFunc) gohttp.HandlerFunc {
+	return next
+}
+
+// AcceptPresignedURL authenticates a request of a presigned URL by its signature, and any other
+// request by authenticate. It runs after the params of the request, which the signature covers, are parsed.
+func AcceptPresignedURL(
+	presigner *auth.Presigner, authenticate http.MiddlewareFunc, writeError ErrorWriter,
+) http.MiddlewareFunc {
+	return func(next gohttp.HandlerFunc) gohttp.HandlerFunc {
+		authenticated := authenticate(next)
+		return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+			if !auth.IsPresignedURL(r) {
+				authenticated(w, r)
+				return
+			}
+
+			c := r.Context()
+			req := dto.RequestFromContext(c, http.RequestContextKey)
+			presigned, err := presigner.Verify(r, auth.PresignedURL{
+				Method:    r.Method,
+				Group:     req.Group,
+				Partition: req.Partition,
+				Path:      req.Path,
+				ObjectID:  req.ObjectID.ToInt64(),
+				Version:   req.Version,
+			})
+			if err != nil {
+				log.FromContext(c).Infof("[AcceptPresignedURL] rejected %s %s from %s: %s", r.Method, r.URL.Path, r.RemoteAddr, err.Error())
+				writeError(w, r, err)
+				return
+			}
+
+			log.FromContext(c).Infof("[AcceptPresignedURL] presigned by %s: %s %s", presigned.Principal, r.Method, r.URL.Path)
+
+			c = auth.WithPresignedURL(c, presigned)
+			if presigned.Principal != "" {
+				c = auth.WithPrincipal(c, auth.Principal{Name: presigned.Principal})
+			}
+			next.ServeHTTP(w, r.WithContext(c))
+		}
+	}
+}
+
+func WriteAuthError(w gohttp.ResponseWriter, _ *gohttp.Request, err error) {
+	var authErr *auth.Error
+	if errors.As(err, &authErr) {
+		gohttp.Error(w, authErr.Error(), authErr.Status)
+		return
+	}
+	gohttp.Error(w, err.Error(), gohttp.StatusInternalServerError)
+}
@@ -25,7 +25,9 @@ package middleware
 import (
 	"context"
 	gohttp "net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/ISSuh/sos/domain/model/dto"
 	"github.com/ISSuh/sos/domain/model/entity"
@@ -37,26 +39,33 @@ func ParseDefaultParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
 	return gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
 		params := http.ParseParm(r)
 
-		group := params[http.GroupParamName]
-		if validation.IsEmpty(group) {
+		// a route apart from the groups, e.g. the garbage collection, has no group
+		groupParam, exist := params[http.GroupParamName]
+		if !exist {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		partition := params[http.PartitionParamName]
-		if validation.IsEmpty(partition) {
+		group, err := url.PathUnescape(groupParam)
+		if err != nil || validation.IsEmpty(group) {
 			return
 		}
 
-		path := params[http.ObjectPathParamName]
-		if validation.IsEmpty(path) {
+		// a group level route, e.g. the policy of a group, has no partition
+		partition, err := url.PathUnescape(params[http.PartitionParamName])
+		if err != nil {
 			return
 		}
 
-		versionStr := params[http.VersionName]
-		if validation.IsEmpty(path) {
+		// the partition root route has no object path and lands on the root directory
+		path, err := url.PathUnescape(params[http.ObjectPathParamName])
+		if err != nil {
 			return
 		}
+		// objects are stored on the clean path, the registry rewrites the paths stored before at startup
+		path = entity.CleanDirectoryPath(path)
 
+		versionStr := params[http.VersionName]
 		version, err := strconv.Atoi(versionStr)
 		if err != nil {
 			version = -1
@@ -97,6 +106,112 @@ func ParseObjectIDParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
 	})
 }
 
+func ParseUploadIDParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
+	return gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		params := http.ParseParm(r)
+
+		uploadID := params[http.UploadIDParamName]
+		if validation.IsEmpty(uploadID) {
+			return
+		}
+
+		req := dto.RequestFromContext(r.Context(), http.RequestContextKey)
+
+		id, err := strconv.ParseInt(uploadID, 10, 64)
+		if err != nil {
+			return
+		}
+
+		req.UploadID = entity.NewUploadIDFrom(id)
+
+		ctx := context.WithValue(r.Context(), http.RequestContextKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func ParsePartNumberParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
+	return gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		params := http.ParseParm(r)
+
+		partNumberStr := params[http.PartNumberParamName]
+		if validation.IsEmpty(partNumberStr) {
+			return
+		}
+
+		partNumber, err := strconv.Atoi(partNumberStr)
+		if err != nil {
+			return
+		}
+
+		req := dto.RequestFromContext(r.Context(), http.RequestContextKey)
+		req.PartNumber = partNumber
+		req.Size = int(r.ContentLength)
+
+		ctx := context.WithValue(r.Context(), http.RequestContextKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func ParseNameQueryParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
+	return gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		name := r.URL.Query().Get(http.ObjectName)
+		if validation.IsEmpty(name) {
+			return
+		}
+
+		req := dto.RequestFromContext(r.Context(), http.RequestContextKey)
+		req.Name = name
+
+		ctx := context.WithValue(r.Context(), http.RequestContextKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func ParseListQueryParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
+	return gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		req := dto.RequestFromContext(r.Context(), http.RequestContextKey)
+
+		query := r.URL.Query()
+		if limitStr := query.Get(http.LimitName); !validation.IsEmpty(limitStr) {
+			limit, err := strconv.Atoi(limitStr)
+			if err != nil || limit < 0 {
+				gohttp.Error(w, "limit is invalid", gohttp.StatusBadRequest)
+				return
+			}
+			req.Limit = limit
+		}
+
+		if cursor := query.Get(http.CursorName); !validation.IsEmpty(cursor) {
+			id, err := strconv.ParseInt(cursor, 10, 64)
+			if err != nil || id < 0 {
+				gohttp.Error(w, "cursor is invalid", gohttp.StatusBadRequest)
+				return
+			}
+			req.LastObjectID = entity.NewObjectIDFrom(id)
+		}
+
+		ctx := context.WithValue(r.Context(), http.RequestContextKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ParseTagQueryParam reads the terms of a tag expression, one term per tag query parameter.
+func ParseTagQueryParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
+	return gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		terms := r.URL.Query()[http.TagName]
+		if len(terms) == 0 {
+			gohttp.Error(w, "tag is empty", gohttp.StatusBadRequest)
+			return
+		}
+
+		req := dto.RequestFromContext(r.Context(), http.RequestContextKey)
+		req.TagExpression = terms
+
+		ctx := context.WithValue(r.Context(), http.RequestContextKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func ParseQueryParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
 	return gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
 		name := r.URL.Query().Get(http.ObjectName)
@@ -118,3 +233,98 @@ func ParseQueryParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// ParsePresignQueryParam reads the URL to presign, the object id and version for a download
+// or the name for an upload, with its method and expiry in seconds.
+func ParsePresignQueryParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
+	return gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		req := dto.RequestFromContext(r.Context(), http.RequestContextKey)
+
+		query := r.URL.Query()
+		req.Method = query.Get(http.MethodName)
+		switch req.Method {
+		case "":
+			req.Method = gohttp.MethodGet
+		case gohttp.MethodGet, gohttp.MethodPut:
+		default:
+			gohttp.Error(w, "method is invalid", gohttp.StatusBadRequest)
+			return
+		}
+
+		if objectIDStr := query.Get(http.ObjectIDName); !validation.IsEmpty(objectIDStr) {
+			id, err := strconv.ParseInt(objectIDStr, 10, 64)
+			if err != nil {
+				gohttp.Error(w, "object id is invalid", gohttp.StatusBadRequest)
+				return
+			}
+			req.ObjectID = entity.NewObjectIDFrom(id)
+		}
+
+		if versionStr := query.Get(http.VersionName); !validation.IsEmpty(versionStr) {
+			version, err := strconv.Atoi(versionStr)
+			if err != nil || version < 0 {
+				gohttp.Error(w, "version is invalid", gohttp.StatusBadRequest)
+				return
+			}
+			req.Version = version
+		}
+
+		req.Name = query.Get(http.ObjectName)
+		if req.Method == gohttp.MethodPut && validation.IsEmpty(req.Name) {
+			gohttp.Error(w, "name is empty", gohttp.StatusBadRequest)
+			return
+		}
+
+		if expiresStr := query.Get(http.ExpiresName); !validation.IsEmpty(expiresStr) {
+			expires, err := strconv.Atoi(expiresStr)
+			if err != nil || expires <= 0 {
+				gohttp.Error(w, "expires is invalid", gohttp.StatusBadRequest)
+				return
+			}
+			req.Expiry = time.Duration(expires) * time.Second
+		}
+
+		ctx := context.WithValue(r.Context(), http.RequestContextKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ParseDryRunQueryParam reads whether a garbage collection only reports the orphans, which it
+// does unless dry_run is false.
+func ParseDryRunQueryParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
+	return gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		req := dto.RequestFromContext(r.Context(), http.RequestContextKey)
+
+		req.DryRun = true
+		if dryRunStr := r.URL.Query().Get(http.DryRunName); !validation.IsEmpty(dryRunStr) {
+			dryRun, err := strconv.ParseBool(dryRunStr)
+			if err != nil {
+				gohttp.Error(w, "dry_run is invalid", gohttp.StatusBadRequest)
+				return
+			}
+			req.DryRun = dryRun
+		}
+
+		ctx := context.WithValue(r.Context(), http.RequestContextKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ParseAllVersionsQueryParam reads whether a copy takes every version of the object rather than one.
+func ParseAllVersionsQueryParam(next gohttp.HandlerFunc) gohttp.HandlerFunc {
+	return gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		req := dto.RequestFromContext(r.Context(), http.RequestContextKey)
+
+		if allVersionsStr := r.URL.Query().Get(http.AllVersionsName); !validation.IsEmpty(allVersionsStr) {
+			allVersions, err := strconv.ParseBool(allVersionsStr)
+			if err != nil {
+				gohttp.Error(w, "all_versions is invalid", gohttp.StatusBadRequest)
+				return
+			}
+			req.AllVersions = allVersions
+		}
+
+		ctx := context.WithValue(r.Context(), http.RequestContextKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
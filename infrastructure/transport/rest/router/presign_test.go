@@ -0,0 +1,197 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package router_test
+
+import (
+	"encoding/json"
+	"fmt"
+	gohttp "net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/internal/auth"
+)
+
+func newPresignServer(t *testing.T) *presignServer {
+	t.Helper()
+
+	presigner, err := auth.NewPresigner("presign-key", time.Minute, time.Hour)
+	check(t, err)
+	return &presignServer{objects: newServer(t, presigner).URL + "/v1/group/partition/docs"}
+}
+
+type presignServer struct {
+	objects string
+}
+
+// presign asks for a presigned URL as s, with the query of the presign request.
+func (p *presignServer) presign(t *testing.T, s signer, query string) (int, dto.PresignedURL) {
+	t.Helper()
+
+	status, body := do(t, gohttp.MethodPost, p.objects+"/presign?"+query, "", nil, &s)
+	var presigned dto.PresignedURL
+	if status == gohttp.StatusOK {
+		check(t, json.Unmarshal(body, &presigned))
+	}
+	return status, presigned
+}
+
+func (p *presignServer) upload(t *testing.T, name, content string) dto.Item {
+	t.Helper()
+
+	contentType, form := uploadForm(t, name, content)
+	status, body := do(t, gohttp.MethodPut, p.objects, contentType, form, &alice)
+	if status != gohttp.StatusOK {
+		t.Fatalf("upload %s: %d %s", name, status, body)
+	}
+	return uploaded(t, body)
+}
+
+func TestPresignedDownload(t *testing.T) {
+	p := newPresignServer(t)
+
+	first := p.upload(t, "a.txt", "first version")
+	p.upload(t, "a.txt", "second version")
+	other := p.upload(t, "b.txt", "another object")
+
+	status, presigned := p.presign(t, alice, "object_id="+first.ID.String())
+	if status != gohttp.StatusOK || presigned.Method != gohttp.MethodGet {
+		t.Fatalf("presign: %d %+v", status, presigned)
+	}
+	if until := time.Until(presigned.ExpiresAt); until <= 0 || until > time.Minute {
+		t.Errorf("presigned url expires in %s, want the default expiry", until)
+	}
+
+	// the url is used without any credential
+	if status, body := do(t, gohttp.MethodGet, presigned.URL, "", nil, nil); status != gohttp.StatusOK || string(body) != "second version" {
+		t.Errorf("presigned download: %d %q", status, body)
+	}
+
+	_, version := p.presign(t, alice, fmt.Sprintf("object_id=%s&version=%d", first.ID, first.Versions[0].Number))
+	if status, body := do(t, gohttp.MethodGet, version.URL, "", nil, nil); status != gohttp.StatusOK || string(body) != "first version" {
+		t.Errorf("presigned download of the first version: %d %q", status, body)
+	}
+
+	u, err := url.Parse(presigned.URL)
+	check(t, err)
+
+	rejected := map[string]func(u url.URL) (string, string){
+		"another object": func(u url.URL) (string, string) {
+			u.Path = strings.Replace(u.Path, first.ID.String(), other.ID.String(), 1)
+			return gohttp.MethodGet, u.String()
+		},
+		"another path": func(u url.URL) (string, string) {
+			u.Path = strings.Replace(u.Path, "/docs/", "/secrets/", 1)
+			return gohttp.MethodGet, u.String()
+		},
+		"a later expiry": func(u url.URL) (string, string) {
+			query := u.Query()
+			query.Set(auth.PresignExpiresQuery, fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+			u.RawQuery = query.Encode()
+			return gohttp.MethodGet, u.String()
+		},
+		"another principal": func(u url.URL) (string, string) {
+			query := u.Query()
+			query.Set(auth.PresignPrincipalQuery, "bob")
+			u.RawQuery = query.Encode()
+			return gohttp.MethodGet, u.String()
+		},
+		"a delete": func(u url.URL) (string, string) {
+			return gohttp.MethodDelete, u.String()
+		},
+		"a head": func(u url.URL) (string, string) {
+			return gohttp.MethodHead, u.String()
+		},
+	}
+
+	for name, mutate := range rejected {
+		method, target := mutate(*u)
+		if status, _ := do(t, method, target, "", nil, nil); status != gohttp.StatusForbidden {
+			t.Errorf("presigned url with %s: %d, want %d", name, status, gohttp.StatusForbidden)
+		}
+	}
+
+	// the object is still there after the rejected delete
+	if status, _ := do(t, gohttp.MethodGet, presigned.URL, "", nil, nil); status != gohttp.StatusOK {
+		t.Errorf("presigned download after the rejected requests: %d", status)
+	}
+}
+
+func TestPresignedUpload(t *testing.T) {
+	p := newPresignServer(t)
+
+	status, presigned := p.presign(t, alice, "method=PUT&name=report.txt&expires=600")
+	if status != gohttp.StatusOK || presigned.Method != gohttp.MethodPut {
+		t.Fatalf("presign: %d %+v", status, presigned)
+	}
+	if until := time.Until(presigned.ExpiresAt); until <= 9*time.Minute || until > 10*time.Minute {
+		t.Errorf("presigned url expires in %s, want 10m", until)
+	}
+
+	contentType, form := uploadForm(t, "other.txt", "not the presigned name")
+	if status, _ := do(t, gohttp.MethodPut, presigned.URL, contentType, form, nil); status != gohttp.StatusForbidden {
+		t.Errorf("presigned upload of another name: %d, want %d", status, gohttp.StatusForbidden)
+	}
+
+	contentType, form = uploadForm(t, "report.txt", "uploaded by a presigned url")
+	status, body := do(t, gohttp.MethodPut, presigned.URL, contentType, form, nil)
+	if status != gohttp.StatusOK {
+		t.Fatalf("presigned upload: %d %s", status, body)
+	}
+
+	item := uploaded(t, body)
+	object := fmt.Sprintf("%s/%s", p.objects, item.ID)
+	if status, body := do(t, gohttp.MethodGet, object, "", nil, &alice); status != gohttp.StatusOK || string(body) != "uploaded by a presigned url" {
+		t.Errorf("download of the presigned upload: %d %q", status, body)
+	}
+}
+
+func TestPresignIsAuthorized(t *testing.T) {
+	p := newPresignServer(t)
+	item := p.upload(t, "a.txt", "content")
+
+	cases := []struct {
+		name   string
+		signer signer
+		query  string
+		status int
+	}{
+		{"download as a principal denied the object", bob, "object_id=" + item.ID.String(), gohttp.StatusForbidden},
+		{"upload as a principal denied the path", bob, "method=PUT&name=b.txt", gohttp.StatusForbidden},
+		{"expiry beyond the max", alice, "object_id=" + item.ID.String() + "&expires=7200", gohttp.StatusBadRequest},
+		{"upload without a name", alice, "method=PUT", gohttp.StatusBadRequest},
+		{"unknown object", alice, "object_id=1", gohttp.StatusNotFound},
+		{"delete", alice, "method=DELETE&object_id=" + item.ID.String(), gohttp.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if status, _ := p.presign(t, c.signer, c.query); status != c.status {
+				t.Errorf("presign: %d, want %d", status, c.status)
+			}
+		})
+	}
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/ISSuh/sos/infrastructure/transport/rest"
 	"github.com/ISSuh/sos/infrastructure/transport/rest/middleware"
+	"github.com/ISSuh/sos/internal/auth"
 	"github.com/ISSuh/sos/internal/http"
 	"github.com/ISSuh/sos/internal/log"
 )
@@ -41,14 +42,50 @@ const (
 	URLMetadata   = "/metadata"
 	URLVersion    = "/version"
 	URLVersionNum = "/{" + http.VersionName + "}"
+	URLUploads    = "/uploads"
+	URLUploadID   = "/{" + http.UploadIDParamName + "}"
+	URLPartNumber = "/{" + http.PartNumberParamName + "}"
+	URLChildren   = "/children"
+	URLPolicies   = "/policies"
+	URLPresignURL = "/presign"
+	URLGC         = "/gc"
+	URLDedup      = "/dedup"
+	URLEncryption = "/encryption"
+	URLRotate     = "/rotate"
+	URLTags       = "/tags"
+	URLCopy       = "/copy"
+	URLMove       = "/move"
 
-	URLDefault        = URLVersion1 + URLGroup + URLPartition + URLObjectPath
+	URLPartitionRoot  = URLVersion1 + URLGroup + URLPartition
+	URLDefault        = URLPartitionRoot + URLObjectPath
+	URLDirectory      = URLDefault + URLChildren
 	URLObject         = URLDefault + URLObjectID
 	URLObjectMetadata = URLObject + URLMetadata
+	URLObjectTags     = URLObject + URLTags
 	URLObjectVersion  = URLObject + URLVersion + URLVersionNum
+	URLObjectCopy     = URLObject + URLCopy
+	URLVersionCopy    = URLObjectVersion + URLCopy
+	URLObjectMove     = URLObject + URLMove
+	URLUploadSessions = URLDefault + URLUploads
+	URLUploadSession  = URLUploadSessions + URLUploadID
+	URLUploadPart     = URLUploadSession + URLPartNumber
+	URLPresign        = URLDefault + URLPresignURL
+
+	// policies are addressed apart from objects, so "policies" can not be used as a group
+	URLGroupPolicy     = URLVersion1 + URLPolicies + URLGroup
+	URLPartitionPolicy = URLGroupPolicy + URLPartition
+
+	// a tag search is addressed apart from objects as well, so "tags" can not be used as a group
+	URLTagSearch = URLVersion1 + URLTags + URLGroup + URLPartition
+
+	URLGarbageCollection = URLVersion1 + URLGC
+	URLDeduplication     = URLVersion1 + URLDedup
+	URLKeyRotation       = URLVersion1 + URLEncryption + URLRotate
 )
 
-func Route(logger log.Logger, s *http.Server, h rest.Explorer) {
+// Route registers the explorer API. Requests are not authenticated when verifier is nil, and
+// presigned URLs are not accepted when presigner is nil.
+func Route(logger log.Logger, s *http.Server, h rest.Explorer, verifier *auth.Verifier, presigner *auth.Presigner) {
 	s.Use(middleware.APM)
 	s.Use(middleware.Recover)
 	s.Use(middleware.WithLog(logger))
@@ -56,13 +93,143 @@ func Route(logger log.Logger, s *http.Server, h rest.Explorer) {
 	s.Use(middleware.ParseDefaultParam)
 	s.Use(middleware.ErrorHandler)
 
+	// authentication runs last on each route, after the params a presigned URL is signed for are parsed
+	authenticate := middleware.Anonymous
+	if verifier != nil {
+		authenticate = middleware.Authenticate(verifier, middleware.WriteAuthError)
+	}
+
+	// only a download or an upload is accepted by a presigned URL
+	authenticateTransfer := authenticate
+	if presigner != nil {
+		authenticateTransfer = middleware.AcceptPresignedURL(presigner, authenticate, middleware.WriteAuthError)
+	}
+
 	routes := http.RouteList{
+		// Garbage collection of the orphan blocks
+		http.RouteItem{
+			URL:     URLGarbageCollection,
+			Method:  gohttp.MethodPost,
+			Handler: h.CollectGarbage(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseDryRunQueryParam,
+				authenticate,
+			},
+		},
+		http.RouteItem{
+			URL:     URLGarbageCollection,
+			Method:  gohttp.MethodGet,
+			Handler: h.GetGarbageReport(),
+			Middlewares: []http.MiddlewareFunc{
+				authenticate,
+			},
+		},
+		// Deduplication ratio of the block storage nodes
+		http.RouteItem{
+			URL:     URLDeduplication,
+			Method:  gohttp.MethodGet,
+			Handler: h.GetDedupReport(),
+			Middlewares: []http.MiddlewareFunc{
+				authenticate,
+			},
+		},
+		// Rotation of the master key the data keys of the objects are wrapped with
+		http.RouteItem{
+			URL:     URLKeyRotation,
+			Method:  gohttp.MethodPost,
+			Handler: h.RotateDataKeys(),
+			Middlewares: []http.MiddlewareFunc{
+				authenticate,
+			},
+		},
+		// Policy of a group or a partition, registered before the routes of a group it would otherwise match
+		http.RouteItem{
+			URL:     URLGroupPolicy,
+			Method:  gohttp.MethodGet,
+			Handler: h.GetPolicy(),
+			Middlewares: []http.MiddlewareFunc{
+				authenticate,
+			},
+		},
+		http.RouteItem{
+			URL:     URLGroupPolicy,
+			Method:  gohttp.MethodPut,
+			Handler: h.PutPolicy(),
+			Middlewares: []http.MiddlewareFunc{
+				authenticate,
+			},
+		},
+		http.RouteItem{
+			URL:     URLGroupPolicy,
+			Method:  gohttp.MethodDelete,
+			Handler: h.DeletePolicy(),
+			Middlewares: []http.MiddlewareFunc{
+				authenticate,
+			},
+		},
+		http.RouteItem{
+			URL:     URLPartitionPolicy,
+			Method:  gohttp.MethodGet,
+			Handler: h.GetPolicy(),
+			Middlewares: []http.MiddlewareFunc{
+				authenticate,
+			},
+		},
+		http.RouteItem{
+			URL:     URLPartitionPolicy,
+			Method:  gohttp.MethodPut,
+			Handler: h.PutPolicy(),
+			Middlewares: []http.MiddlewareFunc{
+				authenticate,
+			},
+		},
+		http.RouteItem{
+			URL:     URLPartitionPolicy,
+			Method:  gohttp.MethodDelete,
+			Handler: h.DeletePolicy(),
+			Middlewares: []http.MiddlewareFunc{
+				authenticate,
+			},
+		},
+		// Search objects of a partition by their tags
+		http.RouteItem{
+			URL:     URLTagSearch,
+			Method:  gohttp.MethodGet,
+			Handler: h.FindByTags(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseListQueryParam,
+				middleware.ParseTagQueryParam,
+				authenticate,
+			},
+		},
+		// List children of the partition root
+		http.RouteItem{
+			URL:     URLPartitionRoot,
+			Method:  gohttp.MethodGet,
+			Handler: h.ListChildren(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseListQueryParam,
+				authenticate,
+			},
+		},
+		// List children of a directory, registered before the object routes it would otherwise match
+		http.RouteItem{
+			URL:     URLDirectory,
+			Method:  gohttp.MethodGet,
+			Handler: h.ListChildren(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseListQueryParam,
+				authenticate,
+			},
+		},
 		// Upload
 		http.RouteItem{
-			URL:         URLDefault,
-			Method:      gohttp.MethodPut,
-			Handler:     h.Upload(),
-			Middlewares: []http.MiddlewareFunc{},
+			URL:     URLDefault,
+			Method:  gohttp.MethodPut,
+			Handler: h.Upload(),
+			Middlewares: []http.MiddlewareFunc{
+				authenticateTransfer,
+			},
 		},
 		// Download latest version
 		http.RouteItem{
@@ -71,6 +238,7 @@ func Route(logger log.Logger, s *http.Server, h rest.Explorer) {
 			Handler: h.Download(true),
 			Middlewares: []http.MiddlewareFunc{
 				middleware.ParseObjectIDParam,
+				authenticateTransfer,
 			},
 		},
 		// Download specific version
@@ -80,6 +248,27 @@ func Route(logger log.Logger, s *http.Server, h rest.Explorer) {
 			Handler: h.Download(false),
 			Middlewares: []http.MiddlewareFunc{
 				middleware.ParseObjectIDParam,
+				authenticateTransfer,
+			},
+		},
+		// Header of the latest version, a presigned URL of a download is not accepted
+		http.RouteItem{
+			URL:     URLObject,
+			Method:  gohttp.MethodHead,
+			Handler: h.Head(true),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseObjectIDParam,
+				authenticate,
+			},
+		},
+		// Header of specific version
+		http.RouteItem{
+			URL:     URLObjectVersion,
+			Method:  gohttp.MethodHead,
+			Handler: h.Head(false),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseObjectIDParam,
+				authenticate,
 			},
 		},
 		// Delete
@@ -89,6 +278,7 @@ func Route(logger log.Logger, s *http.Server, h rest.Explorer) {
 			Handler: h.Delete(false),
 			Middlewares: []http.MiddlewareFunc{
 				middleware.ParseObjectIDParam,
+				authenticate,
 			},
 		},
 		// Delete specific version
@@ -98,6 +288,7 @@ func Route(logger log.Logger, s *http.Server, h rest.Explorer) {
 			Handler: h.Delete(true),
 			Middlewares: []http.MiddlewareFunc{
 				middleware.ParseObjectIDParam,
+				authenticate,
 			},
 		},
 		// metadata
@@ -107,6 +298,107 @@ func Route(logger log.Logger, s *http.Server, h rest.Explorer) {
 			Handler: h.Find(),
 			Middlewares: []http.MiddlewareFunc{
 				middleware.ParseObjectIDParam,
+				authenticate,
+			},
+		},
+		// Copy the latest version, or every version, inside the storage
+		http.RouteItem{
+			URL:     URLObjectCopy,
+			Method:  gohttp.MethodPost,
+			Handler: h.Copy(true),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseObjectIDParam,
+				middleware.ParseAllVersionsQueryParam,
+				authenticate,
+			},
+		},
+		// Copy specific version
+		http.RouteItem{
+			URL:     URLVersionCopy,
+			Method:  gohttp.MethodPost,
+			Handler: h.Copy(false),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseObjectIDParam,
+				authenticate,
+			},
+		},
+		// Move or rename, only the metadata changes
+		http.RouteItem{
+			URL:     URLObjectMove,
+			Method:  gohttp.MethodPost,
+			Handler: h.Move(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseObjectIDParam,
+				authenticate,
+			},
+		},
+		// Tags of an object, changing them creates no version
+		http.RouteItem{
+			URL:     URLObjectTags,
+			Method:  gohttp.MethodGet,
+			Handler: h.GetTags(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseObjectIDParam,
+				authenticate,
+			},
+		},
+		http.RouteItem{
+			URL:     URLObjectTags,
+			Method:  gohttp.MethodPut,
+			Handler: h.PutTags(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseObjectIDParam,
+				authenticate,
+			},
+		},
+		http.RouteItem{
+			URL:     URLObjectTags,
+			Method:  gohttp.MethodDelete,
+			Handler: h.DeleteTags(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseObjectIDParam,
+				authenticate,
+			},
+		},
+		// Initiate multipart upload
+		http.RouteItem{
+			URL:     URLUploadSessions,
+			Method:  gohttp.MethodPost,
+			Handler: h.InitiateUpload(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseNameQueryParam,
+				authenticate,
+			},
+		},
+		// Upload part
+		http.RouteItem{
+			URL:     URLUploadPart,
+			Method:  gohttp.MethodPut,
+			Handler: h.UploadPart(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseUploadIDParam,
+				middleware.ParsePartNumberParam,
+				authenticate,
+			},
+		},
+		// Complete multipart upload
+		http.RouteItem{
+			URL:     URLUploadSession,
+			Method:  gohttp.MethodPost,
+			Handler: h.CompleteUpload(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseUploadIDParam,
+				authenticate,
+			},
+		},
+		// Abort multipart upload
+		http.RouteItem{
+			URL:     URLUploadSession,
+			Method:  gohttp.MethodDelete,
+			Handler: h.AbortUpload(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseUploadIDParam,
+				authenticate,
 			},
 		},
 		// list
@@ -114,8 +406,24 @@ func Route(logger log.Logger, s *http.Server, h rest.Explorer) {
 			URL:     URLDefault,
 			Method:  gohttp.MethodGet,
 			Handler: h.List(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParseListQueryParam,
+				authenticate,
+			},
 		},
 	}
 
+	if presigner != nil {
+		routes = append(routes, http.RouteItem{
+			URL:     URLPresign,
+			Method:  gohttp.MethodPost,
+			Handler: h.Presign(),
+			Middlewares: []http.MiddlewareFunc{
+				middleware.ParsePresignQueryParam,
+				authenticate,
+			},
+		})
+	}
+
 	s.MuxAll(routes)
 }
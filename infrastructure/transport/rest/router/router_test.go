@@ -0,0 +1,316 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package router_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	gohttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/service"
+	"github.com/ISSuh/sos/domain/service/object"
+	local "github.com/ISSuh/sos/infrastructure/persistence/database/local"
+	memorystorage "github.com/ISSuh/sos/infrastructure/persistence/objectstorage/memory"
+	"github.com/ISSuh/sos/infrastructure/transport/rest/handler"
+	"github.com/ISSuh/sos/infrastructure/transport/rest/router"
+	"github.com/ISSuh/sos/internal/app/standalone"
+	"github.com/ISSuh/sos/internal/auth"
+	"github.com/ISSuh/sos/internal/config"
+	"github.com/ISSuh/sos/internal/generator"
+	"github.com/ISSuh/sos/internal/http"
+	"github.com/ISSuh/sos/internal/log"
+)
+
+const testRegion = "us-east-1"
+
+// credentials of the test server. alice is an admin, bob is allowed nothing without a policy.
+var (
+	alice = signer{accessKey: "alice-key", secretKey: "alice-secret"}
+	bob   = signer{accessKey: "bob-key", secretKey: "bob-secret"}
+)
+
+// newServer serves the explorer API of a standalone server kept in memory, with authentication
+// enabled, and presigned URLs when presigner is not nil.
+func newServer(t *testing.T, presigner *auth.Presigner) *httptest.Server {
+	t.Helper()
+	generator.InitIdentifier(1)
+
+	metadataRepo, err := local.NewLocalObjectMetadata()
+	check(t, err)
+	directoryRepo, err := local.NewLocalObjectDirectory()
+	check(t, err)
+	sessionRepo, err := local.NewLocalUploadSession()
+	check(t, err)
+	credentialRepo, err := local.NewLocalCredential()
+	check(t, err)
+	policyRepo, err := local.NewLocalPolicy()
+	check(t, err)
+	storageRepo, err := memorystorage.NewLocalObjectStorage()
+	check(t, err)
+
+	metadataService, err := service.NewObjectMetadata(metadataRepo, directoryRepo)
+	check(t, err)
+	sessionService, err := service.NewUploadSession(sessionRepo)
+	check(t, err)
+	credentialService, err := service.NewCredential(credentialRepo)
+	check(t, err)
+	policyService, err := service.NewPolicy(policyRepo)
+	check(t, err)
+	storageService, err := service.NewObjectStorage(storageRepo)
+	check(t, err)
+
+	for principal, s := range map[string]signer{"alice": alice, "bob": bob} {
+		_, err := credentialService.Put(context.Background(), &dto.Credential{
+			AccessKey: s.accessKey, SecretKey: s.secretKey, Principal: principal,
+		})
+		check(t, err)
+	}
+
+	registry, err := standalone.NewMetadataRegistry(metadataService, sessionService, credentialService, policyService)
+	check(t, err)
+	storage, err := standalone.NewBlockStorage(storageService)
+	check(t, err)
+
+	nodes := []object.StorageNode{{Node: entity.Node{Host: "memory"}, Requestor: storage}}
+	cluster, err := object.NewStorageCluster(nodes, 1, nil, false, nil, 1024, 2)
+	check(t, err)
+
+	authorizer, err := service.NewAuthorizer(registry, []string{"alice"})
+	check(t, err)
+	explorer, err := service.NewExplorer(registry, cluster, time.Hour, authorizer, presigner, nil, nil)
+	check(t, err)
+	h, err := handler.NewExplorer(explorer)
+	check(t, err)
+
+	provider, err := service.NewCredentialProvider(registry)
+	check(t, err)
+	verifier, err := auth.NewVerifier(provider, testRegion, 15*time.Minute)
+	check(t, err)
+
+	s := http.NewServer()
+	router.Route(log.NewZapLogger(config.Logger{Level: "fatal"}), &s, h, verifier, presigner)
+
+	server := httptest.NewServer(&s)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// signer signs requests with AWS signature version 4 as an S3 client does, apart from the
+// implementation of the server.
+type signer struct {
+	accessKey string
+	secretKey string
+}
+
+// sign signs the host, date and payload hash headers of r. The body is hashed unless payloadHash is given.
+func (s signer) sign(r *gohttp.Request, body []byte, payloadHash string) {
+	now := time.Now().UTC()
+	date := now.Format("20060102")
+	scope := date + "/" + testRegion + "/s3/aws4_request"
+
+	if payloadHash == "" {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	r.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		r.URL.Query().Encode(),
+		"host:" + r.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + r.Header.Get("X-Amz-Date"),
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", r.Header.Get("X-Amz-Date"), scope, hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range []string{date, testRegion, "s3", "aws4_request"} {
+		key = hmacSum(key, part)
+	}
+
+	r.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+hex.EncodeToString(hmacSum(key, stringToSign)))
+}
+
+func hmacSum(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uploadForm is the multipart body of an upload of a file of name.
+func uploadForm(t *testing.T, name, content string) (string, []byte) {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	check(t, err)
+	_, err = part.Write([]byte(content))
+	check(t, err)
+	check(t, form.Close())
+	return form.FormDataContentType(), body.Bytes()
+}
+
+// do sends the request, signed by s unless it is nil, and returns the status and the body of the response.
+func do(t *testing.T, method, url, contentType string, body []byte, s *signer) (int, []byte) {
+	t.Helper()
+
+	r, err := gohttp.NewRequest(method, url, bytes.NewReader(body))
+	check(t, err)
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	if s != nil {
+		s.sign(r, body, "")
+	}
+
+	resp, err := gohttp.DefaultClient.Do(r)
+	check(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	check(t, err)
+	return resp.StatusCode, respBody
+}
+
+func uploaded(t *testing.T, body []byte) dto.Item {
+	t.Helper()
+
+	var resp struct {
+		Items dto.Items `json:"items"`
+	}
+	check(t, json.Unmarshal(body, &resp))
+	if len(resp.Items) != 1 {
+		t.Fatalf("uploaded %d items: %s", len(resp.Items), body)
+	}
+	return resp.Items[0]
+}
+
+func TestSignedRequests(t *testing.T) {
+	server := newServer(t, nil)
+	objects := server.URL + "/v1/group/partition/docs"
+
+	contentType, form := uploadForm(t, "a.txt", "signed content")
+	status, body := do(t, gohttp.MethodPut, objects, contentType, form, &alice)
+	if status != gohttp.StatusOK {
+		t.Fatalf("signed upload: %d %s", status, body)
+	}
+	object := objects + "/" + uploaded(t, body).ID.String()
+
+	if status, body := do(t, gohttp.MethodGet, object, "", nil, &alice); status != gohttp.StatusOK || string(body) != "signed content" {
+		t.Errorf("signed download: %d %q", status, body)
+	}
+
+	// bob is authenticated, so the authorizer sees him and denies him without a policy
+	if status, body := do(t, gohttp.MethodGet, object, "", nil, &bob); status != gohttp.StatusForbidden ||
+		!strings.Contains(string(body), "principal bob is denied") {
+		t.Errorf("download as bob: %d %s", status, body)
+	}
+
+	if status, _ := do(t, gohttp.MethodGet, object, "", nil, nil); status != gohttp.StatusForbidden {
+		t.Errorf("unsigned download: %d, want %d", status, gohttp.StatusForbidden)
+	}
+
+	forged := signer{accessKey: alice.accessKey, secretKey: "guessed"}
+	if status, body := do(t, gohttp.MethodGet, object, "", nil, &forged); status != gohttp.StatusForbidden ||
+		!strings.Contains(string(body), "SignatureDoesNotMatch") {
+		t.Errorf("download with a wrong secret: %d %s", status, body)
+	}
+
+	unknown := signer{accessKey: "nobody", secretKey: "secret"}
+	if status, body := do(t, gohttp.MethodGet, object, "", nil, &unknown); status != gohttp.StatusForbidden ||
+		!strings.Contains(string(body), "InvalidAccessKeyId") {
+		t.Errorf("download with an unknown access key: %d %s", status, body)
+	}
+}
+
+func TestSignedBodyIsVerified(t *testing.T) {
+	server := newServer(t, nil)
+	objects := server.URL + "/v1/group/partition/docs"
+
+	contentType, form := uploadForm(t, "a.txt", "signed content")
+	_, tampered := uploadForm(t, "a.txt", "forged content")
+
+	// the signature covers the hash of the original body, the server reads another one
+	r, err := gohttp.NewRequest(gohttp.MethodPut, objects, bytes.NewReader(tampered))
+	check(t, err)
+	r.Header.Set("Content-Type", contentType)
+	alice.sign(r, form, "")
+
+	resp, err := gohttp.DefaultClient.Do(r)
+	check(t, err)
+	body, err := io.ReadAll(resp.Body)
+	check(t, err)
+	resp.Body.Close()
+	if resp.StatusCode == gohttp.StatusOK || !strings.Contains(string(body), "XAmzContentSHA256Mismatch") {
+		t.Fatalf("upload of a body other than the signed one: %d %s", resp.StatusCode, body)
+	}
+
+	status, body := do(t, gohttp.MethodGet, objects, "", nil, &alice)
+	if status != gohttp.StatusOK || strings.Contains(string(body), "a.txt") {
+		t.Errorf("list after the rejected upload: %d %s", status, body)
+	}
+
+	// an unsigned payload is not hashed, only the headers are signed
+	r, err = gohttp.NewRequest(gohttp.MethodPut, objects, bytes.NewReader(form))
+	check(t, err)
+	r.Header.Set("Content-Type", contentType)
+	alice.sign(r, nil, auth.UnsignedPayload)
+
+	resp, err = gohttp.DefaultClient.Do(r)
+	check(t, err)
+	resp.Body.Close()
+	if resp.StatusCode != gohttp.StatusOK {
+		t.Errorf("upload of an unsigned payload: %d", resp.StatusCode)
+	}
+}
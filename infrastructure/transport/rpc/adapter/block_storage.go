@@ -31,6 +31,8 @@ import (
 	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
 	sosrpc "github.com/ISSuh/sos/internal/rpc"
 	"github.com/ISSuh/sos/internal/validation"
+
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type BlockStorage struct {
@@ -57,6 +59,18 @@ func (a *BlockStorage) GetBlock(c context.Context, header *message.BlockHeader)
 	return a.handler.GetBlock(c, header)
 }
 
+func (a *BlockStorage) PutStream(stream rpcmessage.BlockStorage_PutStreamServer) error {
+	res, err := a.handler.PutStream(stream.Context(), stream.Recv)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(res)
+}
+
+func (a *BlockStorage) GetBlockStream(header *message.BlockHeader, stream rpcmessage.BlockStorage_GetBlockStreamServer) error {
+	return a.handler.GetBlockStream(stream.Context(), header, stream.Send)
+}
+
 func (a *BlockStorage) GetBlockHeader(c context.Context, header *message.BlockHeader) (*message.BlockHeader, error) {
 	return a.handler.GetBlockHeader(c, header)
 }
@@ -65,6 +79,34 @@ func (a *BlockStorage) Delete(c context.Context, header *message.BlockHeader) (*
 	return a.handler.Delete(c, header)
 }
 
+func (a *BlockStorage) Copy(c context.Context, req *rpcmessage.CopyBlockRequest) (*rpcmessage.StorageResponse, error) {
+	return a.handler.Copy(c, req)
+}
+
+func (a *BlockStorage) GetScrubStatus(c context.Context, _ *emptypb.Empty) (*message.ScrubStatus, error) {
+	return a.handler.GetScrubStatus(c)
+}
+
+func (a *BlockStorage) StartScrub(c context.Context, _ *emptypb.Empty) (*message.ScrubStatus, error) {
+	return a.handler.StartScrub(c)
+}
+
+func (a *BlockStorage) ListBlocks(req *rpcmessage.ListBlocksRequest, stream rpcmessage.BlockStorage_ListBlocksServer) error {
+	return a.handler.ListBlocks(stream.Context(), req, stream.Send)
+}
+
+func (a *BlockStorage) Reference(c context.Context, header *message.BlockHeader) (*rpcmessage.ReferenceResponse, error) {
+	return a.handler.Reference(c, header)
+}
+
+func (a *BlockStorage) GetDedupStats(c context.Context, _ *emptypb.Empty) (*message.DedupStats, error) {
+	return a.handler.GetDedupStats(c)
+}
+
+func (a *BlockStorage) Reconcile(c context.Context, req *rpcmessage.ReconcileRequest) (*rpcmessage.ReconcileResponse, error) {
+	return a.handler.Reconcile(c, req)
+}
+
 func (a *BlockStorage) Regist() sosrpc.RegisterFunc {
 	return func(engine *sosrpc.Engine) {
 		rpcmessage.RegisterBlockStorageServer(engine.Server, a)
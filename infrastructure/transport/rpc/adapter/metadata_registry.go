@@ -55,12 +55,8 @@ func (a *MetadataRegistry) Put(c context.Context, object *message.Object) (*mess
 	return a.handler.Put(c, object)
 }
 
-func (a *MetadataRegistry) Delete(c context.Context, metadata *message.ObjectMetadata) (*emptypb.Empty, error) {
-	err := a.handler.Delete(c, metadata)
-	if err != nil {
-		return &emptypb.Empty{}, err
-	}
-	return &emptypb.Empty{}, nil
+func (a *MetadataRegistry) Delete(c context.Context, metadata *message.ObjectMetadata) (*message.BlockHeaderList, error) {
+	return a.handler.Delete(c, metadata)
 }
 
 func (a *MetadataRegistry) GetByObjectName(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadata, error) {
@@ -75,6 +71,106 @@ func (a *MetadataRegistry) FindMetadataOnPath(c context.Context, req *rpcmessage
 	return a.handler.FindMetadataOnPath(c, req)
 }
 
+func (a *MetadataRegistry) CreateUploadSession(c context.Context, session *message.UploadSession) (*message.UploadSession, error) {
+	return a.handler.CreateUploadSession(c, session)
+}
+
+func (a *MetadataRegistry) GetUploadSession(c context.Context, req *rpcmessage.UploadSessionRequest) (*message.UploadSession, error) {
+	return a.handler.GetUploadSession(c, req)
+}
+
+func (a *MetadataRegistry) PutUploadPart(c context.Context, req *rpcmessage.UploadPartRequest) (*message.UploadSession, error) {
+	return a.handler.PutUploadPart(c, req)
+}
+
+func (a *MetadataRegistry) DeleteUploadSession(c context.Context, req *rpcmessage.UploadSessionRequest) (*emptypb.Empty, error) {
+	err := a.handler.DeleteUploadSession(c, req)
+	if err != nil {
+		return &emptypb.Empty{}, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (a *MetadataRegistry) FindExpiredUploadSessions(c context.Context, req *rpcmessage.UploadSessionRequest) (*message.UploadSessionList, error) {
+	return a.handler.FindExpiredUploadSessions(c, req)
+}
+
+func (a *MetadataRegistry) GetDirectory(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.Directory, error) {
+	return a.handler.GetDirectory(c, req)
+}
+
+func (a *MetadataRegistry) GetCredential(c context.Context, req *rpcmessage.CredentialRequest) (*message.Credential, error) {
+	return a.handler.GetCredential(c, req)
+}
+
+func (a *MetadataRegistry) PutPolicy(c context.Context, req *message.Policy) (*message.Policy, error) {
+	return a.handler.PutPolicy(c, req)
+}
+
+func (a *MetadataRegistry) GetPolicy(c context.Context, req *rpcmessage.PolicyRequest) (*message.Policy, error) {
+	return a.handler.GetPolicy(c, req)
+}
+
+func (a *MetadataRegistry) DeletePolicy(c context.Context, req *rpcmessage.PolicyRequest) (*emptypb.Empty, error) {
+	err := a.handler.DeletePolicy(c, req)
+	if err != nil {
+		return &emptypb.Empty{}, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (a *MetadataRegistry) FindPolicies(c context.Context, req *rpcmessage.PolicyRequest) (*message.Policies, error) {
+	return a.handler.FindPolicies(c, req)
+}
+
+func (a *MetadataRegistry) FindBlockHeaders(c context.Context, req *rpcmessage.BlockHeaderRequest) (*message.BlockHeaderList, error) {
+	return a.handler.FindBlockHeaders(c, req)
+}
+
+func (a *MetadataRegistry) ReserveVersion(c context.Context, object *message.Object) (*message.Object, error) {
+	return a.handler.ReserveVersion(c, object)
+}
+
+func (a *MetadataRegistry) CommitVersion(c context.Context, object *message.Object) (*message.ObjectMetadata, error) {
+	return a.handler.CommitVersion(c, object)
+}
+
+func (a *MetadataRegistry) RecordBlocks(c context.Context, object *message.Object) (*emptypb.Empty, error) {
+	err := a.handler.RecordBlocks(c, object)
+	if err != nil {
+		return &emptypb.Empty{}, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (a *MetadataRegistry) AbortVersion(c context.Context, object *message.Object) (*message.BlockHeaderList, error) {
+	return a.handler.AbortVersion(c, object)
+}
+
+func (a *MetadataRegistry) ReleaseExpiredVersions(c context.Context, req *rpcmessage.ExpiredVersionRequest) (*message.BlockHeaderList, error) {
+	return a.handler.ReleaseExpiredVersions(c, req)
+}
+
+func (a *MetadataRegistry) PutTags(c context.Context, metadata *message.ObjectMetadata) (*message.ObjectMetadata, error) {
+	return a.handler.PutTags(c, metadata)
+}
+
+func (a *MetadataRegistry) FindMetadataByTags(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
+	return a.handler.FindMetadataByTags(c, req)
+}
+
+func (a *MetadataRegistry) Move(c context.Context, req *rpcmessage.MoveRequest) (*message.ObjectMetadata, error) {
+	return a.handler.Move(c, req)
+}
+
+func (a *MetadataRegistry) FindMetadataByStaleDataKey(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
+	return a.handler.FindMetadataByStaleDataKey(c, req)
+}
+
+func (a *MetadataRegistry) RewrapDataKeys(c context.Context, metadata *message.ObjectMetadata) (*message.ObjectMetadata, error) {
+	return a.handler.RewrapDataKeys(c, metadata)
+}
+
 func (a *MetadataRegistry) Regist() sosrpc.RegisterFunc {
 	return func(engine *sosrpc.Engine) {
 		rpcmessage.RegisterMetadataRegistryServer(engine.Server, a)
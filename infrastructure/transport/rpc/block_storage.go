@@ -32,13 +32,31 @@ import (
 type BlockStorageHandler interface {
 	Put(ctx context.Context, block *message.Block) (*rpcmessage.StorageResponse, error)
 	GetBlock(ctx context.Context, header *message.BlockHeader) (*message.Block, error)
+	PutStream(ctx context.Context, recv func() (*rpcmessage.BlockChunk, error)) (*rpcmessage.StorageResponse, error)
+	GetBlockStream(ctx context.Context, header *message.BlockHeader, send func(*rpcmessage.BlockChunk) error) error
 	GetBlockHeader(ctx context.Context, header *message.BlockHeader) (*message.BlockHeader, error)
 	Delete(ctx context.Context, header *message.BlockHeader) (*rpcmessage.StorageResponse, error)
+	Copy(ctx context.Context, req *rpcmessage.CopyBlockRequest) (*rpcmessage.StorageResponse, error)
+	GetScrubStatus(ctx context.Context) (*message.ScrubStatus, error)
+	StartScrub(ctx context.Context) (*message.ScrubStatus, error)
+	ListBlocks(ctx context.Context, req *rpcmessage.ListBlocksRequest, send func(*message.BlockHeader) error) error
+	Reference(ctx context.Context, header *message.BlockHeader) (*rpcmessage.ReferenceResponse, error)
+	GetDedupStats(ctx context.Context) (*message.DedupStats, error)
+	Reconcile(ctx context.Context, req *rpcmessage.ReconcileRequest) (*rpcmessage.ReconcileResponse, error)
 }
 
 type BlockStorageRequestor interface {
 	Put(ctx context.Context, block *message.Block) (*rpcmessage.StorageResponse, error)
 	GetBlock(ctx context.Context, header *message.BlockHeader) (*message.Block, error)
+	PutStream(ctx context.Context, block *message.Block) (*rpcmessage.StorageResponse, error)
+	GetBlockStream(ctx context.Context, header *message.BlockHeader) (*message.Block, error)
 	GetBlockHeader(ctx context.Context, header *message.BlockHeader) (*message.BlockHeader, error)
 	Delete(ctx context.Context, header *message.BlockHeader) (*rpcmessage.StorageResponse, error)
+	Copy(ctx context.Context, req *rpcmessage.CopyBlockRequest) (*rpcmessage.StorageResponse, error)
+	GetScrubStatus(ctx context.Context) (*message.ScrubStatus, error)
+	StartScrub(ctx context.Context) (*message.ScrubStatus, error)
+	ListBlocks(ctx context.Context, req *rpcmessage.ListBlocksRequest, recv func(*message.BlockHeader) error) error
+	Reference(ctx context.Context, header *message.BlockHeader) (*rpcmessage.ReferenceResponse, error)
+	GetDedupStats(ctx context.Context) (*message.DedupStats, error)
+	Reconcile(ctx context.Context, req *rpcmessage.ReconcileRequest) (*rpcmessage.ReconcileResponse, error)
 }
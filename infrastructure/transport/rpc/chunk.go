@@ -0,0 +1,104 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package rpc
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	message "github.com/ISSuh/sos/domain/model/message"
+	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
+	"github.com/ISSuh/sos/internal/encryption"
+)
+
+const (
+	// ChunkSize keeps every message of a block stream far below the grpc message limit
+	ChunkSize = 1024 * 1024
+)
+
+// SplitBlock sends the block as chunks of ChunkSize, the first chunk carries the header.
+// A block without data is sent as a single chunk.
+func SplitBlock(block *message.Block, send func(*rpcmessage.BlockChunk) error) error {
+	switch {
+	case block == nil:
+		return errors.New("Block is nil")
+	case block.Header == nil:
+		return errors.New("BlockHeader is nil")
+	}
+
+	data := block.Data
+	chunk := &rpcmessage.BlockChunk{
+		Header: block.Header,
+	}
+	for {
+		size := min(ChunkSize, len(data))
+		chunk.Data = data[:size]
+		if err := send(chunk); err != nil {
+			return err
+		}
+
+		data = data[size:]
+		if len(data) == 0 {
+			return nil
+		}
+		chunk = &rpcmessage.BlockChunk{}
+	}
+}
+
+// JoinBlock receives the chunks of a block until the stream ends, and rejects a stream that
+// does not start with the header or carries more than a block can hold.
+func JoinBlock(recv func() (*rpcmessage.BlockChunk, error)) (*message.Block, error) {
+	var block *message.Block
+	for {
+		chunk, err := recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if block == nil {
+			if chunk.Header == nil {
+				return nil, errors.New("BlockHeader is nil")
+			}
+
+			size := min(max(int(chunk.Header.Size), 0), entity.MaxBlockSize)
+			block = &message.Block{
+				Header: chunk.Header,
+				Data:   make([]byte, 0, size),
+			}
+		}
+
+		if len(block.Data)+len(chunk.Data) > entity.MaxBlockSize+encryption.Overhead {
+			return nil, errors.New("block size is too large")
+		}
+		block.Data = append(block.Data, chunk.Data...)
+	}
+
+	if block == nil {
+		return nil, errors.New("block stream is empty")
+	}
+	return block, nil
+}
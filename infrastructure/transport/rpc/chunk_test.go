@@ -0,0 +1,275 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package rpc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	message "github.com/ISSuh/sos/domain/model/message"
+	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
+	"github.com/ISSuh/sos/internal/encryption"
+)
+
+func testBlock(size int) *message.Block {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+
+	return &message.Block{
+		Header: &message.BlockHeader{
+			ObjectID: &message.ObjectID{Id: 1},
+			BlockID:  &message.BlockID{Id: 2},
+			Index:    3,
+			Size:     int32(size),
+		},
+		Data: data,
+	}
+}
+
+func TestSplitBlock(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		chunks []int
+	}{
+		{name: "empty block", size: 0, chunks: []int{0}},
+		{name: "smaller than a chunk", size: 10, chunks: []int{10}},
+		{name: "one chunk", size: ChunkSize, chunks: []int{ChunkSize}},
+		{name: "one byte over a chunk", size: ChunkSize + 1, chunks: []int{ChunkSize, 1}},
+		{name: "several chunks", size: 3*ChunkSize + 5, chunks: []int{ChunkSize, ChunkSize, ChunkSize, 5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			block := testBlock(test.size)
+
+			var chunks []*rpcmessage.BlockChunk
+			err := SplitBlock(block, func(chunk *rpcmessage.BlockChunk) error {
+				chunks = append(chunks, chunk)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("SplitBlock: %v", err)
+			}
+
+			if len(chunks) != len(test.chunks) {
+				t.Fatalf("%d chunks, want %d", len(chunks), len(test.chunks))
+			}
+
+			var data []byte
+			for i, chunk := range chunks {
+				if len(chunk.Data) != test.chunks[i] {
+					t.Errorf("chunk %d is %d bytes, want %d", i, len(chunk.Data), test.chunks[i])
+				}
+				if (chunk.Header != nil) != (i == 0) {
+					t.Errorf("chunk %d header %v", i, chunk.Header)
+				}
+				data = append(data, chunk.Data...)
+			}
+
+			if !bytes.Equal(data, block.Data) {
+				t.Errorf("chunks do not add up to the block")
+			}
+		})
+	}
+}
+
+func TestSplitBlockInvalid(t *testing.T) {
+	errSend := errors.New("send failed")
+
+	tests := []struct {
+		name  string
+		block *message.Block
+		send  func(*rpcmessage.BlockChunk) error
+		err   error
+	}{
+		{name: "nil block"},
+		{name: "nil header", block: &message.Block{Data: []byte("data")}},
+		{
+			name:  "send fails",
+			block: testBlock(2 * ChunkSize),
+			send:  func(*rpcmessage.BlockChunk) error { return errSend },
+			err:   errSend,
+		},
+		{
+			name:  "stream closed early",
+			block: testBlock(2 * ChunkSize),
+			send:  func(*rpcmessage.BlockChunk) error { return io.EOF },
+			err:   io.EOF,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sent := 0
+			send := func(chunk *rpcmessage.BlockChunk) error {
+				sent++
+				if test.send != nil {
+					return test.send(chunk)
+				}
+				return nil
+			}
+
+			err := SplitBlock(test.block, send)
+			switch {
+			case err == nil:
+				t.Fatalf("no error")
+			case test.err != nil && err != test.err:
+				t.Fatalf("error %v, want %v", err, test.err)
+			case test.send != nil && sent != 1:
+				t.Errorf("%d chunks sent after the failure", sent-1)
+			}
+		})
+	}
+}
+
+// chunkStream replays the chunks and then ends the stream with err, or io.EOF.
+func chunkStream(chunks []*rpcmessage.BlockChunk, err error) func() (*rpcmessage.BlockChunk, error) {
+	return func() (*rpcmessage.BlockChunk, error) {
+		if len(chunks) == 0 {
+			if err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+
+		chunk := chunks[0]
+		chunks = chunks[1:]
+		return chunk, nil
+	}
+}
+
+// oversizedStream sends a header chunk followed by data chunks reusing one buffer, so the limit
+// is reached without holding the whole stream in memory.
+func oversizedStream(size int) func() (*rpcmessage.BlockChunk, error) {
+	buffer := make([]byte, ChunkSize)
+	first := true
+	return func() (*rpcmessage.BlockChunk, error) {
+		if size <= 0 {
+			return nil, io.EOF
+		}
+
+		chunk := &rpcmessage.BlockChunk{Data: buffer[:min(ChunkSize, size)]}
+		if first {
+			chunk.Header = &message.BlockHeader{Size: int32(entity.MaxBlockSize)}
+			first = false
+		}
+		size -= len(chunk.Data)
+		return chunk, nil
+	}
+}
+
+func TestJoinBlock(t *testing.T) {
+	errRecv := errors.New("recv failed")
+	maxSize := entity.MaxBlockSize + encryption.Overhead
+
+	tests := []struct {
+		name  string
+		recv  func() (*rpcmessage.BlockChunk, error)
+		size  int
+		valid bool
+	}{
+		{name: "empty block", recv: chunkStream([]*rpcmessage.BlockChunk{{Header: &message.BlockHeader{}}}, nil), valid: true},
+		{
+			name: "header only chunk",
+			recv: chunkStream([]*rpcmessage.BlockChunk{
+				{Header: &message.BlockHeader{Size: 3}}, {Data: []byte("abc")},
+			}, nil),
+			size:  3,
+			valid: true,
+		},
+		{
+			name: "negative header size",
+			recv: chunkStream([]*rpcmessage.BlockChunk{
+				{Header: &message.BlockHeader{Size: -1}, Data: []byte("abc")},
+			}, nil),
+			size:  3,
+			valid: true,
+		},
+		{name: "largest block", recv: oversizedStream(maxSize), size: maxSize, valid: true},
+		{name: "one byte too large", recv: oversizedStream(maxSize + 1)},
+		{name: "far too large", recv: oversizedStream(2 * maxSize)},
+		{name: "empty stream", recv: chunkStream(nil, nil)},
+		{name: "first chunk without header", recv: chunkStream([]*rpcmessage.BlockChunk{{Data: []byte("abc")}}, nil)},
+		{
+			name: "recv fails",
+			recv: chunkStream([]*rpcmessage.BlockChunk{
+				{Header: &message.BlockHeader{}, Data: []byte("abc")},
+			}, errRecv),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			block, err := JoinBlock(test.recv)
+			if (err == nil) != test.valid {
+				t.Fatalf("error %v, valid %t", err, test.valid)
+			}
+			if test.valid && len(block.Data) != test.size {
+				t.Errorf("block is %d bytes, want %d", len(block.Data), test.size)
+			}
+		})
+	}
+}
+
+func TestSplitJoinBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "empty block", size: 0},
+		{name: "one chunk", size: ChunkSize},
+		{name: "several chunks", size: 2*ChunkSize + 7},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			block := testBlock(test.size)
+
+			var chunks []*rpcmessage.BlockChunk
+			if err := SplitBlock(block, func(chunk *rpcmessage.BlockChunk) error {
+				chunks = append(chunks, chunk)
+				return nil
+			}); err != nil {
+				t.Fatalf("SplitBlock: %v", err)
+			}
+
+			joined, err := JoinBlock(chunkStream(chunks, nil))
+			if err != nil {
+				t.Fatalf("JoinBlock: %v", err)
+			}
+
+			if joined.Header.Index != block.Header.Index || joined.Header.Size != block.Header.Size {
+				t.Errorf("header %v, want %v", joined.Header, block.Header)
+			}
+			if !bytes.Equal(joined.Data, block.Data) {
+				t.Errorf("data does not match the block")
+			}
+		})
+	}
+}
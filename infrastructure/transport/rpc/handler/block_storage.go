@@ -26,6 +26,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/model/message"
 	"github.com/ISSuh/sos/domain/service"
 	"github.com/ISSuh/sos/infrastructure/transport/rpc"
@@ -36,9 +37,11 @@ import (
 
 type blockStorage struct {
 	objectStorage service.ObjectStorage
+	scrubber      service.Scrubber
 }
 
-func NewBlockStorage(objectStorage service.ObjectStorage) (rpc.BlockStorageHandler, error) {
+// NewBlockStorage rejects the scrub requests when scrubber is nil.
+func NewBlockStorage(objectStorage service.ObjectStorage, scrubber service.Scrubber) (rpc.BlockStorageHandler, error) {
 	switch {
 	case validation.IsNil(objectStorage):
 		return nil, fmt.Errorf("ObjectStorage service is nil")
@@ -46,6 +49,7 @@ func NewBlockStorage(objectStorage service.ObjectStorage) (rpc.BlockStorageHandl
 
 	return &blockStorage{
 		objectStorage: objectStorage,
+		scrubber:      scrubber,
 	}, nil
 }
 
@@ -69,7 +73,7 @@ func (h *blockStorage) Put(c context.Context, dto *message.Block) (*rpcmessage.S
 	}
 
 	block := message.ToBlock(dto)
-	if err := h.objectStorage.Put(c, &block); err != nil {
+	if err := h.objectStorage.Put(c, &block, dto.Header.DataKey); err != nil {
 		return nil, err
 	}
 
@@ -94,7 +98,7 @@ func (h *blockStorage) GetBlock(c context.Context, dto *message.BlockHeader) (*m
 	}
 
 	header := message.ToBlockHeader(dto)
-	block, err := h.objectStorage.GetBlock(c, header.ObjectID(), header.BlockID(), header.Index())
+	block, err := h.getBlock(c, header, dto.DataKey)
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +106,29 @@ func (h *blockStorage) GetBlock(c context.Context, dto *message.BlockHeader) (*m
 	return message.FromBlock(block), nil
 }
 
+// PutStream stores the block whose chunks are received until the stream ends.
+func (h *blockStorage) PutStream(
+	c context.Context, recv func() (*rpcmessage.BlockChunk, error),
+) (*rpcmessage.StorageResponse, error) {
+	log.FromContext(c).Debugf("[BlockStorage.PutStream]")
+	block, err := rpc.JoinBlock(recv)
+	if err != nil {
+		return nil, err
+	}
+	return h.Put(c, block)
+}
+
+func (h *blockStorage) GetBlockStream(
+	c context.Context, dto *message.BlockHeader, send func(*rpcmessage.BlockChunk) error,
+) error {
+	log.FromContext(c).Debugf("[BlockStorage.GetBlockStream]")
+	block, err := h.GetBlock(c, dto)
+	if err != nil {
+		return err
+	}
+	return rpc.SplitBlock(block, send)
+}
+
 func (h *blockStorage) GetBlockHeader(c context.Context, dto *message.BlockHeader) (*message.BlockHeader, error) {
 	log.FromContext(c).Debugf("[BlockStorage.GetBlockHeader]")
 	switch {
@@ -118,6 +145,16 @@ func (h *blockStorage) GetBlockHeader(c context.Context, dto *message.BlockHeade
 	}
 
 	header := message.ToBlockHeader(dto)
+	if header.Hash() != "" {
+		block, err := h.objectStorage.GetContent(c, header.Hash())
+		if err != nil {
+			return nil, err
+		}
+
+		blockHeader := block.Header()
+		return message.FromBlockHeader(&blockHeader), nil
+	}
+
 	blockHeader, err := h.objectStorage.GetBlockHeader(c, header.ObjectID(), header.BlockID(), header.Index())
 	if err != nil {
 		return nil, err
@@ -126,6 +163,13 @@ func (h *blockStorage) GetBlockHeader(c context.Context, dto *message.BlockHeade
 	return message.FromBlockHeader(blockHeader), nil
 }
 
+func (h *blockStorage) getBlock(c context.Context, header entity.BlockHeader, dataKey []byte) (*entity.Block, error) {
+	if header.Hash() != "" {
+		return h.objectStorage.GetContent(c, header.Hash())
+	}
+	return h.objectStorage.GetBlock(c, header.ObjectID(), header.BlockID(), header.Index(), dataKey)
+}
+
 func (h *blockStorage) Delete(c context.Context, dto *message.BlockHeader) (*rpcmessage.StorageResponse, error) {
 	log.FromContext(c).Debugf("[BlockStorage.Delete]")
 	switch {
@@ -142,6 +186,15 @@ func (h *blockStorage) Delete(c context.Context, dto *message.BlockHeader) (*rpc
 	}
 
 	header := message.ToBlockHeader(dto)
+	if header.Hash() != "" {
+		if err := h.objectStorage.Release(c, header.Hash()); err != nil {
+			return nil, err
+		}
+		return &rpcmessage.StorageResponse{
+			Success: true,
+		}, nil
+	}
+
 	if err := h.objectStorage.Delete(c, header.ObjectID(), header.BlockID(), header.Index()); err != nil {
 		return nil, err
 	}
@@ -150,3 +203,127 @@ func (h *blockStorage) Delete(c context.Context, dto *message.BlockHeader) (*rpc
 		Success: true,
 	}, nil
 }
+
+func (h *blockStorage) Copy(c context.Context, req *rpcmessage.CopyBlockRequest) (*rpcmessage.StorageResponse, error) {
+	log.FromContext(c).Debugf("[BlockStorage.Copy]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(req):
+		return nil, fmt.Errorf("CopyBlockRequest is nil")
+	case validation.IsNil(req.Source), validation.IsNil(req.Target):
+		return nil, fmt.Errorf("BlockHeader is nil")
+	case validation.IsNil(req.Source.ObjectID), validation.IsNil(req.Target.ObjectID):
+		return nil, fmt.Errorf("ObjectID is nil")
+	case validation.IsNil(req.Source.BlockID), validation.IsNil(req.Target.BlockID):
+		return nil, fmt.Errorf("BlockID is nil")
+	}
+
+	source := message.ToBlockHeader(req.Source)
+	target := message.ToBlockHeader(req.Target)
+	if err := h.objectStorage.Copy(c, source, target); err != nil {
+		return nil, err
+	}
+
+	return &rpcmessage.StorageResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *blockStorage) GetScrubStatus(c context.Context) (*message.ScrubStatus, error) {
+	log.FromContext(c).Debugf("[BlockStorage.GetScrubStatus]")
+	if validation.IsNil(h.scrubber) {
+		return nil, fmt.Errorf("Scrubber is disabled")
+	}
+
+	status := h.scrubber.Status()
+	return message.FromScrubStatusDTO(&status), nil
+}
+
+func (h *blockStorage) StartScrub(c context.Context) (*message.ScrubStatus, error) {
+	log.FromContext(c).Debugf("[BlockStorage.StartScrub]")
+	if validation.IsNil(h.scrubber) {
+		return nil, fmt.Errorf("Scrubber is disabled")
+	}
+
+	if !h.scrubber.Trigger() {
+		log.FromContext(c).Debugf("[BlockStorage.StartScrub] scrub is already running")
+	}
+
+	status := h.scrubber.Status()
+	return message.FromScrubStatusDTO(&status), nil
+}
+
+func (h *blockStorage) ListBlocks(
+	c context.Context, req *rpcmessage.ListBlocksRequest, send func(*message.BlockHeader) error,
+) error {
+	log.FromContext(c).Debugf("[BlockStorage.ListBlocks]")
+	switch {
+	case validation.IsNil(c):
+		return fmt.Errorf("Context is nil")
+	case validation.IsNil(req):
+		return fmt.Errorf("ListBlocksRequest is nil")
+	case validation.IsNil(req.Before):
+		return fmt.Errorf("Before is nil")
+	}
+
+	return h.objectStorage.ListBlocks(c, req.Before.AsTime(), func(header *entity.BlockHeader, references int64) error {
+		msg := message.FromBlockHeader(header)
+		msg.References = references
+		return send(msg)
+	})
+}
+
+func (h *blockStorage) Reference(c context.Context, dto *message.BlockHeader) (*rpcmessage.ReferenceResponse, error) {
+	log.FromContext(c).Debugf("[BlockStorage.Reference]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(dto):
+		return nil, fmt.Errorf("Block is nil")
+	case validation.IsEmpty(dto.Hash):
+		return nil, fmt.Errorf("Hash is empty")
+	}
+
+	exist, err := h.objectStorage.Reference(c, dto.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rpcmessage.ReferenceResponse{
+		Exist: exist,
+	}, nil
+}
+
+func (h *blockStorage) GetDedupStats(c context.Context) (*message.DedupStats, error) {
+	log.FromContext(c).Debugf("[BlockStorage.GetDedupStats]")
+	stats, err := h.objectStorage.DedupStats(c)
+	if err != nil {
+		return nil, err
+	}
+	return message.FromDedupStatsDTO(&stats), nil
+}
+
+func (h *blockStorage) Reconcile(c context.Context, req *rpcmessage.ReconcileRequest) (*rpcmessage.ReconcileResponse, error) {
+	log.FromContext(c).Debugf("[BlockStorage.Reconcile]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(req):
+		return nil, fmt.Errorf("ReconcileRequest is nil")
+	case validation.IsEmpty(req.Hash):
+		return nil, fmt.Errorf("Hash is empty")
+	case validation.IsNil(req.Before):
+		return nil, fmt.Errorf("Before is nil")
+	}
+
+	references, reconciled, err := h.objectStorage.Reconcile(c, req.Hash, req.References, req.Before.AsTime())
+	if err != nil {
+		return nil, err
+	}
+
+	return &rpcmessage.ReconcileResponse{
+		References: references,
+		Reconciled: reconciled,
+	}, nil
+}
@@ -27,6 +27,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/model/message"
 	"github.com/ISSuh/sos/domain/service"
 	"github.com/ISSuh/sos/infrastructure/transport/rpc"
@@ -40,16 +42,31 @@ import (
 
 type metadataRegistry struct {
 	objectMetadata service.ObjectMetadata
+	uploadSession  service.UploadSession
+	credential     service.Credential
+	policy         service.Policy
 }
 
-func NewMetadataRegistry(objectMetadata service.ObjectMetadata) (rpc.MetadataRegistryHandler, error) {
+func NewMetadataRegistry(
+	objectMetadata service.ObjectMetadata, uploadSession service.UploadSession, credential service.Credential,
+	policy service.Policy,
+) (rpc.MetadataRegistryHandler, error) {
 	switch {
 	case validation.IsNil(objectMetadata):
 		return nil, fmt.Errorf("ObjectMetadata service is nil")
+	case validation.IsNil(uploadSession):
+		return nil, fmt.Errorf("UploadSession service is nil")
+	case validation.IsNil(credential):
+		return nil, fmt.Errorf("Credential service is nil")
+	case validation.IsNil(policy):
+		return nil, fmt.Errorf("Policy service is nil")
 	}
 
 	return &metadataRegistry{
 		objectMetadata: objectMetadata,
+		uploadSession:  uploadSession,
+		credential:     credential,
+		policy:         policy,
 	}, nil
 }
 
@@ -71,22 +88,22 @@ func (h *metadataRegistry) Put(c context.Context, msg *message.Object) (*message
 	return message.FromObjectMetadataDTO(metadata), nil
 }
 
-func (h *metadataRegistry) Delete(c context.Context, msg *message.ObjectMetadata) error {
+func (h *metadataRegistry) Delete(c context.Context, msg *message.ObjectMetadata) (*message.BlockHeaderList, error) {
 	log.FromContext(c).Debugf("[MetadataRegistry.Delete]")
 	switch {
 	case validation.IsNil(c):
-		return fmt.Errorf("Context is nil")
+		return nil, fmt.Errorf("Context is nil")
 	case validation.IsNil(msg):
-		return fmt.Errorf("ObjectMetadata is nil")
+		return nil, fmt.Errorf("ObjectMetadata is nil")
 	}
 
 	metadata := message.ToObjectMetadataDTO(msg)
-	err := h.objectMetadata.Delete(c, metadata)
+	recorded, err := h.objectMetadata.Delete(c, metadata)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return message.FromBlockHeaderListDTO(recorded), nil
 }
 
 func (h *metadataRegistry) GetByObjectName(c context.Context, msg *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadata, error) {
@@ -106,7 +123,7 @@ func (h *metadataRegistry) GetByObjectName(c context.Context, msg *rpcmessage.Ob
 		return nil, fmt.Errorf("Name is empty")
 	}
 
-	metadata, err := h.objectMetadata.MetadataByObjectName(c, msg.Group, msg.Partition, msg.Name, msg.Path)
+	metadata, err := h.objectMetadata.MetadataByObjectName(c, msg.Group, msg.Partition, msg.Path, msg.Name)
 	if err != nil {
 		if errors.Is(err, soserror.NotFound) {
 			return nil, status.Errorf(soserror.NotFoundErrorCode, "%v", err)
@@ -158,7 +175,54 @@ func (h *metadataRegistry) FindMetadataOnPath(c context.Context, msg *rpcmessage
 		return nil, fmt.Errorf("Partition is empty")
 	}
 
-	list, err := h.objectMetadata.MetadataListOnPath(c, msg.Group, msg.Partition, msg.Path)
+	list, nextCursor, err := h.objectMetadata.MetadataListOnPath(
+		c, msg.Group, msg.Partition, msg.Path, entity.NewObjectIDFrom(msg.LastObjectID), int(msg.Limit),
+	)
+	if err != nil {
+		if errors.Is(err, soserror.NotFound) {
+			return nil, status.Errorf(soserror.NotFoundErrorCode, "%v", err)
+		}
+		return nil, err
+	}
+
+	resp := message.FromObjectMetadataListDTO(list)
+	resp.NextCursor = nextCursor.ToInt64()
+	return resp, nil
+}
+
+func (h *metadataRegistry) CreateUploadSession(c context.Context, msg *message.UploadSession) (*message.UploadSession, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.CreateUploadSession]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("UploadSession is nil")
+	case msg.Id <= 0:
+		return nil, fmt.Errorf("UploadID is invalid")
+	case validation.IsNil(msg.ObjectID):
+		return nil, fmt.Errorf("ObjectID is nil")
+	}
+
+	session, err := h.uploadSession.Create(c, message.ToUploadSessionDTO(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromUploadSessionDTO(session), nil
+}
+
+func (h *metadataRegistry) GetUploadSession(c context.Context, msg *rpcmessage.UploadSessionRequest) (*message.UploadSession, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.GetUploadSession]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("UploadSessionRequest is nil")
+	case msg.UploadID <= 0:
+		return nil, fmt.Errorf("UploadID is invalid")
+	}
+
+	session, err := h.uploadSession.SessionByID(c, msg.UploadID)
 	if err != nil {
 		if errors.Is(err, soserror.NotFound) {
 			return nil, status.Errorf(soserror.NotFoundErrorCode, "%v", err)
@@ -166,5 +230,449 @@ func (h *metadataRegistry) FindMetadataOnPath(c context.Context, msg *rpcmessage
 		return nil, err
 	}
 
-	return message.FromObjectMetadataListDTO(list), nil
+	return message.FromUploadSessionDTO(session), nil
+}
+
+func (h *metadataRegistry) PutUploadPart(c context.Context, msg *rpcmessage.UploadPartRequest) (*message.UploadSession, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.PutUploadPart]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("UploadPartRequest is nil")
+	case msg.UploadID <= 0:
+		return nil, fmt.Errorf("UploadID is invalid")
+	case validation.IsNil(msg.Part):
+		return nil, fmt.Errorf("UploadPart is nil")
+	}
+
+	if err := h.uploadSession.PutPart(c, msg.UploadID, message.ToUploadPartDTO(msg.Part)); err != nil {
+		if errors.Is(err, soserror.NotFound) {
+			return nil, status.Errorf(soserror.NotFoundErrorCode, "%v", err)
+		}
+		return nil, err
+	}
+
+	session, err := h.uploadSession.SessionByID(c, msg.UploadID)
+	if err != nil {
+		if errors.Is(err, soserror.NotFound) {
+			return nil, status.Errorf(soserror.NotFoundErrorCode, "%v", err)
+		}
+		return nil, err
+	}
+
+	return message.FromUploadSessionDTO(session), nil
+}
+
+func (h *metadataRegistry) DeleteUploadSession(c context.Context, msg *rpcmessage.UploadSessionRequest) error {
+	log.FromContext(c).Debugf("[MetadataRegistry.DeleteUploadSession]")
+	switch {
+	case validation.IsNil(c):
+		return fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return fmt.Errorf("UploadSessionRequest is nil")
+	case msg.UploadID <= 0:
+		return fmt.Errorf("UploadID is invalid")
+	}
+
+	if err := h.uploadSession.Delete(c, msg.UploadID); err != nil {
+		if errors.Is(err, soserror.NotFound) {
+			return status.Errorf(soserror.NotFoundErrorCode, "%v", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (h *metadataRegistry) FindExpiredUploadSessions(c context.Context, msg *rpcmessage.UploadSessionRequest) (*message.UploadSessionList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.FindExpiredUploadSessions]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("UploadSessionRequest is nil")
+	case validation.IsNil(msg.Now):
+		return nil, fmt.Errorf("Now is nil")
+	}
+
+	list, err := h.uploadSession.FindExpired(c, msg.Now.AsTime())
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromUploadSessionListDTO(list), nil
+}
+
+func (h *metadataRegistry) GetDirectory(c context.Context, msg *rpcmessage.ObjectMetadataRequest) (*message.Directory, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.GetDirectory]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("ObjectMetadataRequest is nil")
+	case validation.IsEmpty(msg.Group):
+		return nil, fmt.Errorf("Group is empty")
+	case validation.IsEmpty(msg.Partition):
+		return nil, fmt.Errorf("Partition is empty")
+	}
+
+	directory, err := h.objectMetadata.Directory(c, msg.Group, msg.Partition, msg.Path)
+	if err != nil {
+		if errors.Is(err, soserror.NotFound) {
+			return nil, status.Errorf(soserror.NotFoundErrorCode, "%v", err)
+		}
+		return nil, err
+	}
+
+	return message.FromDirectoryDTO(directory), nil
+}
+
+func (h *metadataRegistry) GetCredential(c context.Context, msg *rpcmessage.CredentialRequest) (*message.Credential, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.GetCredential]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("CredentialRequest is nil")
+	case validation.IsEmpty(msg.AccessKey):
+		return nil, fmt.Errorf("AccessKey is empty")
+	}
+
+	credential, err := h.credential.CredentialByAccessKey(c, msg.AccessKey)
+	if err != nil {
+		if errors.Is(err, soserror.NotFound) {
+			return nil, status.Errorf(soserror.NotFoundErrorCode, "%v", err)
+		}
+		return nil, err
+	}
+
+	return message.FromCredentialDTO(credential), nil
+}
+
+func (h *metadataRegistry) PutPolicy(c context.Context, msg *message.Policy) (*message.Policy, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.PutPolicy]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("Policy is nil")
+	}
+
+	policy, err := h.policy.Put(c, message.ToPolicyDTO(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromPolicyDTO(policy), nil
+}
+
+func (h *metadataRegistry) GetPolicy(c context.Context, msg *rpcmessage.PolicyRequest) (*message.Policy, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.GetPolicy]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("PolicyRequest is nil")
+	case validation.IsEmpty(msg.Group):
+		return nil, fmt.Errorf("Group is empty")
+	}
+
+	policy, err := h.policy.PolicyByPartition(c, msg.Group, msg.Partition)
+	if err != nil {
+		if errors.Is(err, soserror.NotFound) {
+			return nil, status.Errorf(soserror.NotFoundErrorCode, "%v", err)
+		}
+		return nil, err
+	}
+
+	return message.FromPolicyDTO(policy), nil
+}
+
+func (h *metadataRegistry) DeletePolicy(c context.Context, msg *rpcmessage.PolicyRequest) error {
+	log.FromContext(c).Debugf("[MetadataRegistry.DeletePolicy]")
+	switch {
+	case validation.IsNil(c):
+		return fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return fmt.Errorf("PolicyRequest is nil")
+	case validation.IsEmpty(msg.Group):
+		return fmt.Errorf("Group is empty")
+	}
+
+	if err := h.policy.Delete(c, msg.Group, msg.Partition); err != nil {
+		if errors.Is(err, soserror.NotFound) {
+			return status.Errorf(soserror.NotFoundErrorCode, "%v", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (h *metadataRegistry) FindPolicies(c context.Context, msg *rpcmessage.PolicyRequest) (*message.Policies, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.FindPolicies]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("PolicyRequest is nil")
+	case validation.IsEmpty(msg.Group):
+		return nil, fmt.Errorf("Group is empty")
+	}
+
+	policies, err := h.policy.FindPolicies(c, msg.Group, msg.Partition)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromPoliciesDTO(policies), nil
+}
+
+// FindBlockHeaders returns the block headers referenced by the objects and those of the deduplicated
+// content. Upload sessions are read before the metadata, so the blocks of a session completed
+// meanwhile are found in either.
+func (h *metadataRegistry) FindBlockHeaders(c context.Context, msg *rpcmessage.BlockHeaderRequest) (*message.BlockHeaderList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.FindBlockHeaders]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("BlockHeaderRequest is nil")
+	}
+
+	sessionHeaders, err := h.uploadSession.BlockHeadersOfObjects(c, msg.ObjectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionContents, err := h.uploadSession.BlockHeadersOfContents(c, msg.Hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := h.objectMetadata.BlockHeadersOfObjects(c, msg.ObjectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := h.objectMetadata.BlockHeadersOfContents(c, msg.Hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	headers = append(headers, contents...)
+	headers = append(headers, sessionHeaders...)
+	return message.FromBlockHeaderListDTO(append(headers, sessionContents...)), nil
+}
+
+func (h *metadataRegistry) ReserveVersion(c context.Context, msg *message.Object) (*message.Object, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.ReserveVersion]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("Object is nil")
+	case validation.IsNil(msg.ExpiresAt):
+		return nil, fmt.Errorf("ExpiresAt is nil")
+	}
+
+	reserved, err := h.objectMetadata.ReserveVersion(c, message.ToObjectDTO(msg))
+	if err != nil {
+		return nil, h.statusError(err)
+	}
+
+	return message.FromObjectDTO(reserved), nil
+}
+
+func (h *metadataRegistry) CommitVersion(c context.Context, msg *message.Object) (*message.ObjectMetadata, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.CommitVersion]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("Object is nil")
+	}
+
+	metadata, err := h.objectMetadata.CommitVersion(c, message.ToObjectDTO(msg))
+	if err != nil {
+		return nil, h.statusError(err)
+	}
+
+	return message.FromObjectMetadataDTO(metadata), nil
+}
+
+func (h *metadataRegistry) RecordBlocks(c context.Context, msg *message.Object) error {
+	log.FromContext(c).Debugf("[MetadataRegistry.RecordBlocks]")
+	switch {
+	case validation.IsNil(c):
+		return fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return fmt.Errorf("Object is nil")
+	}
+
+	if err := h.objectMetadata.RecordBlocks(c, message.ToObjectDTO(msg)); err != nil {
+		return h.statusError(err)
+	}
+	return nil
+}
+
+func (h *metadataRegistry) AbortVersion(c context.Context, msg *message.Object) (*message.BlockHeaderList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.AbortVersion]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("Object is nil")
+	}
+
+	recorded, err := h.objectMetadata.AbortVersion(c, message.ToObjectDTO(msg))
+	if err != nil {
+		return nil, h.statusError(err)
+	}
+	return message.FromBlockHeaderListDTO(recorded), nil
+}
+
+func (h *metadataRegistry) ReleaseExpiredVersions(c context.Context, msg *rpcmessage.ExpiredVersionRequest) (*message.BlockHeaderList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.ReleaseExpiredVersions]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("ExpiredVersionRequest is nil")
+	case validation.IsNil(msg.Now):
+		return nil, fmt.Errorf("Now is nil")
+	}
+
+	released, err := h.objectMetadata.ReleaseExpiredVersions(c, msg.Now.AsTime())
+	if err != nil {
+		return nil, err
+	}
+	return message.FromBlockHeaderListDTO(released), nil
+}
+
+func (h *metadataRegistry) PutTags(c context.Context, msg *message.ObjectMetadata) (*message.ObjectMetadata, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.PutTags]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("ObjectMetadata is nil")
+	}
+
+	metadata, err := h.objectMetadata.PutTags(c, message.ToObjectMetadataDTO(msg))
+	if err != nil {
+		return nil, h.statusError(err)
+	}
+
+	return message.FromObjectMetadataDTO(metadata), nil
+}
+
+func (h *metadataRegistry) FindMetadataByTags(c context.Context, msg *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.FindMetadataByTags]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("ObjectMetadataRequest is nil")
+	case validation.IsEmpty(msg.Group):
+		return nil, fmt.Errorf("Group is empty")
+	case validation.IsEmpty(msg.Partition):
+		return nil, fmt.Errorf("Partition is empty")
+	}
+
+	expression, err := entity.ParseTagExpression(msg.TagExpression)
+	if err != nil {
+		return nil, err
+	}
+
+	list, nextCursor, err := h.objectMetadata.MetadataListByTags(
+		c, msg.Group, msg.Partition, expression, entity.NewObjectIDFrom(msg.LastObjectID), int(msg.Limit),
+	)
+	if err != nil {
+		return nil, h.statusError(err)
+	}
+
+	resp := message.FromObjectMetadataListDTO(list)
+	resp.NextCursor = nextCursor.ToInt64()
+	return resp, nil
+}
+
+func (h *metadataRegistry) FindMetadataByStaleDataKey(c context.Context, msg *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.FindMetadataByStaleDataKey]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("ObjectMetadataRequest is nil")
+	case validation.IsEmpty(msg.KeyID):
+		return nil, fmt.Errorf("KeyID is empty")
+	}
+
+	list, nextCursor, err := h.objectMetadata.MetadataListByStaleDataKey(
+		c, msg.KeyID, entity.NewObjectIDFrom(msg.LastObjectID), int(msg.Limit),
+	)
+	if err != nil {
+		return nil, h.statusError(err)
+	}
+
+	resp := message.FromObjectMetadataListDTO(list)
+	resp.NextCursor = nextCursor.ToInt64()
+	return resp, nil
+}
+
+func (h *metadataRegistry) RewrapDataKeys(c context.Context, msg *message.ObjectMetadata) (*message.ObjectMetadata, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.RewrapDataKeys]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("ObjectMetadata is nil")
+	}
+
+	metadata, err := h.objectMetadata.RewrapDataKeys(c, message.ToObjectMetadataDTO(msg))
+	if err != nil {
+		return nil, h.statusError(err)
+	}
+
+	return message.FromObjectMetadataDTO(metadata), nil
+}
+
+func (h *metadataRegistry) Move(c context.Context, msg *rpcmessage.MoveRequest) (*message.ObjectMetadata, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.Move]")
+	switch {
+	case validation.IsNil(c):
+		return nil, fmt.Errorf("Context is nil")
+	case validation.IsNil(msg):
+		return nil, fmt.Errorf("MoveRequest is nil")
+	case validation.IsNil(msg.Metadata):
+		return nil, fmt.Errorf("ObjectMetadata is nil")
+	case validation.IsEmpty(msg.Path):
+		return nil, fmt.Errorf("Path is empty")
+	case validation.IsEmpty(msg.Name):
+		return nil, fmt.Errorf("Name is empty")
+	}
+
+	destination := dto.Destination{
+		Path: msg.Path,
+		Name: msg.Name,
+	}
+	metadata, err := h.objectMetadata.Move(c, message.ToObjectMetadataDTO(msg.Metadata), destination)
+	if err != nil {
+		return nil, h.statusError(err)
+	}
+
+	return message.FromObjectMetadataDTO(metadata), nil
+}
+
+func (h *metadataRegistry) statusError(err error) error {
+	switch {
+	case errors.Is(err, soserror.NotFound):
+		return status.Errorf(soserror.NotFoundErrorCode, "%v", err)
+	case errors.Is(err, soserror.Conflict):
+		return status.Errorf(soserror.ConflictErrorCode, "%v", err)
+	case errors.Is(err, soserror.PreconditionFailed):
+		return status.Errorf(soserror.PreconditionFailedErrorCode, "%v", err)
+	}
+	return err
 }
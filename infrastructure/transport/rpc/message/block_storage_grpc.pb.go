@@ -12,6 +12,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -25,8 +26,17 @@ const _ = grpc.SupportPackageIsVersion7
 type BlockStorageClient interface {
 	Put(ctx context.Context, in *message.Block, opts ...grpc.CallOption) (*StorageResponse, error)
 	GetBlock(ctx context.Context, in *message.BlockHeader, opts ...grpc.CallOption) (*message.Block, error)
+	PutStream(ctx context.Context, opts ...grpc.CallOption) (BlockStorage_PutStreamClient, error)
+	GetBlockStream(ctx context.Context, in *message.BlockHeader, opts ...grpc.CallOption) (BlockStorage_GetBlockStreamClient, error)
 	GetBlockHeader(ctx context.Context, in *message.BlockHeader, opts ...grpc.CallOption) (*message.BlockHeader, error)
 	Delete(ctx context.Context, in *message.BlockHeader, opts ...grpc.CallOption) (*StorageResponse, error)
+	Copy(ctx context.Context, in *CopyBlockRequest, opts ...grpc.CallOption) (*StorageResponse, error)
+	GetScrubStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*message.ScrubStatus, error)
+	StartScrub(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*message.ScrubStatus, error)
+	ListBlocks(ctx context.Context, in *ListBlocksRequest, opts ...grpc.CallOption) (BlockStorage_ListBlocksClient, error)
+	Reference(ctx context.Context, in *message.BlockHeader, opts ...grpc.CallOption) (*ReferenceResponse, error)
+	GetDedupStats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*message.DedupStats, error)
+	Reconcile(ctx context.Context, in *ReconcileRequest, opts ...grpc.CallOption) (*ReconcileResponse, error)
 }
 
 type blockStorageClient struct {
@@ -55,6 +65,72 @@ func (c *blockStorageClient) GetBlock(ctx context.Context, in *message.BlockHead
 	return out, nil
 }
 
+func (c *blockStorageClient) PutStream(ctx context.Context, opts ...grpc.CallOption) (BlockStorage_PutStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &BlockStorage_ServiceDesc.Streams[0], "/rpcmessage.BlockStorage/PutStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blockStoragePutStreamClient{stream}
+	return x, nil
+}
+
+type BlockStorage_PutStreamClient interface {
+	Send(*BlockChunk) error
+	CloseAndRecv() (*StorageResponse, error)
+	grpc.ClientStream
+}
+
+type blockStoragePutStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *blockStoragePutStreamClient) Send(m *BlockChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *blockStoragePutStreamClient) CloseAndRecv() (*StorageResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(StorageResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *blockStorageClient) GetBlockStream(ctx context.Context, in *message.BlockHeader, opts ...grpc.CallOption) (BlockStorage_GetBlockStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &BlockStorage_ServiceDesc.Streams[1], "/rpcmessage.BlockStorage/GetBlockStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blockStorageGetBlockStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type BlockStorage_GetBlockStreamClient interface {
+	Recv() (*BlockChunk, error)
+	grpc.ClientStream
+}
+
+type blockStorageGetBlockStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *blockStorageGetBlockStreamClient) Recv() (*BlockChunk, error) {
+	m := new(BlockChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *blockStorageClient) GetBlockHeader(ctx context.Context, in *message.BlockHeader, opts ...grpc.CallOption) (*message.BlockHeader, error) {
 	out := new(message.BlockHeader)
 	err := c.cc.Invoke(ctx, "/rpcmessage.BlockStorage/GetBlockHeader", in, out, opts...)
@@ -73,14 +149,109 @@ func (c *blockStorageClient) Delete(ctx context.Context, in *message.BlockHeader
 	return out, nil
 }
 
+func (c *blockStorageClient) Copy(ctx context.Context, in *CopyBlockRequest, opts ...grpc.CallOption) (*StorageResponse, error) {
+	out := new(StorageResponse)
+	err := c.cc.Invoke(ctx, "/rpcmessage.BlockStorage/Copy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockStorageClient) GetScrubStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*message.ScrubStatus, error) {
+	out := new(message.ScrubStatus)
+	err := c.cc.Invoke(ctx, "/rpcmessage.BlockStorage/GetScrubStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockStorageClient) StartScrub(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*message.ScrubStatus, error) {
+	out := new(message.ScrubStatus)
+	err := c.cc.Invoke(ctx, "/rpcmessage.BlockStorage/StartScrub", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockStorageClient) ListBlocks(ctx context.Context, in *ListBlocksRequest, opts ...grpc.CallOption) (BlockStorage_ListBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &BlockStorage_ServiceDesc.Streams[2], "/rpcmessage.BlockStorage/ListBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blockStorageListBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type BlockStorage_ListBlocksClient interface {
+	Recv() (*message.BlockHeader, error)
+	grpc.ClientStream
+}
+
+type blockStorageListBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *blockStorageListBlocksClient) Recv() (*message.BlockHeader, error) {
+	m := new(message.BlockHeader)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *blockStorageClient) Reference(ctx context.Context, in *message.BlockHeader, opts ...grpc.CallOption) (*ReferenceResponse, error) {
+	out := new(ReferenceResponse)
+	err := c.cc.Invoke(ctx, "/rpcmessage.BlockStorage/Reference", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockStorageClient) GetDedupStats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*message.DedupStats, error) {
+	out := new(message.DedupStats)
+	err := c.cc.Invoke(ctx, "/rpcmessage.BlockStorage/GetDedupStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockStorageClient) Reconcile(ctx context.Context, in *ReconcileRequest, opts ...grpc.CallOption) (*ReconcileResponse, error) {
+	out := new(ReconcileResponse)
+	err := c.cc.Invoke(ctx, "/rpcmessage.BlockStorage/Reconcile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BlockStorageServer is the server API for BlockStorage service.
 // All implementations must embed UnimplementedBlockStorageServer
 // for forward compatibility
 type BlockStorageServer interface {
 	Put(context.Context, *message.Block) (*StorageResponse, error)
 	GetBlock(context.Context, *message.BlockHeader) (*message.Block, error)
+	PutStream(BlockStorage_PutStreamServer) error
+	GetBlockStream(*message.BlockHeader, BlockStorage_GetBlockStreamServer) error
 	GetBlockHeader(context.Context, *message.BlockHeader) (*message.BlockHeader, error)
 	Delete(context.Context, *message.BlockHeader) (*StorageResponse, error)
+	Copy(context.Context, *CopyBlockRequest) (*StorageResponse, error)
+	GetScrubStatus(context.Context, *emptypb.Empty) (*message.ScrubStatus, error)
+	StartScrub(context.Context, *emptypb.Empty) (*message.ScrubStatus, error)
+	ListBlocks(*ListBlocksRequest, BlockStorage_ListBlocksServer) error
+	Reference(context.Context, *message.BlockHeader) (*ReferenceResponse, error)
+	GetDedupStats(context.Context, *emptypb.Empty) (*message.DedupStats, error)
+	Reconcile(context.Context, *ReconcileRequest) (*ReconcileResponse, error)
 	mustEmbedUnimplementedBlockStorageServer()
 }
 
@@ -94,12 +265,39 @@ func (UnimplementedBlockStorageServer) Put(context.Context, *message.Block) (*St
 func (UnimplementedBlockStorageServer) GetBlock(context.Context, *message.BlockHeader) (*message.Block, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
 }
+func (UnimplementedBlockStorageServer) PutStream(BlockStorage_PutStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method PutStream not implemented")
+}
+func (UnimplementedBlockStorageServer) GetBlockStream(*message.BlockHeader, BlockStorage_GetBlockStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetBlockStream not implemented")
+}
 func (UnimplementedBlockStorageServer) GetBlockHeader(context.Context, *message.BlockHeader) (*message.BlockHeader, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBlockHeader not implemented")
 }
 func (UnimplementedBlockStorageServer) Delete(context.Context, *message.BlockHeader) (*StorageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedBlockStorageServer) Copy(context.Context, *CopyBlockRequest) (*StorageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Copy not implemented")
+}
+func (UnimplementedBlockStorageServer) GetScrubStatus(context.Context, *emptypb.Empty) (*message.ScrubStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetScrubStatus not implemented")
+}
+func (UnimplementedBlockStorageServer) StartScrub(context.Context, *emptypb.Empty) (*message.ScrubStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartScrub not implemented")
+}
+func (UnimplementedBlockStorageServer) ListBlocks(*ListBlocksRequest, BlockStorage_ListBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListBlocks not implemented")
+}
+func (UnimplementedBlockStorageServer) Reference(context.Context, *message.BlockHeader) (*ReferenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reference not implemented")
+}
+func (UnimplementedBlockStorageServer) GetDedupStats(context.Context, *emptypb.Empty) (*message.DedupStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDedupStats not implemented")
+}
+func (UnimplementedBlockStorageServer) Reconcile(context.Context, *ReconcileRequest) (*ReconcileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reconcile not implemented")
+}
 func (UnimplementedBlockStorageServer) mustEmbedUnimplementedBlockStorageServer() {}
 
 // UnsafeBlockStorageServer may be embedded to opt out of forward compatibility for this service.
@@ -149,6 +347,53 @@ func _BlockStorage_GetBlock_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlockStorage_PutStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BlockStorageServer).PutStream(&blockStoragePutStreamServer{stream})
+}
+
+type BlockStorage_PutStreamServer interface {
+	SendAndClose(*StorageResponse) error
+	Recv() (*BlockChunk, error)
+	grpc.ServerStream
+}
+
+type blockStoragePutStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *blockStoragePutStreamServer) SendAndClose(m *StorageResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *blockStoragePutStreamServer) Recv() (*BlockChunk, error) {
+	m := new(BlockChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _BlockStorage_GetBlockStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(message.BlockHeader)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlockStorageServer).GetBlockStream(m, &blockStorageGetBlockStreamServer{stream})
+}
+
+type BlockStorage_GetBlockStreamServer interface {
+	Send(*BlockChunk) error
+	grpc.ServerStream
+}
+
+type blockStorageGetBlockStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *blockStorageGetBlockStreamServer) Send(m *BlockChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _BlockStorage_GetBlockHeader_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(message.BlockHeader)
 	if err := dec(in); err != nil {
@@ -185,6 +430,135 @@ func _BlockStorage_Delete_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlockStorage_Copy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopyBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockStorageServer).Copy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.BlockStorage/Copy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockStorageServer).Copy(ctx, req.(*CopyBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockStorage_GetScrubStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockStorageServer).GetScrubStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.BlockStorage/GetScrubStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockStorageServer).GetScrubStatus(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockStorage_StartScrub_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockStorageServer).StartScrub(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.BlockStorage/StartScrub",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockStorageServer).StartScrub(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockStorage_ListBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlockStorageServer).ListBlocks(m, &blockStorageListBlocksServer{stream})
+}
+
+type BlockStorage_ListBlocksServer interface {
+	Send(*message.BlockHeader) error
+	grpc.ServerStream
+}
+
+type blockStorageListBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *blockStorageListBlocksServer) Send(m *message.BlockHeader) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _BlockStorage_Reference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(message.BlockHeader)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockStorageServer).Reference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.BlockStorage/Reference",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockStorageServer).Reference(ctx, req.(*message.BlockHeader))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockStorage_GetDedupStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockStorageServer).GetDedupStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.BlockStorage/GetDedupStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockStorageServer).GetDedupStats(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockStorage_Reconcile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockStorageServer).Reconcile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.BlockStorage/Reconcile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockStorageServer).Reconcile(ctx, req.(*ReconcileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BlockStorage_ServiceDesc is the grpc.ServiceDesc for BlockStorage service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -208,7 +582,47 @@ var BlockStorage_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _BlockStorage_Delete_Handler,
 		},
+		{
+			MethodName: "Copy",
+			Handler:    _BlockStorage_Copy_Handler,
+		},
+		{
+			MethodName: "GetScrubStatus",
+			Handler:    _BlockStorage_GetScrubStatus_Handler,
+		},
+		{
+			MethodName: "StartScrub",
+			Handler:    _BlockStorage_StartScrub_Handler,
+		},
+		{
+			MethodName: "Reference",
+			Handler:    _BlockStorage_Reference_Handler,
+		},
+		{
+			MethodName: "GetDedupStats",
+			Handler:    _BlockStorage_GetDedupStats_Handler,
+		},
+		{
+			MethodName: "Reconcile",
+			Handler:    _BlockStorage_Reconcile_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PutStream",
+			Handler:       _BlockStorage_PutStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetBlockStream",
+			Handler:       _BlockStorage_GetBlockStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListBlocks",
+			Handler:       _BlockStorage_ListBlocks_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "message/block_storage.proto",
 }
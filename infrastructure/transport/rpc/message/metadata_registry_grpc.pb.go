@@ -25,10 +25,32 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MetadataRegistryClient interface {
 	Put(ctx context.Context, in *message.Object, opts ...grpc.CallOption) (*message.ObjectMetadata, error)
-	Delete(ctx context.Context, in *message.ObjectMetadata, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Delete(ctx context.Context, in *message.ObjectMetadata, opts ...grpc.CallOption) (*message.BlockHeaderList, error)
 	GetByObjectName(ctx context.Context, in *ObjectMetadataRequest, opts ...grpc.CallOption) (*message.ObjectMetadata, error)
 	GetByObjectID(ctx context.Context, in *ObjectMetadataRequest, opts ...grpc.CallOption) (*message.ObjectMetadata, error)
 	FindMetadataOnPath(ctx context.Context, in *ObjectMetadataRequest, opts ...grpc.CallOption) (*message.ObjectMetadataList, error)
+	CreateUploadSession(ctx context.Context, in *message.UploadSession, opts ...grpc.CallOption) (*message.UploadSession, error)
+	GetUploadSession(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*message.UploadSession, error)
+	PutUploadPart(ctx context.Context, in *UploadPartRequest, opts ...grpc.CallOption) (*message.UploadSession, error)
+	DeleteUploadSession(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	FindExpiredUploadSessions(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*message.UploadSessionList, error)
+	GetDirectory(ctx context.Context, in *ObjectMetadataRequest, opts ...grpc.CallOption) (*message.Directory, error)
+	GetCredential(ctx context.Context, in *CredentialRequest, opts ...grpc.CallOption) (*message.Credential, error)
+	PutPolicy(ctx context.Context, in *message.Policy, opts ...grpc.CallOption) (*message.Policy, error)
+	GetPolicy(ctx context.Context, in *PolicyRequest, opts ...grpc.CallOption) (*message.Policy, error)
+	DeletePolicy(ctx context.Context, in *PolicyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	FindPolicies(ctx context.Context, in *PolicyRequest, opts ...grpc.CallOption) (*message.Policies, error)
+	FindBlockHeaders(ctx context.Context, in *BlockHeaderRequest, opts ...grpc.CallOption) (*message.BlockHeaderList, error)
+	ReserveVersion(ctx context.Context, in *message.Object, opts ...grpc.CallOption) (*message.Object, error)
+	CommitVersion(ctx context.Context, in *message.Object, opts ...grpc.CallOption) (*message.ObjectMetadata, error)
+	RecordBlocks(ctx context.Context, in *message.Object, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	AbortVersion(ctx context.Context, in *message.Object, opts ...grpc.CallOption) (*message.BlockHeaderList, error)
+	ReleaseExpiredVersions(ctx context.Context, in *ExpiredVersionRequest, opts ...grpc.CallOption) (*message.BlockHeaderList, error)
+	PutTags(ctx context.Context, in *message.ObjectMetadata, opts ...grpc.CallOption) (*message.ObjectMetadata, error)
+	FindMetadataByTags(ctx context.Context, in *ObjectMetadataRequest, opts ...grpc.CallOption) (*message.ObjectMetadataList, error)
+	Move(ctx context.Context, in *MoveRequest, opts ...grpc.CallOption) (*message.ObjectMetadata, error)
+	FindMetadataByStaleDataKey(ctx context.Context, in *ObjectMetadataRequest, opts ...grpc.CallOption) (*message.ObjectMetadataList, error)
+	RewrapDataKeys(ctx context.Context, in *message.ObjectMetadata, opts ...grpc.CallOption) (*message.ObjectMetadata, error)
 }
 
 type metadataRegistryClient struct {
@@ -48,8 +70,8 @@ func (c *metadataRegistryClient) Put(ctx context.Context, in *message.Object, op
 	return out, nil
 }
 
-func (c *metadataRegistryClient) Delete(ctx context.Context, in *message.ObjectMetadata, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	out := new(emptypb.Empty)
+func (c *metadataRegistryClient) Delete(ctx context.Context, in *message.ObjectMetadata, opts ...grpc.CallOption) (*message.BlockHeaderList, error) {
+	out := new(message.BlockHeaderList)
 	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/Delete", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -84,15 +106,235 @@ func (c *metadataRegistryClient) FindMetadataOnPath(ctx context.Context, in *Obj
 	return out, nil
 }
 
+func (c *metadataRegistryClient) CreateUploadSession(ctx context.Context, in *message.UploadSession, opts ...grpc.CallOption) (*message.UploadSession, error) {
+	out := new(message.UploadSession)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/CreateUploadSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) GetUploadSession(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*message.UploadSession, error) {
+	out := new(message.UploadSession)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/GetUploadSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) PutUploadPart(ctx context.Context, in *UploadPartRequest, opts ...grpc.CallOption) (*message.UploadSession, error) {
+	out := new(message.UploadSession)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/PutUploadPart", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) DeleteUploadSession(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/DeleteUploadSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) FindExpiredUploadSessions(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*message.UploadSessionList, error) {
+	out := new(message.UploadSessionList)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/FindExpiredUploadSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) GetDirectory(ctx context.Context, in *ObjectMetadataRequest, opts ...grpc.CallOption) (*message.Directory, error) {
+	out := new(message.Directory)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/GetDirectory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) GetCredential(ctx context.Context, in *CredentialRequest, opts ...grpc.CallOption) (*message.Credential, error) {
+	out := new(message.Credential)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/GetCredential", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) PutPolicy(ctx context.Context, in *message.Policy, opts ...grpc.CallOption) (*message.Policy, error) {
+	out := new(message.Policy)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/PutPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) GetPolicy(ctx context.Context, in *PolicyRequest, opts ...grpc.CallOption) (*message.Policy, error) {
+	out := new(message.Policy)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/GetPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) DeletePolicy(ctx context.Context, in *PolicyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/DeletePolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) FindPolicies(ctx context.Context, in *PolicyRequest, opts ...grpc.CallOption) (*message.Policies, error) {
+	out := new(message.Policies)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/FindPolicies", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) FindBlockHeaders(ctx context.Context, in *BlockHeaderRequest, opts ...grpc.CallOption) (*message.BlockHeaderList, error) {
+	out := new(message.BlockHeaderList)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/FindBlockHeaders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) ReserveVersion(ctx context.Context, in *message.Object, opts ...grpc.CallOption) (*message.Object, error) {
+	out := new(message.Object)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/ReserveVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) CommitVersion(ctx context.Context, in *message.Object, opts ...grpc.CallOption) (*message.ObjectMetadata, error) {
+	out := new(message.ObjectMetadata)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/CommitVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) RecordBlocks(ctx context.Context, in *message.Object, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/RecordBlocks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) AbortVersion(ctx context.Context, in *message.Object, opts ...grpc.CallOption) (*message.BlockHeaderList, error) {
+	out := new(message.BlockHeaderList)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/AbortVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) ReleaseExpiredVersions(ctx context.Context, in *ExpiredVersionRequest, opts ...grpc.CallOption) (*message.BlockHeaderList, error) {
+	out := new(message.BlockHeaderList)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/ReleaseExpiredVersions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) PutTags(ctx context.Context, in *message.ObjectMetadata, opts ...grpc.CallOption) (*message.ObjectMetadata, error) {
+	out := new(message.ObjectMetadata)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/PutTags", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) FindMetadataByTags(ctx context.Context, in *ObjectMetadataRequest, opts ...grpc.CallOption) (*message.ObjectMetadataList, error) {
+	out := new(message.ObjectMetadataList)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/FindMetadataByTags", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) Move(ctx context.Context, in *MoveRequest, opts ...grpc.CallOption) (*message.ObjectMetadata, error) {
+	out := new(message.ObjectMetadata)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/Move", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) FindMetadataByStaleDataKey(ctx context.Context, in *ObjectMetadataRequest, opts ...grpc.CallOption) (*message.ObjectMetadataList, error) {
+	out := new(message.ObjectMetadataList)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/FindMetadataByStaleDataKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataRegistryClient) RewrapDataKeys(ctx context.Context, in *message.ObjectMetadata, opts ...grpc.CallOption) (*message.ObjectMetadata, error) {
+	out := new(message.ObjectMetadata)
+	err := c.cc.Invoke(ctx, "/rpcmessage.MetadataRegistry/RewrapDataKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MetadataRegistryServer is the server API for MetadataRegistry service.
 // All implementations must embed UnimplementedMetadataRegistryServer
 // for forward compatibility
 type MetadataRegistryServer interface {
 	Put(context.Context, *message.Object) (*message.ObjectMetadata, error)
-	Delete(context.Context, *message.ObjectMetadata) (*emptypb.Empty, error)
+	Delete(context.Context, *message.ObjectMetadata) (*message.BlockHeaderList, error)
 	GetByObjectName(context.Context, *ObjectMetadataRequest) (*message.ObjectMetadata, error)
 	GetByObjectID(context.Context, *ObjectMetadataRequest) (*message.ObjectMetadata, error)
 	FindMetadataOnPath(context.Context, *ObjectMetadataRequest) (*message.ObjectMetadataList, error)
+	CreateUploadSession(context.Context, *message.UploadSession) (*message.UploadSession, error)
+	GetUploadSession(context.Context, *UploadSessionRequest) (*message.UploadSession, error)
+	PutUploadPart(context.Context, *UploadPartRequest) (*message.UploadSession, error)
+	DeleteUploadSession(context.Context, *UploadSessionRequest) (*emptypb.Empty, error)
+	FindExpiredUploadSessions(context.Context, *UploadSessionRequest) (*message.UploadSessionList, error)
+	GetDirectory(context.Context, *ObjectMetadataRequest) (*message.Directory, error)
+	GetCredential(context.Context, *CredentialRequest) (*message.Credential, error)
+	PutPolicy(context.Context, *message.Policy) (*message.Policy, error)
+	GetPolicy(context.Context, *PolicyRequest) (*message.Policy, error)
+	DeletePolicy(context.Context, *PolicyRequest) (*emptypb.Empty, error)
+	FindPolicies(context.Context, *PolicyRequest) (*message.Policies, error)
+	FindBlockHeaders(context.Context, *BlockHeaderRequest) (*message.BlockHeaderList, error)
+	ReserveVersion(context.Context, *message.Object) (*message.Object, error)
+	CommitVersion(context.Context, *message.Object) (*message.ObjectMetadata, error)
+	RecordBlocks(context.Context, *message.Object) (*emptypb.Empty, error)
+	AbortVersion(context.Context, *message.Object) (*message.BlockHeaderList, error)
+	ReleaseExpiredVersions(context.Context, *ExpiredVersionRequest) (*message.BlockHeaderList, error)
+	PutTags(context.Context, *message.ObjectMetadata) (*message.ObjectMetadata, error)
+	FindMetadataByTags(context.Context, *ObjectMetadataRequest) (*message.ObjectMetadataList, error)
+	Move(context.Context, *MoveRequest) (*message.ObjectMetadata, error)
+	FindMetadataByStaleDataKey(context.Context, *ObjectMetadataRequest) (*message.ObjectMetadataList, error)
+	RewrapDataKeys(context.Context, *message.ObjectMetadata) (*message.ObjectMetadata, error)
 	mustEmbedUnimplementedMetadataRegistryServer()
 }
 
@@ -103,7 +345,7 @@ type UnimplementedMetadataRegistryServer struct {
 func (UnimplementedMetadataRegistryServer) Put(context.Context, *message.Object) (*message.ObjectMetadata, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
 }
-func (UnimplementedMetadataRegistryServer) Delete(context.Context, *message.ObjectMetadata) (*emptypb.Empty, error) {
+func (UnimplementedMetadataRegistryServer) Delete(context.Context, *message.ObjectMetadata) (*message.BlockHeaderList, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
 func (UnimplementedMetadataRegistryServer) GetByObjectName(context.Context, *ObjectMetadataRequest) (*message.ObjectMetadata, error) {
@@ -115,6 +357,72 @@ func (UnimplementedMetadataRegistryServer) GetByObjectID(context.Context, *Objec
 func (UnimplementedMetadataRegistryServer) FindMetadataOnPath(context.Context, *ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FindMetadataOnPath not implemented")
 }
+func (UnimplementedMetadataRegistryServer) CreateUploadSession(context.Context, *message.UploadSession) (*message.UploadSession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUploadSession not implemented")
+}
+func (UnimplementedMetadataRegistryServer) GetUploadSession(context.Context, *UploadSessionRequest) (*message.UploadSession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUploadSession not implemented")
+}
+func (UnimplementedMetadataRegistryServer) PutUploadPart(context.Context, *UploadPartRequest) (*message.UploadSession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutUploadPart not implemented")
+}
+func (UnimplementedMetadataRegistryServer) DeleteUploadSession(context.Context, *UploadSessionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUploadSession not implemented")
+}
+func (UnimplementedMetadataRegistryServer) FindExpiredUploadSessions(context.Context, *UploadSessionRequest) (*message.UploadSessionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindExpiredUploadSessions not implemented")
+}
+func (UnimplementedMetadataRegistryServer) GetDirectory(context.Context, *ObjectMetadataRequest) (*message.Directory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDirectory not implemented")
+}
+func (UnimplementedMetadataRegistryServer) GetCredential(context.Context, *CredentialRequest) (*message.Credential, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCredential not implemented")
+}
+func (UnimplementedMetadataRegistryServer) PutPolicy(context.Context, *message.Policy) (*message.Policy, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutPolicy not implemented")
+}
+func (UnimplementedMetadataRegistryServer) GetPolicy(context.Context, *PolicyRequest) (*message.Policy, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPolicy not implemented")
+}
+func (UnimplementedMetadataRegistryServer) DeletePolicy(context.Context, *PolicyRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePolicy not implemented")
+}
+func (UnimplementedMetadataRegistryServer) FindPolicies(context.Context, *PolicyRequest) (*message.Policies, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindPolicies not implemented")
+}
+func (UnimplementedMetadataRegistryServer) FindBlockHeaders(context.Context, *BlockHeaderRequest) (*message.BlockHeaderList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindBlockHeaders not implemented")
+}
+func (UnimplementedMetadataRegistryServer) ReserveVersion(context.Context, *message.Object) (*message.Object, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveVersion not implemented")
+}
+func (UnimplementedMetadataRegistryServer) CommitVersion(context.Context, *message.Object) (*message.ObjectMetadata, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitVersion not implemented")
+}
+func (UnimplementedMetadataRegistryServer) RecordBlocks(context.Context, *message.Object) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordBlocks not implemented")
+}
+func (UnimplementedMetadataRegistryServer) AbortVersion(context.Context, *message.Object) (*message.BlockHeaderList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AbortVersion not implemented")
+}
+func (UnimplementedMetadataRegistryServer) ReleaseExpiredVersions(context.Context, *ExpiredVersionRequest) (*message.BlockHeaderList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseExpiredVersions not implemented")
+}
+func (UnimplementedMetadataRegistryServer) PutTags(context.Context, *message.ObjectMetadata) (*message.ObjectMetadata, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutTags not implemented")
+}
+func (UnimplementedMetadataRegistryServer) FindMetadataByTags(context.Context, *ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindMetadataByTags not implemented")
+}
+func (UnimplementedMetadataRegistryServer) Move(context.Context, *MoveRequest) (*message.ObjectMetadata, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Move not implemented")
+}
+func (UnimplementedMetadataRegistryServer) FindMetadataByStaleDataKey(context.Context, *ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindMetadataByStaleDataKey not implemented")
+}
+func (UnimplementedMetadataRegistryServer) RewrapDataKeys(context.Context, *message.ObjectMetadata) (*message.ObjectMetadata, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RewrapDataKeys not implemented")
+}
 func (UnimplementedMetadataRegistryServer) mustEmbedUnimplementedMetadataRegistryServer() {}
 
 // UnsafeMetadataRegistryServer may be embedded to opt out of forward compatibility for this service.
@@ -218,6 +526,402 @@ func _MetadataRegistry_FindMetadataOnPath_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MetadataRegistry_CreateUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(message.UploadSession)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).CreateUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/CreateUploadSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).CreateUploadSession(ctx, req.(*message.UploadSession))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_GetUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).GetUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/GetUploadSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).GetUploadSession(ctx, req.(*UploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_PutUploadPart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadPartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).PutUploadPart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/PutUploadPart",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).PutUploadPart(ctx, req.(*UploadPartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_DeleteUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).DeleteUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/DeleteUploadSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).DeleteUploadSession(ctx, req.(*UploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_FindExpiredUploadSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).FindExpiredUploadSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/FindExpiredUploadSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).FindExpiredUploadSessions(ctx, req.(*UploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_GetDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ObjectMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).GetDirectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/GetDirectory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).GetDirectory(ctx, req.(*ObjectMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_GetCredential_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CredentialRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).GetCredential(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/GetCredential",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).GetCredential(ctx, req.(*CredentialRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_PutPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(message.Policy)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).PutPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/PutPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).PutPolicy(ctx, req.(*message.Policy))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_GetPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).GetPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/GetPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).GetPolicy(ctx, req.(*PolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_DeletePolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).DeletePolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/DeletePolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).DeletePolicy(ctx, req.(*PolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_FindPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).FindPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/FindPolicies",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).FindPolicies(ctx, req.(*PolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_FindBlockHeaders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockHeaderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).FindBlockHeaders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/FindBlockHeaders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).FindBlockHeaders(ctx, req.(*BlockHeaderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_ReserveVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(message.Object)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).ReserveVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/ReserveVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).ReserveVersion(ctx, req.(*message.Object))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_CommitVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(message.Object)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).CommitVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/CommitVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).CommitVersion(ctx, req.(*message.Object))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_RecordBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(message.Object)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).RecordBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/RecordBlocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).RecordBlocks(ctx, req.(*message.Object))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_AbortVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(message.Object)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).AbortVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/AbortVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).AbortVersion(ctx, req.(*message.Object))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_ReleaseExpiredVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExpiredVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).ReleaseExpiredVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/ReleaseExpiredVersions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).ReleaseExpiredVersions(ctx, req.(*ExpiredVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_PutTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(message.ObjectMetadata)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).PutTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/PutTags",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).PutTags(ctx, req.(*message.ObjectMetadata))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_FindMetadataByTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ObjectMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).FindMetadataByTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/FindMetadataByTags",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).FindMetadataByTags(ctx, req.(*ObjectMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_Move_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).Move(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/Move",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).Move(ctx, req.(*MoveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_FindMetadataByStaleDataKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ObjectMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).FindMetadataByStaleDataKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/FindMetadataByStaleDataKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).FindMetadataByStaleDataKey(ctx, req.(*ObjectMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataRegistry_RewrapDataKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(message.ObjectMetadata)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataRegistryServer).RewrapDataKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpcmessage.MetadataRegistry/RewrapDataKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataRegistryServer).RewrapDataKeys(ctx, req.(*message.ObjectMetadata))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MetadataRegistry_ServiceDesc is the grpc.ServiceDesc for MetadataRegistry service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -245,6 +949,94 @@ var MetadataRegistry_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FindMetadataOnPath",
 			Handler:    _MetadataRegistry_FindMetadataOnPath_Handler,
 		},
+		{
+			MethodName: "CreateUploadSession",
+			Handler:    _MetadataRegistry_CreateUploadSession_Handler,
+		},
+		{
+			MethodName: "GetUploadSession",
+			Handler:    _MetadataRegistry_GetUploadSession_Handler,
+		},
+		{
+			MethodName: "PutUploadPart",
+			Handler:    _MetadataRegistry_PutUploadPart_Handler,
+		},
+		{
+			MethodName: "DeleteUploadSession",
+			Handler:    _MetadataRegistry_DeleteUploadSession_Handler,
+		},
+		{
+			MethodName: "FindExpiredUploadSessions",
+			Handler:    _MetadataRegistry_FindExpiredUploadSessions_Handler,
+		},
+		{
+			MethodName: "GetDirectory",
+			Handler:    _MetadataRegistry_GetDirectory_Handler,
+		},
+		{
+			MethodName: "GetCredential",
+			Handler:    _MetadataRegistry_GetCredential_Handler,
+		},
+		{
+			MethodName: "PutPolicy",
+			Handler:    _MetadataRegistry_PutPolicy_Handler,
+		},
+		{
+			MethodName: "GetPolicy",
+			Handler:    _MetadataRegistry_GetPolicy_Handler,
+		},
+		{
+			MethodName: "DeletePolicy",
+			Handler:    _MetadataRegistry_DeletePolicy_Handler,
+		},
+		{
+			MethodName: "FindPolicies",
+			Handler:    _MetadataRegistry_FindPolicies_Handler,
+		},
+		{
+			MethodName: "FindBlockHeaders",
+			Handler:    _MetadataRegistry_FindBlockHeaders_Handler,
+		},
+		{
+			MethodName: "ReserveVersion",
+			Handler:    _MetadataRegistry_ReserveVersion_Handler,
+		},
+		{
+			MethodName: "CommitVersion",
+			Handler:    _MetadataRegistry_CommitVersion_Handler,
+		},
+		{
+			MethodName: "RecordBlocks",
+			Handler:    _MetadataRegistry_RecordBlocks_Handler,
+		},
+		{
+			MethodName: "AbortVersion",
+			Handler:    _MetadataRegistry_AbortVersion_Handler,
+		},
+		{
+			MethodName: "ReleaseExpiredVersions",
+			Handler:    _MetadataRegistry_ReleaseExpiredVersions_Handler,
+		},
+		{
+			MethodName: "PutTags",
+			Handler:    _MetadataRegistry_PutTags_Handler,
+		},
+		{
+			MethodName: "FindMetadataByTags",
+			Handler:    _MetadataRegistry_FindMetadataByTags_Handler,
+		},
+		{
+			MethodName: "Move",
+			Handler:    _MetadataRegistry_Move_Handler,
+		},
+		{
+			MethodName: "FindMetadataByStaleDataKey",
+			Handler:    _MetadataRegistry_FindMetadataByStaleDataKey_Handler,
+		},
+		{
+			MethodName: "RewrapDataKeys",
+			Handler:    _MetadataRegistry_RewrapDataKeys_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "message/metadata_registry.proto",
@@ -31,16 +31,60 @@ import (
 
 type MetadataRegistryHandler interface {
 	Put(c context.Context, object *message.Object) (*message.ObjectMetadata, error)
-	Delete(c context.Context, metadata *message.ObjectMetadata) error
+	Delete(c context.Context, metadata *message.ObjectMetadata) (*message.BlockHeaderList, error)
 	GetByObjectName(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadata, error)
 	GetByObjectID(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadata, error)
 	FindMetadataOnPath(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error)
+	CreateUploadSession(c context.Context, session *message.UploadSession) (*message.UploadSession, error)
+	GetUploadSession(c context.Context, req *rpcmessage.UploadSessionRequest) (*message.UploadSession, error)
+	PutUploadPart(c context.Context, req *rpcmessage.UploadPartRequest) (*message.UploadSession, error)
+	DeleteUploadSession(c context.Context, req *rpcmessage.UploadSessionRequest) error
+	FindExpiredUploadSessions(c context.Context, req *rpcmessage.UploadSessionRequest) (*message.UploadSessionList, error)
+	GetDirectory(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.Directory, error)
+	GetCredential(c context.Context, req *rpcmessage.CredentialRequest) (*message.Credential, error)
+	PutPolicy(c context.Context, policy *message.Policy) (*message.Policy, error)
+	GetPolicy(c context.Context, req *rpcmessage.PolicyRequest) (*message.Policy, error)
+	DeletePolicy(c context.Context, req *rpcmessage.PolicyRequest) error
+	FindPolicies(c context.Context, req *rpcmessage.PolicyRequest) (*message.Policies, error)
+	FindBlockHeaders(c context.Context, req *rpcmessage.BlockHeaderRequest) (*message.BlockHeaderList, error)
+	ReserveVersion(c context.Context, object *message.Object) (*message.Object, error)
+	CommitVersion(c context.Context, object *message.Object) (*message.ObjectMetadata, error)
+	RecordBlocks(c context.Context, object *message.Object) error
+	AbortVersion(c context.Context, object *message.Object) (*message.BlockHeaderList, error)
+	ReleaseExpiredVersions(c context.Context, req *rpcmessage.ExpiredVersionRequest) (*message.BlockHeaderList, error)
+	PutTags(c context.Context, metadata *message.ObjectMetadata) (*message.ObjectMetadata, error)
+	FindMetadataByTags(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error)
+	Move(c context.Context, req *rpcmessage.MoveRequest) (*message.ObjectMetadata, error)
+	FindMetadataByStaleDataKey(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error)
+	RewrapDataKeys(c context.Context, metadata *message.ObjectMetadata) (*message.ObjectMetadata, error)
 }
 
 type MetadataRegistryRequestor interface {
 	Put(c context.Context, object *message.Object) (*message.ObjectMetadata, error)
-	Delete(c context.Context, metadata *message.ObjectMetadata) error
+	Delete(c context.Context, metadata *message.ObjectMetadata) (*message.BlockHeaderList, error)
 	GetByObjectName(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadata, error)
 	GetByObjectID(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadata, error)
 	FindMetadataOnPath(c context.Context, rew *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error)
+	CreateUploadSession(c context.Context, session *message.UploadSession) (*message.UploadSession, error)
+	GetUploadSession(c context.Context, req *rpcmessage.UploadSessionRequest) (*message.UploadSession, error)
+	PutUploadPart(c context.Context, req *rpcmessage.UploadPartRequest) (*message.UploadSession, error)
+	DeleteUploadSession(c context.Context, req *rpcmessage.UploadSessionRequest) error
+	FindExpiredUploadSessions(c context.Context, req *rpcmessage.UploadSessionRequest) (*message.UploadSessionList, error)
+	GetDirectory(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.Directory, error)
+	GetCredential(c context.Context, req *rpcmessage.CredentialRequest) (*message.Credential, error)
+	PutPolicy(c context.Context, policy *message.Policy) (*message.Policy, error)
+	GetPolicy(c context.Context, req *rpcmessage.PolicyRequest) (*message.Policy, error)
+	DeletePolicy(c context.Context, req *rpcmessage.PolicyRequest) error
+	FindPolicies(c context.Context, req *rpcmessage.PolicyRequest) (*message.Policies, error)
+	FindBlockHeaders(c context.Context, req *rpcmessage.BlockHeaderRequest) (*message.BlockHeaderList, error)
+	ReserveVersion(c context.Context, object *message.Object) (*message.Object, error)
+	CommitVersion(c context.Context, object *message.Object) (*message.ObjectMetadata, error)
+	RecordBlocks(c context.Context, object *message.Object) error
+	AbortVersion(c context.Context, object *message.Object) (*message.BlockHeaderList, error)
+	ReleaseExpiredVersions(c context.Context, req *rpcmessage.ExpiredVersionRequest) (*message.BlockHeaderList, error)
+	PutTags(c context.Context, metadata *message.ObjectMetadata) (*message.ObjectMetadata, error)
+	FindMetadataByTags(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error)
+	Move(c context.Context, req *rpcmessage.MoveRequest) (*message.ObjectMetadata, error)
+	FindMetadataByStaleDataKey(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error)
+	RewrapDataKeys(c context.Context, metadata *message.ObjectMetadata) (*message.ObjectMetadata, error)
 }
@@ -24,20 +24,24 @@ package requestor
 
 import (
 	"context"
+	"crypto/tls"
+	"io"
 
 	"github.com/ISSuh/sos/domain/model/message"
 	"github.com/ISSuh/sos/infrastructure/transport/rpc"
 	rpcmessage "github.com/ISSuh/sos/infrastructure/transport/rpc/message"
 	"github.com/ISSuh/sos/internal/log"
 	sosrpc "github.com/ISSuh/sos/internal/rpc"
+
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type blockStorage struct {
 	engine rpcmessage.BlockStorageClient
 }
 
-func NewBlockStorage(address string) (rpc.BlockStorageRequestor, error) {
-	conn, err := sosrpc.NewClientConnection(address)
+func NewBlockStorage(address string, tlsConfig *tls.Config) (rpc.BlockStorageRequestor, error) {
+	conn, err := sosrpc.NewClientConnection(address, tlsConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +61,30 @@ func (r *blockStorage) GetBlock(c context.Context, header *message.BlockHeader)
 	return r.engine.GetBlock(c, header)
 }
 
+// PutStream sends the block in chunks, so its size is not bound by the grpc message limit.
+func (r *blockStorage) PutStream(c context.Context, block *message.Block) (*rpcmessage.StorageResponse, error) {
+	log.FromContext(c).Debugf("[BlockStorage.PutStream]")
+	stream, err := r.engine.PutStream(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// the block storage closed the stream early, its error is returned by CloseAndRecv
+	if err := rpc.SplitBlock(block, stream.Send); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return stream.CloseAndRecv()
+}
+
+func (r *blockStorage) GetBlockStream(c context.Context, header *message.BlockHeader) (*message.Block, error) {
+	log.FromContext(c).Debugf("[BlockStorage.GetBlockStream]")
+	stream, err := r.engine.GetBlockStream(c, header)
+	if err != nil {
+		return nil, err
+	}
+	return rpc.JoinBlock(stream.Recv)
+}
+
 func (r *blockStorage) GetBlockHeader(c context.Context, header *message.BlockHeader) (*message.BlockHeader, error) {
 	log.FromContext(c).Debugf("[BlockStorage.Get]")
 	return r.engine.GetBlockHeader(c, header)
@@ -66,3 +94,58 @@ func (r *blockStorage) Delete(c context.Context, header *message.BlockHeader) (*
 	log.FromContext(c).Debugf("[BlockStorage.Delete]")
 	return r.engine.Delete(c, header)
 }
+
+func (r *blockStorage) Copy(c context.Context, req *rpcmessage.CopyBlockRequest) (*rpcmessage.StorageResponse, error) {
+	log.FromContext(c).Debugf("[BlockStorage.Copy]")
+	return r.engine.Copy(c, req)
+}
+
+func (r *blockStorage) GetScrubStatus(c context.Context) (*message.ScrubStatus, error) {
+	log.FromContext(c).Debugf("[BlockStorage.GetScrubStatus]")
+	return r.engine.GetScrubStatus(c, &emptypb.Empty{})
+}
+
+func (r *blockStorage) StartScrub(c context.Context) (*message.ScrubStatus, error) {
+	log.FromContext(c).Debugf("[BlockStorage.StartScrub]")
+	return r.engine.StartScrub(c, &emptypb.Empty{})
+}
+
+func (r *blockStorage) Reference(c context.Context, header *message.BlockHeader) (*rpcmessage.ReferenceResponse, error) {
+	log.FromContext(c).Debugf("[BlockStorage.Reference]")
+	return r.engine.Reference(c, header)
+}
+
+func (r *blockStorage) GetDedupStats(c context.Context) (*message.DedupStats, error) {
+	log.FromContext(c).Debugf("[BlockStorage.GetDedupStats]")
+	return r.engine.GetDedupStats(c, &emptypb.Empty{})
+}
+
+func (r *blockStorage) Reconcile(c context.Context, req *rpcmessage.ReconcileRequest) (*rpcmessage.ReconcileResponse, error) {
+	log.FromContext(c).Debugf("[BlockStorage.Reconcile]")
+	return r.engine.Reconcile(c, req)
+}
+
+// ListBlocks calls recv with each header streamed by the block storage until the stream ends.
+func (r *blockStorage) ListBlocks(
+	c context.Context, req *rpcmessage.ListBlocksRequest, recv func(*message.BlockHeader) error,
+) error {
+	log.FromContext(c).Debugf("[BlockStorage.ListBlocks]")
+	stream, err := r.engine.ListBlocks(c, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		header, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := recv(header); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package requestor_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/model/message"
+	"github.com/ISSuh/sos/domain/service"
+	memorystorage "github.com/ISSuh/sos/infrastructure/persistence/objectstorage/memory"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc/adapter"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc/handler"
+	"github.com/ISSuh/sos/infrastructure/transport/rpc/requestor"
+	"github.com/ISSuh/sos/internal/crc"
+	"github.com/ISSuh/sos/internal/generator"
+	sosrpc "github.com/ISSuh/sos/internal/rpc"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startBlockStorage serves a block storage node kept in memory on a loopback port, with the
+// message limits of the block storage server, and returns a requestor of it.
+func startBlockStorage(t *testing.T) rpc.BlockStorageRequestor {
+	t.Helper()
+	generator.InitIdentifier(1)
+
+	repository, err := memorystorage.NewLocalObjectStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage, err := service.NewObjectStorage(repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := handler.NewBlockStorage(storage, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := adapter.NewBlockStorage(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := sosrpc.NewServer(nil, nil)
+	server.Regist([]sosrpc.RegisterFunc{a.Regist()})
+	go server.Serve(l)
+	t.Cleanup(func() { l.Close() })
+
+	r, err := requestor.NewBlockStorage(l.Addr().String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func newBlock(t *testing.T, size int) *message.Block {
+	t.Helper()
+
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	header := entity.NewBlockHeaderBuilder().
+		ObjectID(entity.NewObjectID()).
+		BlockID(entity.NewBlockID()).
+		Index(0).
+		Size(size).
+		Timestamp(time.Now()).
+		Checksum(crc.Checksum(data)).
+		Build()
+
+	block := entity.NewBlockBuilder().
+		Header(header).
+		Buffer(data).
+		Build()
+	return message.FromBlock(&block)
+}
+
+func TestBlockStreamRoundTrip(t *testing.T) {
+	storage := startBlockStorage(t)
+	c := context.Background()
+
+	sizes := map[string]int{
+		"a byte":                     1,
+		"a chunk":                    rpc.ChunkSize,
+		"a chunk and a byte":         rpc.ChunkSize + 1,
+		"a few chunks and a remnant": 3*rpc.ChunkSize + 17,
+	}
+
+	for name, size := range sizes {
+		t.Run(name, func(t *testing.T) {
+			block := newBlock(t, size)
+			if _, err := storage.PutStream(c, block); err != nil {
+				t.Fatalf("PutStream: %v", err)
+			}
+
+			got, err := storage.GetBlockStream(c, block.Header)
+			if err != nil {
+				t.Fatalf("GetBlockStream: %v", err)
+			}
+			if !bytes.Equal(got.Data, block.Data) {
+				t.Errorf("received %d bytes, which differ from the %d sent", len(got.Data), len(block.Data))
+			}
+			if got.Header.Size != block.Header.Size || got.Header.Checksum != block.Header.Checksum {
+				t.Errorf("received header %+v, want %+v", got.Header, block.Header)
+			}
+		})
+	}
+}
+
+// TestBlockLargerThanMessageLimit transfers a block the unary calls can not carry in one message.
+func TestBlockLargerThanMessageLimit(t *testing.T) {
+	storage := startBlockStorage(t)
+	c := context.Background()
+
+	block := newBlock(t, sosrpc.MaxMessageSize+rpc.ChunkSize/2)
+
+	_, err := storage.Put(c, block)
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Fatalf("unary Put of %d bytes: %v, want %s", len(block.Data), err, codes.ResourceExhausted)
+	}
+
+	if _, err := storage.PutStream(c, block); err != nil {
+		t.Fatalf("PutStream: %v", err)
+	}
+
+	_, err = storage.GetBlock(c, block.Header)
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf("unary GetBlock of %d bytes: %v, want %s", len(block.Data), err, codes.ResourceExhausted)
+	}
+
+	got, err := storage.GetBlockStream(c, block.Header)
+	if err != nil {
+		t.Fatalf("GetBlockStream: %v", err)
+	}
+	if !bytes.Equal(got.Data, block.Data) {
+		t.Errorf("streamed %d bytes, which differ from the %d sent", len(got.Data), len(block.Data))
+	}
+}
+
+func TestBlockStreamRejectsCorruptBlock(t *testing.T) {
+	storage := startBlockStorage(t)
+	c := context.Background()
+
+	// the checksum is taken before the data is damaged in the last chunk
+	block := newBlock(t, 2*rpc.ChunkSize+1)
+	block.Data[len(block.Data)-1] ^= 0xff
+
+	if _, err := storage.PutStream(c, block); err == nil {
+		t.Fatal("PutStream of a block that does not match its checksum succeeded")
+	}
+
+	if _, err := storage.GetBlockStream(c, block.Header); err == nil {
+		t.Error("the rejected block was stored")
+	}
+}
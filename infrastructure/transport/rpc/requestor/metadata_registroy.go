@@ -24,6 +24,7 @@ package requestor
 
 import (
 	"context"
+	"crypto/tls"
 
 	"github.com/ISSuh/sos/domain/model/message"
 	"github.com/ISSuh/sos/infrastructure/transport/rpc"
@@ -39,8 +40,8 @@ type metadataRegistry struct {
 	engine rpcmessage.MetadataRegistryClient
 }
 
-func NewMetadataRegistry(address string) (rpc.MetadataRegistryRequestor, error) {
-	conn, err := sosrpc.NewClientConnection(address)
+func NewMetadataRegistry(address string, tlsConfig *tls.Config) (rpc.MetadataRegistryRequestor, error) {
+	conn, err := sosrpc.NewClientConnection(address, tlsConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -59,13 +60,13 @@ func (r *metadataRegistry) Put(c context.Context, object *message.Object) (*mess
 	return msg, nil
 }
 
-func (r *metadataRegistry) Delete(c context.Context, metadata *message.ObjectMetadata) error {
+func (r *metadataRegistry) Delete(c context.Context, metadata *message.ObjectMetadata) (*message.BlockHeaderList, error) {
 	log.FromContext(c).Debugf("[MetadataRegistry.Delete]")
-	_, err := r.engine.Delete(c, metadata)
+	msg, err := r.engine.Delete(c, metadata)
 	if err != nil {
-		return r.convertError(err)
+		return nil, r.convertError(err)
 	}
-	return nil
+	return msg, nil
 }
 
 func (r *metadataRegistry) GetByObjectName(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadata, error) {
@@ -91,14 +92,217 @@ func (r *metadataRegistry) FindMetadataOnPath(c context.Context, req *rpcmessage
 	return r.engine.FindMetadataOnPath(c, req)
 }
 
+func (r *metadataRegistry) CreateUploadSession(c context.Context, session *message.UploadSession) (*message.UploadSession, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.CreateUploadSession]")
+	msg, err := r.engine.CreateUploadSession(c, session)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) GetUploadSession(c context.Context, req *rpcmessage.UploadSessionRequest) (*message.UploadSession, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.GetUploadSession]")
+	msg, err := r.engine.GetUploadSession(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) PutUploadPart(c context.Context, req *rpcmessage.UploadPartRequest) (*message.UploadSession, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.PutUploadPart]")
+	msg, err := r.engine.PutUploadPart(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) DeleteUploadSession(c context.Context, req *rpcmessage.UploadSessionRequest) error {
+	log.FromContext(c).Debugf("[MetadataRegistry.DeleteUploadSession]")
+	_, err := r.engine.DeleteUploadSession(c, req)
+	if err != nil {
+		return r.convertError(err)
+	}
+	return nil
+}
+
+func (r *metadataRegistry) FindExpiredUploadSessions(c context.Context, req *rpcmessage.UploadSessionRequest) (*message.UploadSessionList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.FindExpiredUploadSessions]")
+	msg, err := r.engine.FindExpiredUploadSessions(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) GetDirectory(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.Directory, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.GetDirectory]")
+	msg, err := r.engine.GetDirectory(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) GetCredential(c context.Context, req *rpcmessage.CredentialRequest) (*message.Credential, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.GetCredential]")
+	msg, err := r.engine.GetCredential(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) PutPolicy(c context.Context, req *message.Policy) (*message.Policy, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.PutPolicy]")
+	msg, err := r.engine.PutPolicy(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) GetPolicy(c context.Context, req *rpcmessage.PolicyRequest) (*message.Policy, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.GetPolicy]")
+	msg, err := r.engine.GetPolicy(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) DeletePolicy(c context.Context, req *rpcmessage.PolicyRequest) error {
+	log.FromContext(c).Debugf("[MetadataRegistry.DeletePolicy]")
+	_, err := r.engine.DeletePolicy(c, req)
+	if err != nil {
+		return r.convertError(err)
+	}
+	return nil
+}
+
+func (r *metadataRegistry) FindPolicies(c context.Context, req *rpcmessage.PolicyRequest) (*message.Policies, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.FindPolicies]")
+	msg, err := r.engine.FindPolicies(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) FindBlockHeaders(c context.Context, req *rpcmessage.BlockHeaderRequest) (*message.BlockHeaderList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.FindBlockHeaders]")
+	msg, err := r.engine.FindBlockHeaders(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) ReserveVersion(c context.Context, object *message.Object) (*message.Object, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.ReserveVersion]")
+	msg, err := r.engine.ReserveVersion(c, object)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) CommitVersion(c context.Context, object *message.Object) (*message.ObjectMetadata, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.CommitVersion]")
+	msg, err := r.engine.CommitVersion(c, object)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) RecordBlocks(c context.Context, object *message.Object) error {
+	log.FromContext(c).Debugf("[MetadataRegistry.RecordBlocks]")
+	_, err := r.engine.RecordBlocks(c, object)
+	if err != nil {
+		return r.convertError(err)
+	}
+	return nil
+}
+
+func (r *metadataRegistry) AbortVersion(c context.Context, object *message.Object) (*message.BlockHeaderList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.AbortVersion]")
+	msg, err := r.engine.AbortVersion(c, object)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) ReleaseExpiredVersions(c context.Context, req *rpcmessage.ExpiredVersionRequest) (*message.BlockHeaderList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.ReleaseExpiredVersions]")
+	msg, err := r.engine.ReleaseExpiredVersions(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) PutTags(c context.Context, metadata *message.ObjectMetadata) (*message.ObjectMetadata, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.PutTags]")
+	msg, err := r.engine.PutTags(c, metadata)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) FindMetadataByTags(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.FindMetadataByTags]")
+	msg, err := r.engine.FindMetadataByTags(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) Move(c context.Context, req *rpcmessage.MoveRequest) (*message.ObjectMetadata, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.Move]")
+	msg, err := r.engine.Move(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) FindMetadataByStaleDataKey(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.FindMetadataByStaleDataKey]")
+	msg, err := r.engine.FindMetadataByStaleDataKey(c, req)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
+func (r *metadataRegistry) RewrapDataKeys(c context.Context, metadata *message.ObjectMetadata) (*message.ObjectMetadata, error) {
+	log.FromContext(c).Debugf("[MetadataRegistry.RewrapDataKeys]")
+	msg, err := r.engine.RewrapDataKeys(c, metadata)
+	if err != nil {
+		return nil, r.convertError(err)
+	}
+	return msg, nil
+}
+
 func (r *metadataRegistry) convertError(err error) error {
 	st, ok := status.FromError(err)
 	if !ok {
 		return err
 	}
 
-	if st.Code() == soserror.NotFoundErrorCode {
+	switch st.Code() {
+	case soserror.NotFoundErrorCode:
 		return soserror.NewNotFoundError(st.Err())
+	case soserror.ConflictErrorCode:
+		return soserror.NewConflictError(st.Err())
+	case soserror.PreconditionFailedErrorCode:
+		return soserror.NewPreconditionFailedError(st.Err())
 	}
 	return err
 }
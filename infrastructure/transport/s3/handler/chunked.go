@@ -0,0 +1,188 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package handler
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ISSuh/sos/internal/auth"
+)
+
+const (
+	maxChunkLineSize = 4096
+
+	chunkSignatureParam = "chunk-signature="
+)
+
+// chunkedReader decodes an aws-chunked body. Every chunk is framed as
+// "<hex size>[;chunk-signature=...]\r\n<data>\r\n" and a zero sized chunk,
+// followed by optional trailers and an empty line, ends the body.
+// Chunk signatures are verified when the request was signed with a streaming payload,
+// trailing checksums are not verified.
+type chunkedReader struct {
+	body      io.ReadCloser
+	reader    *bufio.Reader
+	remaining int64
+	done      bool
+
+	verifier  *auth.ChunkVerifier
+	signature string
+	hash      hash.Hash
+}
+
+func newChunkedReader(body io.ReadCloser, verifier *auth.ChunkVerifier) *chunkedReader {
+	return &chunkedReader{
+		body:     body,
+		reader:   bufio.NewReader(body),
+		verifier: verifier,
+		hash:     sha256.New(),
+	}
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, io.EOF
+	}
+
+	if r.remaining == 0 {
+		size, err := r.nextChunk()
+		if err != nil {
+			return 0, err
+		}
+
+		if size == 0 {
+			r.done = true
+			if err := r.verifyChunk(); err != nil {
+				return 0, err
+			}
+			return 0, r.readTrailers()
+		}
+		r.remaining = size
+	}
+
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		return n, errIncompleteBody
+	}
+	if err != nil {
+		return n, err
+	}
+
+	if r.remaining == 0 {
+		if err := r.readCRLF(); err != nil {
+			return n, err
+		}
+
+		if err := r.verifyChunk(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (r *chunkedReader) Close() error {
+	return r.body.Close()
+}
+
+func (r *chunkedReader) nextChunk() (int64, error) {
+	line, err := r.readLine()
+	if err == io.EOF {
+		return 0, errIncompleteBody
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	sizeStr, extension, _ := strings.Cut(line, ";")
+	size, err := strconv.ParseInt(strings.TrimSpace(sizeStr), 16, 64)
+	if err != nil || size < 0 {
+		return 0, invalidArgument(fmt.Sprintf("invalid aws-chunked chunk size. line: %s", line))
+	}
+
+	r.signature = strings.TrimPrefix(strings.TrimSpace(extension), chunkSignatureParam)
+	r.hash.Reset()
+	return size, nil
+}
+
+func (r *chunkedReader) verifyChunk() error {
+	if r.verifier == nil {
+		return nil
+	}
+
+	if r.signature == "" {
+		return invalidArgument("aws-chunked chunk signature is missing")
+	}
+	return r.verifier.Verify(r.hash.Sum(nil), r.signature)
+}
+
+func (r *chunkedReader) readTrailers() error {
+	for {
+		line, err := r.readLine()
+		switch {
+		case err == io.EOF || errors.Is(err, errIncompleteBody):
+			// the empty line after the last chunk is omitted by some clients
+			return io.EOF
+		case err != nil:
+			return err
+		case line == "":
+			return io.EOF
+		}
+	}
+}
+
+func (r *chunkedReader) readCRLF() error {
+	line, err := r.readLine()
+	switch {
+	case err != nil:
+		return err
+	case line != "":
+		return invalidArgument("invalid aws-chunked chunk delimiter")
+	}
+	return nil
+}
+
+func (r *chunkedReader) readLine() (string, error) {
+	line, err := r.reader.ReadSlice('\n')
+	switch {
+	case errors.Is(err, bufio.ErrBufferFull) || len(line) > maxChunkLineSize:
+		return "", invalidArgument("aws-chunked line is too long")
+	case err == io.EOF && len(line) > 0:
+		return "", errIncompleteBody
+	case err != nil:
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
@@ -0,0 +1,1001 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package handler
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	gohttp "net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/service"
+	"github.com/ISSuh/sos/infrastructure/transport/s3"
+	"github.com/ISSuh/sos/internal/apm"
+	"github.com/ISSuh/sos/internal/auth"
+	"github.com/ISSuh/sos/internal/empty"
+	"github.com/ISSuh/sos/internal/encryption"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/http"
+	"github.com/ISSuh/sos/internal/log"
+	"github.com/ISSuh/sos/internal/validation"
+)
+
+const (
+	octetStreamContentType = "application/octet-stream"
+	standardStorageClass   = "STANDARD"
+	pathDelimiter          = "/"
+
+	copySourceHeader           = "x-amz-copy-source"
+	metadataDirectiveHeader    = "x-amz-metadata-directive"
+	userMetadataHeaderPrefix   = "X-Amz-Meta-"
+	contentSHA256Header        = "x-amz-content-sha256"
+	decodedContentLengthHeader = "x-amz-decoded-content-length"
+	streamingPayloadPrefix     = "STREAMING-"
+	awsChunkedEncoding         = "aws-chunked"
+	replaceMetadataDirective   = "REPLACE"
+
+	customerKeyAlgorithmHeader           = "x-amz-server-side-encryption-customer-algorithm"
+	customerKeyHeader                    = "x-amz-server-side-encryption-customer-key"
+	customerKeyMD5Header                 = "x-amz-server-side-encryption-customer-key-MD5"
+	copySourceCustomerKeyAlgorithmHeader = "x-amz-copy-source-server-side-encryption-customer-algorithm"
+	copySourceCustomerKeyHeader          = "x-amz-copy-source-server-side-encryption-customer-key"
+	copySourceCustomerKeyMD5Header       = "x-amz-copy-source-server-side-encryption-customer-key-MD5"
+
+	listTypeQuery          = "list-type"
+	prefixQuery            = "prefix"
+	delimiterQuery         = "delimiter"
+	maxKeysQuery           = "max-keys"
+	continuationTokenQuery = "continuation-token"
+	startAfterQuery        = "start-after"
+	uploadsQuery           = "uploads"
+	uploadIDQuery          = "uploadId"
+	partNumberQuery        = "partNumber"
+	versionIDQuery         = "versionId"
+
+	maxListKeys = 1000
+)
+
+type gateway struct {
+	explorerService service.Explorer
+	buckets         map[string]s3.Bucket
+	createdAt       time.Time
+}
+
+func NewGateway(explorerService service.Explorer, buckets []s3.Bucket) (s3.Gateway, error) {
+	switch {
+	case validation.IsNil(explorerService):
+		return nil, fmt.Errorf("explorer service is nil")
+	}
+
+	bucketMap := make(map[string]s3.Bucket, len(buckets))
+	for _, bucket := range buckets {
+		bucketMap[bucket.Name] = bucket
+	}
+
+	return &gateway{
+		explorerService: explorerService,
+		buckets:         bucketMap,
+		createdAt:       time.Now(),
+	}, nil
+}
+
+func (h *gateway) ListBuckets() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[gateway.ListBuckets]")
+
+		resp := listAllMyBucketsResult{
+			Xmlns:   xmlNamespace,
+			Buckets: make([]bucketInfo, 0, len(h.buckets)),
+		}
+
+		for name := range h.buckets {
+			resp.Buckets = append(resp.Buckets, bucketInfo{Name: name, CreationDate: xmlTime(h.createdAt)})
+		}
+		sort.Slice(resp.Buckets, func(i, j int) bool {
+			return resp.Buckets[i].Name < resp.Buckets[j].Name
+		})
+
+		if err := writeXML(w, gohttp.StatusOK, resp); err != nil {
+			log.FromContext(c).Errorf("ListBuckets Error: %s\n", err.Error())
+		}
+	}
+}
+
+func (h *gateway) HeadBucket() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[gateway.HeadBucket]")
+
+		if _, err := h.bucket(r); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(gohttp.StatusOK)
+	}
+}
+
+func (h *gateway) ListObjects() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[gateway.ListObjects]")
+
+		bucket, err := h.bucket(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		query := r.URL.Query()
+		if query.Get(listTypeQuery) != "2" {
+			writeError(w, r, errNotImplemented)
+			return
+		}
+
+		resp := listBucketResult{
+			Xmlns:             xmlNamespace,
+			Name:              bucket.Name,
+			Prefix:            query.Get(prefixQuery),
+			Delimiter:         query.Get(delimiterQuery),
+			MaxKeys:           maxListKeys,
+			ContinuationToken: query.Get(continuationTokenQuery),
+			StartAfter:        query.Get(startAfterQuery),
+		}
+
+		if resp.Delimiter != "" && resp.Delimiter != pathDelimiter {
+			writeError(w, r, invalidArgument("only / is supported as delimiter"))
+			return
+		}
+
+		if maxKeysStr := query.Get(maxKeysQuery); maxKeysStr != "" {
+			maxKeys, err := strconv.Atoi(maxKeysStr)
+			if err != nil || maxKeys < 0 {
+				writeError(w, r, invalidArgument("max-keys is invalid"))
+				return
+			}
+			resp.MaxKeys = min(maxKeys, maxListKeys)
+		}
+
+		after := resp.StartAfter
+		if resp.ContinuationToken != "" {
+			token, err := base64.URLEncoding.DecodeString(resp.ContinuationToken)
+			if err != nil {
+				writeError(w, r, invalidArgument("continuation token is invalid"))
+				return
+			}
+			after = string(token)
+		}
+
+		span := apm.SpanStart(c, "ListObjects", "gateway", nil)
+		defer span.End()
+
+		span.Context.SetLabel("bucket", bucket.Name)
+		span.Context.SetLabel("prefix", resp.Prefix)
+
+		entries, err := h.listEntries(r, bucket, resp.Prefix, resp.Delimiter)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		start := sort.Search(len(entries), func(i int) bool {
+			return entries[i].key > after
+		})
+		entries = entries[start:]
+
+		if len(entries) > resp.MaxKeys {
+			entries = entries[:resp.MaxKeys]
+			resp.IsTruncated = true
+			if len(entries) > 0 {
+				resp.NextContinuationToken = base64.URLEncoding.EncodeToString([]byte(entries[len(entries)-1].key))
+			}
+		}
+
+		for _, entry := range entries {
+			if entry.commonPrefix {
+				resp.CommonPrefixes = append(resp.CommonPrefixes, commonPrefix{Prefix: entry.key})
+				continue
+			}
+
+			resp.Contents = append(resp.Contents, objectInfo{
+				Key:          entry.key,
+				LastModified: xmlTime(entry.version.ModifiedAt),
+				ETag:         etag(entry.version),
+				Size:         entry.version.Size,
+				StorageClass: standardStorageClass,
+			})
+		}
+		resp.KeyCount = len(entries)
+
+		if err := writeXML(w, gohttp.StatusOK, resp); err != nil {
+			log.FromContext(c).Errorf("ListObjects Error: %s\n", err.Error())
+		}
+	}
+}
+
+func (h *gateway) PutObject() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Has(uploadIDQuery):
+			h.uploadPart(w, r)
+		case r.Header.Get(copySourceHeader) != "":
+			h.copyObject(w, r)
+		default:
+			h.putObject(w, r)
+		}
+	}
+}
+
+func (h *gateway) GetObject() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[gateway.GetObject]")
+
+		if r.URL.Query().Has(uploadIDQuery) || r.URL.Query().Has(versionIDQuery) {
+			writeError(w, r, errNotImplemented)
+			return
+		}
+
+		req, item, version, err := h.findObject(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		// S3 serves a single range only, a multi range request gets the whole object
+		req.ObjectID = item.ID
+		req.Range = r.Header.Get(http.RangeHeader)
+		req.IfRange = r.Header.Get(http.IfRangeHeader)
+		req.IfMatch = r.Header.Get(http.IfMatchHeader)
+		req.IfNoneMatch = r.Header.Get(http.IfNoneMatchHeader)
+		req.IfModifiedSince = r.Header.Get(http.IfModifiedSinceHeader)
+		req.CustomerKey = customerKey(r.Header, customerKeyAlgorithmHeader, customerKeyHeader, customerKeyMD5Header)
+		if strings.Contains(req.Range, ",") {
+			req.Range = ""
+		}
+
+		span := apm.SpanStart(c, "GetObject", "gateway", nil)
+		defer span.End()
+
+		span.Context.SetLabel("group", req.Group)
+		span.Context.SetLabel("partition", req.Partition)
+		span.Context.SetLabel("path", req.Path)
+		span.Context.SetLabel("name", req.Name)
+
+		written := false
+		writer := http.Writer{
+			ObjectHeader: func(header http.ObjectHeader) {},
+			NotModified: func() {
+				written = true
+				w.Header().Set(http.ETagHeader, etag(version))
+				w.Header().Set(http.LastModifiedHeader, version.ModifiedAt.UTC().Format(gohttp.TimeFormat))
+				w.WriteHeader(gohttp.StatusNotModified)
+			},
+			Header: func(name string, size int) {
+				written = true
+				h.objectHeader(w, item, version)
+				w.WriteHeader(gohttp.StatusOK)
+			},
+			RangeHeader: func(name string, size int, ranges http.Ranges) {
+				written = true
+				h.objectHeader(w, item, version)
+				w.Header().Set("Content-Range", ranges[0].ContentRange(size))
+				w.Header().Set("Content-Length", strconv.Itoa(ranges[0].Length))
+				w.WriteHeader(gohttp.StatusPartialContent)
+			},
+			Part:  func(r http.Range, size int) error { return nil },
+			Body:  bodyWriter(w),
+			Close: func() error { return nil },
+			Unsatisfiable: func(size int) {
+				written = true
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+				writeError(w, r, errInvalidRange)
+			},
+		}
+
+		if err := h.explorerService.Download(c, req, writer, true); err != nil {
+			if written {
+				// the status line is gone already, the client sees a short body
+				log.FromContext(c).Errorf("GetObject Error: %s\n", err.Error())
+				return
+			}
+			writeError(w, r, err)
+		}
+	}
+}
+
+func (h *gateway) HeadObject() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[gateway.HeadObject]")
+
+		_, item, version, err := h.findObject(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		h.objectHeader(w, item, version)
+		w.WriteHeader(gohttp.StatusOK)
+	}
+}
+
+func (h *gateway) DeleteObject() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		c := r.Context()
+		log.FromContext(c).Debugf("[gateway.DeleteObject]")
+
+		if r.URL.Query().Has(uploadIDQuery) {
+			h.abortMultipartUpload(w, r)
+			return
+		}
+
+		req, item, _, err := h.findObject(r)
+		switch {
+		case errors.Is(err, errNoSuchKey):
+			// deleting a key that does not exist succeeds on S3
+			http.NoContent(w)
+			return
+		case err != nil:
+			writeError(w, r, err)
+			return
+		}
+
+		req.ObjectID = item.ID
+		if err := h.explorerService.Delete(c, req, false); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		http.NoContent(w)
+	}
+}
+
+func (h *gateway) PostObject() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Has(uploadsQuery):
+			h.createMultipartUpload(w, r)
+		case query.Has(uploadIDQuery):
+			h.completeMultipartUpload(w, r)
+		default:
+			writeError(w, r, errNotImplemented)
+		}
+	}
+}
+
+func (h *gateway) NotImplemented() http.Handler {
+	return func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		writeError(w, r, errNotImplemented)
+	}
+}
+
+func (h *gateway) WriteError(w gohttp.ResponseWriter, r *gohttp.Request, err error) {
+	writeError(w, r, err)
+}
+
+func (h *gateway) putObject(w gohttp.ResponseWriter, r *gohttp.Request) {
+	c := r.Context()
+	log.FromContext(c).Debugf("[gateway.PutObject]")
+
+	req, err := h.objectRequest(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	body, size, err := requestBody(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	req.Size = size
+	req.IfMatch = r.Header.Get(http.IfMatchHeader)
+	req.IfNoneMatch = r.Header.Get(http.IfNoneMatchHeader)
+	req.ContentMetadata = contentMetadata(r)
+	req.CustomerKey = customerKey(r.Header, customerKeyAlgorithmHeader, customerKeyHeader, customerKeyMD5Header)
+
+	span := apm.SpanStart(c, "PutObject", "gateway", nil)
+	defer span.End()
+
+	span.Context.SetLabel("group", req.Group)
+	span.Context.SetLabel("partition", req.Partition)
+	span.Context.SetLabel("path", req.Path)
+	span.Context.SetLabel("name", req.Name)
+	span.Context.SetLabel("size", req.Size)
+
+	item, err := h.explorerService.Upload(c, req, body)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err := item.Versions.LastVersion()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(version))
+	customerKeyHeaders(w, version)
+	w.WriteHeader(gohttp.StatusOK)
+}
+
+func (h *gateway) copyObject(w gohttp.ResponseWriter, r *gohttp.Request) {
+	c := r.Context()
+	log.FromContext(c).Debugf("[gateway.CopyObject]")
+
+	req, err := h.objectRequest(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	source, err := h.copySource(r.Header.Get(copySourceHeader))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	sourceItem, err := h.explorerService.GetObjectMetadataByName(c, source)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	sourceVersion, err := sourceItem.Versions.LastVersion()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	span := apm.SpanStart(c, "CopyObject", "gateway", nil)
+	defer span.End()
+
+	span.Context.SetLabel("source", source.Path+pathDelimiter+source.Name)
+	span.Context.SetLabel("path", req.Path)
+	span.Context.SetLabel("name", req.Name)
+
+	// the source is streamed into the upload of the destination, each with a key of its own
+	source.ObjectID = sourceItem.ID
+	source.CustomerKey = customerKey(
+		r.Header, copySourceCustomerKeyAlgorithmHeader, copySourceCustomerKeyHeader, copySourceCustomerKeyMD5Header,
+	)
+	req.CustomerKey = customerKey(r.Header, customerKeyAlgorithmHeader, customerKeyHeader, customerKeyMD5Header)
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeReader.Close()
+
+	go func() {
+		writer := http.Writer{
+			ObjectHeader:  func(header http.ObjectHeader) {},
+			NotModified:   func() {},
+			Header:        func(name string, size int) {},
+			RangeHeader:   func(name string, size int, ranges http.Ranges) {},
+			Part:          func(r http.Range, size int) error { return nil },
+			Close:         func() error { return nil },
+			Unsatisfiable: func(size int) {},
+			Body: func(buffer []byte) error {
+				_, err := pipeWriter.Write(buffer)
+				return err
+			},
+		}
+		pipeWriter.CloseWithError(h.explorerService.Download(c, source, writer, true))
+	}()
+
+	req.Size = sourceVersion.Size
+	req.ContentMetadata = sourceVersion.ContentMetadata
+	if strings.EqualFold(r.Header.Get(metadataDirectiveHeader), replaceMetadataDirective) {
+		req.ContentMetadata = contentMetadata(r)
+	}
+
+	item, err := h.explorerService.Upload(c, req, pipeReader)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err := item.Versions.LastVersion()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := copyObjectResult{
+		Xmlns:        xmlNamespace,
+		LastModified: xmlTime(version.ModifiedAt),
+		ETag:         etag(version),
+	}
+
+	if err := writeXML(w, gohttp.StatusOK, resp); err != nil {
+		log.FromContext(c).Errorf("CopyObject Error: %s\n", err.Error())
+	}
+}
+
+func (h *gateway) createMultipartUpload(w gohttp.ResponseWriter, r *gohttp.Request) {
+	c := r.Context()
+	log.FromContext(c).Debugf("[gateway.CreateMultipartUpload]")
+
+	req, err := h.objectRequest(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	req.ContentMetadata = contentMetadata(r)
+	req.CustomerKey = customerKey(r.Header, customerKeyAlgorithmHeader, customerKeyHeader, customerKeyMD5Header)
+
+	session, err := h.explorerService.InitiateUpload(c, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := initiateMultipartUploadResult{
+		Xmlns:    xmlNamespace,
+		Bucket:   pathParam(r, s3.BucketParamName),
+		Key:      pathParam(r, s3.KeyParamName),
+		UploadID: session.ID.String(),
+	}
+
+	if err := writeXML(w, gohttp.StatusOK, resp); err != nil {
+		log.FromContext(c).Errorf("CreateMultipartUpload Error: %s\n", err.Error())
+	}
+}
+
+func (h *gateway) uploadPart(w gohttp.ResponseWriter, r *gohttp.Request) {
+	c := r.Context()
+	log.FromContext(c).Debugf("[gateway.UploadPart]")
+
+	req, err := h.uploadRequest(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	partNumber, err := strconv.Atoi(r.URL.Query().Get(partNumberQuery))
+	if err != nil {
+		writeError(w, r, invalidArgument("part number is invalid"))
+		return
+	}
+	req.PartNumber = partNumber
+	req.CustomerKey = customerKey(r.Header, customerKeyAlgorithmHeader, customerKeyHeader, customerKeyMD5Header)
+
+	body, size, err := requestBody(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	req.Size = size
+
+	span := apm.SpanStart(c, "UploadPart", "gateway", nil)
+	defer span.End()
+
+	span.Context.SetLabel("uploadID", req.UploadID)
+	span.Context.SetLabel("partNumber", req.PartNumber)
+	span.Context.SetLabel("size", req.Size)
+
+	// the part ETag is the MD5 of its content, as clients expect it back on complete
+	hash := md5.New()
+	hashedBody := struct {
+		io.Reader
+		io.Closer
+	}{io.TeeReader(body, hash), body}
+
+	if _, err := h.explorerService.UploadPart(c, req, hashedBody); err != nil {
+		writeError(w, r, uploadError(err))
+		return
+	}
+
+	w.Header().Set("ETag", "\""+hex.EncodeToString(hash.Sum(nil))+"\"")
+	w.WriteHeader(gohttp.StatusOK)
+}
+
+func (h *gateway) completeMultipartUpload(w gohttp.ResponseWriter, r *gohttp.Request) {
+	c := r.Context()
+	log.FromContext(c).Debugf("[gateway.CompleteMultipartUpload]")
+
+	req, err := h.uploadRequest(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// every uploaded part of the session is committed, the part list only has to be well formed
+	var complete completeMultipartUpload
+	if err := xml.NewDecoder(r.Body).Decode(&complete); err != nil || len(complete.Parts) == 0 {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+
+	span := apm.SpanStart(c, "CompleteMultipartUpload", "gateway", nil)
+	defer span.End()
+
+	span.Context.SetLabel("uploadID", req.UploadID)
+
+	item, err := h.explorerService.CompleteUpload(c, req)
+	if err != nil {
+		writeError(w, r, uploadError(err))
+		return
+	}
+
+	version, err := item.Versions.LastVersion()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := completeMultipartUploadResult{
+		Xmlns:    xmlNamespace,
+		Location: r.URL.Path,
+		Bucket:   pathParam(r, s3.BucketParamName),
+		Key:      pathParam(r, s3.KeyParamName),
+		ETag:     etag(version),
+	}
+
+	if err := writeXML(w, gohttp.StatusOK, resp); err != nil {
+		log.FromContext(c).Errorf("CompleteMultipartUpload Error: %s\n", err.Error())
+	}
+}
+
+func (h *gateway) abortMultipartUpload(w gohttp.ResponseWriter, r *gohttp.Request) {
+	c := r.Context()
+	log.FromContext(c).Debugf("[gateway.AbortMultipartUpload]")
+
+	req, err := h.uploadRequest(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.explorerService.AbortUpload(c, req); err != nil {
+		writeError(w, r, uploadError(err))
+		return
+	}
+
+	http.NoContent(w)
+}
+
+func (h *gateway) bucket(r *gohttp.Request) (s3.Bucket, error) {
+	bucket, exist := h.buckets[pathParam(r, s3.BucketParamName)]
+	if !exist {
+		return empty.Struct[s3.Bucket](), errNoSuchBucket
+	}
+	return bucket, nil
+}
+
+// objectRequest maps the bucket to its group and partition and splits the key
+// into a directory path and an object name. e.g. "a/b/c.txt" to "a/b" and "c.txt"
+func (h *gateway) objectRequest(r *gohttp.Request) (dto.Request, error) {
+	bucket, err := h.bucket(r)
+	if err != nil {
+		return empty.Struct[dto.Request](), err
+	}
+	return objectRequestOnBucket(bucket, pathParam(r, s3.KeyParamName))
+}
+
+func (h *gateway) uploadRequest(r *gohttp.Request) (dto.Request, error) {
+	req, err := h.objectRequest(r)
+	if err != nil {
+		return empty.Struct[dto.Request](), err
+	}
+
+	uploadID, err := strconv.ParseInt(r.URL.Query().Get(uploadIDQuery), 10, 64)
+	if err != nil {
+		return empty.Struct[dto.Request](), errNoSuchUpload
+	}
+
+	req.UploadID = entity.NewUploadIDFrom(uploadID)
+	return req, nil
+}
+
+func (h *gateway) findObject(r *gohttp.Request) (dto.Request, dto.Item, dto.Version, error) {
+	req, err := h.objectRequest(r)
+	if err != nil {
+		return empty.Struct[dto.Request](), empty.Struct[dto.Item](), empty.Struct[dto.Version](), err
+	}
+
+	item, err := h.explorerService.GetObjectMetadataByName(r.Context(), req)
+	switch {
+	case errors.Is(err, soserror.NotFound):
+		return empty.Struct[dto.Request](), empty.Struct[dto.Item](), empty.Struct[dto.Version](), errNoSuchKey
+	case err != nil:
+		return empty.Struct[dto.Request](), empty.Struct[dto.Item](), empty.Struct[dto.Version](), err
+	}
+
+	version, err := item.Versions.LastVersion()
+	if err != nil {
+		return empty.Struct[dto.Request](), empty.Struct[dto.Item](), empty.Struct[dto.Version](), errNoSuchKey
+	}
+	return req, item, version, nil
+}
+
+// copySource parses the x-amz-copy-source header, "[/]<bucket>/<key>"
+func (h *gateway) copySource(header string) (dto.Request, error) {
+	source, query, _ := strings.Cut(header, "?")
+	if strings.HasPrefix(query, versionIDQuery+"=") {
+		return empty.Struct[dto.Request](), errNotImplemented
+	}
+
+	source, err := url.PathUnescape(source)
+	if err != nil {
+		return empty.Struct[dto.Request](), invalidArgument("copy source is invalid")
+	}
+
+	bucketName, key, found := strings.Cut(strings.TrimPrefix(source, pathDelimiter), pathDelimiter)
+	if !found {
+		return empty.Struct[dto.Request](), invalidArgument("copy source is invalid")
+	}
+
+	bucket, exist := h.buckets[bucketName]
+	if !exist {
+		return empty.Struct[dto.Request](), errNoSuchBucket
+	}
+	return objectRequestOnBucket(bucket, key)
+}
+
+func (h *gateway) objectHeader(w gohttp.ResponseWriter, item dto.Item, version dto.Version) {
+	contentType := version.ContentType
+	if validation.IsEmpty(contentType) {
+		contentType = octetStreamContentType
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(version.Size))
+	w.Header().Set("ETag", etag(version))
+	w.Header().Set("Last-Modified", version.ModifiedAt.UTC().Format(gohttp.TimeFormat))
+	w.Header().Set("Accept-Ranges", "bytes")
+
+	if !validation.IsEmpty(version.ContentEncoding) {
+		w.Header().Set(http.ContentEncodingHeader, version.ContentEncoding)
+	}
+	if !validation.IsEmpty(version.CacheControl) {
+		w.Header().Set(http.CacheControlHeader, version.CacheControl)
+	}
+	for key, value := range version.UserMetadata {
+		w.Header().Set(userMetadataHeaderPrefix+key, value)
+	}
+	customerKeyHeaders(w, version)
+}
+
+// customerKey reads the key a client encrypts an object with from the request headers.
+func customerKey(header gohttp.Header, algorithm, key, keyMD5 string) dto.CustomerKey {
+	return dto.CustomerKey{
+		Algorithm: header.Get(algorithm),
+		Key:       header.Get(key),
+		KeyMD5:    header.Get(keyMD5),
+	}
+}
+
+// customerKeyHeaders confirms the key a version is encrypted with by its MD5 digest.
+func customerKeyHeaders(w gohttp.ResponseWriter, version dto.Version) {
+	if !version.Encryption.CustomerProvided() {
+		return
+	}
+
+	w.Header().Set(customerKeyAlgorithmHeader, encryption.CustomerKeyAlgorithm)
+	w.Header().Set(customerKeyMD5Header, version.Encryption.CustomerKeyMD5)
+}
+
+// contentMetadata reads the content metadata of an upload from the request headers. The aws-chunked
+// encoding only frames the request body, so it is not part of the stored encoding.
+func contentMetadata(r *gohttp.Request) entity.ContentMetadata {
+	var encodings []string
+	for _, encoding := range strings.Split(r.Header.Get(http.ContentEncodingHeader), ",") {
+		encoding = strings.TrimSpace(encoding)
+		if !validation.IsEmpty(encoding) && encoding != awsChunkedEncoding {
+			encodings = append(encodings, encoding)
+		}
+	}
+
+	content := entity.ContentMetadata{
+		ContentType:     r.Header.Get(http.ContentTypeHeader),
+		ContentEncoding: strings.Join(encodings, ","),
+		CacheControl:    r.Header.Get(http.CacheControlHeader),
+	}
+
+	for key, values := range r.Header {
+		name, found := strings.CutPrefix(gohttp.CanonicalHeaderKey(key), userMetadataHeaderPrefix)
+		if !found || validation.IsEmpty(name) || len(values) == 0 {
+			continue
+		}
+
+		if content.UserMetadata == nil {
+			content.UserMetadata = map[string]string{}
+		}
+		content.UserMetadata[strings.ToLower(name)] = values[0]
+	}
+	return content
+}
+
+type listEntry struct {
+	key          string
+	commonPrefix bool
+	item         dto.Item
+	version      dto.Version
+}
+
+// listEntries collects the keys under prefix in lexicographical order. The walk starts
+// at the directory the prefix ends in and, without a delimiter, descends into every
+// sub directory that can still hold a matching key.
+func (h *gateway) listEntries(r *gohttp.Request, bucket s3.Bucket, prefix, delimiter string) ([]listEntry, error) {
+	dir := entity.RootDirectoryPath
+	if i := strings.LastIndex(prefix, pathDelimiter); i >= 0 {
+		dir = entity.CleanDirectoryPath(prefix[:i])
+	}
+
+	entries := []listEntry{}
+	if err := h.walk(r, bucket, dir, prefix, delimiter, &entries); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+	return entries, nil
+}
+
+func (h *gateway) walk(
+	r *gohttp.Request, bucket s3.Bucket, path, prefix, delimiter string, entries *[]listEntry,
+) error {
+	c := r.Context()
+	req := dto.Request{
+		Group:     bucket.Group,
+		Partition: bucket.Partition,
+		Path:      path,
+	}
+
+	children, err := h.explorerService.ListChildren(c, req)
+	if err != nil {
+		return err
+	}
+
+	items := children.Items
+	for cursor := children.NextCursor; cursor.IsValid(); {
+		req.LastObjectID = cursor
+		page, err := h.explorerService.FindObjectMetadataOnPath(c, req)
+		if err != nil {
+			return err
+		}
+
+		items = append(items, page.Items...)
+		cursor = page.NextCursor
+	}
+
+	for _, item := range items {
+		key := objectKey(path, item.Name)
+		version, err := item.Versions.LastVersion()
+		if err != nil || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		*entries = append(*entries, listEntry{key: key, item: item, version: version})
+	}
+
+	for _, name := range children.Directories {
+		subKey := objectKey(path, name) + pathDelimiter
+		switch {
+		case !strings.HasPrefix(subKey, prefix) && !strings.HasPrefix(prefix, subKey):
+			continue
+		case delimiter != "" && strings.HasPrefix(subKey, prefix):
+			*entries = append(*entries, listEntry{key: subKey, commonPrefix: true})
+			continue
+		}
+
+		// a directory the principal may not list is left out of a recursive listing
+		err := h.walk(r, bucket, entity.CleanDirectoryPath(subKey), prefix, delimiter, entries)
+		switch {
+		case errors.Is(err, soserror.Forbidden):
+			continue
+		case err != nil:
+			return err
+		}
+	}
+	return nil
+}
+
+func objectRequestOnBucket(bucket s3.Bucket, key string) (dto.Request, error) {
+	if key == "" || strings.HasSuffix(key, pathDelimiter) {
+		return empty.Struct[dto.Request](), invalidArgument("object key can not be empty or end with /")
+	}
+
+	path := entity.RootDirectoryPath
+	name := key
+	if i := strings.LastIndex(key, pathDelimiter); i >= 0 {
+		path = entity.CleanDirectoryPath(key[:i])
+		name = key[i+1:]
+	}
+
+	return dto.Request{
+		Group:     bucket.Group,
+		Partition: bucket.Partition,
+		Path:      path,
+		Name:      name,
+	}, nil
+}
+
+func objectKey(path, name string) string {
+	if path == entity.RootDirectoryPath {
+		return name
+	}
+	return path + pathDelimiter + name
+}
+
+// etag is the SHA-256 of the version content, or of its part checksums when it was uploaded
+// in parts. It is not an MD5 as S3 clients may expect.
+func etag(version dto.Version) string {
+	return http.QuoteETag(version.ETag)
+}
+
+// requestBody returns the object content of a put and its size. aws-chunked
+// bodies are decoded and sized by x-amz-decoded-content-length.
+func requestBody(r *gohttp.Request) (io.ReadCloser, int, error) {
+	chunked := strings.HasPrefix(r.Header.Get(contentSHA256Header), streamingPayloadPrefix) ||
+		strings.Contains(r.Header.Get("Content-Encoding"), awsChunkedEncoding)
+	if !chunked {
+		if r.ContentLength < 0 {
+			return nil, 0, errMissingContentLength
+		}
+		return r.Body, int(r.ContentLength), nil
+	}
+
+	size, err := strconv.Atoi(r.Header.Get(decodedContentLengthHeader))
+	if err != nil || size < 0 {
+		return nil, 0, errMissingContentLength
+	}
+	return newChunkedReader(r.Body, auth.ChunkVerifierFromContext(r.Context())), size, nil
+}
+
+func uploadError(err error) error {
+	if errors.Is(err, soserror.NotFound) {
+		return errNoSuchUpload
+	}
+	return err
+}
+
+func bodyWriter(w gohttp.ResponseWriter) http.DownloadBodyWriter {
+	return func(buffer []byte) error {
+		_, err := w.Write(buffer)
+		return err
+	}
+}
+
+// pathParam returns an unescaped path variable, the router matches on the encoded path.
+func pathParam(r *gohttp.Request, name string) string {
+	value := http.ParseParm(r)[name]
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
@@ -0,0 +1,235 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	gohttp "net/http"
+	"time"
+
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/infrastructure/transport/s3/middleware"
+	"github.com/ISSuh/sos/internal/auth"
+	"github.com/ISSuh/sos/internal/encryption"
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/log"
+)
+
+const (
+	xmlNamespace   = "http://s3.amazonaws.com/doc/2006-03-01/"
+	xmlContentType = "application/xml"
+	xmlTimeFormat  = "2006-01-02T15:04:05.000Z"
+)
+
+// apiError is an error that is sent to the client as is, with its S3 error code.
+type apiError struct {
+	code    string
+	message string
+	status  int
+}
+
+func (e apiError) Error() string {
+	return e.code + ": " + e.message
+}
+
+var (
+	errNoSuchBucket = apiError{
+		code: "NoSuchBucket", message: "The specified bucket does not exist", status: gohttp.StatusNotFound,
+	}
+	errNoSuchKey = apiError{
+		code: "NoSuchKey", message: "The specified key does not exist", status: gohttp.StatusNotFound,
+	}
+	errNoSuchUpload = apiError{
+		code: "NoSuchUpload", message: "The specified multipart upload does not exist", status: gohttp.StatusNotFound,
+	}
+	errInvalidRange = apiError{
+		code: "InvalidRange", message: "The requested range is not satisfiable", status: gohttp.StatusRequestedRangeNotSatisfiable,
+	}
+	errMalformedXML = apiError{
+		code: "MalformedXML", message: "The XML you provided was not well-formed", status: gohttp.StatusBadRequest,
+	}
+	errIncompleteBody = apiError{
+		code: "IncompleteBody", message: "You did not provide the number of bytes specified by the Content-Length HTTP header", status: gohttp.StatusBadRequest,
+	}
+	errMissingContentLength = apiError{
+		code: "MissingContentLength", message: "You must provide the Content-Length HTTP header", status: gohttp.StatusLengthRequired,
+	}
+	errAccessDenied = apiError{
+		code: "AccessDenied", message: "Access Denied", status: gohttp.StatusForbidden,
+	}
+	errPreconditionFailed = apiError{
+		code: "PreconditionFailed", message: "At least one of the preconditions you specified did not hold", status: gohttp.StatusPreconditionFailed,
+	}
+	errMetadataTooLarge = apiError{
+		code: "MetadataTooLarge", message: "Your metadata headers exceed the maximum allowed metadata size", status: gohttp.StatusBadRequest,
+	}
+	errNotImplemented = apiError{
+		code: "NotImplemented", message: "A header or query you provided implies functionality that is not implemented", status: gohttp.StatusNotImplemented,
+	}
+)
+
+func invalidArgument(message string) apiError {
+	return apiError{code: "InvalidArgument", message: message, status: gohttp.StatusBadRequest}
+}
+
+type errorResponse struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource"`
+	RequestID string   `xml:"RequestId"`
+}
+
+type owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+type bucketInfo struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name     `xml:"ListAllMyBucketsResult"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Owner   owner        `xml:"Owner"`
+	Buckets []bucketInfo `xml:"Buckets>Bucket"`
+}
+
+type objectInfo struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"ListBucketResult"`
+	Xmlns                 string         `xml:"xmlns,attr"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	KeyCount              int            `xml:"KeyCount"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	Contents              []objectInfo   `xml:"Contents"`
+	CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+}
+
+type copyObjectResult struct {
+	XMLName      xml.Name `xml:"CopyObjectResult"`
+	Xmlns        string   `xml:"xmlns,attr"`
+	LastModified string   `xml:"LastModified"`
+	ETag         string   `xml:"ETag"`
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+func xmlTime(t time.Time) string {
+	return t.UTC().Format(xmlTimeFormat)
+}
+
+func writeXML(w gohttp.ResponseWriter, status int, data any) error {
+	body, err := xml.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", xmlContentType)
+	w.WriteHeader(status)
+	_, err = w.Write(append([]byte(xml.Header), body...))
+	return err
+}
+
+// writeError sends err as an S3 error document. errors that are not an apiError
+// become NoSuchKey when nothing was found and InternalError otherwise.
+func writeError(w gohttp.ResponseWriter, r *gohttp.Request, err error) {
+	c := r.Context()
+	log.FromContext(c).Errorf("[gateway] %s %s Error: %s\n", r.Method, r.URL.Path, err.Error())
+
+	var e apiError
+	var authErr *auth.Error
+	switch {
+	case errors.As(err, &e):
+	case errors.As(err, &authErr):
+		e = apiError{code: authErr.Code, message: authErr.Message, status: authErr.Status}
+	case errors.Is(err, soserror.NotFound):
+		e = errNoSuchKey
+	case errors.Is(err, soserror.Forbidden):
+		e = errAccessDenied
+	case errors.Is(err, soserror.PreconditionFailed):
+		e = errPreconditionFailed
+	case errors.Is(err, entity.ErrUserMetadataTooLarge):
+		e = errMetadataTooLarge
+	case errors.Is(err, encryption.ErrInvalidCustomerKey):
+		e = invalidArgument(err.Error())
+	default:
+		e = apiError{code: "InternalError", message: err.Error(), status: gohttp.StatusInternalServerError}
+	}
+
+	resp := errorResponse{
+		Code:      e.code,
+		Message:   e.message,
+		Resource:  r.URL.Path,
+		RequestID: w.Header().Get(middleware.RequestIDHeader),
+	}
+
+	if err := writeXML(w, e.status, resp); err != nil {
+		log.FromContext(c).Errorf("[gateway] write error response: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,115 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package router
+
+import (
+	gohttp "net/http"
+
+	"github.com/ISSuh/sos/infrastructure/transport/rest/middleware"
+	"github.com/ISSuh/sos/infrastructure/transport/s3"
+	s3middleware "github.com/ISSuh/sos/infrastructure/transport/s3/middleware"
+	"github.com/ISSuh/sos/internal/auth"
+	"github.com/ISSuh/sos/internal/http"
+	"github.com/ISSuh/sos/internal/log"
+)
+
+const (
+	URLRoot   = "/"
+	URLBucket = "/{" + s3.BucketParamName + "}"
+	URLObject = URLBucket + "/{" + s3.KeyParamName + ":.+}"
+)
+
+// Route registers the S3 gateway. Requests are not authenticated when verifier is nil.
+func Route(logger log.Logger, s *http.Server, h s3.Gateway, verifier *auth.Verifier) {
+	s.Use(middleware.APM)
+	s.Use(middleware.Recover)
+	s.Use(middleware.WithLog(logger))
+	s.Use(s3middleware.GenerateRequestID)
+	if verifier != nil {
+		s.Use(middleware.Authenticate(verifier, h.WriteError))
+	}
+
+	routes := http.RouteList{
+		// ListBuckets
+		http.RouteItem{
+			URL:     URLRoot,
+			Method:  gohttp.MethodGet,
+			Handler: h.ListBuckets(),
+		},
+		// HeadBucket
+		http.RouteItem{
+			URL:     URLBucket,
+			Method:  gohttp.MethodHead,
+			Handler: h.HeadBucket(),
+		},
+		// ListObjectsV2
+		http.RouteItem{
+			URL:     URLBucket,
+			Method:  gohttp.MethodGet,
+			Handler: h.ListObjects(),
+		},
+		// buckets are configured, not created or deleted through the gateway
+		http.RouteItem{
+			URL:     URLBucket,
+			Method:  gohttp.MethodPut,
+			Handler: h.NotImplemented(),
+		},
+		http.RouteItem{
+			URL:     URLBucket,
+			Method:  gohttp.MethodDelete,
+			Handler: h.NotImplemented(),
+		},
+		// PutObject, CopyObject and UploadPart
+		http.RouteItem{
+			URL:     URLObject,
+			Method:  gohttp.MethodPut,
+			Handler: h.PutObject(),
+		},
+		// GetObject
+		http.RouteItem{
+			URL:     URLObject,
+			Method:  gohttp.MethodGet,
+			Handler: h.GetObject(),
+		},
+		// HeadObject
+		http.RouteItem{
+			URL:     URLObject,
+			Method:  gohttp.MethodHead,
+			Handler: h.HeadObject(),
+		},
+		// DeleteObject and AbortMultipartUpload
+		http.RouteItem{
+			URL:     URLObject,
+			Method:  gohttp.MethodDelete,
+			Handler: h.DeleteObject(),
+		},
+		// CreateMultipartUpload and CompleteMultipartUpload
+		http.RouteItem{
+			URL:     URLObject,
+			Method:  gohttp.MethodPost,
+			Handler: h.PostObject(),
+		},
+	}
+
+	s.MuxAll(routes)
+}
@@ -29,7 +29,7 @@ import (
 
 func WrapServerInterceptor() grpc.ServerOption {
 	if !a.IsUsingAPM() {
-		return nil
+		return grpc.EmptyServerOption{}
 	}
 
 	option := apmgrpc.WithTracer(a.tracer)
@@ -39,7 +39,7 @@ func WrapServerInterceptor() grpc.ServerOption {
 
 func WrapClientInterceptor() grpc.DialOption {
 	if !a.IsUsingAPM() {
-		return nil
+		return grpc.EmptyDialOption{}
 	}
 
 	apmInterceptor := apmgrpc.NewUnaryClientInterceptor()
@@ -23,6 +23,8 @@
 package app
 
 import (
+	"context"
+
 	"github.com/ISSuh/sos/internal/config"
 	"github.com/ISSuh/sos/internal/factory"
 	"github.com/ISSuh/sos/internal/log"
@@ -37,10 +39,15 @@ type BlockStorage struct {
 }
 
 func NewBlockStorage(c config.SosConfig, l log.Logger) (BlockStorage, error) {
+	server, err := factory.NewRPCServer(c.BlockStorage.TLS)
+	if err != nil {
+		return BlockStorage{}, err
+	}
+
 	a := BlockStorage{
 		config: c,
 		logger: l,
-		server: rpc.NewServer(),
+		server: server,
 	}
 	return a, nil
 }
@@ -64,7 +71,17 @@ func (a *BlockStorage) init() error {
 		return err
 	}
 
-	registers, err := factory.BlockStorageHandler(service)
+	scrubber, err := factory.NewScrubber(repository, a.config.BlockStorage.Scrub)
+	if err != nil {
+		return err
+	}
+
+	if scrubber != nil {
+		c := context.WithValue(context.Background(), log.LoggerKey, a.logger)
+		go scrubber.Run(c, a.config.BlockStorage.Scrub.IntervalOrDefault())
+	}
+
+	registers, err := factory.BlockStorageHandler(service, scrubber)
 	if err != nil {
 		return err
 	}
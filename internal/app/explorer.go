@@ -23,8 +23,12 @@
 package app
 
 import (
+	"context"
+
 	"github.com/ISSuh/sos/domain/service"
 	"github.com/ISSuh/sos/infrastructure/transport/rest/router"
+	s3router "github.com/ISSuh/sos/infrastructure/transport/s3/router"
+	"github.com/ISSuh/sos/internal/auth"
 	"github.com/ISSuh/sos/internal/config"
 	"github.com/ISSuh/sos/internal/factory"
 	"github.com/ISSuh/sos/internal/http"
@@ -34,15 +38,17 @@ import (
 type Explorer struct {
 	logger log.Logger
 
-	config config.SosConfig
-	server http.Server
+	config   config.SosConfig
+	server   http.Server
+	s3Server http.Server
 }
 
 func NewApi(c config.SosConfig, l log.Logger) (Explorer, error) {
 	a := Explorer{
-		config: c,
-		logger: l,
-		server: http.NewServer(),
+		config:   c,
+		logger:   l,
+		server:   http.NewServer(),
+		s3Server: http.NewServer(),
 	}
 	return a, nil
 }
@@ -52,39 +58,110 @@ func (a *Explorer) Run() error {
 	if err := a.init(); err != nil {
 		return err
 	}
-	return a.server.Run(a.config.Explorer.Address.String())
+
+	if !a.config.Explorer.S3.Enabled {
+		return a.server.Run(a.config.Explorer.Address.String())
+	}
+
+	// both servers run until one of them fails
+	errs := make(chan error, 2)
+	go func() {
+		errs <- a.server.Run(a.config.Explorer.Address.String())
+	}()
+	go func() {
+		errs <- a.s3Server.Run(a.config.Explorer.S3.Address.String())
+	}()
+	return <-errs
 }
 
 func (a *Explorer) init() error {
-	service, err := a.initService()
+	presigner, err := factory.NewPresigner(a.config.Explorer.Presign)
+	if err != nil {
+		return err
+	}
+
+	service, sweeper, collector, verifier, err := a.initService(presigner)
 	if err != nil {
 		return err
 	}
 
+	c := context.WithValue(context.Background(), log.LoggerKey, a.logger)
+	go sweeper.Run(c, a.config.Explorer.Upload.SweepIntervalOrDefault())
+	if collector != nil {
+		go collector.Run(c, a.config.Explorer.GC.IntervalOrDefault())
+	}
+
 	handler, err := factory.NewExplorerHandler(service)
 	if err != nil {
 		return err
 	}
 
-	router.Route(a.logger, &a.server, handler)
+	router.Route(a.logger, &a.server, handler, verifier, presigner)
+
+	if a.config.Explorer.S3.Enabled {
+		gateway, err := factory.NewS3Gateway(service, a.config.Explorer.S3)
+		if err != nil {
+			return err
+		}
+
+		s3router.Route(a.logger, &a.s3Server, gateway, verifier)
+	}
 	return nil
 }
 
-func (a *Explorer) initService() (service.Explorer, error) {
-	metadataRequestor, err := factory.NewMetadataRegistryRequestor(a.config.MetadataRegistry.Address.Host)
+func (a *Explorer) initService(presigner *auth.Presigner) (
+	service.Explorer, service.UploadSweeper, service.GarbageCollector, *auth.Verifier, error,
+) {
+	tlsConfig, err := factory.NewRPCClientTLSConfig(a.config.Explorer.TLS)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	metadataRequestor, err := factory.NewMetadataRegistryRequestor(a.config.MetadataRegistry.Address.Host, tlsConfig)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	storageCluster, err := factory.NewBlockStorageCluster(
+		a.config.BlockStorage.NodeHosts(),
+		a.config.BlockStorage.ReplicationOrDefault(),
+		a.config.BlockStorage.ErasureCoding,
+		a.config.BlockStorage.Deduplication,
+		a.config.BlockStorage.Compression,
+		a.config.BlockStorage.BlockSizeOrDefault(),
+		a.config.Explorer.Upload.ConcurrencyOrDefault(),
+		tlsConfig,
+	)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	collector, err := factory.NewGarbageCollector(metadataRequestor, storageCluster, a.config.Explorer.GC)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	keyService, err := factory.NewKMS(a.config.Explorer.Encryption)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	explorer, err := factory.NewExplorerService(
+		metadataRequestor, storageCluster, a.config.Explorer.Upload, a.config.Explorer.Auth, presigner, collector, keyService,
+	)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, nil, err
 	}
 
-	storageRequestor, err := factory.NewBlockStorageRequestor(a.config.BlockStorage.Address.Host)
+	sweeper, err := factory.NewUploadSweeper(metadataRequestor, storageCluster)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, nil, err
 	}
 
-	explorer, err := factory.NewExplorerService(metadataRequestor, storageRequestor)
+	verifier, err := factory.NewVerifier(metadataRequestor, a.config.Explorer.Auth)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, nil, err
 	}
 
-	return explorer, nil
+	return explorer, sweeper, collector, verifier, nil
 }
@@ -23,6 +23,8 @@
 package app
 
 import (
+	"context"
+
 	"github.com/ISSuh/sos/internal/config"
 	"github.com/ISSuh/sos/internal/factory"
 	"github.com/ISSuh/sos/internal/log"
@@ -37,10 +39,15 @@ type MetadataRegistry struct {
 }
 
 func NewMetadata(c config.SosConfig, l log.Logger) (MetadataRegistry, error) {
+	server, err := factory.NewRPCServer(c.MetadataRegistry.TLS)
+	if err != nil {
+		return MetadataRegistry{}, err
+	}
+
 	a := MetadataRegistry{
 		config: c,
 		logger: l,
-		server: rpc.NewServer(),
+		server: server,
 	}
 	return a, nil
 }
@@ -59,12 +66,52 @@ func (a *MetadataRegistry) init() error {
 		return err
 	}
 
-	service, err := factory.NewObjectMetadataService(repository)
+	directoryRepository, err := factory.NewObjectDirectoryRepository(a.logger, a.config.MetadataRegistry.Database)
+	if err != nil {
+		return err
+	}
+
+	service, err := factory.NewObjectMetadataService(repository, directoryRepository)
+	if err != nil {
+		return err
+	}
+
+	sessionRepository, err := factory.NewUploadSessionRepository(a.logger, a.config.MetadataRegistry.Database)
+	if err != nil {
+		return err
+	}
+
+	sessionService, err := factory.NewUploadSessionService(sessionRepository)
+	if err != nil {
+		return err
+	}
+
+	credentialRepository, err := factory.NewCredentialRepository(a.logger, a.config.MetadataRegistry.Database)
+	if err != nil {
+		return err
+	}
+
+	credentialService, err := factory.NewCredentialService(credentialRepository)
+	if err != nil {
+		return err
+	}
+
+	c := context.WithValue(context.Background(), log.LoggerKey, a.logger)
+	if err := putCredentials(c, credentialService, a.config.MetadataRegistry.Credentials); err != nil {
+		return err
+	}
+
+	policyRepository, err := factory.NewPolicyRepository(a.logger, a.config.MetadataRegistry.Database)
+	if err != nil {
+		return err
+	}
+
+	policyService, err := factory.NewPolicyService(policyRepository)
 	if err != nil {
 		return err
 	}
 
-	registers, err := factory.MetadataRegistryHandler(service)
+	registers, err := factory.MetadataRegistryHandler(service, sessionService, credentialService, policyService)
 	if err != nil {
 		return err
 	}
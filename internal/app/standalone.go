@@ -23,27 +23,39 @@
 package app
 
 import (
+	"context"
+
+	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/service"
+	"github.com/ISSuh/sos/domain/service/object"
 	"github.com/ISSuh/sos/infrastructure/transport/rest/router"
+	s3router "github.com/ISSuh/sos/infrastructure/transport/s3/router"
 	"github.com/ISSuh/sos/internal/app/standalone"
+	"github.com/ISSuh/sos/internal/auth"
 	"github.com/ISSuh/sos/internal/config"
 	"github.com/ISSuh/sos/internal/factory"
 	"github.com/ISSuh/sos/internal/http"
 	"github.com/ISSuh/sos/internal/log"
 )
 
+const (
+	standaloneBlockStorageNode = "standalone"
+)
+
 type Standalone struct {
 	logger log.Logger
 
-	config config.SosConfig
-	server http.Server
+	config   config.SosConfig
+	server   http.Server
+	s3Server http.Server
 }
 
 func NewStandalone(c config.SosConfig, l log.Logger) (Standalone, error) {
 	a := Standalone{
-		config: c,
-		logger: l,
-		server: http.NewServer(),
+		config:   c,
+		logger:   l,
+		server:   http.NewServer(),
+		s3Server: http.NewServer(),
 	}
 	return a, nil
 }
@@ -53,59 +65,168 @@ func (a *Standalone) Run() error {
 	if err := a.init(); err != nil {
 		return err
 	}
-	return a.server.Run(a.config.Explorer.Address.String())
+
+	if !a.config.Explorer.S3.Enabled {
+		return a.server.Run(a.config.Explorer.Address.String())
+	}
+
+	// both servers run until one of them fails
+	errs := make(chan error, 2)
+	go func() {
+		errs <- a.server.Run(a.config.Explorer.Address.String())
+	}()
+	go func() {
+		errs <- a.s3Server.Run(a.config.Explorer.S3.Address.String())
+	}()
+	return <-errs
 }
 
 func (a *Standalone) init() error {
-	service, err := a.initService()
+	presigner, err := factory.NewPresigner(a.config.Explorer.Presign)
+	if err != nil {
+		return err
+	}
+
+	service, sweeper, collector, verifier, err := a.initService(presigner)
 	if err != nil {
 		return err
 	}
 
+	c := context.WithValue(context.Background(), log.LoggerKey, a.logger)
+	go sweeper.Run(c, a.config.Explorer.Upload.SweepIntervalOrDefault())
+	if collector != nil {
+		go collector.Run(c, a.config.Explorer.GC.IntervalOrDefault())
+	}
+
 	handler, err := factory.NewExplorerHandler(service)
 	if err != nil {
 		return err
 	}
 
-	router.Route(a.logger, &a.server, handler)
+	router.Route(a.logger, &a.server, handler, verifier, presigner)
+
+	if a.config.Explorer.S3.Enabled {
+		gateway, err := factory.NewS3Gateway(service, a.config.Explorer.S3)
+		if err != nil {
+			return err
+		}
+
+		s3router.Route(a.logger, &a.s3Server, gateway, verifier)
+	}
 	return nil
 }
 
-func (a *Standalone) initService() (service.Explorer, error) {
+func (a *Standalone) initService(presigner *auth.Presigner) (
+	service.Explorer, service.UploadSweeper, service.GarbageCollector, *auth.Verifier, error,
+) {
 	metadataRepo, err := factory.NewObjectMetadataRepository(a.logger, a.config.MetadataRegistry.Database)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, nil, err
+	}
+
+	directoryRepo, err := factory.NewObjectDirectoryRepository(a.logger, a.config.MetadataRegistry.Database)
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
 
-	metadataService, err := factory.NewObjectMetadataService(metadataRepo)
+	metadataService, err := factory.NewObjectMetadataService(metadataRepo, directoryRepo)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, nil, err
 	}
 
 	storageRepo, err := factory.NewObjectStorageRepository(a.logger, a.config.BlockStorage.Database)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, nil, err
 	}
 
 	storageService, err := factory.NewObjectStorageService(storageRepo)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, nil, err
+	}
+
+	sessionRepo, err := factory.NewUploadSessionRepository(a.logger, a.config.MetadataRegistry.Database)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	sessionService, err := factory.NewUploadSessionService(sessionRepo)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	credentialRepo, err := factory.NewCredentialRepository(a.logger, a.config.MetadataRegistry.Database)
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
 
-	metadataRegistry, err := standalone.NewMetadataRegistry(metadataService)
+	credentialService, err := factory.NewCredentialService(credentialRepo)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, nil, err
+	}
+
+	c := context.WithValue(context.Background(), log.LoggerKey, a.logger)
+	if err := putCredentials(c, credentialService, a.config.MetadataRegistry.Credentials); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	policyRepo, err := factory.NewPolicyRepository(a.logger, a.config.MetadataRegistry.Database)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	policyService, err := factory.NewPolicyService(policyRepo)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	metadataRegistry, err := standalone.NewMetadataRegistry(
+		metadataService, sessionService, credentialService, policyService,
+	)
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
 
 	blockStorage, err := standalone.NewBlockStorage(storageService)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, nil, err
+	}
+
+	storageCluster, err := object.NewStorageCluster(
+		[]object.StorageNode{
+			{Node: entity.Node{Host: standaloneBlockStorageNode}, Requestor: blockStorage},
+		}, 1, nil, a.config.BlockStorage.Deduplication, factory.NewCompressionRules(a.config.BlockStorage.Compression),
+		a.config.BlockStorage.BlockSizeOrDefault(), a.config.Explorer.Upload.ConcurrencyOrDefault(),
+	)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	collector, err := factory.NewGarbageCollector(metadataRegistry, storageCluster, a.config.Explorer.GC)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	keyService, err := factory.NewKMS(a.config.Explorer.Encryption)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	explorer, err := factory.NewExplorerService(
+		metadataRegistry, storageCluster, a.config.Explorer.Upload, a.config.Explorer.Auth, presigner, collector, keyService,
+	)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	sweeper, err := factory.NewUploadSweeper(metadataRegistry, storageCluster)
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
 
-	explorer, err := factory.NewExplorerService(metadataRegistry, blockStorage)
+	verifier, err := factory.NewVerifier(metadataRegistry, a.config.Explorer.Auth)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, nil, err
 	}
 
-	return explorer, nil
+	return explorer, sweeper, collector, verifier, nil
 }
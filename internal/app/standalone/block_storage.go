@@ -23,6 +23,7 @@
 package standalone
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 
@@ -61,6 +62,10 @@ func (s *blockStorage) Put(
 			Index(int(blockMessage.Header.Index)).
 			Size(int(blockMessage.Header.Size)).
 			Checksum(blockMessage.Header.Checksum).
+			Hash(blockMessage.Header.Hash).
+			CustomerKeyed(blockMessage.Header.CustomerKeyed).
+			Compression(blockMessage.Header.Compression).
+			UncompressedSize(int(blockMessage.Header.UncompressedSize)).
 			Timestamp(blockMessage.Header.Timestamp.AsTime()).
 			Build()
 
@@ -70,7 +75,7 @@ func (s *blockStorage) Put(
 			Header(header).
 			Build()
 
-	if err := s.objectStorage.Put(ctx, &block); err != nil {
+	if err := s.objectStorage.Put(ctx, &block, blockMessage.Header.DataKey); err != nil {
 		return &rpcmessage.StorageResponse{
 			Success: false,
 			Message: err.Error(),
@@ -85,9 +90,17 @@ func (s *blockStorage) Put(
 func (s *blockStorage) GetBlock(
 	ctx context.Context, headerMessage *message.BlockHeader,
 ) (*message.Block, error) {
+	if headerMessage.Hash != "" {
+		block, err := s.objectStorage.GetContent(ctx, headerMessage.Hash)
+		if err != nil {
+			return nil, err
+		}
+		return message.FromBlock(block), nil
+	}
+
 	block, err := s.objectStorage.GetBlock(
 		ctx, entity.ObjectID(headerMessage.ObjectID.Id),
-		entity.BlockID(headerMessage.BlockID.Id), int(headerMessage.Index),
+		entity.BlockID(headerMessage.BlockID.Id), int(headerMessage.Index), headerMessage.DataKey,
 	)
 
 	if err != nil {
@@ -103,16 +116,46 @@ func (s *blockStorage) GetBlock(
 			BlockID: &message.BlockID{
 				Id: blockHeader.ObjectID().ToInt64(),
 			},
-			Index:     int32(blockHeader.Index()),
-			Timestamp: timestamppb.New(blockHeader.Timestamp()),
+			Index:         int32(blockHeader.Index()),
+			CustomerKeyed: blockHeader.CustomerKeyed(),
+			DataKeySealed: blockHeader.DataKeySealed(),
+			Timestamp:     timestamppb.New(blockHeader.Timestamp()),
 		},
 		Data: block.Buffer(),
 	}, nil
 }
 
+// PutStream stores the block in process, as there is no message limit to stream around. The data is
+// copied because the uploader reuses its buffer once the block is sent, as it does over grpc.
+func (s *blockStorage) PutStream(
+	ctx context.Context, blockMessage *message.Block,
+) (*rpcmessage.StorageResponse, error) {
+	block := &message.Block{
+		Header: blockMessage.Header,
+		Data:   bytes.Clone(blockMessage.Data),
+	}
+	return s.Put(ctx, block)
+}
+
+func (s *blockStorage) GetBlockStream(
+	ctx context.Context, headerMessage *message.BlockHeader,
+) (*message.Block, error) {
+	return s.GetBlock(ctx, headerMessage)
+}
+
 func (s *blockStorage) GetBlockHeader(
 	ctx context.Context, headerMessage *message.BlockHeader,
 ) (*message.BlockHeader, error) {
+	if headerMessage.Hash != "" {
+		block, err := s.objectStorage.GetContent(ctx, headerMessage.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		blockHeader := block.Header()
+		return message.FromBlockHeader(&blockHeader), nil
+	}
+
 	blockHeader, err := s.objectStorage.GetBlockHeader(
 		ctx, entity.ObjectID(headerMessage.ObjectID.Id),
 		entity.BlockID(headerMessage.BlockID.Id), int(headerMessage.Index),
@@ -138,10 +181,15 @@ func (s *blockStorage) GetBlockHeader(
 func (s *blockStorage) Delete(
 	ctx context.Context, headerMessage *message.BlockHeader,
 ) (*rpcmessage.StorageResponse, error) {
-	err := s.objectStorage.Delete(
-		ctx, entity.ObjectID(headerMessage.ObjectID.Id),
-		entity.BlockID(headerMessage.BlockID.Id), int(headerMessage.Index),
-	)
+	var err error
+	if headerMessage.Hash != "" {
+		err = s.objectStorage.Release(ctx, headerMessage.Hash)
+	} else {
+		err = s.objectStorage.Delete(
+			ctx, entity.ObjectID(headerMessage.ObjectID.Id),
+			entity.BlockID(headerMessage.BlockID.Id), int(headerMessage.Index),
+		)
+	}
 
 	if err != nil {
 		return &rpcmessage.StorageResponse{
@@ -153,3 +201,69 @@ func (s *blockStorage) Delete(
 		Success: true,
 	}, nil
 }
+
+func (s *blockStorage) Copy(
+	ctx context.Context, req *rpcmessage.CopyBlockRequest,
+) (*rpcmessage.StorageResponse, error) {
+	err := s.objectStorage.Copy(ctx, message.ToBlockHeader(req.Source), message.ToBlockHeader(req.Target))
+	if err != nil {
+		return &rpcmessage.StorageResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+	return &rpcmessage.StorageResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *blockStorage) GetScrubStatus(ctx context.Context) (*message.ScrubStatus, error) {
+	return nil, fmt.Errorf("standalone does not support scrub")
+}
+
+func (s *blockStorage) StartScrub(ctx context.Context) (*message.ScrubStatus, error) {
+	return nil, fmt.Errorf("standalone does not support scrub")
+}
+
+func (s *blockStorage) ListBlocks(
+	ctx context.Context, req *rpcmessage.ListBlocksRequest, recv func(*message.BlockHeader) error,
+) error {
+	return s.objectStorage.ListBlocks(ctx, req.Before.AsTime(), func(header *entity.BlockHeader, references int64) error {
+		msg := message.FromBlockHeader(header)
+		msg.References = references
+		return recv(msg)
+	})
+}
+
+func (s *blockStorage) Reference(
+	ctx context.Context, headerMessage *message.BlockHeader,
+) (*rpcmessage.ReferenceResponse, error) {
+	exist, err := s.objectStorage.Reference(ctx, headerMessage.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcmessage.ReferenceResponse{
+		Exist: exist,
+	}, nil
+}
+
+func (s *blockStorage) GetDedupStats(ctx context.Context) (*message.DedupStats, error) {
+	stats, err := s.objectStorage.DedupStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return message.FromDedupStatsDTO(&stats), nil
+}
+
+func (s *blockStorage) Reconcile(
+	ctx context.Context, req *rpcmessage.ReconcileRequest,
+) (*rpcmessage.ReconcileResponse, error) {
+	references, reconciled, err := s.objectStorage.Reconcile(ctx, req.Hash, req.References, req.Before.AsTime())
+	if err != nil {
+		return nil, err
+	}
+	return &rpcmessage.ReconcileResponse{
+		References: references,
+		Reconciled: reconciled,
+	}, nil
+}
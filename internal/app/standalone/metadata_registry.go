@@ -26,6 +26,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ISSuh/sos/domain/model/dto"
+	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/model/message"
 	"github.com/ISSuh/sos/domain/service"
 	"github.com/ISSuh/sos/infrastructure/transport/rpc"
@@ -35,16 +37,31 @@ import (
 
 type metadataRegistry struct {
 	objectMetadata service.ObjectMetadata
+	uploadSession  service.UploadSession
+	credential     service.Credential
+	policy         service.Policy
 }
 
-func NewMetadataRegistry(objectMetadata service.ObjectMetadata) (rpc.MetadataRegistryRequestor, error) {
+func NewMetadataRegistry(
+	objectMetadata service.ObjectMetadata, uploadSession service.UploadSession, credential service.Credential,
+	policy service.Policy,
+) (rpc.MetadataRegistryRequestor, error) {
 	switch {
 	case validation.IsNil(objectMetadata):
 		return nil, fmt.Errorf("ObjectMetadata service is nil")
+	case validation.IsNil(uploadSession):
+		return nil, fmt.Errorf("UploadSession service is nil")
+	case validation.IsNil(credential):
+		return nil, fmt.Errorf("Credential service is nil")
+	case validation.IsNil(policy):
+		return nil, fmt.Errorf("Policy service is nil")
 	}
 
 	return &metadataRegistry{
 		objectMetadata: objectMetadata,
+		uploadSession:  uploadSession,
+		credential:     credential,
+		policy:         policy,
 	}, nil
 }
 
@@ -58,12 +75,13 @@ func (s *metadataRegistry) Put(c context.Context, dto *message.Object) (*message
 	return message.FromObjectMetadataDTO(metadata), nil
 }
 
-func (r *metadataRegistry) Delete(c context.Context, dto *message.ObjectMetadata) error {
+func (r *metadataRegistry) Delete(c context.Context, dto *message.ObjectMetadata) (*message.BlockHeaderList, error) {
 	metadata := message.ToObjectMetadataDTO(dto)
-	if err := r.objectMetadata.Delete(c, metadata); err != nil {
-		return err
+	recorded, err := r.objectMetadata.Delete(c, metadata)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return message.FromBlockHeaderListDTO(recorded), nil
 }
 
 func (s *metadataRegistry) GetByObjectName(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadata, error) {
@@ -85,10 +103,228 @@ func (s *metadataRegistry) GetByObjectID(c context.Context, req *rpcmessage.Obje
 }
 
 func (s *metadataRegistry) FindMetadataOnPath(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
-	items, err := s.objectMetadata.MetadataListOnPath(c, req.Group, req.Partition, req.Path)
+	items, nextCursor, err := s.objectMetadata.MetadataListOnPath(
+		c, req.Group, req.Partition, req.Path, entity.NewObjectIDFrom(req.LastObjectID), int(req.Limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := message.FromObjectMetadataListDTO(items)
+	resp.NextCursor = nextCursor.ToInt64()
+	return resp, nil
+}
+
+func (s *metadataRegistry) CreateUploadSession(c context.Context, dto *message.UploadSession) (*message.UploadSession, error) {
+	session, err := s.uploadSession.Create(c, message.ToUploadSessionDTO(dto))
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromUploadSessionDTO(session), nil
+}
+
+func (s *metadataRegistry) GetUploadSession(c context.Context, req *rpcmessage.UploadSessionRequest) (*message.UploadSession, error) {
+	session, err := s.uploadSession.SessionByID(c, req.UploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromUploadSessionDTO(session), nil
+}
+
+func (s *metadataRegistry) PutUploadPart(c context.Context, req *rpcmessage.UploadPartRequest) (*message.UploadSession, error) {
+	if err := s.uploadSession.PutPart(c, req.UploadID, message.ToUploadPartDTO(req.Part)); err != nil {
+		return nil, err
+	}
+
+	session, err := s.uploadSession.SessionByID(c, req.UploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromUploadSessionDTO(session), nil
+}
+
+func (s *metadataRegistry) DeleteUploadSession(c context.Context, req *rpcmessage.UploadSessionRequest) error {
+	return s.uploadSession.Delete(c, req.UploadID)
+}
+
+func (s *metadataRegistry) FindExpiredUploadSessions(c context.Context, req *rpcmessage.UploadSessionRequest) (*message.UploadSessionList, error) {
+	items, err := s.uploadSession.FindExpired(c, req.Now.AsTime())
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromUploadSessionListDTO(items), nil
+}
+
+func (s *metadataRegistry) GetDirectory(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.Directory, error) {
+	directory, err := s.objectMetadata.Directory(c, req.Group, req.Partition, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromDirectoryDTO(directory), nil
+}
+
+func (s *metadataRegistry) GetCredential(c context.Context, req *rpcmessage.CredentialRequest) (*message.Credential, error) {
+	credential, err := s.credential.CredentialByAccessKey(c, req.AccessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromCredentialDTO(credential), nil
+}
+
+func (s *metadataRegistry) PutPolicy(c context.Context, req *message.Policy) (*message.Policy, error) {
+	policy, err := s.policy.Put(c, message.ToPolicyDTO(req))
 	if err != nil {
 		return nil, err
 	}
 
-	return message.FromObjectMetadataListDTO(items), err
+	return message.FromPolicyDTO(policy), nil
+}
+
+func (s *metadataRegistry) GetPolicy(c context.Context, req *rpcmessage.PolicyRequest) (*message.Policy, error) {
+	policy, err := s.policy.PolicyByPartition(c, req.Group, req.Partition)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromPolicyDTO(policy), nil
+}
+
+func (s *metadataRegistry) DeletePolicy(c context.Context, req *rpcmessage.PolicyRequest) error {
+	return s.policy.Delete(c, req.Group, req.Partition)
+}
+
+func (s *metadataRegistry) FindPolicies(c context.Context, req *rpcmessage.PolicyRequest) (*message.Policies, error) {
+	policies, err := s.policy.FindPolicies(c, req.Group, req.Partition)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.FromPoliciesDTO(policies), nil
+}
+
+func (s *metadataRegistry) FindBlockHeaders(c context.Context, req *rpcmessage.BlockHeaderRequest) (*message.BlockHeaderList, error) {
+	sessionHeaders, err := s.uploadSession.BlockHeadersOfObjects(c, req.ObjectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionContents, err := s.uploadSession.BlockHeadersOfContents(c, req.Hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := s.objectMetadata.BlockHeadersOfObjects(c, req.ObjectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := s.objectMetadata.BlockHeadersOfContents(c, req.Hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	headers = append(headers, contents...)
+	headers = append(headers, sessionHeaders...)
+	return message.FromBlockHeaderListDTO(append(headers, sessionContents...)), nil
+}
+
+func (s *metadataRegistry) ReserveVersion(c context.Context, req *message.Object) (*message.Object, error) {
+	reserved, err := s.objectMetadata.ReserveVersion(c, message.ToObjectDTO(req))
+	if err != nil {
+		return nil, err
+	}
+	return message.FromObjectDTO(reserved), nil
+}
+
+func (s *metadataRegistry) CommitVersion(c context.Context, req *message.Object) (*message.ObjectMetadata, error) {
+	metadata, err := s.objectMetadata.CommitVersion(c, message.ToObjectDTO(req))
+	if err != nil {
+		return nil, err
+	}
+	return message.FromObjectMetadataDTO(metadata), nil
+}
+
+func (s *metadataRegistry) RecordBlocks(c context.Context, req *message.Object) error {
+	return s.objectMetadata.RecordBlocks(c, message.ToObjectDTO(req))
+}
+
+func (s *metadataRegistry) AbortVersion(c context.Context, req *message.Object) (*message.BlockHeaderList, error) {
+	recorded, err := s.objectMetadata.AbortVersion(c, message.ToObjectDTO(req))
+	if err != nil {
+		return nil, err
+	}
+	return message.FromBlockHeaderListDTO(recorded), nil
+}
+
+func (s *metadataRegistry) ReleaseExpiredVersions(c context.Context, req *rpcmessage.ExpiredVersionRequest) (*message.BlockHeaderList, error) {
+	released, err := s.objectMetadata.ReleaseExpiredVersions(c, req.Now.AsTime())
+	if err != nil {
+		return nil, err
+	}
+	return message.FromBlockHeaderListDTO(released), nil
+}
+
+func (s *metadataRegistry) PutTags(c context.Context, req *message.ObjectMetadata) (*message.ObjectMetadata, error) {
+	metadata, err := s.objectMetadata.PutTags(c, message.ToObjectMetadataDTO(req))
+	if err != nil {
+		return nil, err
+	}
+	return message.FromObjectMetadataDTO(metadata), nil
+}
+
+func (s *metadataRegistry) FindMetadataByTags(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
+	expression, err := entity.ParseTagExpression(req.TagExpression)
+	if err != nil {
+		return nil, err
+	}
+
+	items, nextCursor, err := s.objectMetadata.MetadataListByTags(
+		c, req.Group, req.Partition, expression, entity.NewObjectIDFrom(req.LastObjectID), int(req.Limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := message.FromObjectMetadataListDTO(items)
+	resp.NextCursor = nextCursor.ToInt64()
+	return resp, nil
+}
+
+func (s *metadataRegistry) FindMetadataByStaleDataKey(c context.Context, req *rpcmessage.ObjectMetadataRequest) (*message.ObjectMetadataList, error) {
+	items, nextCursor, err := s.objectMetadata.MetadataListByStaleDataKey(
+		c, req.KeyID, entity.NewObjectIDFrom(req.LastObjectID), int(req.Limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := message.FromObjectMetadataListDTO(items)
+	resp.NextCursor = nextCursor.ToInt64()
+	return resp, nil
+}
+
+func (s *metadataRegistry) RewrapDataKeys(c context.Context, req *message.ObjectMetadata) (*message.ObjectMetadata, error) {
+	metadata, err := s.objectMetadata.RewrapDataKeys(c, message.ToObjectMetadataDTO(req))
+	if err != nil {
+		return nil, err
+	}
+	return message.FromObjectMetadataDTO(metadata), nil
+}
+
+func (s *metadataRegistry) Move(c context.Context, req *rpcmessage.MoveRequest) (*message.ObjectMetadata, error) {
+	destination := dto.Destination{
+		Path: req.Path,
+		Name: req.Name,
+	}
+	metadata, err := s.objectMetadata.Move(c, message.ToObjectMetadataDTO(req.Metadata), destination)
+	if err != nil {
+		return nil, err
+	}
+	return message.FromObjectMetadataDTO(metadata), nil
 }
@@ -0,0 +1,67 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package auth
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"strings"
+)
+
+// ChunkVerifier checks the signatures of an aws-chunked body. Every chunk signs its
+// data together with the signature of the previous chunk, the first one chaining from
+// the signature of the request.
+type ChunkVerifier struct {
+	key      []byte
+	amzDate  string
+	scope    string
+	previous string
+}
+
+func newChunkVerifier(key []byte, amzDate, scope, seedSignature string) *ChunkVerifier {
+	return &ChunkVerifier{
+		key:      key,
+		amzDate:  amzDate,
+		scope:    scope,
+		previous: seedSignature,
+	}
+}
+
+// Verify checks the signature of the next chunk by the SHA-256 of its data.
+func (v *ChunkVerifier) Verify(chunkSHA256 []byte, signature string) error {
+	stringToSign := strings.Join([]string{
+		Algorithm + "-PAYLOAD",
+		v.amzDate,
+		v.scope,
+		v.previous,
+		emptySHA256,
+		hex.EncodeToString(chunkSHA256),
+	}, "\n")
+
+	if !hmac.Equal([]byte(hex.EncodeToString(hmacSHA256(v.key, stringToSign))), []byte(signature)) {
+		return ErrSignatureDoesNotMatch
+	}
+
+	v.previous = signature
+	return nil
+}
@@ -0,0 +1,70 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package auth
+
+import (
+	"net/http"
+)
+
+// Error is an authentication failure. Code is the matching S3 error code.
+type Error struct {
+	Code    string
+	Message string
+	Status  int
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+var (
+	ErrMissingAuthentication = &Error{
+		Code: "AccessDenied", Message: "request is not signed", Status: http.StatusForbidden,
+	}
+	ErrExpired = &Error{
+		Code: "AccessDenied", Message: "request has expired", Status: http.StatusForbidden,
+	}
+	ErrInvalidAccessKeyID = &Error{
+		Code: "InvalidAccessKeyId", Message: "access key does not exist", Status: http.StatusForbidden,
+	}
+	ErrSignatureDoesNotMatch = &Error{
+		Code: "SignatureDoesNotMatch", Message: "request signature does not match", Status: http.StatusForbidden,
+	}
+	ErrRequestTimeTooSkewed = &Error{
+		Code: "RequestTimeTooSkewed", Message: "request time is too far from the server time", Status: http.StatusForbidden,
+	}
+	ErrContentSHA256Mismatch = &Error{
+		Code: "XAmzContentSHA256Mismatch", Message: "x-amz-content-sha256 does not match the body", Status: http.StatusBadRequest,
+	}
+	ErrUnsignedBodyTooLarge = &Error{
+		Code: "InvalidRequest", Message: "x-amz-content-sha256 is required for a large body", Status: http.StatusBadRequest,
+	}
+)
+
+func malformedAuthorization(message string) *Error {
+	return &Error{Code: "AuthorizationHeaderMalformed", Message: message, Status: http.StatusBadRequest}
+}
+
+func malformedQuery(message string) *Error {
+	return &Error{Code: "AuthorizationQueryParametersError", Message: message, Status: http.StatusBadRequest}
+}
@@ -0,0 +1,174 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PresignAlgorithm = "SOS-HMAC-SHA256"
+
+	PresignExpiresQuery   = "X-Sos-Expires"
+	PresignPrincipalQuery = "X-Sos-Principal"
+	PresignNameQuery      = "X-Sos-Name"
+	PresignSignatureQuery = "X-Sos-Signature"
+)
+
+var ErrPresignExpiryOutOfRange = errors.New("presign expiry is out of range")
+
+type presignedURLContextKey struct{}
+
+// PresignedURL downloads an object, or a version of it, or uploads an object of Name
+// on a path, until it expires. It acts on behalf of Principal, so policies of the principal
+// still apply. A Version of -1 is the last version.
+type PresignedURL struct {
+	Method    string
+	Group     string
+	Partition string
+	Path      string
+	ObjectID  int64
+	Version   int
+	Name      string
+	Principal string
+	Expires   time.Time
+	Signature string
+}
+
+// Query returns the query parameters that carry the signature of u.
+func (u PresignedURL) Query() url.Values {
+	query := url.Values{}
+	query.Set(PresignExpiresQuery, strconv.FormatInt(u.Expires.Unix(), 10))
+	if u.Principal != "" {
+		query.Set(PresignPrincipalQuery, u.Principal)
+	}
+	if u.Name != "" {
+		query.Set(PresignNameQuery, u.Name)
+	}
+	query.Set(PresignSignatureQuery, u.Signature)
+	return query
+}
+
+func (u PresignedURL) stringToSign() string {
+	return strings.Join([]string{
+		PresignAlgorithm,
+		u.Method,
+		u.Group,
+		u.Partition,
+		u.Path,
+		strconv.FormatInt(u.ObjectID, 10),
+		strconv.Itoa(u.Version),
+		u.Name,
+		u.Principal,
+		strconv.FormatInt(u.Expires.Unix(), 10),
+	}, "\n")
+}
+
+// IsPresignedURL reports whether r carries the signature of a presigned URL.
+func IsPresignedURL(r *http.Request) bool {
+	return r.URL.Query().Has(PresignSignatureQuery)
+}
+
+// Presigner signs URLs with a server key, so they can be handed out without a credential.
+type Presigner struct {
+	key       []byte
+	expiry    time.Duration
+	maxExpiry time.Duration
+}
+
+func NewPresigner(key string, expiry, maxExpiry time.Duration) (*Presigner, error) {
+	switch {
+	case key == "":
+		return nil, errors.New("presign key is empty")
+	case expiry <= 0:
+		return nil, errors.New("presign expiry is invalid")
+	case maxExpiry < expiry:
+		return nil, errors.New("presign max expiry is invalid")
+	}
+
+	return &Presigner{
+		key:       []byte(key),
+		expiry:    expiry,
+		maxExpiry: maxExpiry,
+	}, nil
+}
+
+// Sign returns u signed to expire after expiry, or after the default expiry when it is zero.
+func (p *Presigner) Sign(u PresignedURL, expiry time.Duration) (PresignedURL, error) {
+	if expiry == 0 {
+		expiry = p.expiry
+	}
+
+	if expiry < 0 || expiry > p.maxExpiry {
+		return PresignedURL{}, fmt.Errorf("%w. %s, max %s", ErrPresignExpiryOutOfRange, expiry, p.maxExpiry)
+	}
+
+	u.Expires = time.Now().Add(expiry).Truncate(time.Second)
+	u.Signature = hex.EncodeToString(hmacSHA256(p.key, u.stringToSign()))
+	return u, nil
+}
+
+// Verify checks the query of r against the request u it is made for, and returns u
+// with the principal, name and expiry of the signature.
+func (p *Presigner) Verify(r *http.Request, u PresignedURL) (PresignedURL, error) {
+	query := r.URL.Query()
+
+	expires, err := strconv.ParseInt(query.Get(PresignExpiresQuery), 10, 64)
+	if err != nil {
+		return PresignedURL{}, malformedQuery(PresignExpiresQuery + " is invalid")
+	}
+
+	u.Expires = time.Unix(expires, 0)
+	u.Principal = query.Get(PresignPrincipalQuery)
+	u.Name = query.Get(PresignNameQuery)
+	u.Signature = query.Get(PresignSignatureQuery)
+
+	expected := hex.EncodeToString(hmacSHA256(p.key, u.stringToSign()))
+	if !hmac.Equal([]byte(expected), []byte(u.Signature)) {
+		return PresignedURL{}, ErrSignatureDoesNotMatch
+	}
+
+	if time.Now().After(u.Expires) {
+		return PresignedURL{}, ErrExpired
+	}
+	return u, nil
+}
+
+func WithPresignedURL(c context.Context, u PresignedURL) context.Context {
+	return context.WithValue(c, presignedURLContextKey{}, u)
+}
+
+// PresignedURLFromContext returns the presigned URL a request is authenticated by.
+func PresignedURLFromContext(c context.Context) (PresignedURL, bool) {
+	u, ok := c.Value(presignedURLContextKey{}).(PresignedURL)
+	return u, ok
+}
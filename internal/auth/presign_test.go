@@ -0,0 +1,295 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testPresignKey = "presign-key"
+
+func newTestPresigner(t *testing.T) *Presigner {
+	t.Helper()
+	presigner, err := NewPresigner(testPresignKey, 15*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("NewPresigner: %v", err)
+	}
+	return presigner
+}
+
+func testPresignedURL() PresignedURL {
+	return PresignedURL{
+		Method:    http.MethodGet,
+		Group:     "g",
+		Partition: "p",
+		Path:      "/a/b",
+		ObjectID:  42,
+		Version:   -1,
+		Principal: "alice",
+	}
+}
+
+// presignedRequest makes the request a client sends with the query of u.
+func presignedRequest(u PresignedURL) *http.Request {
+	r := httptest.NewRequest(u.Method, "http://example.com/v1/g/p/a/b/42", nil)
+	r.URL.RawQuery = u.Query().Encode()
+	return r
+}
+
+func TestNewPresigner(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		expiry    time.Duration
+		maxExpiry time.Duration
+		valid     bool
+	}{
+		{name: "valid", key: testPresignKey, expiry: time.Minute, maxExpiry: time.Hour, valid: true},
+		{name: "expiry equals max expiry", key: testPresignKey, expiry: time.Hour, maxExpiry: time.Hour, valid: true},
+		{name: "empty key", expiry: time.Minute, maxExpiry: time.Hour},
+		{name: "zero expiry", key: testPresignKey, maxExpiry: time.Hour},
+		{name: "negative expiry", key: testPresignKey, expiry: -time.Minute, maxExpiry: time.Hour},
+		{name: "max expiry shorter than expiry", key: testPresignKey, expiry: time.Hour, maxExpiry: time.Minute},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewPresigner(test.key, test.expiry, test.maxExpiry)
+			if (err == nil) != test.valid {
+				t.Fatalf("error %v, valid %t", err, test.valid)
+			}
+		})
+	}
+}
+
+func TestPresignerSign(t *testing.T) {
+	tests := []struct {
+		name   string
+		expiry time.Duration
+		want   time.Duration
+		err    error
+	}{
+		{name: "default expiry", want: 15 * time.Minute},
+		{name: "given expiry", expiry: 30 * time.Minute, want: 30 * time.Minute},
+		{name: "max expiry", expiry: time.Hour, want: time.Hour},
+		{name: "longer than max expiry", expiry: time.Hour + time.Second, err: ErrPresignExpiryOutOfRange},
+		{name: "negative expiry", expiry: -time.Second, err: ErrPresignExpiryOutOfRange},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before := time.Now().Truncate(time.Second)
+			signed, err := newTestPresigner(t).Sign(testPresignedURL(), test.expiry)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("error %v, want %v", err, test.err)
+			}
+			if test.err != nil {
+				return
+			}
+
+			if signed.Expires.Before(before.Add(test.want)) || signed.Expires.After(time.Now().Add(test.want)) {
+				t.Errorf("expires %s, want %s from now", signed.Expires, test.want)
+			}
+			if signed.Expires.Nanosecond() != 0 {
+				t.Errorf("expires %s is not truncated to the second", signed.Expires)
+			}
+			if signed.Signature == "" {
+				t.Errorf("signature is empty")
+			}
+		})
+	}
+}
+
+func TestPresignerVerify(t *testing.T) {
+	tests := []struct {
+		name string
+		// modify changes the request the signed URL is used for
+		modify func(u *PresignedURL)
+		// tamper changes the query of the signed URL
+		tamper func(r *http.Request)
+		// expires overrides the signed expiry
+		expires time.Duration
+		code    string
+	}{
+		{name: "valid"},
+		{name: "expired", expires: -time.Second, code: ErrExpired.Code},
+		{name: "other method", modify: func(u *PresignedURL) { u.Method = http.MethodPut }, code: ErrSignatureDoesNotMatch.Code},
+		{name: "other group", modify: func(u *PresignedURL) { u.Group = "h" }, code: ErrSignatureDoesNotMatch.Code},
+		{name: "other partition", modify: func(u *PresignedURL) { u.Partition = "q" }, code: ErrSignatureDoesNotMatch.Code},
+		{name: "other path", modify: func(u *PresignedURL) { u.Path = "/a" }, code: ErrSignatureDoesNotMatch.Code},
+		{name: "other object", modify: func(u *PresignedURL) { u.ObjectID = 43 }, code: ErrSignatureDoesNotMatch.Code},
+		{name: "other version", modify: func(u *PresignedURL) { u.Version = 1 }, code: ErrSignatureDoesNotMatch.Code},
+		{
+			name: "tampered expiry",
+			tamper: func(r *http.Request) {
+				setQuery(r, PresignExpiresQuery, strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+			},
+			code: ErrSignatureDoesNotMatch.Code,
+		},
+		{
+			name:   "malformed expiry",
+			tamper: func(r *http.Request) { setQuery(r, PresignExpiresQuery, "tomorrow") },
+			code:   "AuthorizationQueryParametersError",
+		},
+		{
+			name:   "tampered principal",
+			tamper: func(r *http.Request) { setQuery(r, PresignPrincipalQuery, "admin") },
+			code:   ErrSignatureDoesNotMatch.Code,
+		},
+		{
+			name:   "principal removed",
+			tamper: func(r *http.Request) { setQuery(r, PresignPrincipalQuery, "") },
+			code:   ErrSignatureDoesNotMatch.Code,
+		},
+		{
+			name:   "name added",
+			tamper: func(r *http.Request) { setQuery(r, PresignNameQuery, "other.txt") },
+			code:   ErrSignatureDoesNotMatch.Code,
+		},
+		{
+			name: "tampered signature",
+			tamper: func(r *http.Request) {
+				signature := []byte(r.URL.Query().Get(PresignSignatureQuery))
+				signature[len(signature)-1] ^= 1
+				setQuery(r, PresignSignatureQuery, string(signature))
+			},
+			code: ErrSignatureDoesNotMatch.Code,
+		},
+		{
+			name:   "signature missing",
+			tamper: func(r *http.Request) { setQuery(r, PresignSignatureQuery, "") },
+			code:   ErrSignatureDoesNotMatch.Code,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			presigner := newTestPresigner(t)
+			signed, err := presigner.Sign(testPresignedURL(), 0)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			// an expiry in the past can not be asked for, so the signature is made for it here
+			if test.expires != 0 {
+				signed.Expires = time.Now().Add(test.expires).Truncate(time.Second)
+				signed.Signature = hexHMAC([]byte(testPresignKey), signed.stringToSign())
+			}
+
+			r := presignedRequest(signed)
+			if test.tamper != nil {
+				test.tamper(r)
+			}
+
+			target := testPresignedURL()
+			if test.modify != nil {
+				test.modify(&target)
+			}
+
+			verified, err := presigner.Verify(r, target)
+			if code := errorCode(err); code != test.code {
+				t.Fatalf("error %q, want %q", code, test.code)
+			}
+			if test.code != "" {
+				return
+			}
+
+			if verified.Principal != signed.Principal || !verified.Expires.Equal(signed.Expires) {
+				t.Errorf("verified %+v, want %+v", verified, signed)
+			}
+		})
+	}
+}
+
+func TestPresignerVerifyOtherKey(t *testing.T) {
+	signed, err := newTestPresigner(t).Sign(testPresignedURL(), 0)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	other, err := NewPresigner("other-key", 15*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("NewPresigner: %v", err)
+	}
+
+	if _, err := other.Verify(presignedRequest(signed), testPresignedURL()); err != ErrSignatureDoesNotMatch {
+		t.Fatalf("error %v, want %v", err, ErrSignatureDoesNotMatch)
+	}
+}
+
+func TestPresignedURLQuery(t *testing.T) {
+	expires := time.Unix(1700000000, 0)
+	tests := []struct {
+		name string
+		url  PresignedURL
+		want map[string]string
+	}{
+		{
+			name: "download",
+			url:  PresignedURL{Principal: "alice", Expires: expires, Signature: "abc"},
+			want: map[string]string{
+				PresignExpiresQuery: "1700000000", PresignPrincipalQuery: "alice", PresignSignatureQuery: "abc",
+			},
+		},
+		{
+			name: "upload with a name",
+			url:  PresignedURL{Principal: "alice", Name: "a.txt", Expires: expires, Signature: "abc"},
+			want: map[string]string{
+				PresignExpiresQuery: "1700000000", PresignPrincipalQuery: "alice", PresignNameQuery: "a.txt", PresignSignatureQuery: "abc",
+			},
+		},
+		{
+			name: "without principal",
+			url:  PresignedURL{Expires: expires, Signature: "abc"},
+			want: map[string]string{PresignExpiresQuery: "1700000000", PresignSignatureQuery: "abc"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query := test.url.Query()
+			if len(query) != len(test.want) {
+				t.Fatalf("query %v, want %v", query, test.want)
+			}
+			for key, value := range test.want {
+				if query.Get(key) != value {
+					t.Errorf("%s %q, want %q", key, query.Get(key), value)
+				}
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "http://example.com/?"+query.Encode(), nil)
+			if !IsPresignedURL(r) {
+				t.Errorf("request is not presigned")
+			}
+		})
+	}
+
+	if IsPresignedURL(httptest.NewRequest(http.MethodGet, "http://example.com/?"+PresignExpiresQuery+"=1", nil)) {
+		t.Errorf("request without signature is presigned")
+	}
+}
@@ -0,0 +1,68 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package auth
+
+import (
+	"context"
+)
+
+type principalContextKey struct{}
+
+type chunkVerifierContextKey struct{}
+
+// Principal is the identity a request is authenticated as.
+type Principal struct {
+	Name      string
+	AccessKey string
+}
+
+// Credential is an access key with its secret and the principal it authenticates.
+type Credential struct {
+	AccessKey string
+	SecretKey string
+	Principal string
+}
+
+type CredentialProvider interface {
+	Credential(c context.Context, accessKey string) (Credential, error)
+}
+
+func WithPrincipal(c context.Context, principal Principal) context.Context {
+	return context.WithValue(c, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated principal. It is not found when authentication is disabled.
+func PrincipalFromContext(c context.Context) (Principal, bool) {
+	principal, ok := c.Value(principalContextKey{}).(Principal)
+	return principal, ok
+}
+
+func WithChunkVerifier(c context.Context, verifier *ChunkVerifier) context.Context {
+	return context.WithValue(c, chunkVerifierContextKey{}, verifier)
+}
+
+// ChunkVerifierFromContext returns the verifier of a signed aws-chunked body, nil for any other body.
+func ChunkVerifierFromContext(c context.Context) *ChunkVerifier {
+	verifier, _ := c.Value(chunkVerifierContextKey{}).(*ChunkVerifier)
+	return verifier
+}
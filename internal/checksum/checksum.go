@@ -25,6 +25,8 @@ package checksum
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"hash"
+	"io"
 )
 
 func Calculate(data []byte) string {
@@ -38,3 +40,39 @@ func Verify(expect string, data []byte) bool {
 	calculatedChecksum := Calculate(data)
 	return calculatedChecksum == expect
 }
+
+// Combine calculates a checksum of checksums, e.g. of an object from the checksums of its parts.
+func Combine(checksums []string) string {
+	hash := sha256.New()
+	for _, checksum := range checksums {
+		hash.Write([]byte(checksum))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Reader calculates the checksum of everything read through it.
+type Reader struct {
+	reader io.ReadCloser
+	hash   hash.Hash
+}
+
+func NewReader(reader io.ReadCloser) *Reader {
+	return &Reader{
+		reader: reader,
+		hash:   sha256.New(),
+	}
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.hash.Write(p[:n])
+	return n, err
+}
+
+func (r *Reader) Close() error {
+	return r.reader.Close()
+}
+
+func (r *Reader) Checksum() string {
+	return hex.EncodeToString(r.hash.Sum(nil))
+}
@@ -0,0 +1,149 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec compresses the blocks of an object. No codec stores them as they are.
+type Codec string
+
+const (
+	None   Codec = ""
+	Zstd   Codec = "zstd"
+	Snappy Codec = "snappy"
+	Gzip   Codec = "gzip"
+)
+
+var ErrUnknownCodec = errors.New("compression codec is unknown")
+
+// the zstd encoder and decoder are safe for concurrent use and costly to create
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+		return zstd.NewWriter(nil)
+	})
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil)
+	})
+)
+
+func ParseCodec(name string) (Codec, error) {
+	codec := Codec(name)
+	switch codec {
+	case None, Zstd, Snappy, Gzip:
+		return codec, nil
+	}
+	return None, fmt.Errorf("%w. %s", ErrUnknownCodec, name)
+}
+
+func Compress(codec Codec, data []byte) ([]byte, error) {
+	switch codec {
+	case None:
+		return data, nil
+	case Zstd:
+		encoder, err := zstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+		return encoder.EncodeAll(data, nil), nil
+	case Snappy:
+		return snappy.Encode(nil, data), nil
+	case Gzip:
+		var buffer bytes.Buffer
+		writer := gzip.NewWriter(&buffer)
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	}
+	return nil, fmt.Errorf("%w. %s", ErrUnknownCodec, codec)
+}
+
+// Decompress restores data compressed with the codec, and fails when it does not restore to size bytes.
+func Decompress(codec Codec, data []byte, size int) ([]byte, error) {
+	var decompressed []byte
+	var err error
+	switch codec {
+	case None:
+		decompressed = data
+	case Zstd:
+		decompressed, err = decompressZstd(data, size)
+	case Snappy:
+		decompressed, err = decompressSnappy(data, size)
+	case Gzip:
+		decompressed, err = decompressGzip(data, size)
+	default:
+		err = fmt.Errorf("%w. %s", ErrUnknownCodec, codec)
+	}
+
+	switch {
+	case err != nil:
+		return nil, err
+	case len(decompressed) != size:
+		return nil, fmt.Errorf("decompressed size is invalid(%d / %d)", len(decompressed), size)
+	}
+	return decompressed, nil
+}
+
+func decompressZstd(data []byte, size int) ([]byte, error) {
+	decoder, err := zstdDecoder()
+	if err != nil {
+		return nil, err
+	}
+	return decoder.DecodeAll(data, make([]byte, 0, size))
+}
+
+func decompressSnappy(data []byte, size int) ([]byte, error) {
+	// the length is checked first, so corrupted data never allocates more than the block
+	length, err := snappy.DecodedLen(data)
+	switch {
+	case err != nil:
+		return nil, err
+	case length != size:
+		return nil, fmt.Errorf("decompressed size is invalid(%d / %d)", length, size)
+	}
+	return snappy.Decode(make([]byte, size), data)
+}
+
+func decompressGzip(data []byte, size int) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// one byte more than the block is read, so a longer stream is noticed without reading all of it
+	return io.ReadAll(io.LimitReader(reader, int64(size)+1))
+}
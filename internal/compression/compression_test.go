@@ -0,0 +1,133 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+var codecs = []Codec{None, Zstd, Snappy, Gzip}
+
+func testInputs() map[string][]byte {
+	random := make([]byte, 64*1024)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	return map[string][]byte{
+		"empty":  {},
+		"byte":   {0x2a},
+		"text":   bytes.Repeat([]byte("GET /api/v1/items 200 latency_ms=12\n"), 2000),
+		"zeros":  make([]byte, 4*1024*1024),
+		"random": random,
+		"mixed":  append(bytes.Repeat([]byte{7}, 1000), random[:1000]...),
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, codec := range codecs {
+		for name, data := range testInputs() {
+			compressed, err := Compress(codec, data)
+			if err != nil {
+				t.Fatalf("%q %s: compress: %v", codec, name, err)
+			}
+
+			restored, err := Decompress(codec, compressed, len(data))
+			if err != nil {
+				t.Fatalf("%q %s: decompress: %v", codec, name, err)
+			}
+			if !bytes.Equal(restored, data) {
+				t.Errorf("%q %s: restored %d bytes differ from %d", codec, name, len(restored), len(data))
+			}
+		}
+	}
+}
+
+func TestCompressShrinksRepeatedData(t *testing.T) {
+	data := testInputs()["text"]
+	for _, codec := range codecs[1:] {
+		compressed, err := Compress(codec, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(compressed) >= len(data)/10 {
+			t.Errorf("%s compressed %d bytes to %d", codec, len(data), len(compressed))
+		}
+	}
+}
+
+// The size recorded with the block is checked, so a block decompressing to anything else is
+// rejected instead of being served with the wrong length.
+func TestDecompressWrongSize(t *testing.T) {
+	data := testInputs()["text"]
+	for _, codec := range codecs {
+		compressed, err := Compress(codec, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, size := range []int{len(data) - 1, len(data) + 1, 0} {
+			if _, err := Decompress(codec, compressed, size); err == nil {
+				t.Errorf("%q: decompressing %d bytes as %d succeeded", codec, len(data), size)
+			}
+		}
+	}
+}
+
+func TestDecompressCorrupt(t *testing.T) {
+	data := testInputs()["text"]
+	for _, codec := range codecs[1:] {
+		compressed, err := Compress(codec, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		truncated := compressed[:len(compressed)/2]
+		if _, err := Decompress(codec, truncated, len(data)); err == nil {
+			t.Errorf("%s: decompressing truncated data succeeded", codec)
+		}
+
+		if _, err := Decompress(codec, []byte("not compressed at all"), len(data)); err == nil {
+			t.Errorf("%s: decompressing garbage succeeded", codec)
+		}
+	}
+}
+
+func TestParseCodec(t *testing.T) {
+	for _, codec := range codecs {
+		parsed, err := ParseCodec(string(codec))
+		if err != nil || parsed != codec {
+			t.Errorf("ParseCodec(%q) = %q, %v", codec, parsed, err)
+		}
+	}
+
+	if _, err := ParseCodec("lz4"); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("ParseCodec(lz4): %v, want %v", err, ErrUnknownCodec)
+	}
+	if _, err := Compress("lz4", []byte("data")); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("Compress(lz4): %v, want %v", err, ErrUnknownCodec)
+	}
+	if _, err := Decompress("lz4", []byte("data"), 4); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("Decompress(lz4): %v, want %v", err, ErrUnknownCodec)
+	}
+}
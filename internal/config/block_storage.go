@@ -24,11 +24,59 @@ SOFTWARE.
 
 package config
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/ISSuh/sos/internal/compression"
+)
+
+const (
+	defaultScrubInterval       = 24 * time.Hour
+	defaultScrubBytesPerSecond = 16 * 1024 * 1024
+	defaultBlockSize           = 4 * 1024 * 1024
+)
+
 type BlockStorageConfig struct {
-	APM      APM      `yaml:"apm"`
-	Log      Logger   `yaml:"logger"`
-	Address  Address  `yaml:"address"`
-	Database Database `yaml:"db"`
+	APM           APM                 `yaml:"apm"`
+	Log           Logger              `yaml:"logger"`
+	Address       Address             `yaml:"address"`
+	Database      Database            `yaml:"db"`
+	Nodes         []Address           `yaml:"nodes"`
+	Replication   int                 `yaml:"replication"`
+	ErasureCoding []ErasureCodingRule `yaml:"erasure_coding"`
+	TLS           TLS                 `yaml:"tls"`
+	Scrub         Scrub               `yaml:"scrub"`
+
+	// Deduplication stores the blocks of replicated objects once per content, identical
+	// blocks of any object share the stored data through a reference count. Erasure coded objects
+	// and the ones encrypted with a customer key are stored as they are, and it can not be enabled
+	// along with encryption at rest.
+	Deduplication bool `yaml:"deduplication"`
+
+	// Compression compresses the blocks of the objects matching a rule, the first matching rule applies.
+	Compression []CompressionRule `yaml:"compression"`
+
+	// BlockSize is the size in bytes objects are split into, blocks are streamed to the nodes in chunks
+	// so it is not bound by the grpc message limit. It must be the same on every explorer of a deployment.
+	BlockSize int `yaml:"block_size"`
+}
+
+// ErasureCodingRule stores the blocks of a group, or of a single partition when set, as k data + m parity shards.
+type ErasureCodingRule struct {
+	Group        string `yaml:"group"`
+	Partition    string `yaml:"partition"`
+	DataShards   int    `yaml:"data_shards"`
+	ParityShards int    `yaml:"parity_shards"`
+}
+
+// CompressionRule compresses the blocks of the objects on a group, on a single partition of it when set, and
+// of the content types when set, with zstd, snappy or gzip. e.g. "application/json" or "text/*"
+type CompressionRule struct {
+	Group        string   `yaml:"group"`
+	Partition    string   `yaml:"partition"`
+	ContentTypes []string `yaml:"content_types"`
+	Codec        string   `yaml:"codec"`
 }
 
 func (c BlockStorageConfig) Validate(isStandalone bool) error {
@@ -38,5 +86,142 @@ func (c BlockStorageConfig) Validate(isStandalone bool) error {
 		}
 	}
 
+	if err := c.TLS.ValidateServer(); err != nil {
+		return err
+	}
+
+	if isStandalone && c.Scrub.Enabled {
+		return fmt.Errorf("standalone does not support scrub")
+	}
+
+	if err := c.Scrub.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NodeHosts returns the block storage nodes the explorer writes to.
+// A single address is used as the only node when nodes are not configured.
+func (c BlockStorageConfig) NodeHosts() []string {
+	if len(c.Nodes) == 0 {
+		return []string{c.Address.Host}
+	}
+
+	hosts := make([]string, 0, len(c.Nodes))
+	for _, node := range c.Nodes {
+		hosts = append(hosts, node.Host)
+	}
+	return hosts
+}
+
+func (c BlockStorageConfig) ReplicationOrDefault() int {
+	if c.Replication == 0 {
+		return 1
+	}
+	return c.Replication
+}
+
+func (c BlockStorageConfig) BlockSizeOrDefault() int {
+	if c.BlockSize == 0 {
+		return defaultBlockSize
+	}
+	return c.BlockSize
+}
+
+func (c BlockStorageConfig) ValidateNodes(isStandalone bool) error {
+	if c.BlockSize < 0 {
+		return fmt.Errorf("block size is invalid. %d", c.BlockSize)
+	}
+
+	for _, rule := range c.Compression {
+		switch codec, err := compression.ParseCodec(rule.Codec); {
+		case err != nil:
+			return err
+		case codec == compression.None:
+			return fmt.Errorf("compression codec is empty")
+		case rule.Partition != "" && rule.Group == "":
+			return fmt.Errorf("compression group of partition %s is empty", rule.Partition)
+		}
+	}
+
+	replication := c.ReplicationOrDefault()
+	switch {
+	case replication < 0:
+		return fmt.Errorf("block storage replication is invalid. %d", replication)
+	case isStandalone && replication > 1:
+		return fmt.Errorf("standalone supports only one block storage node")
+	case isStandalone && len(c.ErasureCoding) > 0:
+		return fmt.Errorf("standalone does not support erasure coding")
+	case isStandalone:
+		return nil
+	}
+
+	hosts := c.NodeHosts()
+	if replication > len(hosts) {
+		return fmt.Errorf("block storage replication %d is larger than the number of nodes %d", replication, len(hosts))
+	}
+
+	for _, host := range hosts {
+		if host == "" {
+			return fmt.Errorf("block storage node host is empty")
+		}
+	}
+
+	for _, rule := range c.ErasureCoding {
+		switch {
+		case rule.Group == "":
+			return fmt.Errorf("erasure coding group is empty")
+		case rule.DataShards < 1 || rule.ParityShards < 1:
+			return fmt.Errorf("erasure coding shards are invalid. %d+%d", rule.DataShards, rule.ParityShards)
+		case rule.DataShards+rule.ParityShards > len(hosts):
+			return fmt.Errorf("erasure coding needs %d nodes but has %d", rule.DataShards+rule.ParityShards, len(hosts))
+		}
+	}
 	return nil
 }
+
+// Scrub re-verifies the checksum of every stored block each Interval, reading at most
+// BytesPerSecond. Corrupted blocks are repaired from the replicas on Peers, which are reached
+// with TLS, and are moved aside when Quarantine is set so they are no longer served.
+type Scrub struct {
+	Enabled        bool          `yaml:"enabled"`
+	Interval       time.Duration `yaml:"interval"`
+	BytesPerSecond int64         `yaml:"bytes_per_second"`
+	Quarantine     bool          `yaml:"quarantine"`
+	Peers          []Address     `yaml:"peers"`
+	TLS            TLS           `yaml:"tls"`
+}
+
+func (c Scrub) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	switch {
+	case c.Interval < 0:
+		return fmt.Errorf("scrub interval is invalid. %s", c.Interval)
+	case c.BytesPerSecond < 0:
+		return fmt.Errorf("scrub bytes per second is invalid. %d", c.BytesPerSecond)
+	}
+
+	for _, peer := range c.Peers {
+		if peer.Host == "" {
+			return fmt.Errorf("scrub peer host is empty")
+		}
+	}
+	return c.TLS.ValidateClient()
+}
+
+func (c Scrub) IntervalOrDefault() time.Duration {
+	if c.Interval == 0 {
+		return defaultScrubInterval
+	}
+	return c.Interval
+}
+
+func (c Scrub) BytesPerSecondOrDefault() int64 {
+	if c.BytesPerSecond == 0 {
+		return defaultScrubBytesPerSecond
+	}
+	return c.BytesPerSecond
+}
@@ -55,6 +55,12 @@ func (c Config) Validate(appType ApplicationType) error {
 		if err := c.SOS.Explorer.Validate(c.SOS.Standalone); err != nil {
 			return err
 		}
+		if err := c.SOS.Explorer.ValidateDeduplication(c.SOS.BlockStorage); err != nil {
+			return err
+		}
+		if err := c.SOS.BlockStorage.ValidateNodes(c.SOS.Standalone); err != nil {
+			return err
+		}
 	case MetadataRegistry:
 		if err := c.SOS.MetadataRegistry.Validate(c.SOS.Standalone); err != nil {
 			return err
@@ -73,6 +79,12 @@ func (c Config) Validate(appType ApplicationType) error {
 		if err := c.SOS.BlockStorage.Validate(c.SOS.Standalone); err != nil {
 			return err
 		}
+		if err := c.SOS.BlockStorage.ValidateNodes(c.SOS.Standalone); err != nil {
+			return err
+		}
+		if err := c.SOS.Explorer.ValidateDeduplication(c.SOS.BlockStorage); err != nil {
+			return err
+		}
 	}
 
 	return nil
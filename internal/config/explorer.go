@@ -22,10 +22,35 @@
 
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultUploadSessionExpiry = 24 * time.Hour
+	defaultUploadSweepInterval = 10 * time.Minute
+	defaultUploadConcurrency   = 4
+	defaultAuthMaxClockSkew    = 15 * time.Minute
+	defaultPresignExpiry       = 15 * time.Minute
+	defaultPresignMaxExpiry    = 7 * 24 * time.Hour
+	defaultGCInterval          = 24 * time.Hour
+	defaultGCGracePeriod       = 24 * time.Hour
+)
+
 type ExplorerConfig struct {
 	APM     APM     `yaml:"apm"`
 	Log     Logger  `yaml:"logger"`
 	Address Address `yaml:"address"`
+	Upload  Upload  `yaml:"upload"`
+	S3      S3      `yaml:"s3"`
+	Auth    Auth    `yaml:"auth"`
+	Presign Presign `yaml:"presign"`
+	GC      GC      `yaml:"gc"`
+	// Encryption encrypts the blocks of new object versions at rest
+	Encryption Encryption `yaml:"encryption"`
+	// TLS is the client side of the connections to the metadata registry and block storage
+	TLS TLS `yaml:"tls"`
 }
 
 func (c ExplorerConfig) Validate(isStandalone bool) error {
@@ -35,5 +60,251 @@ func (c ExplorerConfig) Validate(isStandalone bool) error {
 		}
 	}
 
+	if err := c.Upload.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.S3.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Auth.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Presign.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.GC.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Encryption.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.TLS.ValidateClient(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidateDeduplication rejects deduplication along with encryption at rest, which seals the blocks of
+// every version with a data key of its own, so no block would ever be deduplicated.
+func (c ExplorerConfig) ValidateDeduplication(storage BlockStorageConfig) error {
+	if storage.Deduplication && c.Encryption.Enabled {
+		return fmt.Errorf("block storage deduplication can not be enabled along with encryption at rest")
+	}
+	return nil
+}
+
+// Upload expires the multipart upload sessions and the versions reserved by uploads after
+// SessionExpiry, which the sweeper releases every SweepInterval.
+type Upload struct {
+	SessionExpiry time.Duration `yaml:"session_expiry"`
+	SweepInterval time.Duration `yaml:"sweep_interval"`
+	// Concurrency is the number of blocks of an object sent to or fetched from the block storage at once
+	Concurrency int `yaml:"concurrency"`
+}
+
+func (c Upload) Validate() error {
+	if c.SessionExpiry < 0 {
+		return fmt.Errorf("upload session expiry is invalid. %s", c.SessionExpiry)
+	}
+
+	if c.SweepInterval < 0 {
+		return fmt.Errorf("upload sweep interval is invalid. %s", c.SweepInterval)
+	}
+
+	if c.Concurrency < 0 {
+		return fmt.Errorf("upload concurrency is invalid. %d", c.Concurrency)
+	}
+	return nil
+}
+
+func (c Upload) SessionExpiryOrDefault() time.Duration {
+	if c.SessionExpiry == 0 {
+		return defaultUploadSessionExpiry
+	}
+	return c.SessionExpiry
+}
+
+func (c Upload) SweepIntervalOrDefault() time.Duration {
+	if c.SweepInterval == 0 {
+		return defaultUploadSweepInterval
+	}
+	return c.SweepInterval
+}
+
+func (c Upload) ConcurrencyOrDefault() int {
+	if c.Concurrency == 0 {
+		return defaultUploadConcurrency
+	}
+	return c.Concurrency
+}
+
+// S3 serves the S3 compatible gateway on its own address.
+type S3 struct {
+	Enabled bool       `yaml:"enabled"`
+	Address Address    `yaml:"address"`
+	Buckets []S3Bucket `yaml:"buckets"`
+}
+
+// S3Bucket maps a bucket name to the group and partition that hold its objects.
+type S3Bucket struct {
+	Name      string `yaml:"name"`
+	Group     string `yaml:"group"`
+	Partition string `yaml:"partition"`
+}
+
+func (c S3) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if err := c.Address.Validate(); err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{}, len(c.Buckets))
+	for _, bucket := range c.Buckets {
+		switch {
+		case bucket.Name == "":
+			return fmt.Errorf("s3 bucket name is empty")
+		case bucket.Group == "" || bucket.Partition == "":
+			return fmt.Errorf("s3 bucket %s has no group or partition", bucket.Name)
+		}
+
+		if _, exist := names[bucket.Name]; exist {
+			return fmt.Errorf("s3 bucket %s is duplicated", bucket.Name)
+		}
+		names[bucket.Name] = struct{}{}
+	}
+	return nil
+}
+
+// Auth requires every request to the explorer and the S3 gateway to be signed with
+// AWS signature version 4 by a credential of the metadata registry.
+// An empty region accepts a signature scoped to any region.
+// Admins are the principals that bypass policies and manage them.
+type Auth struct {
+	Enabled      bool          `yaml:"enabled"`
+	Region       string        `yaml:"region"`
+	MaxClockSkew time.Duration `yaml:"max_clock_skew"`
+	Admins       []string      `yaml:"admins"`
+}
+
+func (c Auth) Validate() error {
+	if c.MaxClockSkew < 0 {
+		return fmt.Errorf("auth max clock skew is invalid. %s", c.MaxClockSkew)
+	}
+	return nil
+}
+
+func (c Auth) MaxClockSkewOrDefault() time.Duration {
+	if c.MaxClockSkew == 0 {
+		return defaultAuthMaxClockSkew
+	}
+	return c.MaxClockSkew
+}
+
+// Presign issues URLs signed with Key that download or upload a single object without
+// a credential until they expire. Explorers behind the same address must share the key.
+type Presign struct {
+	Enabled   bool          `yaml:"enabled"`
+	Key       string        `yaml:"key"`
+	Expiry    time.Duration `yaml:"expiry"`
+	MaxExpiry time.Duration `yaml:"max_expiry"`
+}
+
+func (c Presign) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	switch {
+	case c.Key == "":
+		return fmt.Errorf("presign key is empty")
+	case c.Expiry < 0:
+		return fmt.Errorf("presign expiry is invalid. %s", c.Expiry)
+	case c.MaxExpiry < 0:
+		return fmt.Errorf("presign max expiry is invalid. %s", c.MaxExpiry)
+	case c.ExpiryOrDefault() > c.MaxExpiryOrDefault():
+		return fmt.Errorf("presign expiry %s is longer than max expiry %s", c.ExpiryOrDefault(), c.MaxExpiryOrDefault())
+	}
+	return nil
+}
+
+func (c Presign) ExpiryOrDefault() time.Duration {
+	if c.Expiry == 0 {
+		return defaultPresignExpiry
+	}
+	return c.Expiry
+}
+
+func (c Presign) MaxExpiryOrDefault() time.Duration {
+	if c.MaxExpiry == 0 {
+		return defaultPresignMaxExpiry
+	}
+	return c.MaxExpiry
+}
+
+// GC deletes the blocks no object or upload session references once they are older than
+// GracePeriod, every Interval. The periodic runs only report the orphans in DryRun.
+// Running it on a single explorer is enough.
+type GC struct {
+	Enabled     bool          `yaml:"enabled"`
+	Interval    time.Duration `yaml:"interval"`
+	GracePeriod time.Duration `yaml:"grace_period"`
+	DryRun      bool          `yaml:"dry_run"`
+}
+
+func (c GC) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	switch {
+	case c.Interval < 0:
+		return fmt.Errorf("gc interval is invalid. %s", c.Interval)
+	case c.GracePeriod < 0:
+		return fmt.Errorf("gc grace period is invalid. %s", c.GracePeriod)
+	}
+	return nil
+}
+
+func (c GC) IntervalOrDefault() time.Duration {
+	if c.Interval == 0 {
+		return defaultGCInterval
+	}
+	return c.Interval
+}
+
+func (c GC) GracePeriodOrDefault() time.Duration {
+	if c.GracePeriod == 0 {
+		return defaultGCGracePeriod
+	}
+	return c.GracePeriod
+}
+
+// Encryption encrypts every block of a new object version with a data key of the version, which is
+// kept wrapped by the primary master key of KeyFile. Rotating the master key wraps the data keys
+// again without rewriting any block, so a retired master key stays in KeyFile until the rotation ran.
+type Encryption struct {
+	Enabled bool   `yaml:"enabled"`
+	KeyFile string `yaml:"key_file"`
+}
+
+func (c Encryption) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	switch {
+	case c.KeyFile == "":
+		return fmt.Errorf("encryption key file is empty")
+	}
 	return nil
 }
@@ -24,11 +24,24 @@ SOFTWARE.
 
 package config
 
+import (
+	"fmt"
+)
+
 type MetadataRegistryConfig struct {
-	APM      APM      `yaml:"apm"`
-	Log      Logger   `yaml:"logger"`
-	Address  Address  `yaml:"address"`
-	Database Database `yaml:"db"`
+	APM         APM          `yaml:"apm"`
+	Log         Logger       `yaml:"logger"`
+	Address     Address      `yaml:"address"`
+	Database    Database     `yaml:"db"`
+	Credentials []Credential `yaml:"credentials"`
+	TLS         TLS          `yaml:"tls"`
+}
+
+// Credential is stored in the registry on start, replacing the secret of an existing access key.
+type Credential struct {
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+	Principal string `yaml:"principal"`
 }
 
 func (c MetadataRegistryConfig) Validate(isStandalone bool) error {
@@ -41,5 +54,15 @@ func (c MetadataRegistryConfig) Validate(isStandalone bool) error {
 	if err := c.Database.Validate(); err != nil {
 		return err
 	}
+
+	if err := c.TLS.ValidateServer(); err != nil {
+		return err
+	}
+
+	for _, credential := range c.Credentials {
+		if credential.AccessKey == "" || credential.SecretKey == "" {
+			return fmt.Errorf("credential access key or secret key is empty")
+		}
+	}
 	return nil
 }
@@ -0,0 +1,67 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+)
+
+// TLS secures gRPC between the explorer and the metadata registry and block storage.
+// A server presents Cert and Key, and requires a client certificate signed by CA when it is set,
+// which makes it mutual TLS. AllowedClients limits the callers to client certificates of those
+// common names or DNS names, e.g. only explorers may call block storage.
+// A client verifies the server by CA, or by the system roots when it is empty, and presents
+// Cert and Key when they are set. ServerName overrides the name verified in the server certificate.
+type TLS struct {
+	Enabled        bool     `yaml:"enabled"`
+	Cert           string   `yaml:"cert"`
+	Key            string   `yaml:"key"`
+	CA             string   `yaml:"ca"`
+	ServerName     string   `yaml:"server_name"`
+	AllowedClients []string `yaml:"allowed_clients"`
+}
+
+func (c TLS) ValidateServer() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	switch {
+	case c.Cert == "" || c.Key == "":
+		return fmt.Errorf("tls cert or key is empty")
+	case len(c.AllowedClients) > 0 && c.CA == "":
+		return fmt.Errorf("tls allowed clients need a ca to verify client certificates")
+	}
+	return nil
+}
+
+func (c TLS) ValidateClient() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if (c.Cert == "") != (c.Key == "") {
+		return fmt.Errorf("tls cert and key must be set together")
+	}
+	return nil
+}
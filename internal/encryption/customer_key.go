@@ -0,0 +1,57 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package encryption
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// CustomerKeyAlgorithm is the algorithm a client names along with its key.
+const CustomerKeyAlgorithm = "AES256"
+
+var ErrInvalidCustomerKey = errors.New("customer key is invalid")
+
+// ParseCustomerKey decodes the base64 encoded key a client encrypts its object with, and checks it against
+// the base64 encoded MD5 digest sent along. The digest identifies the key without revealing it.
+func ParseCustomerKey(algorithm, key, keyMD5 string) ([]byte, string, error) {
+	switch {
+	case algorithm != CustomerKeyAlgorithm:
+		return nil, "", fmt.Errorf("%w. algorithm %q is not supported", ErrInvalidCustomerKey, algorithm)
+	case key == "" || keyMD5 == "":
+		return nil, "", fmt.Errorf("%w. key and its MD5 digest are required", ErrInvalidCustomerKey)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(decoded) != KeySize {
+		return nil, "", fmt.Errorf("%w. key is not a base64 encoded %d byte key", ErrInvalidCustomerKey, KeySize)
+	}
+
+	digest := md5.Sum(decoded)
+	if base64.StdEncoding.EncodeToString(digest[:]) != keyMD5 {
+		return nil, "", fmt.Errorf("%w. MD5 digest does not match the key", ErrInvalidCustomerKey)
+	}
+	return decoded, keyMD5, nil
+}
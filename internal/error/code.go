@@ -23,5 +23,8 @@
 package error
 
 var (
-	NotFound error = NewNotFoundError(nil)
+	NotFound           error = NewNotFoundError(nil)
+	Forbidden          error = NewForbiddenError(nil)
+	Conflict           error = NewConflictError(nil)
+	PreconditionFailed error = NewPreconditionFailedError(nil)
 )
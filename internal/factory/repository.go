@@ -53,6 +53,74 @@ func NewObjectMetadataRepository(l log.Logger, dbConfig config.Database) (reposi
 	}
 }
 
+func NewObjectDirectoryRepository(l log.Logger, dbConfig config.Database) (repository.ObjectDirectory, error) {
+	switch dbConfig.Type {
+	case config.DatabaseTypeLocal:
+		l.Infof("[NewObjectDirectoryRepository] use local db")
+		return local.NewLocalObjectDirectory()
+	case config.DatabaseTypeMongoDB:
+		l.Infof("[NewObjectDirectoryRepository] use mongodb")
+		db, err := persistence.ConnectMongoDB(context.Background(), dbConfig)
+		if err != nil {
+			return nil, err
+		}
+		return mongo.NewMongoDBObjectDirectory(db)
+	default:
+		return nil, fmt.Errorf("invalid database type")
+	}
+}
+
+func NewUploadSessionRepository(l log.Logger, dbConfig config.Database) (repository.UploadSession, error) {
+	switch dbConfig.Type {
+	case config.DatabaseTypeLocal:
+		l.Infof("[NewUploadSessionRepository] use local db")
+		return local.NewLocalUploadSession()
+	case config.DatabaseTypeMongoDB:
+		l.Infof("[NewUploadSessionRepository] use mongodb")
+		db, err := persistence.ConnectMongoDB(context.Background(), dbConfig)
+		if err != nil {
+			return nil, err
+		}
+		return mongo.NewMongoDBUploadSession(db)
+	default:
+		return nil, fmt.Errorf("invalid database type")
+	}
+}
+
+func NewCredentialRepository(l log.Logger, dbConfig config.Database) (repository.Credential, error) {
+	switch dbConfig.Type {
+	case config.DatabaseTypeLocal:
+		l.Infof("[NewCredentialRepository] use local db")
+		return local.NewLocalCredential()
+	case config.DatabaseTypeMongoDB:
+		l.Infof("[NewCredentialRepository] use mongodb")
+		db, err := persistence.ConnectMongoDB(context.Background(), dbConfig)
+		if err != nil {
+			return nil, err
+		}
+		return mongo.NewMongoDBCredential(db)
+	default:
+		return nil, fmt.Errorf("invalid database type")
+	}
+}
+
+func NewPolicyRepository(l log.Logger, dbConfig config.Database) (repository.Policy, error) {
+	switch dbConfig.Type {
+	case config.DatabaseTypeLocal:
+		l.Infof("[NewPolicyRepository] use local db")
+		return local.NewLocalPolicy()
+	case config.DatabaseTypeMongoDB:
+		l.Infof("[NewPolicyRepository] use mongodb")
+		db, err := persistence.ConnectMongoDB(context.Background(), dbConfig)
+		if err != nil {
+			return nil, err
+		}
+		return mongo.NewMongoDBPolicy(db)
+	default:
+		return nil, fmt.Errorf("invalid database type")
+	}
+}
+
 func NewObjectStorageRepository(l log.Logger, dbConfig config.Database) (repository.ObjectStorage, error) {
 	switch dbConfig.Type {
 	case config.DatabaseTypeLocal:
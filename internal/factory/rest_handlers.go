@@ -28,6 +28,9 @@ import (
 	"github.com/ISSuh/sos/domain/service"
 	"github.com/ISSuh/sos/infrastructure/transport/rest"
 	"github.com/ISSuh/sos/infrastructure/transport/rest/handler"
+	"github.com/ISSuh/sos/infrastructure/transport/s3"
+	s3handler "github.com/ISSuh/sos/infrastructure/transport/s3/handler"
+	"github.com/ISSuh/sos/internal/config"
 	"github.com/ISSuh/sos/internal/validation"
 )
 
@@ -44,3 +47,21 @@ func NewExplorerHandler(explorer service.Explorer) (rest.Explorer, error) {
 
 	return handler, nil
 }
+
+func NewS3Gateway(explorer service.Explorer, s3Config config.S3) (s3.Gateway, error) {
+	switch {
+	case validation.IsNil(explorer):
+		return nil, fmt.Errorf("explorer service is nil")
+	}
+
+	buckets := make([]s3.Bucket, 0, len(s3Config.Buckets))
+	for _, bucket := range s3Config.Buckets {
+		buckets = append(buckets, s3.Bucket{
+			Name:      bucket.Name,
+			Group:     bucket.Group,
+			Partition: bucket.Partition,
+		})
+	}
+
+	return s3handler.NewGateway(explorer, buckets)
+}
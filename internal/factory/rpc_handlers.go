@@ -32,13 +32,24 @@ import (
 	"github.com/ISSuh/sos/internal/validation"
 )
 
-func MetadataRegistryHandler(metadataService service.ObjectMetadata) ([]sosrpc.RegisterFunc, error) {
+func MetadataRegistryHandler(
+	metadataService service.ObjectMetadata, uploadSessionService service.UploadSession,
+	credentialService service.Credential, policyService service.Policy,
+) ([]sosrpc.RegisterFunc, error) {
 	switch {
 	case validation.IsNil(metadataService):
 		return nil, fmt.Errorf("ObjectMetadata service is nil")
+	case validation.IsNil(uploadSessionService):
+		return nil, fmt.Errorf("UploadSession service is nil")
+	case validation.IsNil(credentialService):
+		return nil, fmt.Errorf("Credential service is nil")
+	case validation.IsNil(policyService):
+		return nil, fmt.Errorf("Policy service is nil")
 	}
 
-	metadataHandler, err := handler.NewMetadataRegistry(metadataService)
+	metadataHandler, err := handler.NewMetadataRegistry(
+		metadataService, uploadSessionService, credentialService, policyService,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -53,13 +64,15 @@ func MetadataRegistryHandler(metadataService service.ObjectMetadata) ([]sosrpc.R
 	}, nil
 }
 
-func BlockStorageHandler(storageService service.ObjectStorage) ([]sosrpc.RegisterFunc, error) {
+func BlockStorageHandler(
+	storageService service.ObjectStorage, scrubber service.Scrubber,
+) ([]sosrpc.RegisterFunc, error) {
 	switch {
 	case validation.IsNil(storageService):
 		return nil, fmt.Errorf("ObjectStorage service is nil")
 	}
 
-	storageHandler, err := handler.NewBlockStorage(storageService)
+	storageHandler, err := handler.NewBlockStorage(storageService, scrubber)
 	if err != nil {
 		return nil, err
 	}
@@ -23,27 +23,106 @@
 package factory
 
 import (
+	"crypto/tls"
 	"fmt"
 
+	"github.com/ISSuh/sos/domain/model/entity"
+	"github.com/ISSuh/sos/domain/service/object"
 	"github.com/ISSuh/sos/infrastructure/transport/rpc"
 	"github.com/ISSuh/sos/infrastructure/transport/rpc/requestor"
+	"github.com/ISSuh/sos/internal/compression"
+	"github.com/ISSuh/sos/internal/config"
+	sosrpc "github.com/ISSuh/sos/internal/rpc"
 	"github.com/ISSuh/sos/internal/validation"
 )
 
-func NewMetadataRegistryRequestor(address string) (rpc.MetadataRegistryRequestor, error) {
+func NewMetadataRegistryRequestor(address string, tlsConfig *tls.Config) (rpc.MetadataRegistryRequestor, error) {
 	switch {
 	case validation.IsEmpty(address):
 		return nil, fmt.Errorf("address is empty")
 	}
 
-	return requestor.NewMetadataRegistry(address)
+	return requestor.NewMetadataRegistry(address, tlsConfig)
 }
 
-func NewBlockStorageRequestor(address string) (rpc.BlockStorageRequestor, error) {
+func NewBlockStorageRequestor(address string, tlsConfig *tls.Config) (rpc.BlockStorageRequestor, error) {
 	switch {
 	case validation.IsEmpty(address):
 		return nil, fmt.Errorf("address is empty")
 	}
 
-	return requestor.NewBlockStorage(address)
+	return requestor.NewBlockStorage(address, tlsConfig)
+}
+
+func NewBlockStorageCluster(
+	hosts []string, replication int, erasureCoding []config.ErasureCodingRule, deduplication bool,
+	compressionRules []config.CompressionRule, blockSize int, concurrency int, tlsConfig *tls.Config,
+) (*object.StorageCluster, error) {
+	switch {
+	case len(hosts) == 0:
+		return nil, fmt.Errorf("block storage hosts are empty")
+	}
+
+	nodes := make([]object.StorageNode, 0, len(hosts))
+	for _, host := range hosts {
+		requestor, err := NewBlockStorageRequestor(host, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, object.StorageNode{
+			Node:      entity.Node{Host: host},
+			Requestor: requestor,
+		})
+	}
+
+	rules := make([]object.ErasureCodingRule, 0, len(erasureCoding))
+	for _, rule := range erasureCoding {
+		rules = append(rules, object.ErasureCodingRule{
+			Group:     rule.Group,
+			Partition: rule.Partition,
+			Scheme: entity.ErasureCoding{
+				DataShards:   rule.DataShards,
+				ParityShards: rule.ParityShards,
+			},
+		})
+	}
+
+	return object.NewStorageCluster(
+		nodes, replication, rules, deduplication, NewCompressionRules(compressionRules), blockSize, concurrency,
+	)
+}
+
+func NewCompressionRules(compressionRules []config.CompressionRule) []object.CompressionRule {
+	rules := make([]object.CompressionRule, 0, len(compressionRules))
+	for _, rule := range compressionRules {
+		rules = append(rules, object.CompressionRule{
+			Group:        rule.Group,
+			Partition:    rule.Partition,
+			ContentTypes: rule.ContentTypes,
+			Codec:        compression.Codec(rule.Codec),
+		})
+	}
+	return rules
+}
+
+// NewRPCServer serves in plaintext when tls is disabled.
+func NewRPCServer(tlsConfig config.TLS) (sosrpc.Server, error) {
+	if !tlsConfig.Enabled {
+		return sosrpc.NewServer(nil, nil), nil
+	}
+
+	serverTLSConfig, err := sosrpc.NewServerTLSConfig(tlsConfig.Cert, tlsConfig.Key, tlsConfig.CA)
+	if err != nil {
+		return sosrpc.Server{}, err
+	}
+	return sosrpc.NewServer(serverTLSConfig, tlsConfig.AllowedClients), nil
+}
+
+// NewRPCClientTLSConfig returns nil when tls is disabled.
+func NewRPCClientTLSConfig(tlsConfig config.TLS) (*tls.Config, error) {
+	if !tlsConfig.Enabled {
+		return nil, nil
+	}
+	return sosrpc.NewClientTLSConfig(tlsConfig.Cert, tlsConfig.Key, tlsConfig.CA, tlsConfig.ServerName)
 }
@@ -25,22 +25,37 @@ package factory
 import (
 	"fmt"
 
+	"github.com/ISSuh/sos/domain/model/entity"
 	"github.com/ISSuh/sos/domain/repository"
 	"github.com/ISSuh/sos/domain/service"
+	"github.com/ISSuh/sos/domain/service/object"
 	"github.com/ISSuh/sos/infrastructure/transport/rpc"
+	"github.com/ISSuh/sos/internal/auth"
+	"github.com/ISSuh/sos/internal/config"
+	"github.com/ISSuh/sos/internal/kms"
 	"github.com/ISSuh/sos/internal/validation"
 )
 
-func NewExplorerService(metadataRequestor rpc.MetadataRegistryRequestor, storageRequestor rpc.BlockStorageRequestor,
+func NewExplorerService(metadataRequestor rpc.MetadataRegistryRequestor, storageCluster *object.StorageCluster,
+	uploadConfig config.Upload, authConfig config.Auth, presigner *auth.Presigner, collector service.GarbageCollector,
+	keyService kms.KMS,
 ) (service.Explorer, error) {
 	switch {
 	case validation.IsNil(metadataRequestor):
 		return nil, fmt.Errorf("MetadataRegistry requestor is nil")
-	case validation.IsNil(storageRequestor):
-		return nil, fmt.Errorf("BlockStorage requestor is nil")
+	case validation.IsNil(storageCluster):
+		return nil, fmt.Errorf("BlockStorage cluster is nil")
 	}
 
-	explorer, err := service.NewExplorer(metadataRequestor, storageRequestor)
+	authorizer, err := service.NewAuthorizer(metadataRequestor, authConfig.Admins)
+	if err != nil {
+		return nil, err
+	}
+
+	explorer, err := service.NewExplorer(
+		metadataRequestor, storageCluster, uploadConfig.SessionExpiryOrDefault(), authorizer, presigner, collector,
+		keyService,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -48,13 +63,54 @@ func NewExplorerService(metadataRequestor rpc.MetadataRegistryRequestor, storage
 	return explorer, nil
 }
 
-func NewObjectMetadataService(repo repository.ObjectMetadata) (service.ObjectMetadata, error) {
+func NewUploadSweeper(metadataRequestor rpc.MetadataRegistryRequestor, storageCluster *object.StorageCluster,
+) (service.UploadSweeper, error) {
+	switch {
+	case validation.IsNil(metadataRequestor):
+		return nil, fmt.Errorf("MetadataRegistry requestor is nil")
+	case validation.IsNil(storageCluster):
+		return nil, fmt.Errorf("BlockStorage cluster is nil")
+	}
+
+	sweeper, err := service.NewUploadSweeper(metadataRequestor, storageCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return sweeper, nil
+}
+
+// NewGarbageCollector returns nil when gc is disabled.
+func NewGarbageCollector(metadataRequestor rpc.MetadataRegistryRequestor, storageCluster *object.StorageCluster,
+	gcConfig config.GC,
+) (service.GarbageCollector, error) {
+	switch {
+	case validation.IsNil(metadataRequestor):
+		return nil, fmt.Errorf("MetadataRegistry requestor is nil")
+	case validation.IsNil(storageCluster):
+		return nil, fmt.Errorf("BlockStorage cluster is nil")
+	}
+
+	if !gcConfig.Enabled {
+		return nil, nil
+	}
+
+	return service.NewGarbageCollector(
+		metadataRequestor, storageCluster, gcConfig.GracePeriodOrDefault(), gcConfig.DryRun,
+	)
+}
+
+func NewObjectMetadataService(
+	repo repository.ObjectMetadata, directoryRepo repository.ObjectDirectory,
+) (service.ObjectMetadata, error) {
 	switch {
 	case validation.IsNil(repo):
 		return nil, fmt.Errorf("ObjectMetadata repository is nil")
+	case validation.IsNil(directoryRepo):
+		return nil, fmt.Errorf("ObjectDirectory repository is nil")
 	}
 
-	objectMetadata, err := service.NewObjectMetadata(repo)
+	objectMetadata, err := service.NewObjectMetadata(repo, directoryRepo)
 	if err != nil {
 		return nil, err
 	}
@@ -62,6 +118,48 @@ func NewObjectMetadataService(repo repository.ObjectMetadata) (service.ObjectMet
 	return objectMetadata, nil
 }
 
+func NewUploadSessionService(repo repository.UploadSession) (service.UploadSession, error) {
+	switch {
+	case validation.IsNil(repo):
+		return nil, fmt.Errorf("UploadSession repository is nil")
+	}
+
+	uploadSession, err := service.NewUploadSession(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadSession, nil
+}
+
+func NewCredentialService(repo repository.Credential) (service.Credential, error) {
+	switch {
+	case validation.IsNil(repo):
+		return nil, fmt.Errorf("Credential repository is nil")
+	}
+
+	credential, err := service.NewCredential(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+func NewPolicyService(repo repository.Policy) (service.Policy, error) {
+	switch {
+	case validation.IsNil(repo):
+		return nil, fmt.Errorf("Policy repository is nil")
+	}
+
+	policy, err := service.NewPolicy(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
 func NewObjectStorageService(repo repository.ObjectStorage) (service.ObjectStorage, error) {
 	switch {
 	case validation.IsNil(repo):
@@ -75,3 +173,73 @@ func NewObjectStorageService(repo repository.ObjectStorage) (service.ObjectStora
 
 	return objectStorage, nil
 }
+
+// NewScrubber returns nil when scrub is disabled.
+func NewScrubber(repo repository.ObjectStorage, scrubConfig config.Scrub) (service.Scrubber, error) {
+	switch {
+	case validation.IsNil(repo):
+		return nil, fmt.Errorf("ObjectStorage repository is nil")
+	}
+
+	if !scrubConfig.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig, err := NewRPCClientTLSConfig(scrubConfig.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make([]object.StorageNode, 0, len(scrubConfig.Peers))
+	for _, peer := range scrubConfig.Peers {
+		requestor, err := NewBlockStorageRequestor(peer.Host, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		peers = append(peers, object.StorageNode{
+			Node:      entity.Node{Host: peer.Host},
+			Requestor: requestor,
+		})
+	}
+
+	return service.NewScrubber(repo, peers, scrubConfig.BytesPerSecondOrDefault(), scrubConfig.Quarantine)
+}
+
+// NewVerifier returns nil when authentication is disabled.
+func NewVerifier(metadataRequestor rpc.MetadataRegistryRequestor, authConfig config.Auth) (*auth.Verifier, error) {
+	switch {
+	case validation.IsNil(metadataRequestor):
+		return nil, fmt.Errorf("MetadataRegistry requestor is nil")
+	}
+
+	if !authConfig.Enabled {
+		return nil, nil
+	}
+
+	provider, err := service.NewCredentialProvider(metadataRequestor)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewVerifier(provider, authConfig.Region, authConfig.MaxClockSkewOrDefault())
+}
+
+// NewPresigner returns nil when presigned URLs are disabled.
+// NewKMS returns nil when encryption is disabled.
+func NewKMS(encryptionConfig config.Encryption) (kms.KMS, error) {
+	if !encryptionConfig.Enabled {
+		return nil, nil
+	}
+	return kms.NewLocalKMS(encryptionConfig.KeyFile)
+}
+
+func NewPresigner(presignConfig config.Presign) (*auth.Presigner, error) {
+	if !presignConfig.Enabled {
+		return nil, nil
+	}
+
+	return auth.NewPresigner(
+		presignConfig.Key, presignConfig.ExpiryOrDefault(), presignConfig.MaxExpiryOrDefault(),
+	)
+}
@@ -0,0 +1,116 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	soserror "github.com/ISSuh/sos/internal/error"
+	"github.com/ISSuh/sos/internal/validation"
+)
+
+const (
+	ETagHeader            = "ETag"
+	LastModifiedHeader    = "Last-Modified"
+	IfMatchHeader         = "If-Match"
+	IfNoneMatchHeader     = "If-None-Match"
+	IfModifiedSinceHeader = "If-Modified-Since"
+
+	anyETag     = "*"
+	weakPrefix  = "W/"
+	etagQuote   = "\""
+	etagDivider = ","
+)
+
+var ErrNotModified = errors.New("not modified")
+
+// QuoteETag returns the entity tag as it is sent in the ETag header.
+func QuoteETag(etag string) string {
+	return etagQuote + etag + etagQuote
+}
+
+// CheckReadPreconditions evaluates the conditional headers of a GET against the current version,
+// in the order of RFC 9110. If-Modified-Since is ignored when If-None-Match is present.
+func CheckReadPreconditions(ifMatch, ifNoneMatch, ifModifiedSince, etag string, modifiedAt time.Time) error {
+	if !validation.IsEmpty(ifMatch) && !etagMatch(ifMatch, etag, true, false) {
+		return soserror.NewPreconditionFailedError(errors.New("If-Match precondition failed"))
+	}
+
+	if !validation.IsEmpty(ifNoneMatch) {
+		if etagMatch(ifNoneMatch, etag, true, true) {
+			return ErrNotModified
+		}
+		return nil
+	}
+
+	if !validation.IsEmpty(ifModifiedSince) {
+		t, err := http.ParseTime(ifModifiedSince)
+		if err == nil && !modifiedAt.Truncate(time.Second).After(t) {
+			return ErrNotModified
+		}
+	}
+	return nil
+}
+
+// CheckWritePreconditions evaluates the conditional headers of a PUT or DELETE against the current
+// version, which does not exist when exist is false. "If-None-Match: *" only creates an object.
+func CheckWritePreconditions(ifMatch, ifNoneMatch, etag string, exist bool) error {
+	if !validation.IsEmpty(ifMatch) && !etagMatch(ifMatch, etag, exist, false) {
+		return soserror.NewPreconditionFailedError(errors.New("If-Match precondition failed"))
+	}
+
+	if !validation.IsEmpty(ifNoneMatch) && etagMatch(ifNoneMatch, etag, exist, true) {
+		return soserror.NewPreconditionFailedError(errors.New("If-None-Match precondition failed"))
+	}
+	return nil
+}
+
+// etagMatch reports whether the entity tag is in the list of an If-Match or If-None-Match header.
+// Weak entity tags only match with the weak comparison of If-None-Match.
+func etagMatch(list, etag string, exist, weak bool) bool {
+	if !exist {
+		return false
+	}
+
+	for _, tag := range strings.Split(list, etagDivider) {
+		tag = strings.TrimSpace(tag)
+		if tag == anyETag {
+			return true
+		}
+
+		if strings.HasPrefix(tag, weakPrefix) {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, weakPrefix)
+		}
+
+		if tag == QuoteETag(etag) {
+			return true
+		}
+	}
+	return false
+}
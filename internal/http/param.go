@@ -39,6 +39,16 @@ const (
 	ObjectSizeName      = "size"
 	ChunkSizeName       = "chunk_size"
 	VersionName         = "version"
+	UploadIDParamName   = "uploadID"
+	PartNumberParamName = "partNumber"
+	LimitName           = "limit"
+	CursorName          = "cursor"
+	MethodName          = "method"
+	ObjectIDName        = "object_id"
+	ExpiresName         = "expires"
+	DryRunName          = "dry_run"
+	TagName             = "tag"
+	AllVersionsName     = "all_versions"
 
 	GroupParamContextKey ParamContextKey = GroupParamName
 	PartitionContextKey  ParamContextKey = PartitionParamName
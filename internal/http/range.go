@@ -0,0 +1,232 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ISSuh/sos/internal/validation"
+)
+
+const (
+	RangeHeader   = "Range"
+	IfRangeHeader = "If-Range"
+
+	byteRangeUnit = "bytes="
+)
+
+var (
+	ErrInvalidRange        = errors.New("invalid range")
+	ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+)
+
+type Ranges []Range
+
+func (r Ranges) Size() int {
+	size := 0
+	for _, item := range r {
+		size += item.Length
+	}
+	return size
+}
+
+type Range struct {
+	Start  int
+	Length int
+}
+
+func (r Range) End() int {
+	return r.Start + r.Length - 1
+}
+
+func (r Range) ContentRange(size int) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.End(), size)
+}
+
+// ParseRange parses a "bytes=" Range header value against an object of the given size.
+// ranges that start beyond the end of the object are dropped, and ErrRangeNotSatisfiable
+// is returned when none of the requested ranges overlap the object.
+func ParseRange(header string, size int) (Ranges, error) {
+	if validation.IsEmpty(header) {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(header, byteRangeUnit) {
+		return nil, ErrInvalidRange
+	}
+
+	var ranges Ranges
+	noOverlap := false
+	for _, spec := range strings.Split(header[len(byteRangeUnit):], ",") {
+		spec = strings.TrimSpace(spec)
+		if validation.IsEmpty(spec) {
+			continue
+		}
+
+		startStr, endStr, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, ErrInvalidRange
+		}
+
+		startStr = strings.TrimSpace(startStr)
+		endStr = strings.TrimSpace(endStr)
+
+		var r Range
+		if validation.IsEmpty(startStr) {
+			// suffix range. "bytes=-N" means the last N bytes
+			suffix, err := strconv.Atoi(endStr)
+			if err != nil || suffix < 0 {
+				return nil, ErrInvalidRange
+			}
+
+			if suffix > size {
+				suffix = size
+			}
+
+			r.Start = size - suffix
+			r.Length = suffix
+		} else {
+			start, err := strconv.Atoi(startStr)
+			if err != nil || start < 0 {
+				return nil, ErrInvalidRange
+			}
+
+			if start >= size {
+				noOverlap = true
+				continue
+			}
+
+			r.Start = start
+			r.Length = size - start
+
+			if !validation.IsEmpty(endStr) {
+				end, err := strconv.Atoi(endStr)
+				if err != nil || start > end {
+					return nil, ErrInvalidRange
+				}
+
+				if end < size-1 {
+					r.Length = end - start + 1
+				}
+			}
+		}
+
+		if r.Length <= 0 {
+			noOverlap = true
+			continue
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if noOverlap && len(ranges) == 0 {
+		return nil, ErrRangeNotSatisfiable
+	}
+	return ranges, nil
+}
+
+// IfRangeMatch reports whether a Range request should be honored for the If-Range value.
+// An entity tag matches with the strong comparison only.
+func IfRangeMatch(ifRange, etag string, modifiedAt time.Time) bool {
+	if validation.IsEmpty(ifRange) {
+		return true
+	}
+
+	if strings.HasPrefix(ifRange, etagQuote) || strings.HasPrefix(ifRange, weakPrefix) {
+		return ifRange == QuoteETag(etag)
+	}
+
+	t, err := http.ParseTime(ifRange)
+	if err != nil {
+		return false
+	}
+	return modifiedAt.Truncate(time.Second).Equal(t)
+}
+
+type ByteRangesWriter struct {
+	writer      *multipart.Writer
+	contentType string
+}
+
+func NewByteRangesWriter(w io.Writer, contentType string) *ByteRangesWriter {
+	return &ByteRangesWriter{
+		writer:      multipart.NewWriter(w),
+		contentType: contentType,
+	}
+}
+
+// SetContentType sets the content type of the parts, which is the type of the whole object.
+func (b *ByteRangesWriter) SetContentType(contentType string) {
+	b.contentType = contentType
+}
+
+func (b *ByteRangesWriter) ContentType() string {
+	return "multipart/byteranges; boundary=" + b.writer.Boundary()
+}
+
+func (b *ByteRangesWriter) ContentLength(ranges Ranges, size int) int {
+	counter := &countingWriter{}
+	dryRun := multipart.NewWriter(counter)
+	dryRun.SetBoundary(b.writer.Boundary())
+
+	for _, r := range ranges {
+		dryRun.CreatePart(b.partHeader(r, size))
+		counter.size += r.Length
+	}
+	dryRun.Close()
+
+	return counter.size
+}
+
+func (b *ByteRangesWriter) WritePart(r Range, size int) error {
+	_, err := b.writer.CreatePart(b.partHeader(r, size))
+	return err
+}
+
+func (b *ByteRangesWriter) Close() error {
+	return b.writer.Close()
+}
+
+func (b *ByteRangesWriter) partHeader(r Range, size int) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		"Content-Range": {r.ContentRange(size)},
+		"Content-Type":  {b.contentType},
+	}
+}
+
+type countingWriter struct {
+	size int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.size += len(p)
+	return len(p), nil
+}
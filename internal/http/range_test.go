@@ -0,0 +1,220 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package http
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		size   int
+		ranges Ranges
+		err    error
+	}{
+		{name: "no range", header: "", size: 100},
+		{name: "first bytes", header: "bytes=0-9", size: 100, ranges: Ranges{{Start: 0, Length: 10}}},
+		{name: "single byte", header: "bytes=5-5", size: 100, ranges: Ranges{{Start: 5, Length: 1}}},
+		{name: "last byte", header: "bytes=99-99", size: 100, ranges: Ranges{{Start: 99, Length: 1}}},
+		{name: "open ended", header: "bytes=90-", size: 100, ranges: Ranges{{Start: 90, Length: 10}}},
+		{name: "open ended from the start", header: "bytes=0-", size: 100, ranges: Ranges{{Start: 0, Length: 100}}},
+		{name: "end beyond the object", header: "bytes=90-200", size: 100, ranges: Ranges{{Start: 90, Length: 10}}},
+		{name: "suffix", header: "bytes=-10", size: 100, ranges: Ranges{{Start: 90, Length: 10}}},
+		{name: "suffix of the whole object", header: "bytes=-100", size: 100, ranges: Ranges{{Start: 0, Length: 100}}},
+		{name: "suffix longer than the object", header: "bytes=-500", size: 100, ranges: Ranges{{Start: 0, Length: 100}}},
+		{
+			name:   "multiple ranges",
+			header: "bytes=0-9, 20-29,-5",
+			size:   100,
+			ranges: Ranges{{Start: 0, Length: 10}, {Start: 20, Length: 10}, {Start: 95, Length: 5}},
+		},
+		{name: "spaces around the bounds", header: "bytes= 1 - 2 ", size: 100, ranges: Ranges{{Start: 1, Length: 2}}},
+		{name: "empty specs are skipped", header: "bytes=0-1,,", size: 100, ranges: Ranges{{Start: 0, Length: 2}}},
+		{name: "range beyond the object is dropped", header: "bytes=0-1,200-300", size: 100, ranges: Ranges{{Start: 0, Length: 2}}},
+		{name: "start at the size", header: "bytes=100-", size: 100, err: ErrRangeNotSatisfiable},
+		{name: "start beyond the size", header: "bytes=200-300", size: 100, err: ErrRangeNotSatisfiable},
+		{name: "empty suffix", header: "bytes=-0", size: 100, err: ErrRangeNotSatisfiable},
+		{name: "empty object", header: "bytes=0-", size: 0, err: ErrRangeNotSatisfiable},
+		{name: "suffix of an empty object", header: "bytes=-10", size: 0, err: ErrRangeNotSatisfiable},
+		{name: "other unit", header: "items=0-1", size: 100, err: ErrInvalidRange},
+		{name: "no dash", header: "bytes=10", size: 100, err: ErrInvalidRange},
+		{name: "end before start", header: "bytes=10-5", size: 100, err: ErrInvalidRange},
+		{name: "negative start", header: "bytes=--5", size: 100, err: ErrInvalidRange},
+		{name: "not a number", header: "bytes=a-b", size: 100, err: ErrInvalidRange},
+		{name: "malformed end", header: "bytes=0-x", size: 100, err: ErrInvalidRange},
+		{name: "malformed suffix", header: "bytes=-x", size: 100, err: ErrInvalidRange},
+		{name: "one malformed spec", header: "bytes=0-1,x-y", size: 100, err: ErrInvalidRange},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ranges, err := ParseRange(test.header, test.size)
+			if err != test.err {
+				t.Fatalf("error %v, want %v", err, test.err)
+			}
+			if !reflect.DeepEqual(ranges, test.ranges) {
+				t.Fatalf("ranges %+v, want %+v", ranges, test.ranges)
+			}
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		name         string
+		ranges       Ranges
+		size         int
+		end          int
+		contentRange string
+		total        int
+	}{
+		{name: "first byte", ranges: Ranges{{Start: 0, Length: 1}}, size: 10, end: 0, contentRange: "bytes 0-0/10", total: 1},
+		{name: "whole object", ranges: Ranges{{Start: 0, Length: 10}}, size: 10, end: 9, contentRange: "bytes 0-9/10", total: 10},
+		{
+			name:   "multiple ranges",
+			ranges: Ranges{{Start: 2, Length: 3}, {Start: 7, Length: 3}},
+			size:   10, end: 4, contentRange: "bytes 2-4/10", total: 6,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if end := test.ranges[0].End(); end != test.end {
+				t.Errorf("end %d, want %d", end, test.end)
+			}
+			if contentRange := test.ranges[0].ContentRange(test.size); contentRange != test.contentRange {
+				t.Errorf("content range %s, want %s", contentRange, test.contentRange)
+			}
+			if total := test.ranges.Size(); total != test.total {
+				t.Errorf("size %d, want %d", total, test.total)
+			}
+		})
+	}
+}
+
+func TestIfRangeMatch(t *testing.T) {
+	modifiedAt := time.Date(2024, 3, 1, 12, 30, 45, 500, time.UTC)
+
+	tests := []struct {
+		name    string
+		ifRange string
+		match   bool
+	}{
+		{name: "no if-range", ifRange: "", match: true},
+		{name: "same entity tag", ifRange: `"etag"`, match: true},
+		{name: "other entity tag", ifRange: `"other"`},
+		{name: "weak entity tag", ifRange: `W/"etag"`},
+		{name: "same date", ifRange: modifiedAt.Format(http.TimeFormat), match: true},
+		{name: "earlier date", ifRange: modifiedAt.Add(-time.Second).Format(http.TimeFormat)},
+		{name: "later date", ifRange: modifiedAt.Add(time.Second).Format(http.TimeFormat)},
+		{name: "malformed date", ifRange: "yesterday"},
+		{name: "unquoted entity tag", ifRange: "etag"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if match := IfRangeMatch(test.ifRange, "etag", modifiedAt); match != test.match {
+				t.Fatalf("match %t, want %t", match, test.match)
+			}
+		})
+	}
+}
+
+func TestByteRangesWriter(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+
+	tests := []struct {
+		name        string
+		ranges      Ranges
+		contentType string
+	}{
+		{name: "single range", ranges: Ranges{{Start: 0, Length: 5}}, contentType: "text/plain"},
+		{
+			name:        "multiple ranges",
+			ranges:      Ranges{{Start: 0, Length: 5}, {Start: 10, Length: 2}, {Start: 19, Length: 1}},
+			contentType: "application/octet-stream",
+		},
+		{name: "whole object", ranges: Ranges{{Start: 0, Length: len(data)}}, contentType: "image/png"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body bytes.Buffer
+			writer := NewByteRangesWriter(&body, "placeholder")
+			writer.SetContentType(test.contentType)
+			contentLength := writer.ContentLength(test.ranges, len(data))
+
+			for _, r := range test.ranges {
+				if err := writer.WritePart(r, len(data)); err != nil {
+					t.Fatalf("WritePart: %v", err)
+				}
+				body.Write(data[r.Start : r.End()+1])
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			if body.Len() != contentLength {
+				t.Errorf("body is %d bytes, content length %d", body.Len(), contentLength)
+			}
+
+			mediaType, params, err := mime.ParseMediaType(writer.ContentType())
+			if err != nil || mediaType != "multipart/byteranges" {
+				t.Fatalf("content type %s, %v", writer.ContentType(), err)
+			}
+
+			reader := multipart.NewReader(&body, params["boundary"])
+			for _, r := range test.ranges {
+				part, err := reader.NextPart()
+				if err != nil {
+					t.Fatalf("NextPart: %v", err)
+				}
+
+				if contentRange := part.Header.Get("Content-Range"); contentRange != r.ContentRange(len(data)) {
+					t.Errorf("content range %s, want %s", contentRange, r.ContentRange(len(data)))
+				}
+				if contentType := part.Header.Get("Content-Type"); contentType != test.contentType {
+					t.Errorf("content type %s, want %s", contentType, test.contentType)
+				}
+
+				content, err := io.ReadAll(part)
+				if err != nil || !bytes.Equal(content, data[r.Start:r.End()+1]) {
+					t.Errorf("part %q, %v, want %q", content, err, data[r.Start:r.End()+1])
+				}
+			}
+
+			if _, err := reader.NextPart(); err != io.EOF {
+				t.Errorf("parts after the ranges, %v", err)
+			}
+		})
+	}
+}
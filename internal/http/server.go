@@ -35,7 +35,8 @@ type Server struct {
 
 func NewServer() Server {
 	return Server{
-		router: mux.NewRouter(),
+		// object paths may hold encoded slashes, e.g. a%2Fb for the directory a/b
+		router: mux.NewRouter().UseEncodedPath(),
 	}
 }
 
@@ -71,3 +72,7 @@ func (s *Server) MuxAll(routeList RouteList) {
 func (s *Server) Run(address string) error {
 	return http.ListenAndServe(address, s.router)
 }
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
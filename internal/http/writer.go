@@ -22,10 +22,36 @@
 
 package http
 
+import "time"
+
+// ObjectHeader describes the version of an object a response is about.
+type ObjectHeader struct {
+	Version         int
+	ETag            string
+	ModifiedAt      time.Time
+	ContentType     string
+	ContentEncoding string
+	CacheControl    string
+	UserMetadata    map[string]string
+}
+
+type DownloadObjectHeaderWriter func(header ObjectHeader)
+type DownloadNotModifiedWriter func()
 type DownloadHeaderWriter func(name string, size int)
+type DownloadRangeHeaderWriter func(name string, size int, ranges Ranges)
+type DownloadPartWriter func(r Range, size int) error
 type DownloadBodyWriter func(buffer []byte) error
+type DownloadCloseWriter func() error
+type DownloadUnsatisfiableWriter func(size int)
 
+// Writer writes a download. ObjectHeader is written first, before any status.
 type Writer struct {
-	Header DownloadHeaderWriter
-	Body   DownloadBodyWriter
+	ObjectHeader  DownloadObjectHeaderWriter
+	NotModified   DownloadNotModifiedWriter
+	Header        DownloadHeaderWriter
+	RangeHeader   DownloadRangeHeaderWriter
+	Part          DownloadPartWriter
+	Body          DownloadBodyWriter
+	Close         DownloadCloseWriter
+	Unsatisfiable DownloadUnsatisfiableWriter
 }
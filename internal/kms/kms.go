@@ -0,0 +1,162 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kms
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ISSuh/sos/internal/encryption"
+	"gopkg.in/yaml.v2"
+)
+
+var ErrUnknownKey = errors.New("master key is unknown")
+
+// KMS wraps the data keys with master keys that never leave it. A data key stays readable as
+// long as the master key it was wrapped with is known, so a retired master key is kept until
+// every data key is wrapped again with the primary one.
+type KMS interface {
+	// PrimaryKeyID is the master key new data keys are wrapped with.
+	PrimaryKeyID() string
+
+	// GenerateDataKey returns a new data key in plain and wrapped by the primary master key.
+	GenerateDataKey() (dataKey []byte, keyID string, wrapped []byte, err error)
+
+	Wrap(dataKey []byte) (keyID string, wrapped []byte, err error)
+	Unwrap(keyID string, wrapped []byte) ([]byte, error)
+
+	// Reload reads the master keys again, e.g. after a new primary key was added.
+	Reload() error
+}
+
+// keyFile lists the base64 encoded 256 bit master keys by id.
+//
+//	primary: k2
+//	keys:
+//	  k1: <base64>
+//	  k2: <base64>
+type keyFile struct {
+	Primary string            `yaml:"primary"`
+	Keys    map[string]string `yaml:"keys"`
+}
+
+type localKMS struct {
+	path string
+
+	mutex   sync.RWMutex
+	primary string
+	keys    map[string][]byte
+}
+
+// NewLocalKMS keeps the master keys in a key file, which is better protected than the data.
+func NewLocalKMS(path string) (KMS, error) {
+	k := &localKMS{
+		path: path,
+	}
+
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (k *localKMS) PrimaryKeyID() string {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	return k.primary
+}
+
+func (k *localKMS) GenerateDataKey() ([]byte, string, []byte, error) {
+	dataKey, err := encryption.NewKey()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	keyID, wrapped, err := k.Wrap(dataKey)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return dataKey, keyID, wrapped, nil
+}
+
+func (k *localKMS) Wrap(dataKey []byte) (string, []byte, error) {
+	k.mutex.RLock()
+	keyID := k.primary
+	masterKey := k.keys[keyID]
+	k.mutex.RUnlock()
+
+	wrapped, err := encryption.Seal(masterKey, dataKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return keyID, wrapped, nil
+}
+
+func (k *localKMS) Unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	k.mutex.RLock()
+	masterKey, exist := k.keys[keyID]
+	k.mutex.RUnlock()
+
+	if !exist {
+		return nil, fmt.Errorf("%w. %s", ErrUnknownKey, keyID)
+	}
+	return encryption.Open(masterKey, wrapped)
+}
+
+func (k *localKMS) Reload() error {
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return err
+	}
+
+	var file keyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("master key %s is invalid. %w", id, err)
+		}
+
+		if len(key) != encryption.KeySize {
+			return fmt.Errorf("master key %s is not %d bytes", id, encryption.KeySize)
+		}
+		keys[id] = key
+	}
+
+	if _, exist := keys[file.Primary]; !exist {
+		return fmt.Errorf("primary master key %s is not in the key file", file.Primary)
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.primary = file.Primary
+	k.keys = keys
+	return nil
+}
@@ -0,0 +1,129 @@
+// MIT License
+
+// Copyright (c) 2024 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package kms
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeKeyFile(t *testing.T, path, primary string, keys map[string][]byte) {
+	t.Helper()
+
+	var file strings.Builder
+	file.WriteString("primary: " + primary + "\nkeys:\n")
+	for id, key := range keys {
+		file.WriteString("  " + id + ": " + base64.StdEncoding.EncodeToString(key) + "\n")
+	}
+
+	if err := os.WriteFile(path, []byte(file.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocalKMSWrapAndRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	k1 := bytes.Repeat([]byte{1}, 32)
+	k2 := bytes.Repeat([]byte{2}, 32)
+	writeKeyFile(t, path, "k1", map[string][]byte{"k1": k1})
+
+	k, err := NewLocalKMS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataKey, keyID, wrapped, err := k.GenerateDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyID != "k1" || bytes.Contains(wrapped, dataKey) {
+		t.Fatalf("data key wrapped by %s, %x", keyID, wrapped)
+	}
+
+	unwrapped, err := k.Unwrap(keyID, wrapped)
+	if err != nil || !bytes.Equal(unwrapped, dataKey) {
+		t.Fatalf("unwrap: %x, %v", unwrapped, err)
+	}
+
+	// a new primary key wraps from the reload on, and the retired one still unwraps
+	writeKeyFile(t, path, "k2", map[string][]byte{"k1": k1, "k2": k2})
+	if err := k.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	rewrappedID, rewrapped, err := k.Wrap(unwrapped)
+	if err != nil || rewrappedID != "k2" || k.PrimaryKeyID() != "k2" {
+		t.Fatalf("wrap after reload: %s, %v", rewrappedID, err)
+	}
+	if unwrapped, err := k.Unwrap("k1", wrapped); err != nil || !bytes.Equal(unwrapped, dataKey) {
+		t.Errorf("unwrap by the retired key: %v", err)
+	}
+	if unwrapped, err := k.Unwrap("k2", rewrapped); err != nil || !bytes.Equal(unwrapped, dataKey) {
+		t.Errorf("unwrap by the primary key: %v", err)
+	}
+
+	// a data key is only unwrapped by the master key it was wrapped with
+	if _, err := k.Unwrap("k2", wrapped); err == nil {
+		t.Error("unwrap by another master key succeeded")
+	}
+	if _, err := k.Unwrap("k3", wrapped); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("unwrap by an unknown key: %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+// A key file that can not be used is rejected, and a failed reload keeps the keys read before.
+func TestLocalKMSInvalidKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	key := bytes.Repeat([]byte{1}, 32)
+
+	writeKeyFile(t, path, "k2", map[string][]byte{"k1": key})
+	if _, err := NewLocalKMS(path); err == nil {
+		t.Error("primary key missing from the file is accepted")
+	}
+
+	writeKeyFile(t, path, "k1", map[string][]byte{"k1": key[:16]})
+	if _, err := NewLocalKMS(path); err == nil {
+		t.Error("short master key is accepted")
+	}
+
+	writeKeyFile(t, path, "k1", map[string][]byte{"k1": key})
+	k, err := NewLocalKMS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("primary: k1\nkeys:\n  k1: not base64!\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Reload(); err == nil {
+		t.Error("malformed master key is accepted")
+	}
+
+	if _, _, err := k.Wrap(key); err != nil || k.PrimaryKeyID() != "k1" {
+		t.Errorf("wrap after a failed reload: %v", err)
+	}
+}
@@ -23,10 +23,11 @@
 package log
 
 import (
+	"context"
+
 	"github.com/ISSuh/sos/internal/apm"
 	"github.com/ISSuh/sos/internal/config"
 	"go.elastic.co/apm/module/apmzap"
-	"golang.org/x/net/context"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
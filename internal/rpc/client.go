@@ -23,23 +23,40 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"fmt"
 
 	"github.com/ISSuh/sos/internal/apm"
 	"github.com/ISSuh/sos/internal/validation"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-func NewClientConnection(address string) (grpc.ClientConnInterface, error) {
+const (
+	// a block of the default 4MiB plus its header does not fit the grpc default of 4MiB on the unary calls,
+	// larger blocks are only transferred by the streaming calls
+	MaxMessageSize = 8 * 1024 * 1024
+)
+
+// NewClientConnection connects in plaintext when tlsConfig is nil.
+func NewClientConnection(address string, tlsConfig *tls.Config) (grpc.ClientConnInterface, error) {
 	if validation.IsEmpty(address) {
 		return nil, fmt.Errorf("address is empty")
 	}
 
 	interceptor := apm.WrapClientInterceptor()
 	credential := grpc.WithTransportCredentials(insecure.NewCredentials())
-	conn, err := grpc.NewClient(address, credential, interceptor)
+	if tlsConfig != nil {
+		credential = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+	callOptions := grpc.WithDefaultCallOptions(
+		grpc.MaxCallRecvMsgSize(MaxMessageSize),
+		grpc.MaxCallSendMsgSize(MaxMessageSize),
+	)
+
+	conn, err := grpc.NewClient(address, credential, interceptor, callOptions)
 	if err != nil {
 		return nil, fmt.Errorf("did not connect: %v", err)
 	}
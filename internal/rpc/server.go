@@ -23,6 +23,7 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/ISSuh/sos/internal/validation"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 type Server struct {
@@ -37,11 +39,26 @@ type Server struct {
 	registers []RegisterFunc
 }
 
-func NewServer() Server {
-	interceptor := apm.WrapServerInterceptor()
+// NewServer listens in plaintext when tlsConfig is nil, and accepts calls from any client
+// when allowedClients is empty.
+func NewServer(tlsConfig *tls.Config, allowedClients []string) Server {
+	options := []grpc.ServerOption{
+		apm.WrapServerInterceptor(),
+		grpc.MaxRecvMsgSize(MaxMessageSize),
+		grpc.MaxSendMsgSize(MaxMessageSize),
+	}
+
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	if len(allowedClients) > 0 {
+		options = append(options, ClientIdentityInterceptors(allowedClients)...)
+	}
+
 	return Server{
 		engine: Engine{
-			Server: grpc.NewServer(interceptor),
+			Server: grpc.NewServer(options...),
 		},
 		registers: make([]RegisterFunc, 0),
 	}
@@ -63,6 +80,14 @@ func (s *Server) Run(address string) error {
 	if err != nil {
 		return err
 	}
+	return s.Serve(l)
+}
+
+// Serve accepts calls on l until l is closed.
+func (s *Server) Serve(l net.Listener) error {
+	if len(s.registers) == 0 {
+		return fmt.Errorf("register functions is empty")
+	}
 
 	for _, f := range s.registers {
 		f(&s.engine)